@@ -0,0 +1,50 @@
+// invite サーバーのコンソールからユーザーの招待トークンを発行する.
+// ローカル認証でログインできるユーザーがいない場合（パスワードを無効にした直後など）に、最初の所有者を招待するために使う.
+//
+//	go run cmd/invite/main.go -email owner@example.com                            # 登録済みのユーザーを招待し直す
+//	go run cmd/invite/main.go -org 1 -name "所有者" -email owner@example.com      # 組織の所有者として招待する
+//
+// 発行した招待トークンは POST /users/invitations/accept でパスワードを設定するときに使う
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb"
+)
+
+func main() {
+	organizationID := flag.Uint("org", 0, "organization ID to invite a new owner to (optional for registered users)")
+	name := flag.String("name", "", "name of the new owner")
+	email := flag.String("email", "", "email address of the user to invite (required)")
+	flag.Parse()
+
+	if *email == "" {
+		flag.Usage()
+		log.Fatal("-email is required")
+	}
+
+	db, err := rdb.NewDB()
+	if err != nil {
+		log.Fatalf("failed to connect to db: %v", err)
+	}
+
+	usecase := application.NewUserBootstrapUsecase(rdb.NewUserRepository(db), rdb.NewOrganizationRepository(db))
+	invitation, err := usecase.IssueInvitation(application.IssueInvitationDto{
+		OrganizationID: *organizationID,
+		Name:           *name,
+		Email:          *email,
+		Now:            time.Now(),
+	})
+	if err != nil {
+		log.Fatalf("failed to issue invitation: %v", err)
+	}
+
+	fmt.Printf("user:       %d %s <%s> (%s)\n", invitation.User.ID, invitation.User.Name, invitation.User.Email, invitation.User.Role)
+	fmt.Printf("token:      %s\n", invitation.Token)
+	fmt.Printf("expires at: %s\n", invitation.ExpiresAt.Format(time.RFC3339))
+}
@@ -6,13 +6,19 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/infrastructure/auth"
 	myHttp "github.com/take73/invoice-api-example/internal/infrastructure/http"
+	"github.com/take73/invoice-api-example/internal/infrastructure/payment"
 	"github.com/take73/invoice-api-example/internal/infrastructure/rdb"
+	"github.com/take73/invoice-api-example/internal/infrastructure/worker"
 	"github.com/take73/invoice-api-example/internal/shared/validation"
+	jose "gopkg.in/go-jose/go-jose.v2"
 	"gorm.io/gorm/logger"
 )
 
@@ -29,15 +35,59 @@ func main() {
 	clientRepo := rdb.NewClientRepository(db)
 	organizationRepo := rdb.NewOrganizationRepository(db)
 	taxRateRepo := rdb.NewTaxRateRepository(db)
-	invoiceUsecase := application.NewInvoiceUsecase(invoiceRepo, clientRepo, organizationRepo, taxRateRepo)
+	feeRateRepo := rdb.NewFeeRateRepository(db)
+	bankAccountRepo := rdb.NewClientBankAccountRepository(db)
+	userRepo := rdb.NewUserRepository(db)
+	apiKeyRepo := rdb.NewAPIKeyRepository(db)
+	idempotencyKeyRepo := rdb.NewIdempotencyKeyRepository(db)
+	invoiceUsecase := application.NewInvoiceUsecase(invoiceRepo, clientRepo, organizationRepo, userRepo, taxRateRepo, feeRateRepo)
+	taxRateUsecase := application.NewTaxRateUsecase(taxRateRepo, invoiceRepo)
+	clientUsecase := application.NewClientUsecase(clientRepo, userRepo)
+	bankAccountUsecase := application.NewClientBankAccountUsecase(bankAccountRepo, clientRepo, userRepo)
+	organizationUsecase := application.NewOrganizationUsecase(organizationRepo, userRepo, feeRateRepo)
+	userUsecase := application.NewUserUsecase(userRepo)
+	apiKeyUsecase := application.NewAPIKeyUsecase(apiKeyRepo, userRepo)
+	idempotencyUsecase := application.NewIdempotencyUsecase(idempotencyKeyRepo)
+
+	// ローカル認証. Auth0 を使う場合はトークンを発行しない
+	var authUsecase application.AuthUsecase
+	var jwks jose.JSONWebKeySet
+	if auth.Provider() == auth.ProviderLocal {
+		authConfig, err := auth.LoadLocalConfig()
+		if err != nil {
+			log.Fatalf("failed to load local auth config: %v", err)
+		}
+		tokenIssuer, err := auth.NewLocalIssuer(authConfig)
+		if err != nil {
+			log.Fatalf("failed to set up token issuer: %v", err)
+		}
+		authUsecase = application.NewAuthUsecase(userRepo, tokenIssuer)
+		jwks = authConfig.Key.JWKS()
+	}
 
 	e := echo.New()
 	e.Validator = validation.NewCustomValidator()
-	myHttp.RegisterRoutes(e, invoiceUsecase)
+	myHttp.RegisterRoutes(e, invoiceUsecase, taxRateUsecase, clientUsecase, bankAccountUsecase, organizationUsecase, userUsecase, apiKeyUsecase, idempotencyUsecase, authUsecase, jwks)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
+	// 支払処理ワーカー
+	var wg sync.WaitGroup
+	if os.Getenv("PAYMENT_WORKER_ENABLED") == "true" {
+		paymentGateway, err := payment.NewGatewayFromEnv()
+		if err != nil {
+			log.Fatalf("failed to set up payment gateway: %v", err)
+		}
+		paymentUsecase := application.NewPaymentUsecase(invoiceRepo, paymentGateway, paymentLeadDays(), paymentBatchSize)
+		paymentWorker := worker.NewPaymentWorker(paymentUsecase, paymentWorkerInterval())
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			paymentWorker.Run(ctx)
+		}(ctx)
+	}
+
 	// Start server
 	go func() {
 		if err := e.Start(":1323"); err != nil && err != http.ErrServerClosed {
@@ -51,4 +101,28 @@ func main() {
 	if err := e.Shutdown(ctx); err != nil {
 		e.Logger.Fatal(err)
 	}
+	// 処理中の支払が記録されるまで待つ
+	wg.Wait()
+}
+
+const (
+	defaultPaymentWorkerInterval = time.Minute
+	defaultPaymentLeadDays       = 0
+	paymentBatchSize             = 100
+)
+
+func paymentWorkerInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("PAYMENT_WORKER_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return defaultPaymentWorkerInterval
+	}
+	return interval
+}
+
+func paymentLeadDays() int {
+	days, err := strconv.Atoi(os.Getenv("PAYMENT_LEAD_DAYS"))
+	if err != nil || days < 0 {
+		return defaultPaymentLeadDays
+	}
+	return days
 }
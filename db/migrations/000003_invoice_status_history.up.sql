@@ -0,0 +1,11 @@
+-- 請求書ステータス変更履歴テーブル
+CREATE TABLE invoice_status_history (
+    invoice_status_history_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    invoice_id INT UNSIGNED NOT NULL,
+    from_status ENUM('pending', 'processing', 'paid', 'error') NOT NULL,
+    to_status ENUM('pending', 'processing', 'paid', 'error') NOT NULL,
+    changed_by VARCHAR(255) NOT NULL, -- 変更者（トークンのsubject等）
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoice(invoice_id) ON DELETE CASCADE,
+    INDEX idx_invoice_id_changed_at (invoice_id, changed_at)
+);
@@ -0,0 +1,11 @@
+-- organization_id の外部キー用インデックスが複合インデックスで代替されている場合があるため、先に単独のインデックスを作成する
+ALTER TABLE invoice
+    ADD INDEX idx_organization_id (organization_id);
+
+ALTER TABLE invoice
+    DROP INDEX idx_org_due_date,
+    DROP INDEX idx_org_issue_date,
+    DROP INDEX idx_org_total_amount,
+    DROP INDEX idx_org_created_at,
+    DROP INDEX idx_org_status_due_date,
+    DROP INDEX idx_org_client_due_date;
@@ -0,0 +1,9 @@
+-- 請求書一覧のキーセットページネーション・絞り込み用の複合インデックス
+-- 並び替えキーごとに (organization_id, 並び替えキー, invoice_id) の順で作成する
+ALTER TABLE invoice
+    ADD INDEX idx_org_due_date (organization_id, due_date, invoice_id),
+    ADD INDEX idx_org_issue_date (organization_id, issue_date, invoice_id),
+    ADD INDEX idx_org_total_amount (organization_id, total_amount, invoice_id),
+    ADD INDEX idx_org_created_at (organization_id, created_at, invoice_id),
+    ADD INDEX idx_org_status_due_date (organization_id, status, due_date),
+    ADD INDEX idx_org_client_due_date (organization_id, client_id, due_date);
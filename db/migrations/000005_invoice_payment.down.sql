@@ -0,0 +1,3 @@
+ALTER TABLE invoice DROP INDEX idx_status_due_date;
+
+DROP TABLE IF EXISTS invoice_payment;
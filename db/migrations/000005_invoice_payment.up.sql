@@ -0,0 +1,15 @@
+-- 請求書の支払処理記録テーブル
+CREATE TABLE invoice_payment (
+    invoice_payment_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    invoice_id INT UNSIGNED NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL, -- 振込金額
+    succeeded BOOLEAN NOT NULL,
+    transaction_id VARCHAR(255), -- 支払ゲートウェイの取引ID
+    response VARCHAR(1024), -- 支払ゲートウェイの応答
+    processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoice(invoice_id) ON DELETE CASCADE,
+    INDEX idx_invoice_id (invoice_id)
+);
+
+-- 支払期日が近い未処理の請求書を組織横断で取得するためのインデックス
+ALTER TABLE invoice ADD INDEX idx_status_due_date (status, due_date);
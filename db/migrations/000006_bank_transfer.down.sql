@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS organization_bank_account;
+
+UPDATE client_bank_account SET account_name = '取引先A口座名義' WHERE account_id = 1;
+UPDATE client_bank_account SET account_name = '取引先B口座名義' WHERE account_id = 2;
+UPDATE client_bank_account SET account_name = '取引先C口座名義' WHERE account_id = 3;
+
+ALTER TABLE client_bank_account
+    DROP COLUMN account_type,
+    DROP COLUMN branch_code,
+    DROP COLUMN bank_code;
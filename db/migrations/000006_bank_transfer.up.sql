@@ -0,0 +1,34 @@
+-- 全銀フォーマットの振込データ作成に必要な銀行コード・支店コード・預金種目を追加
+ALTER TABLE client_bank_account
+    ADD COLUMN bank_code CHAR(4) NOT NULL DEFAULT '' AFTER client_id, -- 金融機関コード
+    ADD COLUMN branch_code CHAR(3) NOT NULL DEFAULT '' AFTER bank_name, -- 支店コード
+    ADD COLUMN account_type ENUM('ordinary', 'checking') NOT NULL DEFAULT 'ordinary' AFTER branch_name; -- 預金種目（普通・当座）
+
+-- 初期データの口座にコードを設定し、口座名義をカナにする
+UPDATE client_bank_account SET bank_code = '0001', branch_code = '100', account_name = 'トリヒキサキエー' WHERE account_id = 1;
+UPDATE client_bank_account SET bank_code = '0005', branch_code = '050', account_name = 'トリヒキサキビー' WHERE account_id = 2;
+UPDATE client_bank_account SET bank_code = '0010', branch_code = '322', account_name = 'トリヒキサキシー' WHERE account_id = 3;
+
+-- 振込依頼人（請求元企業）の出金口座テーブル
+CREATE TABLE organization_bank_account (
+    organization_bank_account_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    organization_id INT UNSIGNED NOT NULL,
+    requester_code VARCHAR(10) NOT NULL, -- 振込依頼人コード（銀行との契約時に払い出される）
+    requester_name VARCHAR(40) NOT NULL, -- 振込依頼人名（カナ）
+    bank_code CHAR(4) NOT NULL,
+    bank_name VARCHAR(255) NOT NULL,
+    branch_code CHAR(3) NOT NULL,
+    branch_name VARCHAR(255) NOT NULL,
+    account_type ENUM('ordinary', 'checking') NOT NULL DEFAULT 'ordinary',
+    account_number VARCHAR(7) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organization(organization_id) ON DELETE CASCADE,
+    UNIQUE INDEX uq_organization_id (organization_id)
+);
+
+INSERT INTO organization_bank_account (
+    organization_id, requester_code, requester_name, bank_code, bank_name, branch_code, branch_name, account_type, account_number
+) VALUES
+    (1, '1234567890', 'カ)サンプル', '0001', 'ミズホ', '001', 'トウキヨウエイギヨウブ', 'ordinary', '7654321'),
+    (2, '2345678901', 'ユ)テスト', '0009', 'ミツイスミトモ', '216', 'シブヤ', 'checking', '8765432');
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS invoice_line_item;
+
+DELETE FROM tax_rate WHERE category = 'reduced';
+
+ALTER TABLE tax_rate DROP COLUMN category;
@@ -0,0 +1,24 @@
+-- 消費税率を税率区分（標準税率・軽減税率）ごとに管理する
+ALTER TABLE tax_rate
+    ADD COLUMN category ENUM('standard', 'reduced') NOT NULL DEFAULT 'standard' AFTER tax_rate_id; -- 税率区分
+
+INSERT INTO tax_rate (category, start_date, end_date, rate)
+VALUES
+    ('reduced', '2019-10-01', NULL, 0.08); -- 軽減税率8%（現在も有効）
+
+-- 請求書明細テーブル
+CREATE TABLE invoice_line_item (
+    invoice_line_item_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    invoice_id INT UNSIGNED NOT NULL,
+    line_number INT UNSIGNED NOT NULL, -- 明細の行番号（1から）
+    description VARCHAR(255) NOT NULL, -- 品目
+    quantity DECIMAL(10, 3) NOT NULL, -- 数量
+    unit_price DECIMAL(10, 2) NOT NULL, -- 単価（税抜）
+    amount DECIMAL(10, 2) NOT NULL, -- 金額（税抜）
+    tax_category ENUM('standard', 'reduced', 'exempt', 'non_taxable') NOT NULL, -- 税区分
+    tax_rate DECIMAL(5, 2) NOT NULL, -- 適用した消費税率
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoice(invoice_id) ON DELETE CASCADE,
+    UNIQUE INDEX uq_invoice_id_line_number (invoice_id, line_number)
+);
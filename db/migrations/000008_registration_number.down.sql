@@ -0,0 +1,7 @@
+ALTER TABLE client
+    DROP CHECK chk_client_registration_number,
+    DROP COLUMN registration_number;
+
+ALTER TABLE organization
+    DROP CHECK chk_organization_registration_number,
+    DROP COLUMN registration_number;
@@ -0,0 +1,11 @@
+-- 適格請求書発行事業者の登録番号（"T" + 13桁の数字）. 未登録の事業者はNULL
+ALTER TABLE organization
+    ADD COLUMN registration_number CHAR(14) NULL AFTER name,
+    ADD CONSTRAINT chk_organization_registration_number CHECK (registration_number REGEXP '^T[0-9]{13}$');
+
+ALTER TABLE client
+    ADD COLUMN registration_number CHAR(14) NULL AFTER name,
+    ADD CONSTRAINT chk_client_registration_number CHECK (registration_number REGEXP '^T[0-9]{13}$');
+
+UPDATE organization SET registration_number = 'T7123456789012' WHERE organization_id = 1;
+UPDATE client SET registration_number = 'T9234567890123' WHERE client_id = 1;
@@ -0,0 +1,5 @@
+ALTER TABLE invoice DROP COLUMN withholding_tax;
+
+ALTER TABLE client
+    DROP COLUMN withholding_category,
+    DROP COLUMN payee_type;
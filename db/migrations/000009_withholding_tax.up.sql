@@ -0,0 +1,8 @@
+-- 取引先の支払先区分と源泉徴収の対象区分
+ALTER TABLE client
+    ADD COLUMN payee_type ENUM('corporation', 'individual') NOT NULL DEFAULT 'corporation' AFTER address, -- 支払先区分（法人・個人）
+    ADD COLUMN withholding_category ENUM('none', 'remuneration') NOT NULL DEFAULT 'none' AFTER payee_type; -- 源泉徴収の対象区分（報酬・料金等）
+
+-- 源泉徴収税額. 取引先への振込金額は payment_amount - withholding_tax
+ALTER TABLE invoice
+    ADD COLUMN withholding_tax DECIMAL(10, 2) NOT NULL DEFAULT 0 AFTER total_amount;
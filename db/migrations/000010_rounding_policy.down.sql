@@ -0,0 +1 @@
+ALTER TABLE organization DROP COLUMN rounding_policy;
@@ -0,0 +1,9 @@
+-- 手数料・消費税の円未満の端数処理（floor: 切り捨て, ceil: 切り上げ, half_up: 四捨五入, bankers: 銀行丸め）
+ALTER TABLE organization
+    ADD COLUMN rounding_policy ENUM('floor', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'floor' AFTER address;
+
+-- 既存の請求書の手数料・消費税を円単位に揃える（従来は表示時に切り捨てていた）
+UPDATE invoice
+SET fee = TRUNCATE(fee, 0),
+    tax = TRUNCATE(tax, 0),
+    total_amount = payment_amount + TRUNCATE(fee, 0) + TRUNCATE(tax, 0);
@@ -0,0 +1,12 @@
+-- 小数点以下3桁以上の手数料率の請求書がある場合は、DECIMAL(5, 2) に戻すと切り捨てられるためロールバックを中止する.
+-- 既存の行が制約を満たさない場合は ADD CONSTRAINT が失敗し、以降の変更は行わない
+ALTER TABLE invoice
+    ADD CONSTRAINT chk_invoice_fee_rate_rollback CHECK (fee_rate IS NULL OR fee_rate = TRUNCATE(fee_rate, 2));
+
+ALTER TABLE invoice
+    DROP CHECK chk_invoice_fee_rate_rollback,
+    DROP FOREIGN KEY fk_invoice_fee_plan,
+    DROP COLUMN fee_plan_id,
+    MODIFY COLUMN fee_rate DECIMAL(5, 2);
+
+DROP TABLE IF EXISTS fee_plan;
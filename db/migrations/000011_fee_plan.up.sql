@@ -0,0 +1,26 @@
+-- 手数料プラン. organization_id がNULLのプランは全組織に適用する標準プラン
+-- 組織ごとのプラン（大口契約など）が適用期間内にあれば標準プランより優先する
+CREATE TABLE fee_plan (
+    fee_plan_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    organization_id INT UNSIGNED NULL, -- 適用する組織（NULLなら標準プラン）
+    name VARCHAR(255) NOT NULL, -- プラン名
+    rate DECIMAL(6, 4) NOT NULL, -- 手数料率（例: 0.0400 = 4%）
+    start_date DATE NOT NULL, -- 適用開始日
+    end_date DATE NULL, -- 適用終了日（NULLなら現在も有効）
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organization(organization_id) ON DELETE CASCADE,
+    INDEX idx_organization_start_date (organization_id, start_date),
+    CONSTRAINT chk_fee_plan_rate CHECK (rate >= 0 AND rate <= 1)
+);
+
+INSERT INTO fee_plan (organization_id, name, rate, start_date, end_date)
+VALUES
+    (NULL, '標準プラン', 0.04, '2000-01-01', NULL),
+    (1, '大口契約プラン', 0.03, '2025-01-01', NULL);
+
+-- 適用した手数料プラン. 手数料率は契約で小数点以下4桁まで指定できるようにする
+ALTER TABLE invoice
+    MODIFY COLUMN fee_rate DECIMAL(6, 4),
+    ADD COLUMN fee_plan_id INT UNSIGNED NULL AFTER fee_rate,
+    ADD CONSTRAINT fk_invoice_fee_plan FOREIGN KEY (fee_plan_id) REFERENCES fee_plan(fee_plan_id);
@@ -0,0 +1,7 @@
+-- organization_id の外部キー用インデックスが複合インデックスで代替されている場合があるため、先に単独のインデックスを作成する
+ALTER TABLE client
+    ADD INDEX idx_organization_id (organization_id);
+
+ALTER TABLE client
+    DROP INDEX idx_org_name,
+    DROP COLUMN archived_at;
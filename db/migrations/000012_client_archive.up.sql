@@ -0,0 +1,7 @@
+-- 取引先のアーカイブ. アーカイブした取引先には請求書を作成できない
+ALTER TABLE client
+    ADD COLUMN archived_at TIMESTAMP NULL DEFAULT NULL AFTER withholding_category; -- アーカイブした日時（NULLならアーカイブしていない）
+
+-- 取引先一覧のキーセットページネーション用の複合インデックス（取引先名・取引先IDの順）
+ALTER TABLE client
+    ADD INDEX idx_org_name (organization_id, name, client_id);
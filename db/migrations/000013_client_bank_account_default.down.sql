@@ -0,0 +1,4 @@
+ALTER TABLE client_bank_account
+    DROP INDEX uq_default_client_id,
+    DROP COLUMN default_client_id,
+    DROP COLUMN is_default;
@@ -0,0 +1,12 @@
+-- 支払に使う既定の振込先口座
+ALTER TABLE client_bank_account
+    ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT FALSE AFTER account_name, -- 既定の口座かどうか
+    -- 既定の口座が取引先ごとに1つになるよう、既定の口座だけ取引先IDを持つ生成列に一意制約を設ける
+    ADD COLUMN default_client_id INT UNSIGNED AS (IF(is_default, client_id, NULL)) STORED AFTER is_default,
+    ADD UNIQUE INDEX uq_default_client_id (default_client_id);
+
+-- これまで支払に使っていた最初に登録された口座を既定にする
+UPDATE client_bank_account a
+    JOIN (SELECT client_id, MIN(account_id) AS account_id FROM client_bank_account GROUP BY client_id) f
+        ON a.account_id = f.account_id
+SET a.is_default = TRUE;
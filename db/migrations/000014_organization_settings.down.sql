@@ -0,0 +1,5 @@
+ALTER TABLE invoice
+    DROP FOREIGN KEY fk_invoice_settings,
+    DROP COLUMN settings_version;
+
+DROP TABLE IF EXISTS organization_settings;
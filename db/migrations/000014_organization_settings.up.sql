@@ -0,0 +1,27 @@
+-- 組織の設定. 変更のたびに新しい版を追加し、過去の版は更新しない
+CREATE TABLE organization_settings (
+    organization_id INT UNSIGNED NOT NULL,
+    version INT UNSIGNED NOT NULL, -- 版数（1から始まる）
+    default_fee_plan_id INT UNSIGNED NULL, -- 組織ごとの契約プランがない期間に標準プランの代わりに適用する手数料プラン
+    rounding_policy ENUM('floor', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'floor', -- 手数料・消費税の端数処理
+    payment_terms_days SMALLINT UNSIGNED NOT NULL DEFAULT 30, -- 支払期日を省略した場合の発行日からの日数
+    notification_email VARCHAR(255) NULL, -- 通知先のメールアドレス
+    notify_on_paid BOOLEAN NOT NULL DEFAULT FALSE, -- 支払済みになったときに通知する
+    notify_on_payment_error BOOLEAN NOT NULL DEFAULT FALSE, -- 支払に失敗したときに通知する
+    notify_on_overdue BOOLEAN NOT NULL DEFAULT FALSE, -- 支払期日を過ぎたときに通知する
+    created_by VARCHAR(255) NOT NULL, -- 設定を変更した操作主体
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, version),
+    FOREIGN KEY (organization_id) REFERENCES organization(organization_id) ON DELETE CASCADE,
+    FOREIGN KEY (default_fee_plan_id) REFERENCES fee_plan(fee_plan_id)
+);
+
+-- 既存の組織の端数処理を最初の版にする
+INSERT INTO organization_settings (organization_id, version, rounding_policy, created_by)
+SELECT organization_id, 1, rounding_policy, 'migration' FROM organization;
+
+-- 請求書の作成時に適用した設定の版（既存の請求書はNULL）
+ALTER TABLE invoice
+    ADD COLUMN settings_version INT UNSIGNED NULL AFTER fee_plan_id,
+    ADD CONSTRAINT fk_invoice_settings FOREIGN KEY (organization_id, settings_version)
+        REFERENCES organization_settings(organization_id, version);
@@ -0,0 +1,9 @@
+-- 無効にした平文のパスワードは復元できないため、空文字に戻す
+UPDATE user SET password_hash = '' WHERE password_hash IS NULL;
+
+ALTER TABLE user
+    DROP INDEX uq_invitation_token_hash,
+    DROP COLUMN deactivated_at,
+    DROP COLUMN invitation_expires_at,
+    DROP COLUMN invitation_token_hash,
+    CHANGE COLUMN password_hash password VARCHAR(255) NOT NULL;
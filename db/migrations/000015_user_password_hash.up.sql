@@ -0,0 +1,10 @@
+-- パスワードはハッシュ（argon2id）だけを保存する. NULL はパスワード未設定（招待中）
+ALTER TABLE user
+    CHANGE COLUMN password password_hash VARCHAR(255) NULL,
+    ADD COLUMN invitation_token_hash CHAR(64) NULL AFTER password_hash, -- 招待トークンのSHA-256
+    ADD COLUMN invitation_expires_at TIMESTAMP NULL AFTER invitation_token_hash, -- 招待の有効期限
+    ADD COLUMN deactivated_at TIMESTAMP NULL AFTER invitation_expires_at, -- 無効化した日時
+    ADD UNIQUE INDEX uq_invitation_token_hash (invitation_token_hash);
+
+-- 平文で保存されていたパスワードは無効にする. 該当するユーザーは招待し直してパスワードを設定する
+UPDATE user SET password_hash = NULL WHERE password_hash NOT LIKE '$argon2id$%';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_role_history;
+
+ALTER TABLE user
+    DROP COLUMN role;
@@ -0,0 +1,21 @@
+-- 組織内のロール. 権限の割り当ては domain/model/role.go で定義する
+ALTER TABLE user
+    ADD COLUMN role ENUM('owner', 'admin', 'accountant', 'approver', 'viewer') NOT NULL DEFAULT 'viewer' AFTER email;
+
+-- これまでのユーザーは全ての操作ができたため、所有者にする
+UPDATE user SET role = 'owner';
+
+-- ロールの変更履歴. 招待時の割り当ても記録し、更新・削除はしない
+CREATE TABLE user_role_history (
+    user_role_history_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    from_role ENUM('owner', 'admin', 'accountant', 'approver', 'viewer') NULL, -- 変更前のロール（招待時はNULL）
+    to_role ENUM('owner', 'admin', 'accountant', 'approver', 'viewer') NOT NULL, -- 変更後のロール
+    changed_by VARCHAR(255) NOT NULL, -- ロールを変更した操作主体
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_changed_at (user_id, changed_at),
+    FOREIGN KEY (user_id) REFERENCES user(user_id) ON DELETE CASCADE
+);
+
+INSERT INTO user_role_history (user_id, to_role, changed_by)
+SELECT user_id, role, 'migration' FROM user;
@@ -0,0 +1,17 @@
+-- 組織のシステム連携用のAPIキー. キー自体は保存せず、SHA-256 だけを保存する
+CREATE TABLE api_key (
+    api_key_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    organization_id INT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    key_id CHAR(12) NOT NULL, -- キーの識別子. キーの先頭に平文で含め、検索に使う
+    key_hash CHAR(64) NOT NULL, -- キー全体のSHA-256（16進数）
+    scopes VARCHAR(1000) NOT NULL, -- 付与した権限（空白区切り）
+    expires_at DATETIME NULL, -- 有効期限（無期限の場合はNULL）
+    last_used_at DATETIME NULL, -- 最後に使われた日時（1分単位で記録）
+    revoked_at DATETIME NULL, -- 失効させた日時
+    created_by VARCHAR(255) NOT NULL, -- 発行した操作主体
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_key_id (key_id),
+    INDEX idx_organization_id (organization_id),
+    FOREIGN KEY (organization_id) REFERENCES organization(organization_id) ON DELETE CASCADE
+);
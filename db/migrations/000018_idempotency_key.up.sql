@@ -0,0 +1,14 @@
+-- Idempotency-Key ヘッダーによる再送の検出. 処理中は response_status が NULL で、応答を保存した後は期限まで同じ応答を返す
+CREATE TABLE idempotency_key (
+    idempotency_key_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    scope VARCHAR(255) NOT NULL, -- キーの名前空間（操作主体）
+    idempotency_key VARCHAR(255) NOT NULL, -- Idempotency-Key ヘッダーの値
+    request_hash CHAR(64) NOT NULL, -- メソッド・パス・ボディのSHA-256（16進数）
+    response_status SMALLINT UNSIGNED NULL, -- 保存した応答のステータスコード（処理中はNULL）
+    response_content_type VARCHAR(255) NULL,
+    response_body MEDIUMBLOB NULL,
+    expires_at DATETIME NOT NULL, -- 予約・応答の有効期限
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_scope_key (scope, idempotency_key),
+    INDEX idx_expires_at (expires_at)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE invoice
+    DROP INDEX idx_org_client_amount_issue_date;
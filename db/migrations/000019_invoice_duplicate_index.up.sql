@@ -0,0 +1,3 @@
+-- 重複の疑いがある請求書の検出用. 同じ取引先・同じ支払金額の請求書を発行日の範囲で絞り込む
+ALTER TABLE invoice
+    ADD INDEX idx_org_client_amount_issue_date (organization_id, client_id, payment_amount, issue_date);
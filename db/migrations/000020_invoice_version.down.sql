@@ -0,0 +1,2 @@
+ALTER TABLE invoice
+    DROP COLUMN version;
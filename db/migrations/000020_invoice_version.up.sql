@@ -0,0 +1,3 @@
+-- 楽観的排他制御用の版数. 作成時は1で、更新するたびに1増やす
+ALTER TABLE invoice
+    ADD COLUMN version INT UNSIGNED NOT NULL DEFAULT 1 AFTER status;
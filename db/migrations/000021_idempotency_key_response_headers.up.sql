@@ -0,0 +1,3 @@
+-- 保存した応答のヘッダー（ETag・Location など Content-Type 以外でハンドラーが設定したもの）. JSON で保存する
+ALTER TABLE idempotency_key
+    ADD COLUMN response_headers TEXT NULL AFTER response_content_type;
@@ -0,0 +1,4 @@
+ALTER TABLE invoice_payment
+    DROP FOREIGN KEY fk_invoice_payment_attempt,
+    DROP INDEX uk_invoice_payment_attempt,
+    DROP COLUMN attempt_id;
@@ -0,0 +1,6 @@
+-- 支払処理の試行（請求書を処理中にした変更履歴）ごとに支払結果を1件だけ記録する.
+-- 処理中のまま中断した試行を再開する際に、記録済みの支払結果を使い、支払ゲートウェイに同じ冪等キーで再送する
+ALTER TABLE invoice_payment
+    ADD COLUMN attempt_id INT UNSIGNED NULL AFTER invoice_id, -- 処理中にした変更履歴のID（消込で記録した支払結果はNULL）
+    ADD UNIQUE KEY uk_invoice_payment_attempt (attempt_id),
+    ADD CONSTRAINT fk_invoice_payment_attempt FOREIGN KEY (attempt_id) REFERENCES invoice_status_history(invoice_status_history_id);
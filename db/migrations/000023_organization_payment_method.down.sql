@@ -0,0 +1,5 @@
+ALTER TABLE organization
+    DROP COLUMN payment_method;
+
+ALTER TABLE organization_settings
+    DROP COLUMN payment_method;
@@ -0,0 +1,8 @@
+-- 請求書の支払方法（transfer_file: 振込ファイルの出力と入出金明細の消込, gateway: 支払処理ワーカーによる支払ゲートウェイへの依頼）.
+-- 既存の組織は振込ファイルとし、支払処理ワーカーの対象にするには設定で gateway に変更する
+ALTER TABLE organization_settings
+    ADD COLUMN payment_method ENUM('transfer_file', 'gateway') NOT NULL DEFAULT 'transfer_file' AFTER payment_terms_days;
+
+-- 最新の設定の値（支払処理ワーカーが組織横断で絞り込むため）
+ALTER TABLE organization
+    ADD COLUMN payment_method ENUM('transfer_file', 'gateway') NOT NULL DEFAULT 'transfer_file' AFTER rounding_policy;
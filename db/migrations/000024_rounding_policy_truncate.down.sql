@@ -0,0 +1,11 @@
+ALTER TABLE organization_settings
+    MODIFY COLUMN rounding_policy ENUM('floor', 'truncate', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'floor';
+UPDATE organization_settings SET rounding_policy = 'floor' WHERE rounding_policy = 'truncate';
+ALTER TABLE organization_settings
+    MODIFY COLUMN rounding_policy ENUM('floor', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'floor';
+
+ALTER TABLE organization
+    MODIFY COLUMN rounding_policy ENUM('floor', 'truncate', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'floor';
+UPDATE organization SET rounding_policy = 'floor' WHERE rounding_policy = 'truncate';
+ALTER TABLE organization
+    MODIFY COLUMN rounding_policy ENUM('floor', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'floor';
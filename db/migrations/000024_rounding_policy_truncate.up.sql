@@ -0,0 +1,13 @@
+-- 端数処理 floor は0に近い方へ丸める（TRUNCATE と同じ）ため、名前を truncate に変える.
+-- 過去の版の設定も値の意味は変わらないため、あわせて置き換える
+ALTER TABLE organization
+    MODIFY COLUMN rounding_policy ENUM('floor', 'truncate', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'truncate';
+UPDATE organization SET rounding_policy = 'truncate' WHERE rounding_policy = 'floor';
+ALTER TABLE organization
+    MODIFY COLUMN rounding_policy ENUM('truncate', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'truncate';
+
+ALTER TABLE organization_settings
+    MODIFY COLUMN rounding_policy ENUM('floor', 'truncate', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'truncate';
+UPDATE organization_settings SET rounding_policy = 'truncate' WHERE rounding_policy = 'floor';
+ALTER TABLE organization_settings
+    MODIFY COLUMN rounding_policy ENUM('truncate', 'ceil', 'half_up', 'bankers') NOT NULL DEFAULT 'truncate';
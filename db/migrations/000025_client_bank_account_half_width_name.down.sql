@@ -0,0 +1,3 @@
+UPDATE client_bank_account SET account_name = 'トリヒキサキエー' WHERE account_id = 1 AND account_name = 'ﾄﾘﾋｷｻｷｴ-';
+UPDATE client_bank_account SET account_name = 'トリヒキサキビー' WHERE account_id = 2 AND account_name = 'ﾄﾘﾋｷｻｷﾋﾞ-';
+UPDATE client_bank_account SET account_name = 'トリヒキサキシー' WHERE account_id = 3 AND account_name = 'ﾄﾘﾋｷｻｷｼ-';
@@ -0,0 +1,4 @@
+-- 初期データの口座名義は全角カナのため、登録時と同じく全銀フォーマットの半角カナに変換する
+UPDATE client_bank_account SET account_name = 'ﾄﾘﾋｷｻｷｴ-' WHERE account_id = 1 AND account_name = 'トリヒキサキエー';
+UPDATE client_bank_account SET account_name = 'ﾄﾘﾋｷｻｷﾋﾞ-' WHERE account_id = 2 AND account_name = 'トリヒキサキビー';
+UPDATE client_bank_account SET account_name = 'ﾄﾘﾋｷｻｷｼ-' WHERE account_id = 3 AND account_name = 'トリヒキサキシー';
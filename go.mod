@@ -14,6 +14,9 @@ require (
 	github.com/labstack/echo/v4 v4.13.0
 	github.com/shopspring/decimal v1.2.0
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/crypto v0.27.0
+	golang.org/x/text v0.18.0
+	gopkg.in/go-jose/go-jose.v2 v2.6.3
 	gorm.io/driver/mysql v1.5.7
 	gorm.io/gorm v1.25.12
 )
@@ -37,11 +40,8 @@ require (
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
 	go.uber.org/atomic v1.7.0 // indirect
-	golang.org/x/crypto v0.27.0 // indirect
 	golang.org/x/net v0.29.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
-	golang.org/x/text v0.18.0 // indirect
-	gopkg.in/go-jose/go-jose.v2 v2.6.3 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
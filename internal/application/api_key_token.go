@@ -0,0 +1,56 @@
+package application
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+// apiKeySecretLength APIキーのシークレットのバイト数
+const apiKeySecretLength = 32
+
+// newAPIKey APIキーを "iak_{識別子}_{シークレット}" の形式で生成する. キーは発行時に一度だけ返し、保存するのは識別子とハッシュだけとする
+func newAPIKey() (key, keyID, keyHash string, err error) {
+	id := make([]byte, model.APIKeyIDLength/2)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret := make([]byte, apiKeySecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	keyID = hex.EncodeToString(id)
+	key = model.APIKeyPrefix + keyID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, keyID, hashAPIKey(key), nil
+}
+
+// parseAPIKeyID APIキーから識別子を取り出す. 形式が正しくない場合は false を返す
+func parseAPIKeyID(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, model.APIKeyPrefix)
+	if !ok || len(rest) <= model.APIKeyIDLength+1 || rest[model.APIKeyIDLength] != '_' {
+		return "", false
+	}
+	keyID := rest[:model.APIKeyIDLength]
+	if _, err := hex.DecodeString(keyID); err != nil {
+		return "", false
+	}
+	return keyID, true
+}
+
+// hashAPIKey APIキーのSHA-256を16進数で返す
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// matchAPIKeyHash APIキーが保存したハッシュと一致するかどうか. 比較にかかる時間から推測されないよう定数時間で比較する
+func matchAPIKeyHash(key, keyHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(keyHash)) == 1
+}
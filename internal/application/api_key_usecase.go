@@ -0,0 +1,238 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// APIKeyUsecase 組織のシステム連携用のAPIキーの管理と認証. 管理は操作主体の所属組織のAPIキーに限る
+type APIKeyUsecase interface {
+	CreateAPIKey(dto CreateAPIKeyDto) (*IssuedAPIKeyDto, error)
+	ListAPIKeys(dto ListAPIKeysDto) ([]*APIKeyDto, error)
+	GetAPIKey(dto GetAPIKeyDto) (*APIKeyDto, error)
+	RevokeAPIKey(dto RevokeAPIKeyDto) (*APIKeyDto, error)
+	AuthenticateAPIKey(dto AuthenticateAPIKeyDto) (*Principal, error)
+}
+
+type apiKeyUsecase struct {
+	apiKeyRepo repository.APIKey
+	userRepo   repository.User
+}
+
+func NewAPIKeyUsecase(apiKeyRepo repository.APIKey, userRepo repository.User) APIKeyUsecase {
+	return &apiKeyUsecase{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+type CreateAPIKeyDto struct {
+	Principal Principal
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time // 無期限の場合はnil
+	Now       time.Time
+}
+
+type ListAPIKeysDto struct {
+	Principal Principal
+	Now       time.Time // 状態（有効期限切れ）の判定に使う
+}
+
+type GetAPIKeyDto struct {
+	Principal Principal
+	ID        uint
+	Now       time.Time // 状態（有効期限切れ）の判定に使う
+}
+
+type RevokeAPIKeyDto struct {
+	Principal Principal
+	ID        uint
+	Now       time.Time
+}
+
+// AuthenticateAPIKeyDto APIキーによる認証. キーで操作主体を確認するため操作主体は不要
+type AuthenticateAPIKeyDto struct {
+	Key string
+	Now time.Time
+}
+
+type APIKeyDto struct {
+	ID         uint
+	Name       string
+	KeyID      string // キーの識別子. キーの先頭部分で、どのキーかを見分けるために使う
+	Scopes     []string
+	Status     string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedBy  string
+	CreatedAt  time.Time
+}
+
+type IssuedAPIKeyDto struct {
+	APIKey APIKeyDto
+	Key    string // APIキー（この応答でのみ返す）
+}
+
+// CreateAPIKey APIキーを発行する. 操作主体がもたない権限は付与できない
+func (s *apiKeyUsecase) CreateAPIKey(dto CreateAPIKeyDto) (*IssuedAPIKeyDto, error) {
+	a, err := authorizeActor(s.userRepo, dto.Principal, model.PermissionWriteAPIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make([]model.Permission, len(dto.Scopes))
+	for i, scope := range dto.Scopes {
+		scopes[i] = model.Permission(scope)
+	}
+	key := &model.APIKey{
+		OrganizationID: a.organizationID,
+		Name:           dto.Name,
+		Scopes:         scopes,
+		ExpiresAt:      dto.ExpiresAt,
+		CreatedBy:      dto.Principal.Subject,
+	}
+	if err := key.Validate(dto.Now); err != nil {
+		return nil, err
+	}
+	for _, scope := range key.Scopes {
+		if !a.can(scope) {
+			return nil, commonErrors.ErrForbidden
+		}
+	}
+
+	secret, keyID, keyHash, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key.KeyID = keyID
+	key.KeyHash = keyHash
+
+	created, err := s.apiKeyRepo.Create(key)
+	if err != nil {
+		return nil, err
+	}
+	return &IssuedAPIKeyDto{APIKey: *apiKeyToDto(created, dto.Now), Key: secret}, nil
+}
+
+// ListAPIKeys 組織のAPIキーを失効済みも含めて取得する
+func (s *apiKeyUsecase) ListAPIKeys(dto ListAPIKeysDto) ([]*APIKeyDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadAPIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.apiKeyRepo.FindByOrganizationID(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*APIKeyDto, len(keys))
+	for i, key := range keys {
+		result[i] = apiKeyToDto(key, dto.Now)
+	}
+	return result, nil
+}
+
+func (s *apiKeyUsecase) GetAPIKey(dto GetAPIKeyDto) (*APIKeyDto, error) {
+	key, err := s.findAPIKey(dto.Principal, dto.ID, model.PermissionReadAPIKey)
+	if err != nil {
+		return nil, err
+	}
+	return apiKeyToDto(key, dto.Now), nil
+}
+
+// RevokeAPIKey APIキーを失効させる. 以降はそのキーで認証できない. 失効済みの場合は何もしない
+func (s *apiKeyUsecase) RevokeAPIKey(dto RevokeAPIKeyDto) (*APIKeyDto, error) {
+	key, err := s.findAPIKey(dto.Principal, dto.ID, model.PermissionWriteAPIKey)
+	if err != nil {
+		return nil, err
+	}
+	if key.Status(dto.Now) == model.APIKeyStatusRevoked {
+		return apiKeyToDto(key, dto.Now), nil
+	}
+
+	key.Revoke(dto.Now)
+	revoked, err := s.apiKeyRepo.Revoke(key)
+	if err != nil {
+		return nil, err
+	}
+	return apiKeyToDto(revoked, dto.Now), nil
+}
+
+// AuthenticateAPIKey APIキーを検証し、キーの組織・スコープをもつ操作主体を返す.
+// 存在しない・失効済み・有効期限切れのキーは、原因を区別せず ErrInvalidCredentials とする
+func (s *apiKeyUsecase) AuthenticateAPIKey(dto AuthenticateAPIKeyDto) (*Principal, error) {
+	keyID, ok := parseAPIKeyID(dto.Key)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	key, err := s.apiKeyRepo.GetByKeyID(keyID)
+	if err != nil {
+		if errors.Is(err, commonErrors.ErrNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if !matchAPIKeyHash(dto.Key, key.KeyHash) || key.Status(dto.Now) != model.APIKeyStatusActive {
+		return nil, ErrInvalidCredentials
+	}
+
+	if key.RecordUse(dto.Now) {
+		if err := s.apiKeyRepo.UpdateLastUsedAt(key.ID, dto.Now); err != nil {
+			return nil, err
+		}
+	}
+
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+	return &Principal{
+		Subject:        fmt.Sprintf("api_key|%d", key.ID),
+		OrganizationID: key.OrganizationID,
+		Scopes:         scopes,
+	}, nil
+}
+
+// findAPIKey 操作主体が権限をもつことを確認し、所属組織のAPIキーを取得する. 他組織のAPIキーは ErrNotFound とする
+func (s *apiKeyUsecase) findAPIKey(principal Principal, id uint, permission model.Permission) (*model.APIKey, error) {
+	organizationID, err := authorize(s.userRepo, principal, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.apiKeyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !key.BelongsTo(organizationID) {
+		return nil, commonErrors.ErrNotFound
+	}
+	return key, nil
+}
+
+func apiKeyToDto(key *model.APIKey, now time.Time) *APIKeyDto {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+	return &APIKeyDto{
+		ID:         key.ID,
+		Name:       key.Name,
+		KeyID:      key.KeyID,
+		Scopes:     scopes,
+		Status:     string(key.Status(now)),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+	}
+}
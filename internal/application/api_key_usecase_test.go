@@ -0,0 +1,246 @@
+package application_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+type inMemoryAPIKeyRepository struct {
+	keys           map[uint]*model.APIKey
+	lastUsedWrites int
+}
+
+func (r *inMemoryAPIKeyRepository) GetByID(id uint) (*model.APIKey, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, commonErrors.ErrNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (r *inMemoryAPIKeyRepository) GetByKeyID(keyID string) (*model.APIKey, error) {
+	for _, key := range r.keys {
+		if key.KeyID == keyID {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, commonErrors.ErrNotFound
+}
+
+func (r *inMemoryAPIKeyRepository) FindByOrganizationID(organizationID uint) ([]*model.APIKey, error) {
+	var keys []*model.APIKey
+	for id := uint(1); id <= uint(len(r.keys)); id++ {
+		if key, ok := r.keys[id]; ok && key.BelongsTo(organizationID) {
+			copied := *key
+			keys = append(keys, &copied)
+		}
+	}
+	return keys, nil
+}
+
+func (r *inMemoryAPIKeyRepository) Create(key *model.APIKey) (*model.APIKey, error) {
+	created := *key
+	created.ID = uint(len(r.keys) + 1)
+	r.keys[created.ID] = &created
+	copied := created
+	return &copied, nil
+}
+
+func (r *inMemoryAPIKeyRepository) Revoke(key *model.APIKey) (*model.APIKey, error) {
+	stored, ok := r.keys[key.ID]
+	if !ok || !stored.BelongsTo(key.OrganizationID) {
+		return nil, commonErrors.ErrNotFound
+	}
+	stored.RevokedAt = key.RevokedAt
+	copied := *stored
+	return &copied, nil
+}
+
+func (r *inMemoryAPIKeyRepository) UpdateLastUsedAt(id uint, at time.Time) error {
+	r.keys[id].LastUsedAt = &at
+	r.lastUsedWrites++
+	return nil
+}
+
+func newAPIKeyUsecaseForTest() (application.APIKeyUsecase, *inMemoryAPIKeyRepository) {
+	apiKeyRepo := &inMemoryAPIKeyRepository{keys: map[uint]*model.APIKey{}}
+	userRepo := newInMemoryUserRepository(
+		&model.User{ID: 1, OrganizationID: 1, Role: model.RoleAdmin},
+		&model.User{ID: 2, OrganizationID: 1, Role: model.RoleAccountant},
+		&model.User{ID: 3, OrganizationID: 2, Role: model.RoleOwner},
+	)
+	return application.NewAPIKeyUsecase(apiKeyRepo, userRepo), apiKeyRepo
+}
+
+func Test_APIKeyUsecase_IssueAndAuthenticate(t *testing.T) {
+	usecase, repo := newAPIKeyUsecaseForTest()
+	admin := application.Principal{Subject: "auth0|user1", UserID: 1}
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(30 * 24 * time.Hour)
+
+	issued, err := usecase.CreateAPIKey(application.CreateAPIKeyDto{
+		Principal: admin,
+		Name:      "会計システム連携",
+		Scopes:    []string{"read:invoice", "write:invoice"},
+		ExpiresAt: &expiresAt,
+		Now:       now,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(issued.Key, model.APIKeyPrefix+issued.APIKey.KeyID+"_") {
+		t.Errorf("key = %s, want prefix %s%s_", issued.Key, model.APIKeyPrefix, issued.APIKey.KeyID)
+	}
+	if issued.APIKey.Status != string(model.APIKeyStatusActive) || issued.APIKey.CreatedBy != "auth0|user1" {
+		t.Errorf("issued api key = %+v, want active key created by the user", issued.APIKey)
+	}
+	// キー自体は保存しない
+	if stored := repo.keys[issued.APIKey.ID]; stored.KeyHash == "" || strings.Contains(stored.KeyHash, issued.Key) {
+		t.Errorf("stored key hash = %q, want the hash of the key", stored.KeyHash)
+	}
+
+	// キーの組織・スコープをもつ操作主体として認証する
+	principal, err := usecase.AuthenticateAPIKey(application.AuthenticateAPIKeyDto{Key: issued.Key, Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := application.Principal{Subject: fmt.Sprintf("api_key|%d", issued.APIKey.ID), OrganizationID: 1, Scopes: []string{"read:invoice", "write:invoice"}}
+	if principal.Subject != want.Subject || principal.OrganizationID != want.OrganizationID || strings.Join(principal.Scopes, " ") != strings.Join(want.Scopes, " ") {
+		t.Errorf("principal = %+v, want %+v", principal, want)
+	}
+
+	// 使用日時は1分単位で記録する
+	if _, err := usecase.AuthenticateAPIKey(application.AuthenticateAPIKeyDto{Key: issued.Key, Now: now.Add(30 * time.Second)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := usecase.AuthenticateAPIKey(application.AuthenticateAPIKeyDto{Key: issued.Key, Now: now.Add(time.Minute)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastUsedWrites != 2 || !repo.keys[issued.APIKey.ID].LastUsedAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("last used writes = %d (lastUsedAt %v), want 2", repo.lastUsedWrites, repo.keys[issued.APIKey.ID].LastUsedAt)
+	}
+
+	// 有効期限を過ぎたキー・シークレットが違うキー・形式が違うキーは認証しない
+	for name, dto := range map[string]application.AuthenticateAPIKeyDto{
+		"有効期限切れ":    {Key: issued.Key, Now: expiresAt},
+		"シークレットが違う": {Key: issued.Key[:len(issued.Key)-1] + "x", Now: now},
+		"形式が違う":     {Key: "iak_invalid", Now: now},
+		"存在しない識別子":  {Key: model.APIKeyPrefix + "000000000000_secret", Now: now},
+	} {
+		if _, err := usecase.AuthenticateAPIKey(dto); !errors.Is(err, application.ErrInvalidCredentials) {
+			t.Errorf("%s: error = %v, want %v", name, err, application.ErrInvalidCredentials)
+		}
+	}
+
+	// 失効させたキーは認証しない
+	revoked, err := usecase.RevokeAPIKey(application.RevokeAPIKeyDto{Principal: admin, ID: issued.APIKey.ID, Now: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revoked.Status != string(model.APIKeyStatusRevoked) || revoked.RevokedAt == nil {
+		t.Errorf("revoked api key = %+v, want revoked", revoked)
+	}
+	if _, err := usecase.AuthenticateAPIKey(application.AuthenticateAPIKeyDto{Key: issued.Key, Now: now.Add(2 * time.Hour)}); !errors.Is(err, application.ErrInvalidCredentials) {
+		t.Errorf("error = %v, want %v", err, application.ErrInvalidCredentials)
+	}
+
+	// 失効済みのキーも一覧に含める. 他組織の一覧には含めない
+	keys, err := usecase.ListAPIKeys(application.ListAPIKeysDto{Principal: admin, Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Status != string(model.APIKeyStatusRevoked) {
+		t.Errorf("api keys = %+v, want the revoked key", keys)
+	}
+	keys, _ = usecase.ListAPIKeys(application.ListAPIKeysDto{Principal: application.Principal{UserID: 3}, Now: now})
+	if len(keys) != 0 {
+		t.Errorf("api keys of other organization = %d, want 0", len(keys))
+	}
+	if _, err := usecase.GetAPIKey(application.GetAPIKeyDto{Principal: application.Principal{UserID: 3}, ID: issued.APIKey.ID, Now: now}); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
+
+func Test_APIKeyUsecase_CreateAPIKey(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		dto     application.CreateAPIKeyDto
+		wantErr error
+	}{
+		{
+			name: "無期限のキー",
+			dto:  application.CreateAPIKeyDto{Principal: application.Principal{UserID: 1}, Name: "連携", Scopes: []string{"read:client"}},
+		},
+		{
+			name:    "権限をもたないロール",
+			dto:     application.CreateAPIKeyDto{Principal: application.Principal{UserID: 2}, Name: "連携", Scopes: []string{"read:invoice"}},
+			wantErr: commonErrors.ErrForbidden,
+		},
+		{
+			name: "操作主体がもたない権限を付与する",
+			dto: application.CreateAPIKeyDto{
+				Principal: application.Principal{OrganizationID: 1, Scopes: scopesOf(model.PermissionWriteAPIKey, model.PermissionReadInvoice)},
+				Name:      "連携",
+				Scopes:    []string{"read:invoice", "write:invoice"},
+			},
+			wantErr: commonErrors.ErrForbidden,
+		},
+		{
+			name:    "APIキーに付与できない権限",
+			dto:     application.CreateAPIKeyDto{Principal: application.Principal{UserID: 1}, Name: "連携", Scopes: []string{"write:api_key"}},
+			wantErr: model.ErrInvalidAPIKey,
+		},
+		{
+			name:    "定義されていない権限",
+			dto:     application.CreateAPIKeyDto{Principal: application.Principal{UserID: 1}, Name: "連携", Scopes: []string{"admin:tax_rate"}},
+			wantErr: model.ErrInvalidAPIKey,
+		},
+		{
+			name:    "権限を指定しない",
+			dto:     application.CreateAPIKeyDto{Principal: application.Principal{UserID: 1}, Name: "連携"},
+			wantErr: model.ErrInvalidAPIKey,
+		},
+		{
+			name:    "名前を指定しない",
+			dto:     application.CreateAPIKeyDto{Principal: application.Principal{UserID: 1}, Scopes: []string{"read:invoice"}},
+			wantErr: model.ErrInvalidAPIKey,
+		},
+		{
+			name:    "過去の有効期限",
+			dto:     application.CreateAPIKeyDto{Principal: application.Principal{UserID: 1}, Name: "連携", Scopes: []string{"read:invoice"}, ExpiresAt: &past},
+			wantErr: model.ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase, repo := newAPIKeyUsecaseForTest()
+			tt.dto.Now = now
+			issued, err := usecase.CreateAPIKey(tt.dto)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.keys) != 0 {
+					t.Errorf("api keys = %d, want none to be created", len(repo.keys))
+				}
+				return
+			}
+			if issued.APIKey.ExpiresAt != nil || issued.Key == "" {
+				t.Errorf("issued api key = %+v, want a key without expiry", issued)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/gateway"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/password"
+)
+
+// ErrInvalidCredentials メールアドレス・パスワード、またはリフレッシュトークンが不正.
+// ユーザーの存在を推測されないよう、原因は区別しない
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// userScopes ローカル認証でユーザーに付与するスコープ. ロールの権限と税率の参照（read:tax_rate）で、
+// 全組織に共通の税率の変更（admin:tax_rate）は含めない. 組織内の操作の権限はユースケースでロールから判定する
+func userScopes(role model.Role) []string {
+	scopes := []string{"read:tax_rate"}
+	for _, permission := range role.Permissions() {
+		scopes = append(scopes, string(permission))
+	}
+	return scopes
+}
+
+// AuthUsecase ローカル認証. ユーザーのメールアドレス・パスワードでトークンを発行する
+type AuthUsecase interface {
+	Login(dto LoginDto) (*TokenDto, error)
+	Refresh(dto RefreshTokenDto) (*TokenDto, error)
+}
+
+type authUsecase struct {
+	userRepo    repository.User
+	tokenIssuer gateway.TokenIssuer
+}
+
+func NewAuthUsecase(userRepo repository.User, tokenIssuer gateway.TokenIssuer) AuthUsecase {
+	return &authUsecase{
+		userRepo:    userRepo,
+		tokenIssuer: tokenIssuer,
+	}
+}
+
+type LoginDto struct {
+	Email    string
+	Password string
+	Now      time.Time
+}
+
+type RefreshTokenDto struct {
+	RefreshToken string
+	Now          time.Time
+}
+
+type TokenDto struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time // アクセストークンの有効期限
+	Scopes       []string
+}
+
+// Login パスワードを検証してトークンを発行する. 招待中・無効化したユーザーはログインできない
+func (s *authUsecase) Login(dto LoginDto) (*TokenDto, error) {
+	user, err := s.userRepo.GetByEmail(dto.Email)
+	if err != nil {
+		if errors.Is(err, commonErrors.ErrNotFound) {
+			// 応答時間でユーザーの存在を推測されないよう、存在しない場合もハッシュを計算する
+			_, _ = password.Hash(dto.Password)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if user.Status() != model.UserStatusActive {
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, err := password.Verify(user.PasswordHash, dto.Password)
+	if err != nil {
+		if errors.Is(err, password.ErrUnsupportedHash) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issue(user, dto.Now)
+}
+
+// Refresh リフレッシュトークンでトークンを再発行する. 所属組織はユーザーの現在の登録内容から求める
+func (s *authUsecase) Refresh(dto RefreshTokenDto) (*TokenDto, error) {
+	userID, err := s.tokenIssuer.VerifyRefreshToken(dto.RefreshToken, dto.Now)
+	if err != nil {
+		if errors.Is(err, gateway.ErrInvalidToken) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, commonErrors.ErrNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if user.Status() != model.UserStatusActive {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issue(user, dto.Now)
+}
+
+func (s *authUsecase) issue(user *model.User, now time.Time) (*TokenDto, error) {
+	scopes := userScopes(user.Role)
+	tokens, err := s.tokenIssuer.Issue(gateway.TokenClaims{
+		Subject:        fmt.Sprintf("local|%d", user.ID),
+		UserID:         user.ID,
+		OrganizationID: user.OrganizationID,
+		Scopes:         scopes,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenDto{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.AccessTokenExpiresAt,
+		Scopes:       scopes,
+	}, nil
+}
@@ -0,0 +1,121 @@
+package application_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/gateway"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/shared/password"
+)
+
+// fakeTokenIssuer クレームをそのまま文字列にしたトークンを発行する
+type fakeTokenIssuer struct{}
+
+func (fakeTokenIssuer) Issue(claims gateway.TokenClaims, now time.Time) (*gateway.IssuedTokens, error) {
+	return &gateway.IssuedTokens{
+		AccessToken:          fmt.Sprintf("access:%s:%d:%d", claims.Subject, claims.OrganizationID, len(claims.Scopes)),
+		AccessTokenExpiresAt: now.Add(15 * time.Minute),
+		RefreshToken:         fmt.Sprintf("refresh:%d", claims.UserID),
+	}, nil
+}
+
+func (fakeTokenIssuer) VerifyRefreshToken(token string, now time.Time) (uint, error) {
+	var userID uint
+	if _, err := fmt.Sscanf(token, "refresh:%d", &userID); err != nil {
+		return 0, gateway.ErrInvalidToken
+	}
+	return userID, nil
+}
+
+func newAuthUsecaseForTest(t *testing.T) (application.AuthUsecase, *inMemoryUserRepository) {
+	t.Helper()
+	hash, err := password.Hash("correct horse battery")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	deactivatedAt := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	userRepo := &inMemoryUserRepository{users: map[uint]*model.User{
+		1: {ID: 1, OrganizationID: 1, Name: "佐藤 一郎", Email: "ichiro.sato@example.com", Role: model.RoleAccountant, PasswordHash: hash},
+		2: {ID: 2, OrganizationID: 1, Name: "田中 二郎", Email: "jiro.tanaka@example.com", PasswordHash: hash, DeactivatedAt: &deactivatedAt},
+		3: {ID: 3, OrganizationID: 2, Name: "高橋 三郎", Email: "saburo.takahashi@example.com"},
+	}}
+	return application.NewAuthUsecase(userRepo, fakeTokenIssuer{}), userRepo
+}
+
+func Test_AuthUsecase_Login(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "success", email: "ichiro.sato@example.com", password: "correct horse battery"},
+		{name: "パスワードが違う", email: "ichiro.sato@example.com", password: "wrong password!", wantErr: application.ErrInvalidCredentials},
+		{name: "存在しないユーザー", email: "unknown@example.com", password: "correct horse battery", wantErr: application.ErrInvalidCredentials},
+		{name: "無効化したユーザー", email: "jiro.tanaka@example.com", password: "correct horse battery", wantErr: application.ErrInvalidCredentials},
+		{name: "招待中のユーザー", email: "saburo.takahashi@example.com", password: "", wantErr: application.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase, _ := newAuthUsecaseForTest(t)
+
+			got, err := usecase.Login(application.LoginDto{Email: tt.email, Password: tt.password, Now: now})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(got.AccessToken, "access:local|1:1:") || got.RefreshToken != "refresh:1" {
+				t.Errorf("tokens = %+v, want tokens for user 1 of organization 1", got)
+			}
+			if !got.ExpiresAt.Equal(now.Add(15*time.Minute)) || len(got.Scopes) == 0 {
+				t.Errorf("token = %+v, want expiry and scopes", got)
+			}
+			// ロールの権限と税率の参照だけを付与する
+			want := []string{"read:tax_rate", "write:invoice", "export:transfer_file", "write:client", "read:invoice", "read:client", "read:organization", "read:user"}
+			if diff := cmp.Diff(want, got.Scopes); diff != "" {
+				t.Errorf("scopes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_AuthUsecase_Refresh(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	usecase, userRepo := newAuthUsecaseForTest(t)
+
+	got, err := usecase.Refresh(application.RefreshTokenDto{RefreshToken: "refresh:1", Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.RefreshToken != "refresh:1" {
+		t.Errorf("refresh token = %s, want refresh:1", got.RefreshToken)
+	}
+
+	if _, err := usecase.Refresh(application.RefreshTokenDto{RefreshToken: "invalid", Now: now}); !errors.Is(err, application.ErrInvalidCredentials) {
+		t.Errorf("invalid token: error = %v, want %v", err, application.ErrInvalidCredentials)
+	}
+	if _, err := usecase.Refresh(application.RefreshTokenDto{RefreshToken: "refresh:99", Now: now}); !errors.Is(err, application.ErrInvalidCredentials) {
+		t.Errorf("unknown user: error = %v, want %v", err, application.ErrInvalidCredentials)
+	}
+
+	// 無効化したユーザーは発行済みのリフレッシュトークンでも再発行できない
+	userRepo.users[1].DeactivatedAt = &now
+	if _, err := usecase.Refresh(application.RefreshTokenDto{RefreshToken: "refresh:1", Now: now}); !errors.Is(err, application.ErrInvalidCredentials) {
+		t.Errorf("deactivated user: error = %v, want %v", err, application.ErrInvalidCredentials)
+	}
+}
@@ -0,0 +1,226 @@
+package application
+
+import (
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+	"github.com/take73/invoice-api-example/internal/shared/zengin"
+)
+
+// ClientBankAccountUsecase 取引先の振込先口座の管理. 操作主体の所属組織の取引先に限る
+type ClientBankAccountUsecase interface {
+	ListBankAccounts(dto ListBankAccountsDto) ([]ClientBankAccountDto, error)
+	GetBankAccount(dto GetBankAccountDto) (*ClientBankAccountDto, error)
+	CreateBankAccount(dto CreateBankAccountDto) (*ClientBankAccountDto, error)
+	UpdateBankAccount(dto UpdateBankAccountDto) (*ClientBankAccountDto, error)
+	DeleteBankAccount(dto DeleteBankAccountDto) error
+	SetDefaultBankAccount(dto SetDefaultBankAccountDto) (*ClientBankAccountDto, error)
+}
+
+type clientBankAccountUsecase struct {
+	bankAccountRepo repository.ClientBankAccount
+	clientRepo      repository.Client
+	userRepo        repository.User
+}
+
+func NewClientBankAccountUsecase(bankAccountRepo repository.ClientBankAccount, clientRepo repository.Client, userRepo repository.User) ClientBankAccountUsecase {
+	return &clientBankAccountUsecase{
+		bankAccountRepo: bankAccountRepo,
+		clientRepo:      clientRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// BankAccountInputDto 振込先口座の登録内容
+type BankAccountInputDto struct {
+	BankCode      string
+	BankName      string
+	BranchCode    string
+	BranchName    string
+	AccountType   string // ordinary: 普通, checking: 当座
+	AccountNumber string
+	AccountName   string // 口座名義（半角カナ）
+}
+
+type ListBankAccountsDto struct {
+	Principal Principal
+	ClientID  uint
+}
+
+type GetBankAccountDto struct {
+	Principal Principal
+	ClientID  uint
+	ID        uint
+}
+
+type CreateBankAccountDto struct {
+	Principal Principal
+	ClientID  uint
+	BankAccountInputDto
+	IsDefault bool // 既定の口座にする（取引先の最初の口座は指定しなくても既定になる）
+}
+
+type UpdateBankAccountDto struct {
+	Principal Principal
+	ClientID  uint
+	ID        uint
+	BankAccountInputDto
+}
+
+type DeleteBankAccountDto struct {
+	Principal Principal
+	ClientID  uint
+	ID        uint
+}
+
+type SetDefaultBankAccountDto struct {
+	Principal Principal
+	ClientID  uint
+	ID        uint
+}
+
+type ClientBankAccountDto struct {
+	ID                  uint
+	ClientID            uint
+	BankCode            string
+	BankName            string
+	BranchCode          string
+	BranchName          string
+	AccountType         string
+	MaskedAccountNumber string // 末尾以外をマスクした口座番号
+	AccountName         string
+	IsDefault           bool
+}
+
+func (s *clientBankAccountUsecase) ListBankAccounts(dto ListBankAccountsDto) ([]ClientBankAccountDto, error) {
+	if _, err := findOrganizationClient(s.clientRepo, s.userRepo, dto.Principal, dto.ClientID, model.PermissionReadClient); err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.bankAccountRepo.FindByClientID(dto.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ClientBankAccountDto, len(accounts))
+	for i, account := range accounts {
+		result[i] = *bankAccountToDto(account)
+	}
+	return result, nil
+}
+
+func (s *clientBankAccountUsecase) GetBankAccount(dto GetBankAccountDto) (*ClientBankAccountDto, error) {
+	if _, err := findOrganizationClient(s.clientRepo, s.userRepo, dto.Principal, dto.ClientID, model.PermissionReadClient); err != nil {
+		return nil, err
+	}
+
+	account, err := s.bankAccountRepo.GetByID(dto.ClientID, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	return bankAccountToDto(account), nil
+}
+
+func (s *clientBankAccountUsecase) CreateBankAccount(dto CreateBankAccountDto) (*ClientBankAccountDto, error) {
+	if err := s.checkEditable(dto.Principal, dto.ClientID); err != nil {
+		return nil, err
+	}
+
+	account := &model.ClientBankAccount{ClientID: dto.ClientID, IsDefault: dto.IsDefault}
+	applyBankAccountInput(account, dto.BankAccountInputDto)
+	if err := account.Validate(); err != nil {
+		return nil, err
+	}
+
+	created, err := s.bankAccountRepo.Create(account)
+	if err != nil {
+		return nil, err
+	}
+	return bankAccountToDto(created), nil
+}
+
+func (s *clientBankAccountUsecase) UpdateBankAccount(dto UpdateBankAccountDto) (*ClientBankAccountDto, error) {
+	if err := s.checkEditable(dto.Principal, dto.ClientID); err != nil {
+		return nil, err
+	}
+
+	account, err := s.bankAccountRepo.GetByID(dto.ClientID, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	applyBankAccountInput(account, dto.BankAccountInputDto)
+	if err := account.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.bankAccountRepo.Update(account)
+	if err != nil {
+		return nil, err
+	}
+	return bankAccountToDto(updated), nil
+}
+
+// DeleteBankAccount 振込先口座を削除する. 既定の口座を削除した場合は残りの最も古い口座が既定になる
+func (s *clientBankAccountUsecase) DeleteBankAccount(dto DeleteBankAccountDto) error {
+	if err := s.checkEditable(dto.Principal, dto.ClientID); err != nil {
+		return err
+	}
+	return s.bankAccountRepo.Delete(dto.ClientID, dto.ID)
+}
+
+// SetDefaultBankAccount 支払に使う既定の口座を変更する
+func (s *clientBankAccountUsecase) SetDefaultBankAccount(dto SetDefaultBankAccountDto) (*ClientBankAccountDto, error) {
+	if err := s.checkEditable(dto.Principal, dto.ClientID); err != nil {
+		return nil, err
+	}
+
+	account, err := s.bankAccountRepo.SetDefault(dto.ClientID, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	return bankAccountToDto(account), nil
+}
+
+// checkEditable 操作主体が取引先を変更でき、口座を変更できる取引先かどうか. アーカイブした取引先の口座は変更できない
+func (s *clientBankAccountUsecase) checkEditable(principal Principal, clientID uint) error {
+	client, err := findOrganizationClient(s.clientRepo, s.userRepo, principal, clientID, model.PermissionWriteClient)
+	if err != nil {
+		return err
+	}
+	if client.IsArchived() {
+		return model.ErrClientArchived
+	}
+	return nil
+}
+
+// applyBankAccountInput 入力を口座に反映する. 半角カナでない口座名義は全銀フォーマットの半角カナに変換し、
+// 変換できない場合はそのまま残して Validate でエラーにする
+func applyBankAccountInput(account *model.ClientBankAccount, input BankAccountInputDto) {
+	account.BankCode = input.BankCode
+	account.BankName = input.BankName
+	account.BranchCode = input.BranchCode
+	account.BranchName = input.BranchName
+	account.AccountType = model.AccountType(input.AccountType)
+	account.AccountNumber = input.AccountNumber
+	account.AccountName = input.AccountName
+	if !validation.ValidAccountName(input.AccountName) {
+		if name, err := zengin.ToHalfWidthKana(input.AccountName); err == nil {
+			account.AccountName = name
+		}
+	}
+}
+
+func bankAccountToDto(account *model.ClientBankAccount) *ClientBankAccountDto {
+	return &ClientBankAccountDto{
+		ID:                  account.ID,
+		ClientID:            account.ClientID,
+		BankCode:            account.BankCode,
+		BankName:            account.BankName,
+		BranchCode:          account.BranchCode,
+		BranchName:          account.BranchName,
+		AccountType:         string(account.AccountType),
+		MaskedAccountNumber: account.MaskedAccountNumber(),
+		AccountName:         account.AccountName,
+		IsDefault:           account.IsDefault,
+	}
+}
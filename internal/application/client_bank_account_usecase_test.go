@@ -0,0 +1,217 @@
+package application_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// inMemoryClientBankAccountRepository インメモリの振込先口座リポジトリ
+type inMemoryClientBankAccountRepository struct {
+	repository.ClientBankAccount // 使わないメソッドは実装しない
+
+	accounts map[uint]*model.ClientBankAccount
+	nextID   uint
+}
+
+func (r *inMemoryClientBankAccountRepository) FindByClientID(clientID uint) ([]*model.ClientBankAccount, error) {
+	var found []*model.ClientBankAccount
+	for _, account := range r.accounts {
+		if account.ClientID == clientID {
+			copied := *account
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, nil
+}
+
+func (r *inMemoryClientBankAccountRepository) GetByID(clientID, id uint) (*model.ClientBankAccount, error) {
+	account, ok := r.accounts[id]
+	if !ok || account.ClientID != clientID {
+		return nil, commonErrors.ErrNotFound
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (r *inMemoryClientBankAccountRepository) Create(account *model.ClientBankAccount) (*model.ClientBankAccount, error) {
+	existing, _ := r.FindByClientID(account.ClientID)
+	created := *account
+	created.IsDefault = account.IsDefault || len(existing) == 0
+	if created.IsDefault {
+		r.clearDefault(account.ClientID)
+	}
+	r.nextID++
+	created.ID = r.nextID
+	r.accounts[created.ID] = &created
+	copied := created
+	return &copied, nil
+}
+
+func (r *inMemoryClientBankAccountRepository) Update(account *model.ClientBankAccount) (*model.ClientBankAccount, error) {
+	stored, err := r.GetByID(account.ClientID, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	updated := *account
+	updated.IsDefault = stored.IsDefault
+	r.accounts[account.ID] = &updated
+	return r.GetByID(account.ClientID, account.ID)
+}
+
+func (r *inMemoryClientBankAccountRepository) SetDefault(clientID, id uint) (*model.ClientBankAccount, error) {
+	if _, err := r.GetByID(clientID, id); err != nil {
+		return nil, err
+	}
+	r.clearDefault(clientID)
+	r.accounts[id].IsDefault = true
+	return r.GetByID(clientID, id)
+}
+
+func (r *inMemoryClientBankAccountRepository) clearDefault(clientID uint) {
+	for _, account := range r.accounts {
+		if account.ClientID == clientID {
+			account.IsDefault = false
+		}
+	}
+}
+
+func newClientBankAccountUsecaseForTest() (application.ClientBankAccountUsecase, *inMemoryClientBankAccountRepository) {
+	archivedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	bankAccountRepo := &inMemoryClientBankAccountRepository{accounts: map[uint]*model.ClientBankAccount{}}
+	clientRepo := &inMemoryClientRepository{
+		clients: map[uint]*model.Client{
+			1: {ID: 1, OrganizationID: 1, Name: "取引先A"},
+			2: {ID: 2, OrganizationID: 1, Name: "取引先B", ArchivedAt: &archivedAt},
+			3: {ID: 3, OrganizationID: 2, Name: "取引先C"},
+		},
+	}
+	return application.NewClientBankAccountUsecase(bankAccountRepo, clientRepo, newInMemoryUserRepository()), bankAccountRepo
+}
+
+func validBankAccountInput() application.BankAccountInputDto {
+	return application.BankAccountInputDto{
+		BankCode:      "0001",
+		BankName:      "みずほ銀行",
+		BranchCode:    "100",
+		BranchName:    "本店",
+		AccountType:   "ordinary",
+		AccountNumber: "1234567",
+		AccountName:   "ﾄﾘﾋｷｻｷｴｰ",
+	}
+}
+
+func Test_ClientBankAccountUsecase_CreateBankAccount(t *testing.T) {
+	principal := application.Principal{OrganizationID: 1, Scopes: clientScopes}
+	invalid := validBankAccountInput()
+	invalid.AccountName = "取引先A"
+	fullWidth := validBankAccountInput()
+	fullWidth.AccountName = "トリヒキサキビー"
+
+	tests := []struct {
+		name    string
+		dto     application.CreateBankAccountDto
+		want    *application.ClientBankAccountDto
+		wantErr error
+	}{
+		{
+			name: "最初の口座は既定の口座になり、口座番号はマスクして返す",
+			dto:  application.CreateBankAccountDto{Principal: principal, ClientID: 1, BankAccountInputDto: validBankAccountInput()},
+			want: &application.ClientBankAccountDto{
+				ID: 1, ClientID: 1, BankCode: "0001", BankName: "みずほ銀行", BranchCode: "100", BranchName: "本店",
+				AccountType: "ordinary", MaskedAccountNumber: "****567", AccountName: "ﾄﾘﾋｷｻｷｴｰ", IsDefault: true,
+			},
+		},
+		{
+			name: "全角カナの口座名義は半角カナに変換する",
+			dto:  application.CreateBankAccountDto{Principal: principal, ClientID: 1, BankAccountInputDto: fullWidth},
+			want: &application.ClientBankAccountDto{
+				ID: 1, ClientID: 1, BankCode: "0001", BankName: "みずほ銀行", BranchCode: "100", BranchName: "本店",
+				AccountType: "ordinary", MaskedAccountNumber: "****567", AccountName: "ﾄﾘﾋｷｻｷﾋﾞ-", IsDefault: true,
+			},
+		},
+		{
+			name:    "口座名義が半角カナに変換できない",
+			dto:     application.CreateBankAccountDto{Principal: principal, ClientID: 1, BankAccountInputDto: invalid},
+			wantErr: model.ErrInvalidBankAccount,
+		},
+		{
+			name:    "アーカイブした取引先",
+			dto:     application.CreateBankAccountDto{Principal: principal, ClientID: 2, BankAccountInputDto: validBankAccountInput()},
+			wantErr: model.ErrClientArchived,
+		},
+		{
+			name:    "他組織の取引先",
+			dto:     application.CreateBankAccountDto{Principal: principal, ClientID: 3, BankAccountInputDto: validBankAccountInput()},
+			wantErr: commonErrors.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase, _ := newClientBankAccountUsecaseForTest()
+
+			got, err := usecase.CreateBankAccount(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("bank account mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ClientBankAccountUsecase_SetDefaultBankAccount(t *testing.T) {
+	usecase, _ := newClientBankAccountUsecaseForTest()
+	principal := application.Principal{OrganizationID: 1, Scopes: clientScopes}
+
+	first, err := usecase.CreateBankAccount(application.CreateBankAccountDto{Principal: principal, ClientID: 1, BankAccountInputDto: validBankAccountInput()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := usecase.CreateBankAccount(application.CreateBankAccountDto{Principal: principal, ClientID: 1, BankAccountInputDto: validBankAccountInput()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.IsDefault {
+		t.Error("second account should not be default")
+	}
+
+	if _, err := usecase.SetDefaultBankAccount(application.SetDefaultBankAccountDto{Principal: principal, ClientID: 1, ID: second.ID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	accounts, err := usecase.ListBankAccounts(application.ListBankAccountsDto{Principal: principal, ClientID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defaults := map[uint]bool{}
+	for _, account := range accounts {
+		defaults[account.ID] = account.IsDefault
+	}
+	if diff := cmp.Diff(map[uint]bool{first.ID: false, second.ID: true}, defaults); diff != "" {
+		t.Errorf("default accounts mismatch (-want +got):\n%s", diff)
+	}
+
+	// 他の取引先の口座は指定できない
+	_, err = usecase.SetDefaultBankAccount(application.SetDefaultBankAccountDto{Principal: application.Principal{OrganizationID: 2, Scopes: clientScopes}, ClientID: 1, ID: first.ID})
+	if !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
@@ -0,0 +1,39 @@
+package application
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+)
+
+// clientCursorToken クライアントに返す取引先一覧のカーソルの中身
+type clientCursorToken struct {
+	Name string `json:"n"`
+	ID   uint   `json:"i"`
+}
+
+// encodeClientCursor カーソルを不透明な文字列に変換する
+func encodeClientCursor(cursor *repository.ClientCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	b, _ := json.Marshal(clientCursorToken{Name: cursor.Name, ID: cursor.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeClientCursor 文字列からカーソルを復元する. 空文字の場合はnilを返す
+func decodeClientCursor(s string) (*repository.ClientCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var token clientCursorToken
+	if err := json.Unmarshal(b, &token); err != nil || token.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.ClientCursor{Name: token.Name, ID: token.ID}, nil
+}
@@ -0,0 +1,251 @@
+package application
+
+import (
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// ClientUsecase 取引先の管理. 操作主体の所属組織の取引先に限る
+type ClientUsecase interface {
+	CreateClient(dto CreateClientDto) (*ClientDetailDto, error)
+	ListClients(dto ListClientsDto) (*ClientListDto, error)
+	GetClient(dto GetClientDto) (*ClientDetailDto, error)
+	UpdateClient(dto UpdateClientDto) (*ClientDetailDto, error)
+	ArchiveClient(dto ArchiveClientDto) (*ClientDetailDto, error)
+}
+
+type clientUsecase struct {
+	clientRepo repository.Client
+	userRepo   repository.User
+}
+
+func NewClientUsecase(clientRepo repository.Client, userRepo repository.User) ClientUsecase {
+	return &clientUsecase{
+		clientRepo: clientRepo,
+		userRepo:   userRepo,
+	}
+}
+
+const (
+	DefaultClientPageSize = 50  // 1ページあたりの件数（既定値）
+	MaxClientPageSize     = 100 // 1ページあたりの件数（上限）
+)
+
+// ClientInputDto 取引先の登録内容
+type ClientInputDto struct {
+	Name                string
+	RegistrationNumber  string // 未登録の場合は空文字
+	Representative      string
+	PhoneNumber         string // 任意
+	PostalCode          string
+	Address             string
+	PayeeType           string // 空の場合は法人
+	WithholdingCategory string // 空の場合は源泉徴収の対象外
+}
+
+type CreateClientDto struct {
+	Principal Principal
+	ClientInputDto
+}
+
+type UpdateClientDto struct {
+	Principal Principal
+	ID        uint
+	ClientInputDto
+}
+
+type GetClientDto struct {
+	Principal Principal
+	ID        uint
+}
+
+type ArchiveClientDto struct {
+	Principal Principal
+	ID        uint
+	Now       time.Time
+}
+
+type ListClientsDto struct {
+	Principal       Principal
+	Name            string // 取引先名の部分一致
+	IncludeArchived bool   // アーカイブした取引先も含める
+	Cursor          string // 前のページの NextCursor
+	Limit           int    // 1ページあたりの件数（既定: DefaultClientPageSize）
+}
+
+type ClientListDto struct {
+	Clients    []*ClientDetailDto
+	NextCursor string // 次のページがない場合は空文字
+}
+
+type ClientDetailDto struct {
+	ClientDto
+	PayeeType           string
+	WithholdingCategory string
+	ArchivedAt          *time.Time // アーカイブしていない場合はnil
+}
+
+func (s *clientUsecase) CreateClient(dto CreateClientDto) (*ClientDetailDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionWriteClient)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &model.Client{OrganizationID: organizationID}
+	applyClientInput(client, dto.ClientInputDto)
+	if err := client.Validate(); err != nil {
+		return nil, err
+	}
+
+	created, err := s.clientRepo.Create(client)
+	if err != nil {
+		return nil, err
+	}
+	return clientToDetailDto(created), nil
+}
+
+func (s *clientUsecase) ListClients(dto ListClientsDto) (*ClientListDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadClient)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeClientCursor(dto.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := dto.Limit
+	if limit <= 0 {
+		limit = DefaultClientPageSize
+	}
+	if limit > MaxClientPageSize {
+		limit = MaxClientPageSize
+	}
+
+	page, err := s.clientRepo.Search(repository.ClientSearchCondition{
+		OrganizationID:  organizationID,
+		Name:            dto.Name,
+		IncludeArchived: dto.IncludeArchived,
+		After:           after,
+		Limit:           limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ClientListDto{
+		Clients:    make([]*ClientDetailDto, len(page.Clients)),
+		NextCursor: encodeClientCursor(page.NextCursor),
+	}
+	for i, client := range page.Clients {
+		result.Clients[i] = clientToDetailDto(client)
+	}
+	return result, nil
+}
+
+func (s *clientUsecase) GetClient(dto GetClientDto) (*ClientDetailDto, error) {
+	client, err := s.findClient(dto.Principal, dto.ID, model.PermissionReadClient)
+	if err != nil {
+		return nil, err
+	}
+	return clientToDetailDto(client), nil
+}
+
+// UpdateClient 取引先の登録内容を更新する. アーカイブした取引先は更新できない
+func (s *clientUsecase) UpdateClient(dto UpdateClientDto) (*ClientDetailDto, error) {
+	client, err := s.findClient(dto.Principal, dto.ID, model.PermissionWriteClient)
+	if err != nil {
+		return nil, err
+	}
+	if client.IsArchived() {
+		return nil, model.ErrClientArchived
+	}
+
+	applyClientInput(client, dto.ClientInputDto)
+	if err := client.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.clientRepo.Update(client)
+	if err != nil {
+		return nil, err
+	}
+	return clientToDetailDto(updated), nil
+}
+
+// ArchiveClient 取引先をアーカイブする. 作成済みの請求書はそのまま支払・消込できる
+func (s *clientUsecase) ArchiveClient(dto ArchiveClientDto) (*ClientDetailDto, error) {
+	client, err := s.findClient(dto.Principal, dto.ID, model.PermissionWriteClient)
+	if err != nil {
+		return nil, err
+	}
+	if client.IsArchived() {
+		return clientToDetailDto(client), nil
+	}
+
+	client.Archive(dto.Now)
+	updated, err := s.clientRepo.Update(client)
+	if err != nil {
+		return nil, err
+	}
+	return clientToDetailDto(updated), nil
+}
+
+func (s *clientUsecase) findClient(principal Principal, id uint, permission model.Permission) (*model.Client, error) {
+	return findOrganizationClient(s.clientRepo, s.userRepo, principal, id, permission)
+}
+
+// findOrganizationClient 操作主体が権限をもつことを確認し、所属組織の取引先を取得する. 他組織の取引先は ErrNotFound とする
+func findOrganizationClient(clientRepo repository.Client, userRepo repository.User, principal Principal, id uint, permission model.Permission) (*model.Client, error) {
+	organizationID, err := authorize(userRepo, principal, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := clientRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !client.BelongsTo(organizationID) {
+		return nil, commonErrors.ErrNotFound
+	}
+	return client, nil
+}
+
+// applyClientInput 登録内容を取引先に反映する. 区分が未指定の場合は法人・源泉徴収の対象外とする
+func applyClientInput(client *model.Client, input ClientInputDto) {
+	client.Name = input.Name
+	client.RegistrationNumber = input.RegistrationNumber
+	client.Representative = input.Representative
+	client.PhoneNumber = input.PhoneNumber
+	client.PostalCode = input.PostalCode
+	client.Address = input.Address
+	client.PayeeType = model.PayeeType(input.PayeeType)
+	if client.PayeeType == "" {
+		client.PayeeType = model.PayeeTypeCorporation
+	}
+	client.WithholdingCategory = model.WithholdingCategory(input.WithholdingCategory)
+	if client.WithholdingCategory == "" {
+		client.WithholdingCategory = model.WithholdingCategoryNone
+	}
+}
+
+func clientToDetailDto(client *model.Client) *ClientDetailDto {
+	return &ClientDetailDto{
+		ClientDto: ClientDto{
+			ID:                 client.ID,
+			Name:               client.Name,
+			RegistrationNumber: client.RegistrationNumber,
+			Representative:     client.Representative,
+			PhoneNumber:        client.PhoneNumber,
+			PostalCode:         client.PostalCode,
+			Address:            client.Address,
+		},
+		PayeeType:           string(client.PayeeType),
+		WithholdingCategory: string(client.WithholdingCategory),
+		ArchivedAt:          client.ArchivedAt,
+	}
+}
@@ -0,0 +1,277 @@
+package application_test
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+func (r *inMemoryClientRepository) Create(client *model.Client) (*model.Client, error) {
+	created := *client
+	created.ID = uint(len(r.clients) + 1)
+	r.clients[created.ID] = &created
+	return &created, nil
+}
+
+func (r *inMemoryClientRepository) Update(client *model.Client) (*model.Client, error) {
+	stored, ok := r.clients[client.ID]
+	if !ok || !stored.BelongsTo(client.OrganizationID) {
+		return nil, commonErrors.ErrNotFound
+	}
+	updated := *client
+	r.clients[client.ID] = &updated
+	return &updated, nil
+}
+
+func (r *inMemoryClientRepository) Search(cond repository.ClientSearchCondition) (*repository.ClientPage, error) {
+	var found []*model.Client
+	for _, client := range r.clients {
+		if !client.BelongsTo(cond.OrganizationID) || !strings.Contains(client.Name, cond.Name) {
+			continue
+		}
+		if client.IsArchived() && !cond.IncludeArchived {
+			continue
+		}
+		if cond.After != nil && (client.Name < cond.After.Name || client.Name == cond.After.Name && client.ID <= cond.After.ID) {
+			continue
+		}
+		found = append(found, client)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Name != found[j].Name {
+			return found[i].Name < found[j].Name
+		}
+		return found[i].ID < found[j].ID
+	})
+
+	page := &repository.ClientPage{Clients: found}
+	if len(found) > cond.Limit {
+		page.Clients = found[:cond.Limit]
+		last := page.Clients[cond.Limit-1]
+		page.NextCursor = &repository.ClientCursor{Name: last.Name, ID: last.ID}
+	}
+	return page, nil
+}
+
+func newClientUsecaseForTest(clients ...*model.Client) application.ClientUsecase {
+	clientRepo := &inMemoryClientRepository{clients: map[uint]*model.Client{}}
+	for _, client := range clients {
+		clientRepo.clients[client.ID] = client
+	}
+	return application.NewClientUsecase(clientRepo, newInMemoryUserRepository())
+}
+
+func validClientInput() application.ClientInputDto {
+	return application.ClientInputDto{
+		Name:           "株式会社取引先",
+		Representative: "取引 太郎",
+		PhoneNumber:    "03-1234-5678",
+		PostalCode:     "100-0001",
+		Address:        "東京都千代田区千代田1-1",
+	}
+}
+
+func Test_ClientUsecase_CreateClient(t *testing.T) {
+	invalid := validClientInput()
+	invalid.PhoneNumber = "123"
+
+	tests := []struct {
+		name    string
+		dto     application.CreateClientDto
+		want    *application.ClientDetailDto
+		wantErr error
+	}{
+		{
+			name: "区分を省略した場合は法人・源泉徴収の対象外",
+			dto:  application.CreateClientDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, ClientInputDto: validClientInput()},
+			want: &application.ClientDetailDto{
+				ClientDto: application.ClientDto{
+					ID: 1, Name: "株式会社取引先", Representative: "取引 太郎",
+					PhoneNumber: "03-1234-5678", PostalCode: "100-0001", Address: "東京都千代田区千代田1-1",
+				},
+				PayeeType:           "corporation",
+				WithholdingCategory: "none",
+			},
+		},
+		{
+			name:    "電話番号の形式が不正",
+			dto:     application.CreateClientDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, ClientInputDto: invalid},
+			wantErr: model.ErrInvalidClient,
+		},
+		{
+			name:    "組織を特定できない",
+			dto:     application.CreateClientDto{ClientInputDto: validClientInput()},
+			wantErr: commonErrors.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := newClientUsecaseForTest()
+
+			got, err := usecase.CreateClient(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("client mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ClientUsecase_ListClients(t *testing.T) {
+	archivedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	usecase := newClientUsecaseForTest(
+		&model.Client{ID: 1, OrganizationID: 1, Name: "A商事"},
+		&model.Client{ID: 2, OrganizationID: 1, Name: "B商事", ArchivedAt: &archivedAt},
+		&model.Client{ID: 3, OrganizationID: 1, Name: "C物産"},
+		&model.Client{ID: 4, OrganizationID: 1, Name: "D商事"},
+		&model.Client{ID: 5, OrganizationID: 2, Name: "E商事"},
+	)
+	principal := application.Principal{OrganizationID: 1, Scopes: clientScopes}
+
+	ids := func(list *application.ClientListDto) []uint {
+		var ids []uint
+		for _, client := range list.Clients {
+			ids = append(ids, client.ID)
+		}
+		return ids
+	}
+
+	t.Run("アーカイブした取引先と他組織の取引先は含まない", func(t *testing.T) {
+		got, err := usecase.ListClients(application.ListClientsDto{Principal: principal, Name: "商事"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]uint{1, 4}, ids(got)); diff != "" {
+			t.Errorf("clients mismatch (-want +got):\n%s", diff)
+		}
+		if got.NextCursor != "" {
+			t.Errorf("NextCursor = %q, want empty", got.NextCursor)
+		}
+	})
+
+	t.Run("カーソルで次のページを取得する", func(t *testing.T) {
+		first, err := usecase.ListClients(application.ListClientsDto{Principal: principal, IncludeArchived: true, Limit: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]uint{1, 2}, ids(first)); diff != "" {
+			t.Errorf("first page mismatch (-want +got):\n%s", diff)
+		}
+
+		second, err := usecase.ListClients(application.ListClientsDto{Principal: principal, IncludeArchived: true, Limit: 2, Cursor: first.NextCursor})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]uint{3, 4}, ids(second)); diff != "" {
+			t.Errorf("second page mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("不正なカーソル", func(t *testing.T) {
+		_, err := usecase.ListClients(application.ListClientsDto{Principal: principal, Cursor: "!!"})
+		if !errors.Is(err, application.ErrInvalidCursor) {
+			t.Errorf("error = %v, want %v", err, application.ErrInvalidCursor)
+		}
+	})
+}
+
+func Test_ClientUsecase_UpdateClient(t *testing.T) {
+	archivedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := validClientInput()
+	input.RegistrationNumber = "T7000012050002"
+
+	tests := []struct {
+		name    string
+		dto     application.UpdateClientDto
+		wantErr error
+	}{
+		{
+			name: "更新できる",
+			dto:  application.UpdateClientDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, ID: 1, ClientInputDto: input},
+		},
+		{
+			name:    "他組織の取引先",
+			dto:     application.UpdateClientDto{Principal: application.Principal{OrganizationID: 2, Scopes: clientScopes}, ID: 1, ClientInputDto: input},
+			wantErr: commonErrors.ErrNotFound,
+		},
+		{
+			name:    "アーカイブした取引先",
+			dto:     application.UpdateClientDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, ID: 2, ClientInputDto: input},
+			wantErr: model.ErrClientArchived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := newClientUsecaseForTest(
+				&model.Client{ID: 1, OrganizationID: 1, Name: "A商事"},
+				&model.Client{ID: 2, OrganizationID: 1, Name: "B商事", ArchivedAt: &archivedAt},
+			)
+
+			got, err := usecase.UpdateClient(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Name != input.Name || got.RegistrationNumber != input.RegistrationNumber {
+				t.Errorf("client = %+v, want input applied", got)
+			}
+		})
+	}
+}
+
+func Test_ClientUsecase_ArchiveClient(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	earlier := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	usecase := newClientUsecaseForTest(
+		&model.Client{ID: 1, OrganizationID: 1, Name: "A商事"},
+		&model.Client{ID: 2, OrganizationID: 1, Name: "B商事", ArchivedAt: &earlier},
+	)
+	principal := application.Principal{OrganizationID: 1, Scopes: clientScopes}
+
+	got, err := usecase.ArchiveClient(application.ArchiveClientDto{Principal: principal, ID: 1, Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ArchivedAt == nil || !got.ArchivedAt.Equal(now) {
+		t.Errorf("ArchivedAt = %v, want %v", got.ArchivedAt, now)
+	}
+
+	// アーカイブ済みの場合は日時を変えない
+	got, err = usecase.ArchiveClient(application.ArchiveClientDto{Principal: principal, ID: 2, Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ArchivedAt == nil || !got.ArchivedAt.Equal(earlier) {
+		t.Errorf("ArchivedAt = %v, want %v", got.ArchivedAt, earlier)
+	}
+
+	_, err = usecase.ArchiveClient(application.ArchiveClientDto{Principal: application.Principal{OrganizationID: 2, Scopes: clientScopes}, ID: 1, Now: now})
+	if !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+// DuplicateInvoiceError 作成しようとした請求書と重複の疑いがある請求書が既にある
+type DuplicateInvoiceError struct {
+	Candidates []*InvoiceDto // 重複の疑いがある既存の請求書
+}
+
+func (e *DuplicateInvoiceError) Error() string {
+	ids := make([]uint, len(e.Candidates))
+	for i, candidate := range e.Candidates {
+		ids[i] = candidate.ID
+	}
+	return fmt.Sprintf("likely duplicate of invoices %v", ids)
+}
+
+// findLikelyDuplicates 作成しようとしている請求書と重複の疑いがある既存の請求書を取得する
+func (s *invoiceUsecase) findLikelyDuplicates(invoice *model.Invoice) ([]*InvoiceDto, error) {
+	tolerance := model.DuplicateInvoiceDateTolerance
+	invoices, err := s.invoiceRepo.FindByClientAndAmount(
+		invoice.Organization.ID,
+		invoice.Client.ID,
+		invoice.Amount,
+		invoice.IssueDate.AddDate(0, 0, -tolerance),
+		invoice.IssueDate.AddDate(0, 0, tolerance),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*InvoiceDto
+	for _, existing := range invoices {
+		if !invoice.IsLikelyDuplicateOf(existing) {
+			continue
+		}
+		dto, err := s.invoiceToDto(existing)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, dto)
+	}
+	return candidates, nil
+}
+
+type ReportDuplicateInvoicesDto struct {
+	Principal      Principal
+	IssueStartDate time.Time // 発行日（開始）. 省略した場合（ゼロ値）は絞り込まない
+	IssueEndDate   time.Time // 発行日（終了）. 省略した場合（ゼロ値）は絞り込まない
+}
+
+// DuplicateInvoiceReportDto 重複の疑いがある請求書の一覧
+type DuplicateInvoiceReportDto struct {
+	Groups []DuplicateInvoiceGroupDto
+}
+
+// DuplicateInvoiceGroupDto 互いに重複の疑いがある請求書のまとまり
+type DuplicateInvoiceGroupDto struct {
+	Invoices []*InvoiceDto // 発行日の昇順
+}
+
+// ReportDuplicateInvoices 組織の請求書のうち重複の疑いがあるものをまとまりごとに返す.
+// A と B、B と C が重複の疑いがある場合は A・B・C を1つのまとまりにする
+func (s *invoiceUsecase) ReportDuplicateInvoices(dto ReportDuplicateInvoicesDto) (*DuplicateInvoiceReportDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadInvoice)
+	if err != nil {
+		return nil, err
+	}
+
+	// 取引先・支払金額・発行日の順で並んでいるため、取引先と支払金額が同じ請求書は連続する
+	invoices, err := s.invoiceRepo.FindSharingClientAndAmount(organizationID, dto.IssueStartDate, dto.IssueEndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DuplicateInvoiceReportDto{Groups: []DuplicateInvoiceGroupDto{}}
+	for start := 0; start < len(invoices); {
+		end := start + 1
+		for end < len(invoices) && invoices[end].Client.ID == invoices[start].Client.ID && invoices[end].Amount.Equal(invoices[start].Amount) {
+			end++
+		}
+		for _, group := range groupDuplicates(invoices[start:end]) {
+			groupDto := DuplicateInvoiceGroupDto{Invoices: make([]*InvoiceDto, len(group))}
+			for i, invoice := range group {
+				if groupDto.Invoices[i], err = s.invoiceToDto(invoice); err != nil {
+					return nil, err
+				}
+			}
+			report.Groups = append(report.Groups, groupDto)
+		}
+		start = end
+	}
+
+	return report, nil
+}
+
+// groupDuplicates 請求書を重複の疑いでつながるまとまりに分け、2件以上のまとまりだけを返す. まとまりの中の順序は元の順序のまま
+func groupDuplicates(invoices []*model.Invoice) [][]*model.Invoice {
+	parents := make([]int, len(invoices))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	for i := range invoices {
+		for j := i + 1; j < len(invoices); j++ {
+			if invoices[i].IsLikelyDuplicateOf(invoices[j]) {
+				parents[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := make(map[int][]*model.Invoice)
+	var roots []int
+	for i, invoice := range invoices {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], invoice)
+	}
+
+	var groups [][]*model.Invoice
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, members[root])
+		}
+	}
+	return groups
+}
@@ -0,0 +1,211 @@
+package application_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+func (r *inMemoryInvoiceRepository) FindByClientAndAmount(organizationID, clientID uint, amount decimal.Decimal, issueDateFrom, issueDateTo time.Time) ([]*model.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []*model.Invoice
+	for _, invoice := range r.invoices {
+		if invoice.Organization.ID == organizationID && invoice.Client.ID == clientID && invoice.Amount.Equal(amount) &&
+			!invoice.IssueDate.Before(issueDateFrom) && !invoice.IssueDate.After(issueDateTo) {
+			copied := *invoice
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, nil
+}
+
+func (r *inMemoryInvoiceRepository) FindSharingClientAndAmount(organizationID uint, issueDateFrom, issueDateTo time.Time) ([]*model.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type key struct {
+		clientID uint
+		amount   string
+	}
+	counts := map[key]int{}
+	var inPeriod []*model.Invoice
+	for _, invoice := range r.invoices {
+		if invoice.Organization.ID != organizationID ||
+			(!issueDateFrom.IsZero() && invoice.IssueDate.Before(issueDateFrom)) ||
+			(!issueDateTo.IsZero() && invoice.IssueDate.After(issueDateTo)) {
+			continue
+		}
+		counts[key{invoice.Client.ID, invoice.Amount.String()}]++
+		inPeriod = append(inPeriod, invoice)
+	}
+
+	var found []*model.Invoice
+	for _, invoice := range inPeriod {
+		if counts[key{invoice.Client.ID, invoice.Amount.String()}] > 1 {
+			copied := *invoice
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		switch {
+		case a.Client.ID != b.Client.ID:
+			return a.Client.ID < b.Client.ID
+		case !a.Amount.Equal(b.Amount):
+			return a.Amount.LessThan(b.Amount)
+		case !a.IssueDate.Equal(b.IssueDate):
+			return a.IssueDate.Before(b.IssueDate)
+		}
+		return a.ID < b.ID
+	})
+	return found, nil
+}
+
+// newDuplicateTestInvoice 重複の検出用の請求書. 支払期日は発行日の30日後とする
+func newDuplicateTestInvoice(id, clientID uint, amount int64, issueDate time.Time) *model.Invoice {
+	return &model.Invoice{
+		ID:           id,
+		Organization: &model.Organization{ID: 1, Name: "株式会社サンプル"},
+		Client:       &model.Client{ID: clientID, OrganizationID: 1, Name: "取引先A"},
+		IssueDate:    issueDate,
+		Amount:       decimal.NewFromInt(amount),
+		DueDate:      issueDate.AddDate(0, 0, 30),
+		Status:       model.StatusPending,
+	}
+}
+
+func Test_InvoiceUsecase_CreateInvoice_Duplicate(t *testing.T) {
+	issueDate := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	organizationRepo := &inMemoryOrganizationRepository{
+		organizations: map[uint]*model.Organization{1: {ID: 1, Name: "株式会社サンプル"}},
+	}
+	clientRepo := &inMemoryClientRepository{
+		clients: map[uint]*model.Client{1: {ID: 1, OrganizationID: 1, Name: "取引先A"}},
+	}
+	taxRateRepo := fixedTaxRateRepository{rates: map[model.TaxCategory]float64{model.TaxCategoryStandard: 0.1}}
+	newUsecase := func(repo *inMemoryInvoiceRepository) application.InvoiceUsecase {
+		return application.NewInvoiceUsecase(repo, clientRepo, organizationRepo, newInMemoryUserRepository(), taxRateRepo, inMemoryFeeRateRepository{})
+	}
+	dto := application.CreateInvoiceDto{
+		Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes},
+		ClientID:  1,
+		IssueDate: issueDate,
+		Amount:    10000,
+		DueDate:   issueDate.AddDate(0, 0, 30),
+	}
+
+	t.Run("重複の疑いがある請求書を返して作成しない", func(t *testing.T) {
+		repo := newInMemoryInvoiceRepository(
+			newDuplicateTestInvoice(1, 1, 10000, issueDate.AddDate(0, 0, -2)),
+			newDuplicateTestInvoice(2, 1, 10000, issueDate.AddDate(0, 0, -10)), // 発行日が離れている
+			newDuplicateTestInvoice(3, 1, 20000, issueDate),                    // 金額が違う
+		)
+
+		_, err := newUsecase(repo).CreateInvoice(dto)
+
+		var duplicateErr *application.DuplicateInvoiceError
+		if !errors.As(err, &duplicateErr) {
+			t.Fatalf("error = %v, want DuplicateInvoiceError", err)
+		}
+		if len(duplicateErr.Candidates) != 1 || duplicateErr.Candidates[0].ID != 1 {
+			t.Errorf("unexpected candidates: %+v", duplicateErr.Candidates)
+		}
+		if len(repo.invoices) != 3 {
+			t.Errorf("expected no invoice to be created, got %d invoices", len(repo.invoices))
+		}
+	})
+
+	t.Run("確認済みの場合は作成する", func(t *testing.T) {
+		repo := newInMemoryInvoiceRepository(newDuplicateTestInvoice(1, 1, 10000, issueDate))
+		confirmed := dto
+		confirmed.ConfirmDuplicate = true
+
+		got, err := newUsecase(repo).CreateInvoice(confirmed)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.ID != 2 {
+			t.Errorf("ID = %d, want 2", got.ID)
+		}
+	})
+}
+
+func Test_InvoiceUsecase_ReportDuplicateInvoices(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 4, d, 0, 0, 0, 0, time.UTC) }
+	repo := newInMemoryInvoiceRepository(
+		// 1・2・3 は隣りあう請求書どうしが重複の疑いでつながる
+		newDuplicateTestInvoice(1, 1, 10000, day(1)),
+		newDuplicateTestInvoice(2, 1, 10000, day(4)),
+		newDuplicateTestInvoice(3, 1, 10000, day(7)),
+		newDuplicateTestInvoice(4, 1, 10000, day(20)), // 同じ取引先・金額だが日付が離れている
+		newDuplicateTestInvoice(5, 2, 10000, day(1)),  // 取引先が違う
+		newDuplicateTestInvoice(6, 1, 30000, day(2)),
+		newDuplicateTestInvoice(7, 1, 30000, day(2)),
+	)
+	usecase := application.NewInvoiceUsecase(repo, nil, nil, newInMemoryUserRepository(), nil, nil)
+	principal := application.Principal{OrganizationID: 1, Scopes: clientScopes}
+
+	groupIDs := func(report *application.DuplicateInvoiceReportDto) [][]uint {
+		groups := [][]uint{}
+		for _, group := range report.Groups {
+			var ids []uint
+			for _, invoice := range group.Invoices {
+				ids = append(ids, invoice.ID)
+			}
+			groups = append(groups, ids)
+		}
+		return groups
+	}
+
+	tests := []struct {
+		name string
+		dto  application.ReportDuplicateInvoicesDto
+		want [][]uint
+	}{
+		{
+			name: "組織全体",
+			dto:  application.ReportDuplicateInvoicesDto{Principal: principal},
+			want: [][]uint{{1, 2, 3}, {6, 7}},
+		},
+		{
+			name: "発行日で絞り込む",
+			dto:  application.ReportDuplicateInvoicesDto{Principal: principal, IssueStartDate: day(3), IssueEndDate: day(30)},
+			want: [][]uint{{2, 3}},
+		},
+		{
+			name: "重複がない",
+			dto:  application.ReportDuplicateInvoicesDto{Principal: principal, IssueStartDate: day(10)},
+			want: [][]uint{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := usecase.ReportDuplicateInvoices(tt.dto)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, groupIDs(got)); diff != "" {
+				t.Errorf("groups mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("組織に所属していない", func(t *testing.T) {
+		_, err := usecase.ReportDuplicateInvoices(application.ReportDuplicateInvoicesDto{Principal: application.Principal{Scopes: clientScopes}})
+		if !errors.Is(err, commonErrors.ErrUnauthorized) {
+			t.Errorf("error = %v, want ErrUnauthorized", err)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"errors"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// ErrIdempotencyKeyReused 同じ冪等キーが異なるリクエストに使われた
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+// ErrIdempotencyKeyInProgress 同じ冪等キーのリクエストを処理中
+var ErrIdempotencyKeyInProgress = errors.New("a request with the same idempotency key is in progress")
+
+// IdempotencyUsecase Idempotency-Key による再送の検出. 処理を始める前にキーを予約し、処理後に応答を保存する.
+// 同時に同じキーで送られた場合は一方だけが処理し、他方は ErrIdempotencyKeyInProgress とする
+type IdempotencyUsecase interface {
+	Begin(dto BeginIdempotentRequestDto) (*IdempotentRequestDto, error)
+	Complete(dto CompleteIdempotentRequestDto) error
+	Release(dto ReleaseIdempotentRequestDto) error
+}
+
+type idempotencyUsecase struct {
+	idempotencyKeyRepo repository.IdempotencyKey
+}
+
+func NewIdempotencyUsecase(idempotencyKeyRepo repository.IdempotencyKey) IdempotencyUsecase {
+	return &idempotencyUsecase{
+		idempotencyKeyRepo: idempotencyKeyRepo,
+	}
+}
+
+type BeginIdempotentRequestDto struct {
+	Scope       string // キーの名前空間. 他の操作主体のキーと衝突しないようにする
+	Key         string
+	RequestHash string
+	Now         time.Time
+}
+
+type CompleteIdempotentRequestDto struct {
+	ID       uint
+	Response IdempotentResponseDto
+	Now      time.Time
+}
+
+type ReleaseIdempotentRequestDto struct {
+	ID uint
+}
+
+// IdempotentRequestDto 予約したキー. 再送の場合は保存した応答をもつ
+type IdempotentRequestDto struct {
+	ID       uint
+	Response *IdempotentResponseDto // 保存した応答（処理を始める場合はnil）
+}
+
+type IdempotentResponseDto struct {
+	StatusCode  int
+	ContentType string
+	Headers     map[string][]string // Content-Type 以外でハンドラーが設定したヘッダー
+	Body        []byte
+}
+
+// Begin キーを予約する. 同じリクエストの再送には保存した応答を返し、異なるリクエストへの再利用は ErrIdempotencyKeyReused とする.
+// 有効期限を過ぎたキー（処理中に停止した予約を含む）は削除して予約し直す
+func (s *idempotencyUsecase) Begin(dto BeginIdempotentRequestDto) (*IdempotentRequestDto, error) {
+	reservation, err := model.NewIdempotencyKey(dto.Scope, dto.Key, dto.RequestHash, dto.Now)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.idempotencyKeyRepo.Create(reservation)
+	if err == nil {
+		return &IdempotentRequestDto{ID: created.ID}, nil
+	}
+	if !errors.Is(err, commonErrors.ErrConflict) {
+		return nil, err
+	}
+
+	existing, err := s.idempotencyKeyRepo.Get(dto.Scope, dto.Key)
+	if err != nil {
+		if errors.Is(err, commonErrors.ErrNotFound) {
+			// 予約した処理が失敗して削除された直後. 再送で処理し直してもらう
+			return nil, ErrIdempotencyKeyInProgress
+		}
+		return nil, err
+	}
+	if existing.IsExpired(dto.Now) {
+		if err := s.idempotencyKeyRepo.Delete(existing.ID); err != nil {
+			return nil, err
+		}
+		created, err := s.idempotencyKeyRepo.Create(reservation)
+		if err != nil {
+			if errors.Is(err, commonErrors.ErrConflict) {
+				return nil, ErrIdempotencyKeyInProgress
+			}
+			return nil, err
+		}
+		return &IdempotentRequestDto{ID: created.ID}, nil
+	}
+	if !existing.Matches(dto.RequestHash) {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if !existing.IsCompleted() {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return &IdempotentRequestDto{
+		ID: existing.ID,
+		Response: &IdempotentResponseDto{
+			StatusCode:  existing.Response.StatusCode,
+			ContentType: existing.Response.ContentType,
+			Headers:     existing.Response.Headers,
+			Body:        existing.Response.Body,
+		},
+	}, nil
+}
+
+// Complete 予約したキーに応答を保存する. 以降の再送には保存期間の間この応答を返す
+func (s *idempotencyUsecase) Complete(dto CompleteIdempotentRequestDto) error {
+	key := &model.IdempotencyKey{ID: dto.ID}
+	key.Complete(model.StoredResponse{
+		StatusCode:  dto.Response.StatusCode,
+		ContentType: dto.Response.ContentType,
+		Headers:     dto.Response.Headers,
+		Body:        dto.Response.Body,
+	}, dto.Now)
+	return s.idempotencyKeyRepo.Complete(key)
+}
+
+// Release 応答を保存せずに予約を取り消す. 処理に失敗したリクエストを同じキーで再送できるようにする
+func (s *idempotencyUsecase) Release(dto ReleaseIdempotentRequestDto) error {
+	return s.idempotencyKeyRepo.Delete(dto.ID)
+}
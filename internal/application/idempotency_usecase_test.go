@@ -0,0 +1,191 @@
+package application_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// inMemoryIdempotencyKeyRepository 一意制約と同じく、操作主体ごとのキーの予約を排他的に行う
+type inMemoryIdempotencyKeyRepository struct {
+	mu     sync.Mutex
+	keys   map[uint]*model.IdempotencyKey
+	nextID uint
+}
+
+func newInMemoryIdempotencyKeyRepository() *inMemoryIdempotencyKeyRepository {
+	return &inMemoryIdempotencyKeyRepository{keys: map[uint]*model.IdempotencyKey{}}
+}
+
+func (r *inMemoryIdempotencyKeyRepository) Get(scope, key string) (*model.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if k.Scope == scope && k.Key == key {
+			copied := *k
+			return &copied, nil
+		}
+	}
+	return nil, commonErrors.ErrNotFound
+}
+
+func (r *inMemoryIdempotencyKeyRepository) Create(key *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if k.Scope == key.Scope && k.Key == key.Key {
+			return nil, commonErrors.ErrConflict
+		}
+	}
+	r.nextID++
+	created := *key
+	created.ID = r.nextID
+	r.keys[created.ID] = &created
+	copied := created
+	return &copied, nil
+}
+
+func (r *inMemoryIdempotencyKeyRepository) Complete(key *model.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.keys[key.ID]
+	if !ok {
+		return commonErrors.ErrNotFound
+	}
+	stored.Response = key.Response
+	stored.ExpiresAt = key.ExpiresAt
+	return nil
+}
+
+func (r *inMemoryIdempotencyKeyRepository) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, id)
+	return nil
+}
+
+func Test_IdempotencyUsecase(t *testing.T) {
+	usecase := application.NewIdempotencyUsecase(newInMemoryIdempotencyKeyRepository())
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	begin := application.BeginIdempotentRequestDto{Scope: "auth0|user1", Key: "order-1", RequestHash: "hash-1", Now: now}
+
+	reserved, err := usecase.Begin(begin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reserved.Response != nil {
+		t.Fatalf("response = %+v, want nil for a new request", reserved.Response)
+	}
+
+	// 処理中の再送
+	if _, err := usecase.Begin(begin); !errors.Is(err, application.ErrIdempotencyKeyInProgress) {
+		t.Errorf("error = %v, want %v", err, application.ErrIdempotencyKeyInProgress)
+	}
+
+	response := application.IdempotentResponseDto{StatusCode: 201, ContentType: "application/json", Headers: map[string][]string{"Etag": {`"1"`}}, Body: []byte(`{"id":1}`)}
+	if err := usecase.Complete(application.CompleteIdempotentRequestDto{ID: reserved.ID, Response: response, Now: now}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 同じリクエストの再送には保存した応答を返す
+	replayed, err := usecase.Begin(application.BeginIdempotentRequestDto{Scope: "auth0|user1", Key: "order-1", RequestHash: "hash-1", Now: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replayed.Response == nil || replayed.Response.StatusCode != 201 || string(replayed.Response.Body) != `{"id":1}` ||
+		len(replayed.Response.Headers["Etag"]) != 1 || replayed.Response.Headers["Etag"][0] != `"1"` {
+		t.Errorf("replayed = %+v, want the stored response", replayed.Response)
+	}
+
+	// 異なるリクエストへの再利用
+	if _, err := usecase.Begin(application.BeginIdempotentRequestDto{Scope: "auth0|user1", Key: "order-1", RequestHash: "hash-2", Now: now}); !errors.Is(err, application.ErrIdempotencyKeyReused) {
+		t.Errorf("error = %v, want %v", err, application.ErrIdempotencyKeyReused)
+	}
+
+	// 他の操作主体は同じキーを使える
+	if other, err := usecase.Begin(application.BeginIdempotentRequestDto{Scope: "auth0|user2", Key: "order-1", RequestHash: "hash-2", Now: now}); err != nil || other.Response != nil {
+		t.Errorf("other scope = %+v, %v, want a new reservation", other, err)
+	}
+
+	// 保存期間を過ぎたキーは新しいリクエストとして扱う
+	expired, err := usecase.Begin(application.BeginIdempotentRequestDto{Scope: "auth0|user1", Key: "order-1", RequestHash: "hash-2", Now: now.Add(model.IdempotencyKeyRetention)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expired.Response != nil || expired.ID == reserved.ID {
+		t.Errorf("expired = %+v, want a new reservation", expired)
+	}
+
+	// 不正なキー
+	if _, err := usecase.Begin(application.BeginIdempotentRequestDto{Scope: "auth0|user1", Key: "order 1", RequestHash: "hash-1", Now: now}); !errors.Is(err, model.ErrInvalidIdempotencyKey) {
+		t.Errorf("error = %v, want %v", err, model.ErrInvalidIdempotencyKey)
+	}
+}
+
+func Test_IdempotencyUsecase_Release(t *testing.T) {
+	usecase := application.NewIdempotencyUsecase(newInMemoryIdempotencyKeyRepository())
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	begin := application.BeginIdempotentRequestDto{Scope: "auth0|user1", Key: "order-1", RequestHash: "hash-1", Now: now}
+
+	reserved, err := usecase.Begin(begin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := usecase.Release(application.ReleaseIdempotentRequestDto{ID: reserved.ID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 取り消した予約は同じキーで処理し直せる
+	retried, err := usecase.Begin(begin)
+	if err != nil || retried.Response != nil {
+		t.Errorf("retried = %+v, %v, want a new reservation", retried, err)
+	}
+
+	// 処理中に停止した予約は、予約の有効期間を過ぎれば処理し直せる
+	begin.Now = now.Add(model.IdempotencyKeyLockTimeout)
+	if abandoned, err := usecase.Begin(begin); err != nil || abandoned.Response != nil {
+		t.Errorf("abandoned = %+v, %v, want a new reservation", abandoned, err)
+	}
+}
+
+func Test_IdempotencyUsecase_ConcurrentRequests(t *testing.T) {
+	usecase := application.NewIdempotencyUsecase(newInMemoryIdempotencyKeyRepository())
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	begin := application.BeginIdempotentRequestDto{Scope: "auth0|user1", Key: "order-1", RequestHash: "hash-1", Now: now}
+
+	const requests = 20
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		reserved   int
+		inProgress int
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := usecase.Begin(begin)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				reserved++
+			case errors.Is(err, application.ErrIdempotencyKeyInProgress):
+				inProgress++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 同時に送られても処理するのは1件だけ
+	if reserved != 1 || inProgress != requests-1 {
+		t.Errorf("reserved = %d, in progress = %d, want 1 and %d", reserved, inProgress, requests-1)
+	}
+}
@@ -0,0 +1,28 @@
+package application
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// invitationTokenLength 招待トークンのバイト数
+const invitationTokenLength = 32
+
+// newInvitationToken 招待トークンを生成する. トークンはユーザーに一度だけ返し、保存するのはハッシュだけとする
+func newInvitationToken() (token, tokenHash string, err error) {
+	b := make([]byte, invitationTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashInvitationToken(token), nil
+}
+
+// hashInvitationToken 招待トークンのSHA-256を16進数で返す
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
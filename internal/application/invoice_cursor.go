@@ -0,0 +1,53 @@
+package application
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+)
+
+// ErrInvalidCursor カーソルが不正、または並び順と一致しない
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// invoiceCursorToken クライアントに返すカーソルの中身. 並び順もあわせて保持し、異なる並び順での再利用を防ぐ
+type invoiceCursorToken struct {
+	SortKey    repository.InvoiceSortKey `json:"k"`
+	Descending bool                      `json:"d"`
+	SortValue  string                    `json:"v"`
+	ID         uint                      `json:"i"`
+}
+
+// encodeInvoiceCursor カーソルを不透明な文字列に変換する
+func encodeInvoiceCursor(sortKey repository.InvoiceSortKey, descending bool, cursor *repository.InvoiceCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	b, _ := json.Marshal(invoiceCursorToken{
+		SortKey:    sortKey,
+		Descending: descending,
+		SortValue:  cursor.SortValue,
+		ID:         cursor.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeInvoiceCursor 文字列からカーソルを復元する. 空文字の場合はnilを返す
+func decodeInvoiceCursor(sortKey repository.InvoiceSortKey, descending bool, s string) (*repository.InvoiceCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var token invoiceCursorToken
+	if err := json.Unmarshal(b, &token); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if token.SortKey != sortKey || token.Descending != descending || token.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.InvoiceCursor{SortValue: token.SortValue, ID: token.ID}, nil
+}
@@ -0,0 +1,72 @@
+package application
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+)
+
+func Test_InvoiceCursor_EncodeDecode(t *testing.T) {
+	cursor := &repository.InvoiceCursor{SortValue: "2024-01-10", ID: 1}
+	encoded := encodeInvoiceCursor(repository.InvoiceSortByDueDate, false, cursor)
+
+	tests := []struct {
+		name       string
+		sortKey    repository.InvoiceSortKey
+		descending bool
+		input      string
+		want       *repository.InvoiceCursor
+		wantErr    error
+	}{
+		{
+			name:    "同じ並び順で復元できる",
+			sortKey: repository.InvoiceSortByDueDate,
+			input:   encoded,
+			want:    cursor,
+		},
+		{
+			name:    "空文字の場合はnil",
+			sortKey: repository.InvoiceSortByDueDate,
+			input:   "",
+			want:    nil,
+		},
+		{
+			name:    "並び替えキーが異なる場合はエラー",
+			sortKey: repository.InvoiceSortByIssueDate,
+			input:   encoded,
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:       "並び順が異なる場合はエラー",
+			sortKey:    repository.InvoiceSortByDueDate,
+			descending: true,
+			input:      encoded,
+			wantErr:    ErrInvalidCursor,
+		},
+		{
+			name:    "不正な文字列の場合はエラー",
+			sortKey: repository.InvoiceSortByDueDate,
+			input:   "!!!",
+			wantErr: ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeInvoiceCursor(tt.sortKey, tt.descending, tt.input)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("cursor got != want (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
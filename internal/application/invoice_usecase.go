@@ -1,45 +1,92 @@
 package application
 
 import (
-	"os"
-	"strconv"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/take73/invoice-api-example/internal/domain/model"
 	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 )
 
 type InvoiceUsecase interface {
 	CreateInvoice(dto CreateInvoiceDto) (*InvoiceDto, error)
-	ListInvoice(dto ListInvoiceDto) ([]*InvoiceDto, error)
+	ListInvoice(dto ListInvoiceDto) (*InvoiceListDto, error)
+	GetInvoice(dto GetInvoiceDto) (*InvoiceDetailDto, error)
+	ChangeInvoiceStatus(dto ChangeInvoiceStatusDto) (*InvoiceDto, error)
+	ExportTransferFile(dto ExportTransferFileDto) (*TransferFileDto, error)
+	ReconcileStatement(dto ReconcileStatementDto) (*ReconciliationReportDto, error)
+	ReportDuplicateInvoices(dto ReportDuplicateInvoicesDto) (*DuplicateInvoiceReportDto, error)
 }
 type invoiceUsecase struct {
 	invoiceRepo      repository.Invoice
 	clientRepo       repository.Client
 	organizationRepo repository.Organization
+	userRepo         repository.User
 	taxRateRepo      repository.TaxRate
+	feeRateRepo      repository.FeeRate
 }
 
 func NewInvoiceUsecase(
 	invoiceRepo repository.Invoice,
 	clientRepo repository.Client,
 	organizationRepo repository.Organization,
+	userRepo repository.User,
 	taxRateRepo repository.TaxRate,
+	feeRateRepo repository.FeeRate,
 ) InvoiceUsecase {
 	return &invoiceUsecase{
 		invoiceRepo:      invoiceRepo,
 		clientRepo:       clientRepo,
 		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
 		taxRateRepo:      taxRateRepo,
+		feeRateRepo:      feeRateRepo,
 	}
 }
 
+// ErrLineItemAmountMismatch 指定された支払金額が明細から計算した金額と一致しない
+var ErrLineItemAmountMismatch = errors.New("amount does not match the total of line items")
+
 type CreateInvoiceDto struct {
-	UserID    uint
+	Principal Principal
 	ClientID  uint
 	IssueDate time.Time
-	Amount    int64
-	DueDate   time.Time
+	Amount    int64     // 明細を指定した場合は省略可（0）. 指定した場合は明細から計算した金額と一致すること
+	DueDate   time.Time // 省略した場合（ゼロ値）は組織の設定の支払期日までの日数から求める
+	LineItems []CreateInvoiceLineItemDto
+	// RequireQualifiedInvoice 適格請求書の記載事項が不足している場合に作成を拒否する.
+	// false の場合は作成したうえで InvoiceDto.Warnings に不足している事項を返す
+	RequireQualifiedInvoice bool
+	// ConfirmDuplicate 重複の疑いがある請求書があっても作成する.
+	// false の場合は DuplicateInvoiceError を返して作成しない
+	ConfirmDuplicate bool
+}
+
+type CreateInvoiceLineItemDto struct {
+	Description string
+	Quantity    float64
+	UnitPrice   int64
+	TaxCategory string
+}
+
+type TaxSummaryDto struct {
+	TaxCategory   string
+	TaxRate       float64
+	TaxableAmount int64
+	Tax           int64
+}
+
+type InvoiceLineItemDto struct {
+	LineNumber  int
+	Description string
+	Quantity    float64
+	UnitPrice   int64
+	Amount      int64
+	TaxCategory string
+	TaxRate     float64
 }
 
 type InvoiceDto struct {
@@ -52,48 +99,117 @@ type InvoiceDto struct {
 	Amount           int64
 	Fee              int64
 	FeeRate          float64
+	FeePlanID        uint // 適用した手数料プランID（プランを適用していない場合は0）
+	SettingsVersion  uint // 作成時に適用した組織の設定の版（設定を登録する前に作成した請求書は0）
 	Tax              int64
 	TaxRate          float64
 	TotalAmount      int64
+	WithholdingTax   int64 // 源泉徴収税額
+	TransferAmount   int64 // 取引先への振込金額（支払金額 - 源泉徴収税額）
 	DueDate          time.Time
 	Status           string
+	LineItems        []InvoiceLineItemDto // 明細を指定せずに作成した請求書は空
+	TaxSummaries     []TaxSummaryDto      // 税区分・税率ごとの集計（明細を指定せずに作成した請求書は空）
+	Warnings         []string             // 適格請求書の記載事項のうち不足しているもの（作成時のみ）
+	Version          uint                 // 版数（更新の条件に使う）
 }
 
 // CreateInvoice 請求書を作成する.
 // 現時点ではユースケース層に実装.
 // ロジックを再利用したい場合や複雑になった場合はドメインサービスを作ることを検討する.
 func (s *invoiceUsecase) CreateInvoice(invoice CreateInvoiceDto) (*InvoiceDto, error) {
-	// 会社を取得
-	organization, err := s.organizationRepo.GetByID(invoice.UserID)
+	// 操作主体の所属組織を請求元企業とする
+	organizationID, err := authorize(s.userRepo, invoice.Principal, model.PermissionWriteInvoice)
+	if err != nil {
+		return nil, err
+	}
+	organization, err := s.organizationRepo.GetByID(organizationID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 取引先を取得
+	// 作成時点の組織の設定を適用する
+	settings, err := latestOrganizationSettings(s.organizationRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	organization.RoundingPolicy = settings.RoundingPolicy
+
+	// 取引先を取得（請求元企業に属しているかは NewInvoice で検証する）
 	client, err := s.clientRepo.GetByID(invoice.ClientID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 手数料率を取得
-	var feeRate float64
-	if feeRateStr := os.Getenv("FEE_RATE"); feeRateStr != "" {
-		if parsedFeeRate, err := strconv.ParseFloat(feeRateStr, 64); err == nil {
-			feeRate = parsedFeeRate
+	// 発行日に適用される手数料プランを取得（プランがない場合は既定の手数料率）
+	feeRate, feePlanID := model.DefaultFeeRate, uint(0)
+	plan, err := s.feeRateRepo.GetPlanByDate(organizationID, invoice.IssueDate)
+	switch {
+	case err == nil:
+		feeRate, feePlanID = plan.Rate, plan.ID
+	case !errors.Is(err, commonErrors.ErrNotFound):
+		return nil, err
+	}
+	// 組織ごとの契約プランがない場合は、設定の既定のプランを標準プランより優先する
+	if settings.DefaultFeePlanID != 0 && (plan == nil || plan.OrganizationID == 0) {
+		defaultPlan, err := s.feeRateRepo.GetPlanByID(settings.DefaultFeePlanID)
+		if err != nil {
+			return nil, err
+		}
+		if defaultPlan.AppliesTo(invoice.IssueDate) {
+			feeRate, feePlanID = defaultPlan.Rate, defaultPlan.ID
 		}
 	}
 
+	dueDate := invoice.DueDate
+	if dueDate.IsZero() {
+		dueDate = settings.DueDate(invoice.IssueDate)
+	}
+
 	newInvoice, err := model.NewInvoice(
 		organization,
 		client,
 		invoice.Amount,
 		invoice.IssueDate,
-		invoice.DueDate,
+		dueDate,
 		feeRate,
 	)
 	if err != nil {
 		return nil, err
 	}
+	newInvoice.FeePlanID = feePlanID
+	newInvoice.SettingsVersion = settings.Version
+
+	// 明細を指定した場合は明細から支払金額を計算する
+	if len(invoice.LineItems) > 0 {
+		lineItems, err := s.newLineItems(invoice.IssueDate, invoice.LineItems, settings.RoundingPolicy)
+		if err != nil {
+			return nil, err
+		}
+		if err := newInvoice.SetLineItems(lineItems); err != nil {
+			return nil, err
+		}
+		if invoice.Amount != 0 && invoice.Amount != newInvoice.AmountAsInt() {
+			return nil, ErrLineItemAmountMismatch
+		}
+	}
+
+	// 適格請求書の記載事項を確認
+	missing := newInvoice.MissingQualifiedInvoiceFields()
+	if len(missing) > 0 && invoice.RequireQualifiedInvoice {
+		return nil, &model.QualifiedInvoiceError{Missing: missing}
+	}
+
+	// 同じ請求書の二重登録を検出
+	if !invoice.ConfirmDuplicate {
+		candidates, err := s.findLikelyDuplicates(newInvoice)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) > 0 {
+			return nil, &DuplicateInvoiceError{Candidates: candidates}
+		}
+	}
 
 	// 消費税率を取得して金額を計算
 	taxRate, err := s.taxRateRepo.GetRateByDate(invoice.IssueDate)
@@ -113,11 +229,67 @@ func (s *invoiceUsecase) CreateInvoice(invoice CreateInvoiceDto) (*InvoiceDto, e
 	if err != nil {
 		return nil, err
 	}
+	dto.Warnings = missing
 
 	return dto, nil
 }
 
+// newLineItems 明細行を生成する. 税率は発行日時点の税区分ごとの税率を適用し、金額の端数は組織の設定の端数処理で処理する
+func (s *invoiceUsecase) newLineItems(issueDate time.Time, dtos []CreateInvoiceLineItemDto, policy model.RoundingPolicy) ([]*model.InvoiceLineItem, error) {
+	rates := make(map[model.TaxCategory]float64)
+	items := make([]*model.InvoiceLineItem, len(dtos))
+	for i, dto := range dtos {
+		category := model.TaxCategory(dto.TaxCategory)
+		rate, ok := rates[category]
+		if !ok && category.IsTaxable() {
+			var err error
+			if rate, err = s.taxRateRepo.GetRateByCategory(issueDate, category); err != nil {
+				return nil, err
+			}
+			rates[category] = rate
+		}
+
+		item, err := model.NewInvoiceLineItem(
+			dto.Description,
+			decimal.NewFromFloat(dto.Quantity),
+			decimal.NewFromInt(dto.UnitPrice),
+			category,
+			rate,
+			policy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
 func (s *invoiceUsecase) invoiceToDto(invoice *model.Invoice) (*InvoiceDto, error) {
+	var lineItems []InvoiceLineItemDto
+	for _, item := range invoice.LineItems {
+		quantity, _ := item.Quantity.Float64()
+		lineItems = append(lineItems, InvoiceLineItemDto{
+			LineNumber:  item.LineNumber,
+			Description: item.Description,
+			Quantity:    quantity,
+			UnitPrice:   item.UnitPriceAsInt(),
+			Amount:      item.AmountAsInt(),
+			TaxCategory: string(item.TaxCategory),
+			TaxRate:     item.TaxRate,
+		})
+	}
+
+	var taxSummaries []TaxSummaryDto
+	for _, summary := range invoice.TaxSummaries() {
+		taxSummaries = append(taxSummaries, TaxSummaryDto{
+			TaxCategory:   string(summary.TaxCategory),
+			TaxRate:       summary.TaxRate,
+			TaxableAmount: summary.TaxableAmountAsInt(),
+			Tax:           summary.TaxAsInt(),
+		})
+	}
+
 	return &InvoiceDto{
 		ID:               invoice.ID,
 		OrganizationID:   invoice.Organization.ID,
@@ -128,35 +300,245 @@ func (s *invoiceUsecase) invoiceToDto(invoice *model.Invoice) (*InvoiceDto, erro
 		Amount:           invoice.AmountAsInt(),
 		Fee:              invoice.FeeAsInt(),
 		FeeRate:          invoice.FeeRate,
+		FeePlanID:        invoice.FeePlanID,
+		SettingsVersion:  invoice.SettingsVersion,
 		Tax:              invoice.TaxAsInt(),
 		TaxRate:          invoice.TaxRate,
 		TotalAmount:      invoice.TotalAmountAsInt(),
+		WithholdingTax:   invoice.WithholdingTaxAsInt(),
+		TransferAmount:   invoice.TransferAmountAsInt(),
 		DueDate:          invoice.DueDate,
 		Status:           string(invoice.Status),
+		LineItems:        lineItems,
+		TaxSummaries:     taxSummaries,
+		Version:          invoice.Version,
 	}, nil
 }
 
+const (
+	DefaultInvoicePageSize = 50  // 1ページあたりの件数（既定値）
+	MaxInvoicePageSize     = 100 // 1ページあたりの件数（上限）
+)
+
 type ListInvoiceDto struct {
-	StartDate time.Time
-	EndDate   time.Time
+	Principal      Principal
+	StartDate      time.Time // 支払期日（開始）
+	EndDate        time.Time // 支払期日（終了）
+	IssueStartDate time.Time // 発行日（開始）
+	IssueEndDate   time.Time // 発行日（終了）
+	Statuses       []string
+	ClientID       uint
+	MinAmount      *int64 // 支払金額の下限
+	MaxAmount      *int64 // 支払金額の上限
+	Sort           string // due_date, issue_date, total_amount, created_at（既定: due_date）
+	Order          string // asc, desc（既定: asc）
+	Cursor         string // 前のページの NextCursor
+	Limit          int    // 1ページあたりの件数（既定: DefaultInvoicePageSize）
+}
+
+type InvoiceListDto struct {
+	Invoices   []*InvoiceDto
+	NextCursor string // 次のページがない場合は空文字
 }
 
-func (s *invoiceUsecase) ListInvoice(dto ListInvoiceDto) ([]*InvoiceDto, error) {
-	// 指定された日付範囲内の請求書を取得
-	invoices, err := s.invoiceRepo.FindByDueDateRange(dto.StartDate, dto.EndDate)
+func (s *invoiceUsecase) ListInvoice(dto ListInvoiceDto) (*InvoiceListDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadInvoice)
+	if err != nil {
+		return nil, err
+	}
+
+	sortKey := repository.InvoiceSortByDueDate
+	if dto.Sort != "" {
+		sortKey = repository.InvoiceSortKey(dto.Sort)
+	}
+	descending := dto.Order == "desc"
+	after, err := decodeInvoiceCursor(sortKey, descending, dto.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := dto.Limit
+	if limit <= 0 {
+		limit = DefaultInvoicePageSize
+	}
+	if limit > MaxInvoicePageSize {
+		limit = MaxInvoicePageSize
+	}
+
+	condition := repository.InvoiceSearchCondition{
+		OrganizationID: organizationID,
+		DueDateFrom:    dto.StartDate,
+		DueDateTo:      dto.EndDate,
+		IssueDateFrom:  dto.IssueStartDate,
+		IssueDateTo:    dto.IssueEndDate,
+		ClientID:       dto.ClientID,
+		SortKey:        sortKey,
+		Descending:     descending,
+		After:          after,
+		Limit:          limit,
+	}
+	for _, status := range dto.Statuses {
+		condition.Statuses = append(condition.Statuses, model.InvoiceStatus(status))
+	}
+	if dto.MinAmount != nil {
+		minAmount := decimal.NewFromInt(*dto.MinAmount)
+		condition.MinAmount = &minAmount
+	}
+	if dto.MaxAmount != nil {
+		maxAmount := decimal.NewFromInt(*dto.MaxAmount)
+		condition.MaxAmount = &maxAmount
+	}
+
+	// 自組織の請求書を検索
+	page, err := s.invoiceRepo.Search(condition)
 	if err != nil {
 		return nil, err
 	}
 
 	// DTOリストに変換
-	result := make([]*InvoiceDto, len(invoices))
-	for i, invoice := range invoices {
+	result := &InvoiceListDto{
+		Invoices:   make([]*InvoiceDto, len(page.Invoices)),
+		NextCursor: encodeInvoiceCursor(sortKey, descending, page.NextCursor),
+	}
+	for i, invoice := range page.Invoices {
 		dto, err := s.invoiceToDto(invoice)
 		if err != nil {
 			return nil, err
 		}
-		result[i] = dto
+		result.Invoices[i] = dto
 	}
 
 	return result, nil
 }
+
+type GetInvoiceDto struct {
+	Principal Principal
+	ID        uint
+}
+
+type InvoiceDetailDto struct {
+	InvoiceDto
+	Organization OrganizationDto
+	Client       ClientDto
+	BankAccount  *BankAccountDto // 振込先口座が未登録の場合はnil
+}
+
+type OrganizationDto struct {
+	ID                 uint
+	Name               string
+	RegistrationNumber string // 未登録の場合は空文字
+	Representative     string
+	PhoneNumber        string
+	PostalCode         string
+	Address            string
+}
+
+type ClientDto struct {
+	ID                 uint
+	Name               string
+	RegistrationNumber string // 未登録の場合は空文字
+	Representative     string
+	PhoneNumber        string
+	PostalCode         string
+	Address            string
+}
+
+type BankAccountDto struct {
+	ID                  uint
+	BankName            string
+	BranchName          string
+	MaskedAccountNumber string // 末尾以外をマスクした口座番号
+	AccountName         string
+}
+
+// GetInvoice 請求書を取得する. 請求元企業・請求先取引先の詳細と振込先口座を含む
+func (s *invoiceUsecase) GetInvoice(dto GetInvoiceDto) (*InvoiceDetailDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadInvoice)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.invoiceRepo.FindByID(organizationID, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	invoiceDto, err := s.invoiceToDto(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &InvoiceDetailDto{
+		InvoiceDto:   *invoiceDto,
+		Organization: *organizationToDto(invoice.Organization),
+		Client: ClientDto{
+			ID:                 invoice.Client.ID,
+			Name:               invoice.Client.Name,
+			RegistrationNumber: invoice.Client.RegistrationNumber,
+			Representative:     invoice.Client.Representative,
+			PhoneNumber:        invoice.Client.PhoneNumber,
+			PostalCode:         invoice.Client.PostalCode,
+			Address:            invoice.Client.Address,
+		},
+	}
+	if account := invoice.Client.BankAccount; account != nil {
+		detail.BankAccount = &BankAccountDto{
+			ID:                  account.ID,
+			BankName:            account.BankName,
+			BranchName:          account.BranchName,
+			MaskedAccountNumber: account.MaskedAccountNumber(),
+			AccountName:         account.AccountName,
+		}
+	}
+
+	return detail, nil
+}
+
+// ErrVersionMismatch 指定された版数が請求書の現在の版数と一致しない（取得した後に他のリクエストで更新された）
+var ErrVersionMismatch = errors.New("invoice has been modified since it was retrieved")
+
+type ChangeInvoiceStatusDto struct {
+	Principal Principal
+	ID        uint
+	Status    string
+	Version   uint // 変更の前提とする請求書の版数
+}
+
+// ChangeInvoiceStatus 請求書のステータスを遷移させ、変更履歴を記録する.
+// 請求書の版数が dto.Version でない場合、または並行して更新された場合は ErrVersionMismatch、
+// 許可されていない遷移の場合は model.InvalidStatusTransitionError を返す
+func (s *invoiceUsecase) ChangeInvoiceStatus(dto ChangeInvoiceStatusDto) (*InvoiceDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionWriteInvoiceStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.invoiceRepo.FindByID(organizationID, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Version != dto.Version {
+		return nil, ErrVersionMismatch
+	}
+
+	from := invoice.Status
+	if err := invoice.TransitionTo(model.InvoiceStatus(dto.Status)); err != nil {
+		return nil, err
+	}
+
+	history := &model.InvoiceStatusHistory{
+		InvoiceID:  invoice.ID,
+		FromStatus: from,
+		ToStatus:   invoice.Status,
+		ChangedBy:  dto.Principal.Subject,
+	}
+	if err := s.invoiceRepo.UpdateStatus(organizationID, dto.Version, history); err != nil {
+		if errors.Is(err, commonErrors.ErrConflict) {
+			return nil, ErrVersionMismatch
+		}
+		return nil, err
+	}
+	invoice.Version++
+
+	return s.invoiceToDto(invoice)
+}
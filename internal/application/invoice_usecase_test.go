@@ -0,0 +1,391 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+func (r *inMemoryInvoiceRepository) Create(invoice *model.Invoice) (*model.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := *invoice
+	created.ID = uint(len(r.invoices) + 1)
+	r.invoices[created.ID] = &created
+	return &created, nil
+}
+
+func (r *inMemoryInvoiceRepository) FindByID(organizationID, id uint) (*model.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invoice, ok := r.invoices[id]
+	if !ok || invoice.Organization.ID != organizationID {
+		return nil, commonErrors.ErrNotFound
+	}
+	copied := *invoice
+	return &copied, nil
+}
+
+func (r *inMemoryOrganizationRepository) GetByID(id uint) (*model.Organization, error) {
+	organization, ok := r.organizations[id]
+	if !ok {
+		return nil, commonErrors.ErrNotFound
+	}
+	return organization, nil
+}
+
+// inMemoryClientRepository 取引先の取得だけを実装したインメモリの取引先リポジトリ
+type inMemoryClientRepository struct {
+	repository.Client // 使わないメソッドは実装しない
+
+	clients map[uint]*model.Client
+}
+
+func (r *inMemoryClientRepository) GetByID(id uint) (*model.Client, error) {
+	client, ok := r.clients[id]
+	if !ok {
+		return nil, commonErrors.ErrNotFound
+	}
+	return client, nil
+}
+
+// fixedTaxRateRepository 日付によらず税区分ごとに固定の税率を返す税率リポジトリ
+type fixedTaxRateRepository struct {
+	repository.TaxRate // 使わないメソッドは実装しない
+
+	rates map[model.TaxCategory]float64
+}
+
+func (r fixedTaxRateRepository) GetRateByDate(date time.Time) (float64, error) {
+	return r.GetRateByCategory(date, model.TaxCategoryStandard)
+}
+
+func (r fixedTaxRateRepository) GetRateByCategory(date time.Time, category model.TaxCategory) (float64, error) {
+	rate, ok := r.rates[category]
+	if !ok {
+		return 0, commonErrors.ErrNotFound
+	}
+	return rate, nil
+}
+
+// inMemoryFeeRateRepository 組織ごとのプランを標準プラン（OrganizationID が0）より優先するインメモリの手数料プランリポジトリ
+type inMemoryFeeRateRepository []*model.FeePlan
+
+func (r inMemoryFeeRateRepository) GetPlanByDate(organizationID uint, date time.Time) (*model.FeePlan, error) {
+	var found *model.FeePlan
+	for _, plan := range r {
+		if plan.OrganizationID != 0 && plan.OrganizationID != organizationID {
+			continue
+		}
+		if plan.StartDate.After(date) || (plan.EndDate != nil && plan.EndDate.Before(date)) {
+			continue
+		}
+		if found == nil || (found.OrganizationID == 0 && plan.OrganizationID != 0) {
+			found = plan
+		}
+	}
+	if found == nil {
+		return nil, commonErrors.ErrNotFound
+	}
+	return found, nil
+}
+
+func (r inMemoryFeeRateRepository) GetPlanByID(id uint) (*model.FeePlan, error) {
+	for _, plan := range r {
+		if plan.ID == id {
+			return plan, nil
+		}
+	}
+	return nil, commonErrors.ErrNotFound
+}
+
+func Test_InvoiceUsecase_CreateInvoice(t *testing.T) {
+	contractEnd := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	feePlans := inMemoryFeeRateRepository{
+		{ID: 1, Name: "標準プラン", Rate: 0.04, StartDate: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, OrganizationID: 1, Name: "大口契約プラン", Rate: 0.03, StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &contractEnd},
+	}
+	organizationRepo := &inMemoryOrganizationRepository{
+		organizations: map[uint]*model.Organization{
+			1: {ID: 1, Name: "株式会社サンプル", RegistrationNumber: "T7000012050002"},
+			2: {ID: 2, Name: "有限会社テスト"},
+			3: {ID: 3, Name: "合同会社設定済み"},
+		},
+		settings: map[uint][]*model.OrganizationSettings{
+			3: {
+				{OrganizationID: 3, Version: 1, RoundingPolicy: model.RoundingTruncate, PaymentTermsDays: 30},
+				{OrganizationID: 3, Version: 2, DefaultFeePlanID: 3, RoundingPolicy: model.RoundingCeil, PaymentTermsDays: 14},
+			},
+		},
+	}
+	clientRepo := &inMemoryClientRepository{
+		clients: map[uint]*model.Client{
+			1: {ID: 1, OrganizationID: 1, Name: "取引先A", RegistrationNumber: "T7000012050002"},
+			3: {ID: 3, OrganizationID: 2, Name: "取引先C"},
+			4: {ID: 4, OrganizationID: 1, Name: "取引先D", ArchivedAt: &contractEnd},
+			5: {ID: 5, OrganizationID: 3, Name: "取引先E"},
+			6: {ID: 6, OrganizationID: 1, Name: "取引先F"},
+		},
+	}
+	taxRateRepo := fixedTaxRateRepository{rates: map[model.TaxCategory]float64{model.TaxCategoryStandard: 0.1, model.TaxCategoryReduced: 0.08}}
+
+	tests := []struct {
+		name     string
+		feePlans inMemoryFeeRateRepository
+		dto      application.CreateInvoiceDto
+		want     *application.InvoiceDto
+		wantErr  error
+	}{
+		{
+			name:     "契約期間中は組織のプランを適用",
+			feePlans: feePlans,
+			dto: application.CreateInvoiceDto{
+				Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes},
+				ClientID:  1,
+				IssueDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    10000,
+				DueDate:   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			},
+			want: &application.InvoiceDto{
+				ID: 1, OrganizationID: 1, OrganizationName: "株式会社サンプル", ClientID: 1, ClientName: "取引先A", Version: 1,
+				IssueDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    10000, Fee: 300, FeeRate: 0.03, FeePlanID: 2, Tax: 30, TaxRate: 0.1, TotalAmount: 10330, TransferAmount: 10000,
+				DueDate:  time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+				Status:   "pending",
+				Warnings: []string{"line items are required to show taxable amounts and taxes per tax rate"},
+			},
+		},
+		{
+			name:     "契約期間外は標準プランを適用",
+			feePlans: feePlans,
+			dto: application.CreateInvoiceDto{
+				Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes},
+				ClientID:  1,
+				IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				DueDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+				LineItems: []application.CreateInvoiceLineItemDto{
+					{Description: "コンサルティング", Quantity: 2, UnitPrice: 5000, TaxCategory: "standard"},
+					{Description: "お弁当", Quantity: 10, UnitPrice: 500, TaxCategory: "reduced"},
+				},
+			},
+			want: &application.InvoiceDto{
+				ID: 1, OrganizationID: 1, OrganizationName: "株式会社サンプル", ClientID: 1, ClientName: "取引先A", Version: 1,
+				IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    16400, Fee: 656, FeeRate: 0.04, FeePlanID: 1, Tax: 65, TaxRate: 0.1, TotalAmount: 17121, TransferAmount: 16400,
+				DueDate: time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+				Status:  "pending",
+				LineItems: []application.InvoiceLineItemDto{
+					{LineNumber: 1, Description: "コンサルティング", Quantity: 2, UnitPrice: 5000, Amount: 10000, TaxCategory: "standard", TaxRate: 0.1},
+					{LineNumber: 2, Description: "お弁当", Quantity: 10, UnitPrice: 500, Amount: 5000, TaxCategory: "reduced", TaxRate: 0.08},
+				},
+				TaxSummaries: []application.TaxSummaryDto{
+					{TaxCategory: "standard", TaxRate: 0.1, TaxableAmount: 10000, Tax: 1000},
+					{TaxCategory: "reduced", TaxRate: 0.08, TaxableAmount: 5000, Tax: 400},
+				},
+			},
+		},
+		{
+			name: "プランがない場合は既定の手数料率",
+			dto: application.CreateInvoiceDto{
+				Principal: application.Principal{OrganizationID: 2, Scopes: clientScopes},
+				ClientID:  3,
+				IssueDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    10000,
+				DueDate:   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			},
+			want: &application.InvoiceDto{
+				ID: 1, OrganizationID: 2, OrganizationName: "有限会社テスト", ClientID: 3, ClientName: "取引先C", Version: 1,
+				IssueDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    10000, Fee: 400, FeeRate: model.DefaultFeeRate, Tax: 40, TaxRate: 0.1, TotalAmount: 10440, TransferAmount: 10000,
+				DueDate: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+				Status:  "pending",
+				Warnings: []string{
+					"registration number of the organization is not registered",
+					"registration number of the client is not registered",
+					"line items are required to show taxable amounts and taxes per tax rate",
+				},
+			},
+		},
+		{
+			name:     "組織の設定の既定のプラン・端数処理・支払期日を適用",
+			feePlans: append(feePlans, &model.FeePlan{ID: 3, Name: "優待プラン", Rate: 0.025, StartDate: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}),
+			dto: application.CreateInvoiceDto{
+				Principal: application.Principal{OrganizationID: 3, Scopes: clientScopes},
+				ClientID:  5,
+				IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    10005,
+			},
+			want: &application.InvoiceDto{
+				ID: 1, OrganizationID: 3, OrganizationName: "合同会社設定済み", ClientID: 5, ClientName: "取引先E", Version: 1,
+				IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    10005, Fee: 251, FeeRate: 0.025, FeePlanID: 3, SettingsVersion: 2, Tax: 26, TaxRate: 0.1, TotalAmount: 10282, TransferAmount: 10005,
+				DueDate: time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC),
+				Status:  "pending",
+				Warnings: []string{
+					"registration number of the organization is not registered",
+					"registration number of the client is not registered",
+					"line items are required to show taxable amounts and taxes per tax rate",
+				},
+			},
+		},
+		{
+			name:     "支払金額が明細の合計と一致しない",
+			feePlans: feePlans,
+			dto: application.CreateInvoiceDto{
+				Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes},
+				ClientID:  1,
+				IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    10000,
+				DueDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+				LineItems: []application.CreateInvoiceLineItemDto{
+					{Description: "コンサルティング", Quantity: 1, UnitPrice: 10000, TaxCategory: "standard"},
+				},
+			},
+			wantErr: application.ErrLineItemAmountMismatch,
+		},
+		{
+			name:     "他組織の取引先",
+			feePlans: feePlans,
+			dto: application.CreateInvoiceDto{
+				Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes},
+				ClientID:  3,
+				IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    10000,
+				DueDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: model.ErrClientNotInOrganization,
+		},
+		{
+			name:     "アーカイブした取引先",
+			feePlans: feePlans,
+			dto: application.CreateInvoiceDto{
+				Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes},
+				ClientID:  4,
+				IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    10000,
+				DueDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: model.ErrClientArchived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := application.NewInvoiceUsecase(newInMemoryInvoiceRepository(), clientRepo, organizationRepo, newInMemoryUserRepository(), taxRateRepo, tt.feePlans)
+
+			got, err := usecase.CreateInvoice(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("invoice mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("適格請求書を求めた場合は取引先の登録番号がなければ作成しない", func(t *testing.T) {
+		usecase := application.NewInvoiceUsecase(newInMemoryInvoiceRepository(), clientRepo, organizationRepo, newInMemoryUserRepository(), taxRateRepo, feePlans)
+
+		_, err := usecase.CreateInvoice(application.CreateInvoiceDto{
+			Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes},
+			ClientID:  6,
+			IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			DueDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+			LineItems: []application.CreateInvoiceLineItemDto{
+				{Description: "コンサルティング", Quantity: 1, UnitPrice: 10000, TaxCategory: "standard"},
+			},
+			RequireQualifiedInvoice: true,
+		})
+
+		var qualifiedErr *model.QualifiedInvoiceError
+		if !errors.As(err, &qualifiedErr) {
+			t.Fatalf("error = %v, want QualifiedInvoiceError", err)
+		}
+		if diff := cmp.Diff([]string{"registration number of the client is not registered"}, qualifiedErr.Missing); diff != "" {
+			t.Errorf("missing fields mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func Test_InvoiceUsecase_ChangeInvoiceStatus(t *testing.T) {
+	dueDate := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	newRepo := func() *inMemoryInvoiceRepository {
+		invoice := newPaymentTestInvoice(1, 10000, dueDate, model.StatusPending, nil)
+		invoice.Version = 3
+		return newInMemoryInvoiceRepository(invoice)
+	}
+	principal := application.Principal{Subject: "auth0|user1", OrganizationID: 1, Scopes: clientScopes}
+
+	t.Run("版数が一致する場合は遷移させて版数を1増やす", func(t *testing.T) {
+		repo := newRepo()
+		usecase := application.NewInvoiceUsecase(repo, nil, nil, newInMemoryUserRepository(), nil, nil)
+
+		got, err := usecase.ChangeInvoiceStatus(application.ChangeInvoiceStatusDto{Principal: principal, ID: 1, Status: "processing", Version: 3})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Status != "processing" || got.Version != 4 {
+			t.Errorf("status = %s, version = %d, want processing, 4", got.Status, got.Version)
+		}
+		if repo.invoices[1].Version != 4 {
+			t.Errorf("stored version = %d, want 4", repo.invoices[1].Version)
+		}
+	})
+
+	t.Run("版数が一致しない場合は遷移させない", func(t *testing.T) {
+		repo := newRepo()
+		usecase := application.NewInvoiceUsecase(repo, nil, nil, newInMemoryUserRepository(), nil, nil)
+
+		_, err := usecase.ChangeInvoiceStatus(application.ChangeInvoiceStatusDto{Principal: principal, ID: 1, Status: "processing", Version: 2})
+
+		if !errors.Is(err, application.ErrVersionMismatch) {
+			t.Errorf("error = %v, want ErrVersionMismatch", err)
+		}
+		if repo.invoices[1].Status != model.StatusPending || repo.invoices[1].Version != 3 {
+			t.Errorf("invoice was updated: %+v", repo.invoices[1])
+		}
+	})
+
+	t.Run("取得した後に更新された場合は版数の不一致とする", func(t *testing.T) {
+		repo := &concurrentlyUpdatedInvoiceRepository{inMemoryInvoiceRepository: newRepo()}
+		usecase := application.NewInvoiceUsecase(repo, nil, nil, newInMemoryUserRepository(), nil, nil)
+
+		_, err := usecase.ChangeInvoiceStatus(application.ChangeInvoiceStatusDto{Principal: principal, ID: 1, Status: "processing", Version: 3})
+
+		if !errors.Is(err, application.ErrVersionMismatch) {
+			t.Errorf("error = %v, want ErrVersionMismatch", err)
+		}
+	})
+}
+
+// concurrentlyUpdatedInvoiceRepository 請求書を取得した直後に他のリクエストが版数を進めたように振る舞う
+type concurrentlyUpdatedInvoiceRepository struct {
+	*inMemoryInvoiceRepository
+}
+
+func (r *concurrentlyUpdatedInvoiceRepository) FindByID(organizationID, id uint) (*model.Invoice, error) {
+	invoice, err := r.inMemoryInvoiceRepository.FindByID(organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.invoices[id].Version++
+	r.mu.Unlock()
+	return invoice, nil
+}
@@ -0,0 +1,284 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// OrganizationUsecase 組織のプロフィールと設定の管理. 操作主体の所属組織に限る
+type OrganizationUsecase interface {
+	GetOrganization(dto GetOrganizationDto) (*OrganizationDto, error)
+	UpdateOrganization(dto UpdateOrganizationDto) (*OrganizationDto, error)
+	GetSettings(dto GetOrganizationDto) (*OrganizationSettingsDto, error)
+	UpdateSettings(dto UpdateOrganizationSettingsDto) (*OrganizationSettingsDto, error)
+	ListSettingsVersions(dto GetOrganizationDto) ([]*OrganizationSettingsDto, error)
+	GetSettingsVersion(dto GetOrganizationSettingsVersionDto) (*OrganizationSettingsDto, error)
+}
+
+type organizationUsecase struct {
+	organizationRepo repository.Organization
+	userRepo         repository.User
+	feeRateRepo      repository.FeeRate
+}
+
+func NewOrganizationUsecase(organizationRepo repository.Organization, userRepo repository.User, feeRateRepo repository.FeeRate) OrganizationUsecase {
+	return &organizationUsecase{
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+		feeRateRepo:      feeRateRepo,
+	}
+}
+
+type GetOrganizationDto struct {
+	Principal Principal
+}
+
+type UpdateOrganizationDto struct {
+	Principal          Principal
+	Name               string
+	RegistrationNumber string // 未登録の場合は空文字
+	Representative     string
+	PhoneNumber        string // 任意
+	PostalCode         string
+	Address            string
+}
+
+type GetOrganizationSettingsVersionDto struct {
+	Principal Principal
+	Version   uint
+}
+
+type UpdateOrganizationSettingsDto struct {
+	Principal        Principal
+	DefaultFeePlanID uint   // 指定しない場合は0
+	RoundingPolicy   string // 空の場合は切り捨て
+	PaymentTermsDays int    // 0の場合は DefaultPaymentTermsDays
+	PaymentMethod    string // 空の場合は振込ファイル
+	Notification     NotificationPreferencesDto
+	Now              time.Time
+}
+
+type NotificationPreferencesDto struct {
+	Email          string // 通知しない場合は空文字
+	OnPaid         bool
+	OnPaymentError bool
+	OnOverdue      bool
+}
+
+type OrganizationSettingsDto struct {
+	Version          uint // 設定を登録していない場合は0
+	DefaultFeePlanID uint // 指定していない場合は0
+	RoundingPolicy   string
+	PaymentTermsDays int
+	PaymentMethod    string
+	Notification     NotificationPreferencesDto
+	CreatedBy        string     // 設定を登録していない場合は空文字
+	CreatedAt        *time.Time // 設定を登録していない場合はnil
+}
+
+func (s *organizationUsecase) GetOrganization(dto GetOrganizationDto) (*OrganizationDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadOrganization)
+	if err != nil {
+		return nil, err
+	}
+
+	organization, err := s.organizationRepo.GetByID(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	return organizationToDto(organization), nil
+}
+
+// UpdateOrganization 組織のプロフィールを更新する
+func (s *organizationUsecase) UpdateOrganization(dto UpdateOrganizationDto) (*OrganizationDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionWriteOrganization)
+	if err != nil {
+		return nil, err
+	}
+
+	organization, err := s.organizationRepo.GetByID(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	organization.Name = dto.Name
+	organization.RegistrationNumber = dto.RegistrationNumber
+	organization.Representative = dto.Representative
+	organization.PhoneNumber = dto.PhoneNumber
+	organization.PostalCode = dto.PostalCode
+	organization.Address = dto.Address
+	if err := organization.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.organizationRepo.UpdateProfile(organization)
+	if err != nil {
+		return nil, err
+	}
+	return organizationToDto(updated), nil
+}
+
+// GetSettings 最新の設定を取得する. 設定を登録していない場合は既定の設定を返す
+func (s *organizationUsecase) GetSettings(dto GetOrganizationDto) (*OrganizationSettingsDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadOrganization)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.latestSettings(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	return organizationSettingsToDto(settings), nil
+}
+
+// UpdateSettings 設定の新しい版を登録する. 過去の版は変更しない
+func (s *organizationUsecase) UpdateSettings(dto UpdateOrganizationSettingsDto) (*OrganizationSettingsDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionWriteOrganization)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.latestSettings(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &model.OrganizationSettings{
+		OrganizationID:   organizationID,
+		Version:          latest.Version + 1,
+		DefaultFeePlanID: dto.DefaultFeePlanID,
+		RoundingPolicy:   model.RoundingPolicy(dto.RoundingPolicy),
+		PaymentTermsDays: dto.PaymentTermsDays,
+		PaymentMethod:    model.PaymentMethod(dto.PaymentMethod),
+		Notification: model.NotificationPreferences{
+			Email:          dto.Notification.Email,
+			OnPaid:         dto.Notification.OnPaid,
+			OnPaymentError: dto.Notification.OnPaymentError,
+			OnOverdue:      dto.Notification.OnOverdue,
+		},
+		CreatedBy: dto.Principal.Subject,
+		CreatedAt: dto.Now,
+	}
+	if settings.RoundingPolicy == "" {
+		settings.RoundingPolicy = model.DefaultRoundingPolicy
+	}
+	if settings.PaymentTermsDays == 0 {
+		settings.PaymentTermsDays = model.DefaultPaymentTermsDays
+	}
+	if settings.PaymentMethod == "" {
+		settings.PaymentMethod = model.DefaultPaymentMethod
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.validateFeePlan(organizationID, settings.DefaultFeePlanID); err != nil {
+		return nil, err
+	}
+
+	created, err := s.organizationRepo.CreateSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	return organizationSettingsToDto(created), nil
+}
+
+// ListSettingsVersions 設定の全ての版を新しい順に取得する
+func (s *organizationUsecase) ListSettingsVersions(dto GetOrganizationDto) ([]*OrganizationSettingsDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadOrganization)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.organizationRepo.FindSettingsVersions(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*OrganizationSettingsDto, len(versions))
+	for i, settings := range versions {
+		result[i] = organizationSettingsToDto(settings)
+	}
+	return result, nil
+}
+
+// GetSettingsVersion 指定した版の設定を取得する. 請求書の作成時に適用した設定の確認に使う
+func (s *organizationUsecase) GetSettingsVersion(dto GetOrganizationSettingsVersionDto) (*OrganizationSettingsDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadOrganization)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.organizationRepo.GetSettingsByVersion(organizationID, dto.Version)
+	if err != nil {
+		return nil, err
+	}
+	return organizationSettingsToDto(settings), nil
+}
+
+func (s *organizationUsecase) latestSettings(organizationID uint) (*model.OrganizationSettings, error) {
+	return latestOrganizationSettings(s.organizationRepo, organizationID)
+}
+
+// latestOrganizationSettings 組織の最新の設定を取得する. 設定を登録していない場合は既定の設定（版数0）を返す
+func latestOrganizationSettings(organizationRepo repository.Organization, organizationID uint) (*model.OrganizationSettings, error) {
+	settings, err := organizationRepo.GetSettings(organizationID)
+	if errors.Is(err, commonErrors.ErrNotFound) {
+		return model.DefaultOrganizationSettings(organizationID), nil
+	}
+	return settings, err
+}
+
+// validateFeePlan 既定の手数料プランが存在し、組織が選択できるプランであることを確認する
+func (s *organizationUsecase) validateFeePlan(organizationID, feePlanID uint) error {
+	if feePlanID == 0 {
+		return nil
+	}
+
+	plan, err := s.feeRateRepo.GetPlanByID(feePlanID)
+	if err != nil && !errors.Is(err, commonErrors.ErrNotFound) {
+		return err
+	}
+	if plan == nil || !plan.IsAvailableTo(organizationID) {
+		return fmt.Errorf("%w: default fee plan %d is not available", model.ErrInvalidOrganizationSettings, feePlanID)
+	}
+	return nil
+}
+
+func organizationToDto(organization *model.Organization) *OrganizationDto {
+	return &OrganizationDto{
+		ID:                 organization.ID,
+		Name:               organization.Name,
+		RegistrationNumber: organization.RegistrationNumber,
+		Representative:     organization.Representative,
+		PhoneNumber:        organization.PhoneNumber,
+		PostalCode:         organization.PostalCode,
+		Address:            organization.Address,
+	}
+}
+
+func organizationSettingsToDto(settings *model.OrganizationSettings) *OrganizationSettingsDto {
+	dto := &OrganizationSettingsDto{
+		Version:          settings.Version,
+		DefaultFeePlanID: settings.DefaultFeePlanID,
+		RoundingPolicy:   string(settings.RoundingPolicy),
+		PaymentTermsDays: settings.PaymentTermsDays,
+		PaymentMethod:    string(settings.PaymentMethod),
+		Notification: NotificationPreferencesDto{
+			Email:          settings.Notification.Email,
+			OnPaid:         settings.Notification.OnPaid,
+			OnPaymentError: settings.Notification.OnPaymentError,
+			OnOverdue:      settings.Notification.OnOverdue,
+		},
+		CreatedBy: settings.CreatedBy,
+	}
+	if settings.Version != 0 {
+		createdAt := settings.CreatedAt
+		dto.CreatedAt = &createdAt
+	}
+	return dto
+}
@@ -0,0 +1,268 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+func (r *inMemoryOrganizationRepository) UpdateProfile(organization *model.Organization) (*model.Organization, error) {
+	if _, ok := r.organizations[organization.ID]; !ok {
+		return nil, commonErrors.ErrNotFound
+	}
+	updated := *organization
+	r.organizations[organization.ID] = &updated
+	return &updated, nil
+}
+
+func (r *inMemoryOrganizationRepository) GetSettings(organizationID uint) (*model.OrganizationSettings, error) {
+	versions := r.settings[organizationID]
+	if len(versions) == 0 {
+		return nil, commonErrors.ErrNotFound
+	}
+	return versions[len(versions)-1], nil
+}
+
+func (r *inMemoryOrganizationRepository) GetSettingsByVersion(organizationID, version uint) (*model.OrganizationSettings, error) {
+	for _, settings := range r.settings[organizationID] {
+		if settings.Version == version {
+			return settings, nil
+		}
+	}
+	return nil, commonErrors.ErrNotFound
+}
+
+func (r *inMemoryOrganizationRepository) FindSettingsVersions(organizationID uint) ([]*model.OrganizationSettings, error) {
+	versions := r.settings[organizationID]
+	found := make([]*model.OrganizationSettings, len(versions))
+	for i, settings := range versions {
+		found[len(versions)-1-i] = settings
+	}
+	return found, nil
+}
+
+func (r *inMemoryOrganizationRepository) CreateSettings(settings *model.OrganizationSettings) (*model.OrganizationSettings, error) {
+	if _, err := r.GetSettingsByVersion(settings.OrganizationID, settings.Version); err == nil {
+		return nil, commonErrors.ErrConflict
+	}
+	if r.settings == nil {
+		r.settings = map[uint][]*model.OrganizationSettings{}
+	}
+	created := *settings
+	r.settings[settings.OrganizationID] = append(r.settings[settings.OrganizationID], &created)
+	r.organizations[settings.OrganizationID].RoundingPolicy = settings.RoundingPolicy
+	return &created, nil
+}
+
+func newOrganizationUsecaseForTest() (application.OrganizationUsecase, *inMemoryOrganizationRepository) {
+	organizationRepo := &inMemoryOrganizationRepository{
+		organizations: map[uint]*model.Organization{
+			1: {ID: 1, Name: "株式会社サンプル", Representative: "山田 太郎", PostalCode: "100-0001", Address: "東京都千代田区丸の内1-1-1"},
+			2: {ID: 2, Name: "有限会社テスト"},
+		},
+		settings: map[uint][]*model.OrganizationSettings{
+			1: {{OrganizationID: 1, Version: 1, RoundingPolicy: model.RoundingTruncate, PaymentTermsDays: 30, PaymentMethod: model.PaymentMethodTransferFile, CreatedBy: "migration"}},
+		},
+	}
+	feePlans := inMemoryFeeRateRepository{
+		{ID: 1, Name: "標準プラン", Rate: 0.04, StartDate: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, OrganizationID: 1, Name: "大口契約プラン", Rate: 0.03, StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	return application.NewOrganizationUsecase(organizationRepo, newInMemoryUserRepository(), feePlans), organizationRepo
+}
+
+func Test_OrganizationUsecase_UpdateOrganization(t *testing.T) {
+	valid := application.UpdateOrganizationDto{
+		Principal:          application.Principal{OrganizationID: 1, Scopes: clientScopes},
+		Name:               "株式会社サンプルホールディングス",
+		RegistrationNumber: "T7123456789012",
+		Representative:     "山田 花子",
+		PostalCode:         "100-0005",
+		Address:            "東京都千代田区丸の内2-2-2",
+	}
+	invalid := valid
+	invalid.RegistrationNumber = "T8000012050002"
+
+	tests := []struct {
+		name    string
+		dto     application.UpdateOrganizationDto
+		want    *application.OrganizationDto
+		wantErr error
+	}{
+		{
+			name: "プロフィールを更新",
+			dto:  valid,
+			want: &application.OrganizationDto{
+				ID: 1, Name: "株式会社サンプルホールディングス", RegistrationNumber: "T7123456789012",
+				Representative: "山田 花子", PostalCode: "100-0005", Address: "東京都千代田区丸の内2-2-2",
+			},
+		},
+		{
+			name:    "登録番号が不正",
+			dto:     invalid,
+			wantErr: model.ErrInvalidOrganization,
+		},
+		{
+			name:    "組織を特定できない",
+			dto:     application.UpdateOrganizationDto{Name: "株式会社サンプル"},
+			wantErr: commonErrors.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase, _ := newOrganizationUsecaseForTest()
+
+			got, err := usecase.UpdateOrganization(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("organization mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_OrganizationUsecase_GetSettings(t *testing.T) {
+	usecase, _ := newOrganizationUsecaseForTest()
+
+	t.Run("設定を登録していない場合は既定の設定", func(t *testing.T) {
+		got, err := usecase.GetSettings(application.GetOrganizationDto{Principal: application.Principal{OrganizationID: 2, Scopes: clientScopes}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := &application.OrganizationSettingsDto{RoundingPolicy: "truncate", PaymentTermsDays: model.DefaultPaymentTermsDays, PaymentMethod: "transfer_file"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("settings mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func Test_OrganizationUsecase_UpdateSettings(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	principal := application.Principal{Subject: "auth0|user1", OrganizationID: 1, Scopes: clientScopes}
+
+	tests := []struct {
+		name    string
+		dto     application.UpdateOrganizationSettingsDto
+		want    *application.OrganizationSettingsDto
+		wantErr error
+	}{
+		{
+			name: "新しい版を登録",
+			dto: application.UpdateOrganizationSettingsDto{
+				Principal:        principal,
+				DefaultFeePlanID: 2,
+				RoundingPolicy:   "half_up",
+				PaymentTermsDays: 45,
+				PaymentMethod:    "gateway",
+				Notification:     application.NotificationPreferencesDto{Email: "billing@example.com", OnPaid: true},
+				Now:              now,
+			},
+			want: &application.OrganizationSettingsDto{
+				Version: 2, DefaultFeePlanID: 2, RoundingPolicy: "half_up", PaymentTermsDays: 45, PaymentMethod: "gateway",
+				Notification: application.NotificationPreferencesDto{Email: "billing@example.com", OnPaid: true},
+				CreatedBy:    "auth0|user1", CreatedAt: &now,
+			},
+		},
+		{
+			name: "省略した項目は既定値",
+			dto:  application.UpdateOrganizationSettingsDto{Principal: principal, Now: now},
+			want: &application.OrganizationSettingsDto{
+				Version: 2, RoundingPolicy: "truncate", PaymentTermsDays: model.DefaultPaymentTermsDays, PaymentMethod: "transfer_file",
+				CreatedBy: "auth0|user1", CreatedAt: &now,
+			},
+		},
+		{
+			name: "他組織のプランは指定できない",
+			dto: application.UpdateOrganizationSettingsDto{
+				Principal:        application.Principal{Subject: "auth0|user2", OrganizationID: 2, Scopes: clientScopes},
+				DefaultFeePlanID: 2,
+				Now:              now,
+			},
+			wantErr: model.ErrInvalidOrganizationSettings,
+		},
+		{
+			name:    "存在しないプラン",
+			dto:     application.UpdateOrganizationSettingsDto{Principal: principal, DefaultFeePlanID: 99, Now: now},
+			wantErr: model.ErrInvalidOrganizationSettings,
+		},
+		{
+			name:    "通知先がないのに通知を有効にした",
+			dto:     application.UpdateOrganizationSettingsDto{Principal: principal, Notification: application.NotificationPreferencesDto{OnOverdue: true}, Now: now},
+			wantErr: model.ErrInvalidOrganizationSettings,
+		},
+		{
+			name:    "未定義の支払方法",
+			dto:     application.UpdateOrganizationSettingsDto{Principal: principal, PaymentMethod: "cash", Now: now},
+			wantErr: model.ErrInvalidOrganizationSettings,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase, organizationRepo := newOrganizationUsecaseForTest()
+
+			got, err := usecase.UpdateSettings(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("settings mismatch (-want +got):\n%s", diff)
+			}
+			// 過去の版は残る
+			if _, err := organizationRepo.GetSettingsByVersion(1, 1); err != nil {
+				t.Errorf("previous version is not kept: %v", err)
+			}
+		})
+	}
+}
+
+func Test_OrganizationUsecase_SettingsVersions(t *testing.T) {
+	usecase, _ := newOrganizationUsecaseForTest()
+	principal := application.Principal{Subject: "auth0|user1", OrganizationID: 1, Scopes: clientScopes}
+	if _, err := usecase.UpdateSettings(application.UpdateOrganizationSettingsDto{Principal: principal, RoundingPolicy: "ceil", PaymentTermsDays: 14}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	versions, err := usecase.ListSettingsVersions(application.GetOrganizationDto{Principal: principal})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 2 || versions[1].Version != 1 {
+		t.Errorf("versions = %+v, want versions 2 and 1 in order", versions)
+	}
+
+	got, err := usecase.GetSettingsVersion(application.GetOrganizationSettingsVersionDto{Principal: principal, Version: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.RoundingPolicy != "truncate" || got.PaymentTermsDays != 30 {
+		t.Errorf("version 1 = %+v, want the initial settings", got)
+	}
+
+	_, err = usecase.GetSettingsVersion(application.GetOrganizationSettingsVersionDto{Principal: principal, Version: 3})
+	if !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
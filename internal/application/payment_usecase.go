@@ -0,0 +1,220 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/gateway"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// PaymentProcessorActor 支払処理によるステータス変更の変更者
+const PaymentProcessorActor = "system:payment-processor"
+
+// PaymentAttemptTimeout 処理中にしてからこの時間を過ぎても支払済み・エラーにならない試行は、
+// 支払処理が中断したとみなして同じ冪等キーで再開する
+const PaymentAttemptTimeout = 15 * time.Minute
+
+type PaymentUsecase interface {
+	// ProcessDuePayments 中断した支払処理を再開してから、支払期日が近い未処理の請求書を処理中にし、支払ゲートウェイに依頼して結果を記録する
+	ProcessDuePayments(ctx context.Context, now time.Time) (*ProcessPaymentsResultDto, error)
+}
+
+type paymentUsecase struct {
+	invoiceRepo    repository.Invoice
+	paymentGateway gateway.PaymentGateway
+	leadDays       int // 支払期日の何日前から処理対象にするか
+	batchSize      int // 1回の処理で扱う最大件数
+}
+
+func NewPaymentUsecase(
+	invoiceRepo repository.Invoice,
+	paymentGateway gateway.PaymentGateway,
+	leadDays int,
+	batchSize int,
+) PaymentUsecase {
+	return &paymentUsecase{
+		invoiceRepo:    invoiceRepo,
+		paymentGateway: paymentGateway,
+		leadDays:       leadDays,
+		batchSize:      batchSize,
+	}
+}
+
+type ProcessPaymentsResultDto struct {
+	Paid         int // 支払済みになった件数
+	Failed       int // エラーになった件数
+	Skipped      int // 他の処理が先に処理中にしたため処理しなかった件数
+	Unreconciled int // 支払を依頼した後に結果をステータスに反映できず、照合が必要な件数
+	Recovered    int // 中断した支払処理を再開して支払済み・エラーにした件数（Paid・Failed にも含む）
+}
+
+// errPaymentUnreconciled 支払を依頼した後に、支払結果またはステータスを記録できなかった
+var errPaymentUnreconciled = errors.New("payment was submitted but could not be recorded")
+
+func (s *paymentUsecase) ProcessDuePayments(ctx context.Context, now time.Time) (*ProcessPaymentsResultDto, error) {
+	result := &ProcessPaymentsResultDto{}
+
+	// 処理中にした後でプロセスが停止した試行を先に再開する. 処理中のままでは未処理として取得されない
+	attempts, err := s.invoiceRepo.FindStalledPaymentAttempts(PaymentProcessorActor, now.Add(-PaymentAttemptTimeout), s.batchSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, attempt := range attempts {
+		if ctx.Err() != nil {
+			return result, nil
+		}
+
+		log.Printf("Resuming stalled payment attempt %d for invoice %d", attempt.ID, attempt.Invoice.ID)
+		status, err := s.completePayment(ctx, attempt)
+		if err := result.add(status, err); err != nil {
+			return result, err
+		}
+		if err == nil {
+			result.Recovered++
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	invoices, err := s.invoiceRepo.FindPendingDueBy(today.AddDate(0, 0, s.leadDays), s.batchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, invoice := range invoices {
+		// シャットダウン中は新たな支払を依頼しない
+		if ctx.Err() != nil {
+			break
+		}
+
+		status, err := s.processPayment(ctx, invoice)
+		if err := result.add(status, err); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// add 1件の処理結果を集計する. 処理を続けられないエラーはそのまま返す
+func (r *ProcessPaymentsResultDto) add(status model.InvoiceStatus, err error) error {
+	switch {
+	case errors.Is(err, errPaymentUnreconciled):
+		// 支払は依頼済みのため、他の請求書の処理は続ける
+		r.Unreconciled++
+	case errors.Is(err, commonErrors.ErrConflict):
+		r.Skipped++
+	case err != nil:
+		return err
+	case status == model.StatusPaid:
+		r.Paid++
+	default:
+		r.Failed++
+	}
+	return nil
+}
+
+// processPayment 1件の請求書を処理中にしてから支払を依頼し、支払済みまたはエラーにする.
+// 依頼した後に記録できなかった場合は errPaymentUnreconciled を返す
+func (s *paymentUsecase) processPayment(ctx context.Context, invoice *model.Invoice) (model.InvoiceStatus, error) {
+	// 処理中にする. 他のワーカーが先に処理中にした場合は ErrConflict になる
+	history, err := s.transition(invoice.Organization.ID, invoice, model.StatusProcessing)
+	if err != nil {
+		return "", err
+	}
+
+	// 処理中にした変更履歴を試行IDとし、途中で停止しても同じ冪等キーで再開できるようにする
+	return s.completePayment(ctx, &model.PaymentAttempt{ID: history.ID, Invoice: invoice})
+}
+
+// completePayment 処理中の試行について支払を依頼し、支払済みまたはエラーにする.
+// 支払結果が記録済みの試行は依頼し直さずにステータスだけを更新する.
+// 依頼した後に記録できなかった場合は errPaymentUnreconciled を返す
+func (s *paymentUsecase) completePayment(ctx context.Context, attempt *model.PaymentAttempt) (model.InvoiceStatus, error) {
+	invoice := attempt.Invoice
+	payment := attempt.Payment
+	var recordErr error
+	if payment == nil {
+		payment = s.submit(ctx, attempt)
+
+		// 支払は依頼済みのため、請求書が並行して更新されていても支払結果は必ず記録する
+		recordErr = s.invoiceRepo.CreatePayment(payment)
+		if errors.Is(recordErr, commonErrors.ErrConflict) {
+			// 同じ試行を再開した他のワーカーが記録済み. 同じ冪等キーで依頼したため支払結果も同じになる
+			recordErr = nil
+		}
+		if recordErr != nil {
+			log.Printf("ERROR: failed to record submitted payment for invoice %d (transactionId=%q, succeeded=%t): %v",
+				invoice.ID, payment.TransactionID, payment.Succeeded, recordErr)
+		}
+	}
+
+	next := model.StatusError
+	if payment.Succeeded {
+		next = model.StatusPaid
+	}
+	if _, err := s.transition(invoice.Organization.ID, invoice, next); err != nil {
+		log.Printf("ERROR: payment for invoice %d was submitted (transactionId=%q, succeeded=%t) but status could not be changed to %s, needs reconciliation: %v",
+			invoice.ID, payment.TransactionID, payment.Succeeded, next, err)
+		return "", fmt.Errorf("%w: %v", errPaymentUnreconciled, err)
+	}
+	if recordErr != nil {
+		return "", fmt.Errorf("%w: %v", errPaymentUnreconciled, recordErr)
+	}
+
+	return next, nil
+}
+
+// submit 試行の冪等キーを付けて支払を依頼し、記録する支払結果を返す
+func (s *paymentUsecase) submit(ctx context.Context, attempt *model.PaymentAttempt) *model.InvoicePayment {
+	invoice := attempt.Invoice
+
+	// 源泉徴収税額を差し引いた額を振り込む
+	payment := &model.InvoicePayment{
+		InvoiceID: invoice.ID,
+		AttemptID: attempt.ID,
+		Amount:    invoice.TransferAmount(),
+	}
+	res, err := s.paymentGateway.Submit(ctx, gateway.PaymentRequest{
+		IdempotencyKey: attempt.IdempotencyKey(),
+		InvoiceID:      invoice.ID,
+		Amount:         payment.Amount,
+		BankAccount:    invoice.Client.BankAccount,
+	})
+	if err != nil {
+		// 依頼自体に失敗した場合もエラーとして記録し、再処理（error -> pending）に委ねる
+		log.Printf("Failed to submit payment for invoice %d: %v", invoice.ID, err)
+		payment.Response = err.Error()
+	} else {
+		payment.Succeeded = res.Succeeded
+		payment.TransactionID = res.TransactionID
+		payment.Response = res.Message
+	}
+	return payment
+}
+
+// transition ステータスを遷移させて記録し、記録した変更履歴を返す
+func (s *paymentUsecase) transition(organizationID uint, invoice *model.Invoice, next model.InvoiceStatus) (*model.InvoiceStatusHistory, error) {
+	from := invoice.Status
+	if err := invoice.TransitionTo(next); err != nil {
+		return nil, err
+	}
+
+	history := &model.InvoiceStatusHistory{
+		InvoiceID:  invoice.ID,
+		FromStatus: from,
+		ToStatus:   next,
+		ChangedBy:  PaymentProcessorActor,
+	}
+	if err := s.invoiceRepo.UpdateStatus(organizationID, invoice.Version, history); err != nil {
+		return nil, err
+	}
+	// 続けて遷移させる場合に備えて保存後の版数にする
+	invoice.Version++
+	return history, nil
+}
@@ -0,0 +1,468 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/gateway"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	"github.com/take73/invoice-api-example/internal/infrastructure/payment"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// inMemoryInvoiceRepository 支払処理で使うメソッドだけを実装したインメモリの請求書リポジトリ
+type inMemoryInvoiceRepository struct {
+	repository.Invoice // 支払処理で使わないメソッドは実装しない
+
+	mu        sync.Mutex
+	invoices  map[uint]*model.Invoice
+	histories []*model.InvoiceStatusHistory
+	payments  []*model.InvoicePayment
+}
+
+func newInMemoryInvoiceRepository(invoices ...*model.Invoice) *inMemoryInvoiceRepository {
+	r := &inMemoryInvoiceRepository{invoices: map[uint]*model.Invoice{}}
+	for _, invoice := range invoices {
+		r.invoices[invoice.ID] = invoice
+	}
+	return r
+}
+
+func (r *inMemoryInvoiceRepository) FindPendingDueBy(dueBy time.Time, limit int) ([]*model.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []*model.Invoice
+	for _, invoice := range r.invoices {
+		if invoice.Status == model.StatusPending && !invoice.DueDate.After(dueBy) {
+			copied := *invoice
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (r *inMemoryInvoiceRepository) UpdateStatus(organizationID, version uint, history *model.InvoiceStatusHistory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateStatus(organizationID, version, history)
+}
+
+func (r *inMemoryInvoiceRepository) RecordPayment(organizationID, version uint, history *model.InvoiceStatusHistory, payment *model.InvoicePayment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.updateStatus(organizationID, version, history); err != nil {
+		return err
+	}
+	r.payments = append(r.payments, payment)
+	return nil
+}
+
+func (r *inMemoryInvoiceRepository) CreatePayment(payment *model.InvoicePayment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, recorded := range r.payments {
+		if payment.AttemptID != 0 && recorded.AttemptID == payment.AttemptID {
+			return commonErrors.ErrConflict
+		}
+	}
+	r.payments = append(r.payments, payment)
+	return nil
+}
+
+func (r *inMemoryInvoiceRepository) FindStalledPaymentAttempts(changedBy string, startedBefore time.Time, limit int) ([]*model.PaymentAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latest := map[uint]*model.InvoiceStatusHistory{}
+	for _, history := range r.histories {
+		latest[history.InvoiceID] = history
+	}
+	var found []*model.PaymentAttempt
+	for id, invoice := range r.invoices {
+		history := latest[id]
+		if invoice.Status != model.StatusProcessing || history == nil || history.ToStatus != model.StatusProcessing ||
+			history.ChangedBy != changedBy || !history.ChangedAt.Before(startedBefore) {
+			continue
+		}
+		copied := *invoice
+		attempt := &model.PaymentAttempt{ID: history.ID, Invoice: &copied}
+		for _, payment := range r.payments {
+			if payment.AttemptID == history.ID {
+				attempt.Payment = payment
+			}
+		}
+		found = append(found, attempt)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Invoice.ID < found[j].Invoice.ID })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (r *inMemoryInvoiceRepository) updateStatus(organizationID, version uint, history *model.InvoiceStatusHistory) error {
+	invoice, ok := r.invoices[history.InvoiceID]
+	if !ok || invoice.Organization.ID != organizationID {
+		return commonErrors.ErrNotFound
+	}
+	if invoice.Status != history.FromStatus || invoice.Version != version {
+		return commonErrors.ErrConflict
+	}
+	invoice.Status = history.ToStatus
+	invoice.Version++
+	history.ID = uint(len(r.histories) + 1)
+	history.ChangedAt = time.Now()
+	r.histories = append(r.histories, history)
+	return nil
+}
+
+func (r *inMemoryInvoiceRepository) statuses() map[uint]model.InvoiceStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statuses := map[uint]model.InvoiceStatus{}
+	for id, invoice := range r.invoices {
+		statuses[id] = invoice.Status
+	}
+	return statuses
+}
+
+func newPaymentTestInvoice(id uint, amount int64, dueDate time.Time, status model.InvoiceStatus, bankAccount *model.ClientBankAccount) *model.Invoice {
+	return &model.Invoice{
+		ID:           id,
+		Organization: &model.Organization{ID: 1},
+		Client:       &model.Client{ID: 1, OrganizationID: 1, BankAccount: bankAccount},
+		Amount:       decimal.NewFromInt(amount),
+		DueDate:      dueDate,
+		Status:       status,
+	}
+}
+
+func Test_PaymentUsecase_ProcessDuePayments(t *testing.T) {
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	bankAccount := &model.ClientBankAccount{ID: 1, ClientID: 1, BankName: "テスト銀行", AccountNumber: "1234567"}
+
+	repo := newInMemoryInvoiceRepository(
+		newPaymentTestInvoice(1, 10000, today.AddDate(0, 0, -1), model.StatusPending, bankAccount), // 期日超過
+		newPaymentTestInvoice(2, 20000, today.AddDate(0, 0, 3), model.StatusPending, bankAccount),  // 3日後が期日
+		newPaymentTestInvoice(3, 30000, today.AddDate(0, 0, 2), model.StatusPending, nil),          // 口座未登録
+		newPaymentTestInvoice(4, 40000, today.AddDate(0, 0, 1), model.StatusPaid, bankAccount),     // 支払済み
+		newPaymentTestInvoice(5, 50000, today.AddDate(0, 0, 10), model.StatusPending, bankAccount), // 期日が先
+		newPaymentTestInvoice(6, 0, today, model.StatusPending, bankAccount),                       // 金額が0
+	)
+	gateway := payment.NewFakeGateway()
+	usecase := application.NewPaymentUsecase(repo, gateway, 3, 100)
+
+	result, err := usecase.ProcessDuePayments(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(&application.ProcessPaymentsResultDto{Paid: 2, Failed: 2}, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	wantStatuses := map[uint]model.InvoiceStatus{
+		1: model.StatusPaid,
+		2: model.StatusPaid,
+		3: model.StatusError,
+		4: model.StatusPaid,
+		5: model.StatusPending,
+		6: model.StatusError,
+	}
+	if diff := cmp.Diff(wantStatuses, repo.statuses()); diff != "" {
+		t.Errorf("statuses mismatch (-want +got):\n%s", diff)
+	}
+
+	// 処理中を経由して支払済みまたはエラーになる
+	for _, history := range repo.histories {
+		if history.ChangedBy != application.PaymentProcessorActor {
+			t.Errorf("unexpected changedBy: %s", history.ChangedBy)
+		}
+	}
+	if len(repo.histories) != 8 {
+		t.Errorf("expected 8 histories, got %d", len(repo.histories))
+	}
+
+	wantPayments := []*model.InvoicePayment{
+		{InvoiceID: 1, AttemptID: 1, Amount: decimal.NewFromInt(10000), Succeeded: true, TransactionID: "FAKE-00000001", Response: "accepted"},
+		{InvoiceID: 2, AttemptID: 3, Amount: decimal.NewFromInt(20000), Succeeded: true, TransactionID: "FAKE-00000002", Response: "accepted"},
+		{InvoiceID: 3, AttemptID: 5, Amount: decimal.NewFromInt(30000), Response: "rejected: bank account is not registered"},
+		{InvoiceID: 6, AttemptID: 7, Amount: decimal.NewFromInt(0), Response: "rejected: amount must be positive"},
+	}
+	if diff := cmp.Diff(wantPayments, repo.payments); diff != "" {
+		t.Errorf("payments mismatch (-want +got):\n%s", diff)
+	}
+
+	// 2回目は処理対象がない
+	result, err = usecase.ProcessDuePayments(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&application.ProcessPaymentsResultDto{}, result); diff != "" {
+		t.Errorf("second result mismatch (-want +got):\n%s", diff)
+	}
+	if len(gateway.Requests()) != 4 {
+		t.Errorf("expected 4 gateway requests, got %d", len(gateway.Requests()))
+	}
+}
+
+func Test_PaymentUsecase_ProcessDuePayments_WithholdingTax(t *testing.T) {
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	bankAccount := &model.ClientBankAccount{ID: 1, ClientID: 1, BankName: "テスト銀行", AccountNumber: "1234567"}
+
+	invoice := newPaymentTestInvoice(1, 100000, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), model.StatusPending, bankAccount)
+	invoice.WithholdingTax = decimal.NewFromInt(10210)
+	repo := newInMemoryInvoiceRepository(invoice)
+	gateway := payment.NewFakeGateway()
+	usecase := application.NewPaymentUsecase(repo, gateway, 0, 100)
+
+	if _, err := usecase.ProcessDuePayments(context.Background(), now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 源泉徴収税額を差し引いた額を振り込む
+	requests := gateway.Requests()
+	if len(requests) != 1 || !requests[0].Amount.Equal(decimal.NewFromInt(89790)) {
+		t.Errorf("unexpected gateway requests: %+v", requests)
+	}
+	if len(repo.payments) != 1 || !repo.payments[0].Amount.Equal(decimal.NewFromInt(89790)) {
+		t.Errorf("unexpected payments: %+v", repo.payments)
+	}
+}
+
+func Test_PaymentUsecase_ProcessDuePayments_Concurrent(t *testing.T) {
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	bankAccount := &model.ClientBankAccount{ID: 1, ClientID: 1, AccountNumber: "1234567"}
+
+	var invoices []*model.Invoice
+	for id := uint(1); id <= 20; id++ {
+		invoices = append(invoices, newPaymentTestInvoice(id, 10000, today, model.StatusPending, bankAccount))
+	}
+	repo := newInMemoryInvoiceRepository(invoices...)
+	gateway := payment.NewFakeGateway()
+
+	// 複数のワーカーが同時に処理しても同じ請求書を二重に支払わない
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			usecase := application.NewPaymentUsecase(repo, gateway, 0, 100)
+			if _, err := usecase.ProcessDuePayments(context.Background(), now); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(repo.payments) != 20 {
+		t.Errorf("expected 20 payments, got %d", len(repo.payments))
+	}
+	if len(gateway.Requests()) != 20 {
+		t.Errorf("expected 20 gateway requests, got %d", len(gateway.Requests()))
+	}
+}
+
+func Test_PaymentUsecase_ProcessDuePayments_Canceled(t *testing.T) {
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	repo := newInMemoryInvoiceRepository(newPaymentTestInvoice(1, 10000, today, model.StatusPending, nil))
+	usecase := application.NewPaymentUsecase(repo, payment.NewFakeGateway(), 0, 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// 停止後は新たな支払を依頼しない
+	result, err := usecase.ProcessDuePayments(ctx, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&application.ProcessPaymentsResultDto{}, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	if repo.statuses()[1] != model.StatusPending {
+		t.Errorf("expected pending, got %s", repo.statuses()[1])
+	}
+}
+
+// interceptingGateway 支払を依頼した直後に onSubmit を呼ぶ支払ゲートウェイ
+type interceptingGateway struct {
+	*payment.FakeGateway
+	onSubmit func(req gateway.PaymentRequest)
+}
+
+func (g *interceptingGateway) Submit(ctx context.Context, req gateway.PaymentRequest) (*gateway.PaymentResult, error) {
+	res, err := g.FakeGateway.Submit(ctx, req)
+	g.onSubmit(req)
+	return res, err
+}
+
+// failingStatusRepository 指定したステータスへの更新だけ失敗する請求書リポジトリ
+type failingStatusRepository struct {
+	*inMemoryInvoiceRepository
+	failTo model.InvoiceStatus
+}
+
+func (r *failingStatusRepository) UpdateStatus(organizationID, version uint, history *model.InvoiceStatusHistory) error {
+	if history.ToStatus == r.failTo {
+		return errors.New("connection lost")
+	}
+	return r.inMemoryInvoiceRepository.UpdateStatus(organizationID, version, history)
+}
+
+func Test_PaymentUsecase_ProcessDuePayments_Unreconciled(t *testing.T) {
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	bankAccount := &model.ClientBankAccount{ID: 1, ClientID: 1, AccountNumber: "1234567"}
+	newRepo := func() *inMemoryInvoiceRepository {
+		return newInMemoryInvoiceRepository(
+			newPaymentTestInvoice(1, 10000, today, model.StatusPending, bankAccount),
+			newPaymentTestInvoice(2, 20000, today, model.StatusPending, bankAccount),
+		)
+	}
+	// 支払結果には処理中にした変更履歴のIDを試行IDとして記録する
+	wantPayments := func(attemptIDs ...uint) []*model.InvoicePayment {
+		return []*model.InvoicePayment{
+			{InvoiceID: 1, AttemptID: attemptIDs[0], Amount: decimal.NewFromInt(10000), Succeeded: true, TransactionID: "FAKE-00000001", Response: "accepted"},
+			{InvoiceID: 2, AttemptID: attemptIDs[1], Amount: decimal.NewFromInt(20000), Succeeded: true, TransactionID: "FAKE-00000002", Response: "accepted"},
+		}
+	}
+
+	t.Run("支払の依頼中に請求書が更新された場合も支払結果を記録する", func(t *testing.T) {
+		repo := newRepo()
+		// 支払の依頼中に操作者が請求書1をエラーにする
+		interceptor := &interceptingGateway{FakeGateway: payment.NewFakeGateway(), onSubmit: func(req gateway.PaymentRequest) {
+			if req.InvoiceID != 1 {
+				return
+			}
+			invoice := repo.invoices[1]
+			if err := repo.UpdateStatus(1, invoice.Version, &model.InvoiceStatusHistory{
+				InvoiceID: 1, FromStatus: model.StatusProcessing, ToStatus: model.StatusError, ChangedBy: "auth0|operator",
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}}
+		usecase := application.NewPaymentUsecase(repo, interceptor, 0, 100)
+
+		result, err := usecase.ProcessDuePayments(context.Background(), now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if diff := cmp.Diff(&application.ProcessPaymentsResultDto{Paid: 1, Unreconciled: 1}, result); diff != "" {
+			t.Errorf("result mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(wantPayments(1, 3), repo.payments); diff != "" {
+			t.Errorf("payments mismatch (-want +got):\n%s", diff)
+		}
+		wantStatuses := map[uint]model.InvoiceStatus{1: model.StatusError, 2: model.StatusPaid}
+		if diff := cmp.Diff(wantStatuses, repo.statuses()); diff != "" {
+			t.Errorf("statuses mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("支払の依頼後にステータスを更新できない場合も他の請求書の処理を続ける", func(t *testing.T) {
+		repo := newRepo()
+		usecase := application.NewPaymentUsecase(&failingStatusRepository{inMemoryInvoiceRepository: repo, failTo: model.StatusPaid}, payment.NewFakeGateway(), 0, 100)
+
+		result, err := usecase.ProcessDuePayments(context.Background(), now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if diff := cmp.Diff(&application.ProcessPaymentsResultDto{Unreconciled: 2}, result); diff != "" {
+			t.Errorf("result mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(wantPayments(1, 2), repo.payments); diff != "" {
+			t.Errorf("payments mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func Test_PaymentUsecase_ProcessDuePayments_ResumeStalledAttempts(t *testing.T) {
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	bankAccount := &model.ClientBankAccount{ID: 1, ClientID: 1, AccountNumber: "1234567"}
+
+	repo := newInMemoryInvoiceRepository(
+		newPaymentTestInvoice(1, 10000, today, model.StatusProcessing, bankAccount), // 支払の依頼後に停止
+		newPaymentTestInvoice(2, 20000, today, model.StatusProcessing, nil),         // 支払結果の記録後に停止
+		newPaymentTestInvoice(3, 30000, today, model.StatusProcessing, bankAccount), // 処理中にしてから間もない
+		newPaymentTestInvoice(4, 40000, today, model.StatusProcessing, bankAccount), // 操作者が処理中にした
+	)
+	processing := func(id, invoiceID uint, changedBy string, changedAt time.Time) *model.InvoiceStatusHistory {
+		return &model.InvoiceStatusHistory{
+			ID: id, InvoiceID: invoiceID, FromStatus: model.StatusPending, ToStatus: model.StatusProcessing, ChangedBy: changedBy, ChangedAt: changedAt,
+		}
+	}
+	repo.histories = []*model.InvoiceStatusHistory{
+		processing(1, 1, application.PaymentProcessorActor, now.Add(-time.Hour)),
+		processing(2, 2, application.PaymentProcessorActor, now.Add(-time.Hour)),
+		processing(3, 3, application.PaymentProcessorActor, now.Add(-5*time.Minute)),
+		processing(4, 4, "auth0|operator", now.Add(-time.Hour)),
+	}
+	repo.payments = []*model.InvoicePayment{
+		{InvoiceID: 2, AttemptID: 2, Amount: decimal.NewFromInt(20000), Response: "rejected: bank account is not registered"},
+	}
+
+	// 停止前に請求書1の支払は依頼済み
+	gateway := payment.NewFakeGateway()
+	if _, err := gateway.Submit(context.Background(), gatewayRequest(1, 10000, "invoice-1-attempt-1", bankAccount)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	usecase := application.NewPaymentUsecase(repo, gateway, 0, 100)
+
+	result, err := usecase.ProcessDuePayments(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(&application.ProcessPaymentsResultDto{Paid: 1, Failed: 1, Recovered: 2}, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	wantStatuses := map[uint]model.InvoiceStatus{
+		1: model.StatusPaid,
+		2: model.StatusError,
+		3: model.StatusProcessing,
+		4: model.StatusProcessing,
+	}
+	if diff := cmp.Diff(wantStatuses, repo.statuses()); diff != "" {
+		t.Errorf("statuses mismatch (-want +got):\n%s", diff)
+	}
+
+	// 同じ冪等キーで再送するため二重に支払わず、記録済みの試行は依頼し直さない
+	if len(gateway.Requests()) != 1 {
+		t.Errorf("expected 1 gateway request, got %d", len(gateway.Requests()))
+	}
+	wantPayments := []*model.InvoicePayment{
+		{InvoiceID: 2, AttemptID: 2, Amount: decimal.NewFromInt(20000), Response: "rejected: bank account is not registered"},
+		{InvoiceID: 1, AttemptID: 1, Amount: decimal.NewFromInt(10000), Succeeded: true, TransactionID: "FAKE-00000001", Response: "accepted"},
+	}
+	if diff := cmp.Diff(wantPayments, repo.payments); diff != "" {
+		t.Errorf("payments mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func gatewayRequest(invoiceID uint, amount int64, idempotencyKey string, bankAccount *model.ClientBankAccount) gateway.PaymentRequest {
+	return gateway.PaymentRequest{
+		IdempotencyKey: idempotencyKey,
+		InvoiceID:      invoiceID,
+		Amount:         decimal.NewFromInt(amount),
+		BankAccount:    bankAccount,
+	}
+}
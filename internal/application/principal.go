@@ -0,0 +1,94 @@
+package application
+
+import (
+	"errors"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// Principal 認証済みの操作主体
+type Principal struct {
+	Subject        string   // トークンのsubject
+	UserID         uint     // ユーザーID（ユーザーに紐づかないクライアントの場合は0）
+	OrganizationID uint     // トークンに含まれる組織ID（含まれない場合は0）
+	Scopes         []string // トークンのスコープ. ユーザーに紐づかないクライアントの権限の判定に使う
+}
+
+// hasScope トークンが権限と同じ名前のスコープをもつかどうか
+func (p Principal) hasScope(permission model.Permission) bool {
+	for _, scope := range p.Scopes {
+		if scope == string(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// actor 権限を確認した操作主体
+type actor struct {
+	organizationID uint
+	user           *model.User // ユーザーに紐づかないクライアントの場合はnil
+	scopes         []string    // ユーザーに紐づかないクライアントのスコープ
+}
+
+// role 操作主体のロール. ユーザーに紐づかないクライアントの場合は空文字
+func (a *actor) role() model.Role {
+	if a.user == nil {
+		return ""
+	}
+	return a.user.Role
+}
+
+// can 操作主体が権限をもつかどうか. ユーザーはロール、ユーザーに紐づかないクライアントはスコープで判定する
+func (a *actor) can(permission model.Permission) bool {
+	if a.user == nil {
+		return Principal{Scopes: a.scopes}.hasScope(permission)
+	}
+	return a.user.Role.Can(permission)
+}
+
+// authorize 操作主体が権限をもつことを確認し、所属する組織IDを返す
+func authorize(userRepo repository.User, principal Principal, permission model.Permission) (uint, error) {
+	a, err := authorizeActor(userRepo, principal, permission)
+	if err != nil {
+		return 0, err
+	}
+	return a.organizationID, nil
+}
+
+// authorizeActor 操作主体の所属組織を解決し、権限をもつことを確認する.
+// ユーザーに紐づく場合はユーザーの所属組織とロールを正とし、ユーザーが存在しない・無効化された・トークンの組織IDと食い違う場合は ErrUnauthenticated を返す.
+// ユーザーに紐づかないクライアントはトークンのスコープで判定する
+func authorizeActor(userRepo repository.User, principal Principal, permission model.Permission) (*actor, error) {
+	if principal.UserID == 0 {
+		if principal.OrganizationID == 0 {
+			return nil, commonErrors.ErrUnauthorized
+		}
+		if !principal.hasScope(permission) {
+			return nil, commonErrors.ErrForbidden
+		}
+		return &actor{organizationID: principal.OrganizationID, scopes: principal.Scopes}, nil
+	}
+
+	// トークンの発行後にユーザーが削除・無効化された、または所属組織が変わった場合は認証に失敗したものとする
+	user, err := userRepo.GetByID(principal.UserID)
+	if err != nil {
+		if errors.Is(err, commonErrors.ErrNotFound) {
+			return nil, commonErrors.ErrUnauthenticated
+		}
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		return nil, commonErrors.ErrUnauthenticated
+	}
+	if principal.OrganizationID != 0 && principal.OrganizationID != user.OrganizationID {
+		return nil, commonErrors.ErrUnauthenticated
+	}
+	if !user.Role.Can(permission) {
+		return nil, commonErrors.ErrForbidden
+	}
+
+	return &actor{organizationID: user.OrganizationID, user: user}, nil
+}
@@ -0,0 +1,96 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// clientScopes ユーザーに紐づかないクライアントに全ての権限を与えるスコープ
+var clientScopes = scopesOf(model.RoleOwner.Permissions()...)
+
+func scopesOf(permissions ...model.Permission) []string {
+	scopes := make([]string, len(permissions))
+	for i, permission := range permissions {
+		scopes[i] = string(permission)
+	}
+	return scopes
+}
+
+func newInMemoryUserRepository(users ...*model.User) *inMemoryUserRepository {
+	repo := &inMemoryUserRepository{users: map[uint]*model.User{}}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func Test_Authorization(t *testing.T) {
+	deactivatedAt := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	userRepo := newInMemoryUserRepository(
+		&model.User{ID: 1, OrganizationID: 1, Role: model.RoleAccountant},
+		&model.User{ID: 2, OrganizationID: 1, Role: model.RoleViewer},
+		&model.User{ID: 3, OrganizationID: 1, Role: model.RoleAdmin, DeactivatedAt: &deactivatedAt},
+	)
+	clientRepo := &inMemoryClientRepository{clients: map[uint]*model.Client{}}
+	usecase := application.NewClientUsecase(clientRepo, userRepo)
+	input := validClientInput()
+
+	tests := []struct {
+		name      string
+		principal application.Principal
+		wantErr   error
+	}{
+		{
+			name:      "ロールが権限をもつユーザー",
+			principal: application.Principal{Subject: "auth0|user1", UserID: 1},
+		},
+		{
+			name:      "ロールが権限をもたないユーザー",
+			principal: application.Principal{Subject: "auth0|user2", UserID: 2, Scopes: clientScopes},
+			wantErr:   commonErrors.ErrForbidden,
+		},
+		{
+			name:      "無効化したユーザー",
+			principal: application.Principal{Subject: "auth0|user3", UserID: 3},
+			wantErr:   commonErrors.ErrUnauthenticated,
+		},
+		{
+			name:      "存在しないユーザー",
+			principal: application.Principal{Subject: "auth0|user9", UserID: 9},
+			wantErr:   commonErrors.ErrUnauthenticated,
+		},
+		{
+			name:      "トークンの組織IDがユーザーの所属組織と異なる",
+			principal: application.Principal{Subject: "auth0|user1", UserID: 1, OrganizationID: 2},
+			wantErr:   commonErrors.ErrUnauthenticated,
+		},
+		{
+			name:      "スコープが権限をもつクライアント",
+			principal: application.Principal{Subject: "client@clients", OrganizationID: 1, Scopes: scopesOf(model.PermissionWriteClient)},
+		},
+		{
+			name:      "スコープが権限をもたないクライアント",
+			principal: application.Principal{Subject: "client@clients", OrganizationID: 1, Scopes: scopesOf(model.PermissionReadClient)},
+			wantErr:   commonErrors.ErrForbidden,
+		},
+		{
+			name:      "組織を特定できないクライアント",
+			principal: application.Principal{Subject: "client@clients", Scopes: clientScopes},
+			wantErr:   commonErrors.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := usecase.CreateClient(application.CreateClientDto{Principal: tt.principal, ClientInputDto: input})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
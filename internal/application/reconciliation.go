@@ -0,0 +1,246 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/shared/bankstatement"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/zengin"
+)
+
+const (
+	// DefaultReconciliationDateTolerance 取引日と支払期日のずれとして許容する日数の既定値
+	DefaultReconciliationDateTolerance = 3
+	// MaxReconciliationDateTolerance 取引日と支払期日のずれとして許容する日数の上限
+	MaxReconciliationDateTolerance = 31
+)
+
+// 照合できなかった理由
+const (
+	reasonNoMatchingInvoice = "no matching invoice"
+	reasonNoPayee           = "payee is unknown"
+	reasonInvoiceContended  = "matched invoice is also matched by another line"
+	reasonStatusChanged     = "invoice status was changed by another request"
+)
+
+type ReconcileStatementDto struct {
+	Principal     Principal
+	Format        bankstatement.Format
+	Content       []byte
+	DateTolerance int  // 取引日と支払期日のずれとして許容する日数
+	DryRun        bool // true の場合は照合結果を返すだけでステータスを変更しない
+}
+
+// ReconciliationReportDto 照合結果
+type ReconciliationReportDto struct {
+	Matched   []ReconciledLineDto // 1件の請求書に照合でき、支払済みにした明細
+	Unmatched []ReconciledLineDto // 照合できる請求書がなかった明細
+	Ambiguous []ReconciledLineDto // 照合できる請求書が複数あり、特定できなかった明細
+	Skipped   int                 // 入金など照合の対象外とした明細の件数
+	DryRun    bool
+}
+
+type ReconciledLineDto struct {
+	LineNumber int
+	Reference  string
+	Date       time.Time
+	Amount     int64
+	PayeeName  string
+	InvoiceIDs []uint // 照合した請求書（曖昧な場合は候補）
+	Reason     string // 照合できなかった理由
+}
+
+// ReconcileStatement 銀行の入出金明細を読み込み、出金を処理中の請求書と照合して支払済みにする.
+// 金額が支払金額から源泉徴収税額を差し引いた額（振込金額）と一致し、振込先が取引先の口座で、取引日が支払期日の前後 DateTolerance 日以内の請求書を候補とする.
+// 候補が1件に定まらない明細、または同じ請求書が複数の明細の候補になった場合は曖昧として支払済みにしない.
+// 支払方法が振込ファイルの組織に限る（支払ゲートウェイの組織は支払処理ワーカーが支払結果を記録する）
+func (s *invoiceUsecase) ReconcileStatement(dto ReconcileStatementDto) (*ReconciliationReportDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionWriteInvoiceStatus)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requirePaymentMethod(organizationID, model.PaymentMethodTransferFile); err != nil {
+		return nil, err
+	}
+	if dto.DateTolerance < 0 || dto.DateTolerance > MaxReconciliationDateTolerance {
+		return nil, fmt.Errorf("invalid date tolerance: %d", dto.DateTolerance)
+	}
+
+	lines, err := bankstatement.Parse(dto.Format, dto.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReportDto{DryRun: dto.DryRun}
+	var debits []bankstatement.Line
+	for _, line := range lines {
+		if line.Direction != bankstatement.DirectionDebit {
+			report.Skipped++
+			continue
+		}
+		debits = append(debits, line)
+	}
+	if len(debits) == 0 {
+		return report, nil
+	}
+
+	// 明細の取引日の範囲に許容日数を加えた期間を支払期日とする処理中の請求書を候補にする
+	from, to := debits[0].Date, debits[0].Date
+	for _, line := range debits {
+		if line.Date.Before(from) {
+			from = line.Date
+		}
+		if line.Date.After(to) {
+			to = line.Date
+		}
+	}
+	invoices, err := s.invoiceRepo.FindByStatusAndDueDate(
+		organizationID, model.StatusProcessing,
+		from.AddDate(0, 0, -dto.DateTolerance), to.AddDate(0, 0, dto.DateTolerance),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([][]*model.Invoice, len(debits))
+	claims := make(map[uint]int) // 請求書ごとに、候補が1件だけの明細から照合された数
+	for i := range debits {
+		candidates[i] = matchInvoices(&debits[i], invoices, dto.DateTolerance)
+		if len(candidates[i]) == 1 {
+			claims[candidates[i][0].ID]++
+		}
+	}
+
+	for i := range debits {
+		line := &debits[i]
+		result := newReconciledLineDto(line, candidates[i])
+		switch {
+		case len(candidates[i]) == 0:
+			result.Reason = reasonNoMatchingInvoice
+			if !hasPayee(line) {
+				result.Reason = reasonNoPayee
+			}
+			report.Unmatched = append(report.Unmatched, result)
+		case len(candidates[i]) > 1:
+			report.Ambiguous = append(report.Ambiguous, result)
+		case claims[candidates[i][0].ID] > 1:
+			result.Reason = reasonInvoiceContended
+			report.Ambiguous = append(report.Ambiguous, result)
+		default:
+			if !dto.DryRun {
+				if err := s.markPaid(organizationID, dto.Principal, candidates[i][0], line); err != nil {
+					if !errors.Is(err, commonErrors.ErrConflict) {
+						return nil, err
+					}
+					result.Reason = reasonStatusChanged
+					report.Unmatched = append(report.Unmatched, result)
+					continue
+				}
+			}
+			report.Matched = append(report.Matched, result)
+		}
+	}
+
+	return report, nil
+}
+
+// markPaid 照合できた請求書を支払済みにし、明細を支払結果として記録する
+func (s *invoiceUsecase) markPaid(organizationID uint, principal Principal, invoice *model.Invoice, line *bankstatement.Line) error {
+	from := invoice.Status
+	if err := invoice.TransitionTo(model.StatusPaid); err != nil {
+		return err
+	}
+
+	history := &model.InvoiceStatusHistory{
+		InvoiceID:  invoice.ID,
+		FromStatus: from,
+		ToStatus:   model.StatusPaid,
+		ChangedBy:  principal.Subject,
+	}
+	payment := &model.InvoicePayment{
+		InvoiceID:     invoice.ID,
+		Amount:        invoice.TransferAmount(),
+		Succeeded:     true,
+		TransactionID: line.Reference,
+		Response:      fmt.Sprintf("reconciled with bank statement line %d", line.Number),
+	}
+	return s.invoiceRepo.RecordPayment(organizationID, invoice.Version, history, payment)
+}
+
+// matchInvoices 明細と金額・振込先・日付が一致する請求書を返す
+func matchInvoices(line *bankstatement.Line, invoices []*model.Invoice, tolerance int) []*model.Invoice {
+	if !hasPayee(line) {
+		return nil
+	}
+
+	var matched []*model.Invoice
+	for _, invoice := range invoices {
+		// 出金額は源泉徴収税額を差し引いた振込金額と一致する
+		amount := invoice.TransferAmount()
+		if !amount.Equal(amount.Truncate(0)) || amount.IntPart() != line.Amount {
+			continue
+		}
+		if days := line.Date.Sub(invoice.DueDate).Hours() / 24; days < -float64(tolerance) || days > float64(tolerance) {
+			continue
+		}
+		if !payeeMatches(line, invoice.Client.BankAccount) {
+			continue
+		}
+		matched = append(matched, invoice)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched
+}
+
+func hasPayee(line *bankstatement.Line) bool {
+	return line.AccountNumber != "" || line.PayeeName != ""
+}
+
+// payeeMatches 明細の振込先が取引先の口座と一致するかどうか.
+// 口座番号が分かる場合は口座番号（と分かれば金融機関・支店コード）で、分からない場合は名義で比較する.
+// 明細の名義は桁数の都合で切り詰められていることがあるため前方一致とする
+func payeeMatches(line *bankstatement.Line, account *model.ClientBankAccount) bool {
+	if account == nil {
+		return false
+	}
+
+	if line.AccountNumber != "" {
+		if strings.TrimLeft(line.AccountNumber, "0") != strings.TrimLeft(account.AccountNumber, "0") {
+			return false
+		}
+		if line.BankCode != "" && line.BankCode != account.BankCode {
+			return false
+		}
+		if line.BranchCode != "" && line.BranchCode != account.BranchCode {
+			return false
+		}
+		return true
+	}
+
+	name, err := zengin.ToHalfWidthKana(account.AccountName)
+	if err != nil {
+		return false
+	}
+	name = strings.ReplaceAll(name, " ", "")
+	return name != "" && (strings.HasPrefix(name, line.PayeeName) || strings.HasPrefix(line.PayeeName, name))
+}
+
+func newReconciledLineDto(line *bankstatement.Line, candidates []*model.Invoice) ReconciledLineDto {
+	ids := make([]uint, len(candidates))
+	for i, invoice := range candidates {
+		ids[i] = invoice.ID
+	}
+	return ReconciledLineDto{
+		LineNumber: line.Number,
+		Reference:  line.Reference,
+		Date:       line.Date,
+		Amount:     line.Amount,
+		PayeeName:  line.PayeeName,
+		InvoiceIDs: ids,
+	}
+}
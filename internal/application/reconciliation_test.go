@@ -0,0 +1,208 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/shared/bankstatement"
+)
+
+func (r *inMemoryInvoiceRepository) FindByStatusAndDueDate(organizationID uint, status model.InvoiceStatus, dueDateFrom, dueDateTo time.Time) ([]*model.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []*model.Invoice
+	for _, invoice := range r.invoices {
+		if invoice.Organization.ID == organizationID && invoice.Status == status &&
+			!invoice.DueDate.Before(dueDateFrom) && !invoice.DueDate.After(dueDateTo) {
+			copied := *invoice
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func Test_InvoiceUsecase_ReconcileStatement(t *testing.T) {
+	dueDate := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	accountA := &model.ClientBankAccount{ID: 1, ClientID: 1, BankCode: "0001", BranchCode: "100", AccountNumber: "1234567", AccountName: "トリヒキサキエー"}
+	accountB := &model.ClientBankAccount{ID: 2, ClientID: 2, BankCode: "0005", BranchCode: "050", AccountNumber: "2345678", AccountName: "トリヒキサキビー"}
+
+	newRepo := func() *inMemoryInvoiceRepository {
+		return newInMemoryInvoiceRepository(
+			newPaymentTestInvoice(1, 10000, dueDate, model.StatusProcessing, accountA),
+			newPaymentTestInvoice(2, 20000, dueDate, model.StatusProcessing, accountB),
+			newPaymentTestInvoice(3, 30000, dueDate, model.StatusProcessing, accountA),
+			newPaymentTestInvoice(4, 30000, dueDate.AddDate(0, 0, 1), model.StatusProcessing, accountA),
+			newPaymentTestInvoice(5, 40000, dueDate, model.StatusPending, accountA),
+		)
+	}
+	organizationRepo := &inMemoryOrganizationRepository{
+		organizations: map[uint]*model.Organization{
+			1: {ID: 1, PaymentMethod: model.PaymentMethodTransferFile},
+			2: {ID: 2, PaymentMethod: model.PaymentMethodGateway},
+		},
+	}
+	principal := application.Principal{Subject: "auth0|user1", OrganizationID: 1, Scopes: clientScopes}
+	statement := "reference,date,type,amount,payee_name,account_number\n" +
+		"R1,2024-01-20,debit,10000,ﾄﾘﾋｷｻｷｴ-,\n" + // 名義で照合
+		"R2,2024-01-21,debit,20000,,2345678\n" + // 口座番号で照合
+		"R3,2024-01-20,debit,30000,ﾄﾘﾋｷｻｷｴ-,\n" + // 候補が2件
+		"R4,2024-01-20,debit,40000,ﾄﾘﾋｷｻｷｴ-,\n" + // 未処理の請求書は対象外
+		"R5,2024-01-30,debit,10000,ﾄﾘﾋｷｻｷｴ-,\n" + // 日付が許容範囲外
+		"R6,2024-01-20,debit,50000,,\n" + // 振込先が分からない
+		"R7,2024-01-20,credit,10000,ｶ)ｺｷﾔｸ,\n" // 入金は対象外
+
+	line := func(number int, reference string, day, amount int64, payee string, ids ...uint) application.ReconciledLineDto {
+		if ids == nil {
+			ids = []uint{}
+		}
+		return application.ReconciledLineDto{
+			LineNumber: number,
+			Reference:  reference,
+			Date:       time.Date(2024, 1, int(day), 0, 0, 0, 0, time.UTC),
+			Amount:     amount,
+			PayeeName:  payee,
+			InvoiceIDs: ids,
+		}
+	}
+	withReason := func(dto application.ReconciledLineDto, reason string) application.ReconciledLineDto {
+		dto.Reason = reason
+		return dto
+	}
+
+	want := &application.ReconciliationReportDto{
+		Matched: []application.ReconciledLineDto{
+			line(1, "R1", 20, 10000, "ﾄﾘﾋｷｻｷｴ-", 1),
+			line(2, "R2", 21, 20000, "", 2),
+		},
+		Unmatched: []application.ReconciledLineDto{
+			withReason(line(4, "R4", 20, 40000, "ﾄﾘﾋｷｻｷｴ-"), "no matching invoice"),
+			withReason(line(5, "R5", 30, 10000, "ﾄﾘﾋｷｻｷｴ-"), "no matching invoice"),
+			withReason(line(6, "R6", 20, 50000, ""), "payee is unknown"),
+		},
+		Ambiguous: []application.ReconciledLineDto{
+			line(3, "R3", 20, 30000, "ﾄﾘﾋｷｻｷｴ-", 3, 4),
+		},
+		Skipped: 1,
+	}
+
+	t.Run("照合した請求書を支払済みにする", func(t *testing.T) {
+		repo := newRepo()
+		usecase := application.NewInvoiceUsecase(repo, nil, organizationRepo, newInMemoryUserRepository(), nil, nil)
+		got, err := usecase.ReconcileStatement(application.ReconcileStatementDto{
+			Principal:     principal,
+			Format:        bankstatement.FormatCSV,
+			Content:       []byte(statement),
+			DateTolerance: 3,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("report mismatch (-want +got):\n%s", diff)
+		}
+
+		wantStatuses := map[uint]model.InvoiceStatus{
+			1: model.StatusPaid,
+			2: model.StatusPaid,
+			3: model.StatusProcessing,
+			4: model.StatusProcessing,
+			5: model.StatusPending,
+		}
+		if diff := cmp.Diff(wantStatuses, repo.statuses()); diff != "" {
+			t.Errorf("statuses mismatch (-want +got):\n%s", diff)
+		}
+		if len(repo.payments) != 2 || repo.payments[0].TransactionID != "R1" || repo.payments[1].TransactionID != "R2" {
+			t.Errorf("unexpected payments: %+v", repo.payments)
+		}
+		for _, history := range repo.histories {
+			if history.ChangedBy != principal.Subject {
+				t.Errorf("changedBy = %s, want %s", history.ChangedBy, principal.Subject)
+			}
+		}
+
+		// 同じ明細を再度取り込んでも二重に支払済みにしない
+		got, err = usecase.ReconcileStatement(application.ReconcileStatementDto{
+			Principal:     principal,
+			Format:        bankstatement.FormatCSV,
+			Content:       []byte(statement),
+			DateTolerance: 3,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got.Matched) != 0 {
+			t.Errorf("expected no matched lines, got %+v", got.Matched)
+		}
+	})
+
+	t.Run("dryRunの場合はステータスを変更しない", func(t *testing.T) {
+		repo := newRepo()
+		usecase := application.NewInvoiceUsecase(repo, nil, organizationRepo, newInMemoryUserRepository(), nil, nil)
+		got, err := usecase.ReconcileStatement(application.ReconcileStatementDto{
+			Principal:     principal,
+			Format:        bankstatement.FormatCSV,
+			Content:       []byte(statement),
+			DateTolerance: 3,
+			DryRun:        true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		wantDryRun := *want
+		wantDryRun.DryRun = true
+		if diff := cmp.Diff(&wantDryRun, got); diff != "" {
+			t.Errorf("report mismatch (-want +got):\n%s", diff)
+		}
+		if len(repo.histories) != 0 || len(repo.payments) != 0 {
+			t.Errorf("expected no changes, got histories=%d payments=%d", len(repo.histories), len(repo.payments))
+		}
+	})
+
+	t.Run("同じ請求書に照合する明細が複数ある場合は曖昧", func(t *testing.T) {
+		repo := newRepo()
+		usecase := application.NewInvoiceUsecase(repo, nil, organizationRepo, newInMemoryUserRepository(), nil, nil)
+		got, err := usecase.ReconcileStatement(application.ReconcileStatementDto{
+			Principal: principal,
+			Format:    bankstatement.FormatCSV,
+			Content: []byte("reference,date,type,amount,payee_name\n" +
+				"R1,2024-01-20,debit,10000,ﾄﾘﾋｷｻｷｴ-\n" +
+				"R2,2024-01-21,debit,10000,ﾄﾘﾋｷｻｷｴ-\n"),
+			DateTolerance: 3,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		wantContended := &application.ReconciliationReportDto{
+			Ambiguous: []application.ReconciledLineDto{
+				withReason(line(1, "R1", 20, 10000, "ﾄﾘﾋｷｻｷｴ-", 1), "matched invoice is also matched by another line"),
+				withReason(line(2, "R2", 21, 10000, "ﾄﾘﾋｷｻｷｴ-", 1), "matched invoice is also matched by another line"),
+			},
+		}
+		if diff := cmp.Diff(wantContended, got); diff != "" {
+			t.Errorf("report mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("支払方法が支払ゲートウェイの組織は消し込めない", func(t *testing.T) {
+		repo := newRepo()
+		usecase := application.NewInvoiceUsecase(repo, nil, organizationRepo, newInMemoryUserRepository(), nil, nil)
+		_, err := usecase.ReconcileStatement(application.ReconcileStatementDto{
+			Principal:     application.Principal{Subject: "auth0|user2", OrganizationID: 2, Scopes: clientScopes},
+			Format:        bankstatement.FormatCSV,
+			Content:       []byte(statement),
+			DateTolerance: 3,
+		})
+		if !errors.Is(err, model.ErrPaymentMethodMismatch) {
+			t.Errorf("error = %v, want %v", err, model.ErrPaymentMethodMismatch)
+		}
+		if len(repo.histories) != 0 || len(repo.payments) != 0 {
+			t.Errorf("expected no changes, got histories=%d payments=%d", len(repo.histories), len(repo.payments))
+		}
+	})
+}
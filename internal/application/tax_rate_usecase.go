@@ -0,0 +1,324 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// TaxRateUsecase 消費税率の管理. 税率は全組織に共通のため組織では絞り込まない
+type TaxRateUsecase interface {
+	ListTaxRates(dto ListTaxRatesDto) ([]TaxRateDto, error)
+	GetTaxRatesByDate(dto GetTaxRatesByDateDto) ([]TaxRateDto, error)
+	CreateTaxRate(dto CreateTaxRateDto) (*TaxRateDto, error)
+	UpdateTaxRate(dto UpdateTaxRateDto) (*TaxRateDto, error)
+	DeleteTaxRate(dto DeleteTaxRateDto) error
+	PreviewTaxRateChange(dto PreviewTaxRateChangeDto) (*TaxRateChangePreviewDto, error)
+}
+
+type taxRateUsecase struct {
+	taxRateRepo repository.TaxRate
+	invoiceRepo repository.Invoice
+}
+
+func NewTaxRateUsecase(taxRateRepo repository.TaxRate, invoiceRepo repository.Invoice) TaxRateUsecase {
+	return &taxRateUsecase{
+		taxRateRepo: taxRateRepo,
+		invoiceRepo: invoiceRepo,
+	}
+}
+
+// ErrTaxRateInEffect 適用が始まっている税率は、発行済みの請求書の消費税が変わるため変更できない
+var ErrTaxRateInEffect = errors.New("tax rate already in effect cannot be changed")
+
+// taxRateCategories 税率を持つ税区分
+var taxRateCategories = []model.TaxCategory{model.TaxCategoryStandard, model.TaxCategoryReduced}
+
+type TaxRateDto struct {
+	ID        uint
+	Category  string
+	StartDate time.Time
+	EndDate   *time.Time // nilなら現在も有効
+	Rate      float64
+}
+
+type ListTaxRatesDto struct {
+	Category string // 空の場合はすべての税区分
+}
+
+type GetTaxRatesByDateDto struct {
+	Date time.Time
+}
+
+// CreateTaxRateDto 税率の追加. 追加する税率は終了日を持たず、直前の税率は開始日の前日で終了する
+type CreateTaxRateDto struct {
+	Category  string
+	StartDate time.Time // 翌日以降であること
+	Rate      float64
+	Now       time.Time
+}
+
+// UpdateTaxRateDto 適用前の税率の変更. 開始日を変更した場合は直前の税率の終了日もあわせて変更する
+type UpdateTaxRateDto struct {
+	ID        uint
+	StartDate time.Time // 翌日以降であること
+	Rate      float64
+	Now       time.Time
+}
+
+// DeleteTaxRateDto 適用前の税率の削除. 削除した期間は直前の税率を延長して適用する
+type DeleteTaxRateDto struct {
+	ID  uint
+	Now time.Time
+}
+
+// PreviewTaxRateChangeDto 税率を変更した場合に影響を受ける請求書の確認
+type PreviewTaxRateChangeDto struct {
+	Category  string
+	StartDate time.Time
+	Rate      float64
+}
+
+type TaxRateChangePreviewDto struct {
+	Category  string
+	StartDate time.Time
+	EndDate   *time.Time // 以降の税率が登録されている場合はその開始日の前日
+	Rate      float64
+	Invoices  []AffectedInvoiceDto
+}
+
+// AffectedInvoiceDto 税率の変更で消費税が変わる支払済みでない請求書
+type AffectedInvoiceDto struct {
+	ID               uint
+	OrganizationID   uint
+	OrganizationName string
+	ClientID         uint
+	ClientName       string
+	IssueDate        time.Time
+	DueDate          time.Time
+	Status           string
+	TaxRate          float64 // 手数料に適用済みの標準税率
+	TotalAmount      int64
+}
+
+func (s *taxRateUsecase) ListTaxRates(dto ListTaxRatesDto) ([]TaxRateDto, error) {
+	categories := taxRateCategories
+	if dto.Category != "" {
+		categories = []model.TaxCategory{model.TaxCategory(dto.Category)}
+	}
+
+	dtos := []TaxRateDto{}
+	for _, category := range categories {
+		rates, err := s.taxRateRepo.FindByCategory(category)
+		if err != nil {
+			return nil, err
+		}
+		dtos = append(dtos, taxRatesToDto(rates)...)
+	}
+	return dtos, nil
+}
+
+func (s *taxRateUsecase) GetTaxRatesByDate(dto GetTaxRatesByDateDto) ([]TaxRateDto, error) {
+	rates, err := s.taxRateRepo.FindByDate(dto.Date)
+	if err != nil {
+		return nil, err
+	}
+	return taxRatesToDto(rates), nil
+}
+
+func (s *taxRateUsecase) CreateTaxRate(dto CreateTaxRateDto) (*TaxRateDto, error) {
+	if !dto.StartDate.After(truncateToDate(dto.Now)) {
+		return nil, fmt.Errorf("%w: start date %s is not in the future", ErrTaxRateInEffect, dto.StartDate.Format("2006-01-02"))
+	}
+
+	category := model.TaxCategory(dto.Category)
+	read, err := s.taxRateRepo.FindByCategory(category)
+	if err != nil {
+		return nil, err
+	}
+	rates := copyTaxRates(read)
+
+	// 現在有効な税率を新しい税率の開始日の前日で終了する
+	if len(rates) > 0 {
+		last := rates[len(rates)-1]
+		if last.EndDate == nil && last.StartDate.Before(dto.StartDate) {
+			end := dto.StartDate.AddDate(0, 0, -1)
+			last.EndDate = &end
+		}
+	}
+	rates = append(rates, &model.TaxRate{Category: category, StartDate: dto.StartDate, Rate: dto.Rate})
+
+	saved, err := s.replaceRates(category, read, rates)
+	if err != nil {
+		return nil, err
+	}
+	created := taxRateToDto(saved[len(saved)-1])
+	return &created, nil
+}
+
+func (s *taxRateUsecase) UpdateTaxRate(dto UpdateTaxRateDto) (*TaxRateDto, error) {
+	today := truncateToDate(dto.Now)
+	if !dto.StartDate.After(today) {
+		return nil, fmt.Errorf("%w: start date %s is not in the future", ErrTaxRateInEffect, dto.StartDate.Format("2006-01-02"))
+	}
+
+	read, index, err := s.findWithCategory(dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	rates := copyTaxRates(read)
+	target := rates[index]
+	if !target.StartDate.After(today) {
+		return nil, fmt.Errorf("%w: tax rate with ID %d started on %s", ErrTaxRateInEffect, target.ID, target.StartDate.Format("2006-01-02"))
+	}
+
+	target.StartDate = dto.StartDate
+	target.Rate = dto.Rate
+	if index > 0 {
+		end := dto.StartDate.AddDate(0, 0, -1)
+		rates[index-1].EndDate = &end
+	}
+
+	saved, err := s.replaceRates(target.Category, read, rates)
+	if err != nil {
+		return nil, err
+	}
+	updated := taxRateToDto(saved[index])
+	return &updated, nil
+}
+
+func (s *taxRateUsecase) DeleteTaxRate(dto DeleteTaxRateDto) error {
+	read, index, err := s.findWithCategory(dto.ID)
+	if err != nil {
+		return err
+	}
+	rates := copyTaxRates(read)
+	target := rates[index]
+	if !target.StartDate.After(truncateToDate(dto.Now)) {
+		return fmt.Errorf("%w: tax rate with ID %d started on %s", ErrTaxRateInEffect, target.ID, target.StartDate.Format("2006-01-02"))
+	}
+
+	// 削除した期間は直前の税率を延長して適用する
+	if index > 0 {
+		rates[index-1].EndDate = target.EndDate
+	}
+	rates = append(rates[:index], rates[index+1:]...)
+
+	_, err = s.replaceRates(target.Category, read, rates)
+	return err
+}
+
+func (s *taxRateUsecase) PreviewTaxRateChange(dto PreviewTaxRateChangeDto) (*TaxRateChangePreviewDto, error) {
+	category := model.TaxCategory(dto.Category)
+	if !category.IsTaxable() {
+		return nil, fmt.Errorf("%w: tax category %q has no tax rate", model.ErrInvalidTaxRatePeriod, category)
+	}
+
+	// 以降の税率が登録されている場合、変更後の税率はその開始日の前日まで適用される
+	rate := &model.TaxRate{Category: category, StartDate: dto.StartDate, Rate: dto.Rate}
+	rates, err := s.taxRateRepo.FindByCategory(category)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rates {
+		if r.StartDate.After(dto.StartDate) {
+			end := r.StartDate.AddDate(0, 0, -1)
+			rate.EndDate = &end
+			break
+		}
+	}
+
+	invoices, err := s.invoiceRepo.FindUnpaidIssuedFrom(dto.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &TaxRateChangePreviewDto{
+		Category:  dto.Category,
+		StartDate: rate.StartDate,
+		EndDate:   rate.EndDate,
+		Rate:      rate.Rate,
+		Invoices:  []AffectedInvoiceDto{},
+	}
+	for _, invoice := range invoices {
+		if invoice.IsAffectedByTaxRate(rate) {
+			preview.Invoices = append(preview.Invoices, AffectedInvoiceDto{
+				ID:               invoice.ID,
+				OrganizationID:   invoice.Organization.ID,
+				OrganizationName: invoice.Organization.Name,
+				ClientID:         invoice.Client.ID,
+				ClientName:       invoice.Client.Name,
+				IssueDate:        invoice.IssueDate,
+				DueDate:          invoice.DueDate,
+				Status:           string(invoice.Status),
+				TaxRate:          invoice.TaxRate,
+				TotalAmount:      invoice.TotalAmount.IntPart(),
+			})
+		}
+	}
+	return preview, nil
+}
+
+// findWithCategory 税率と同じ税区分の税率を開始日の昇順で取得し、税率の位置とあわせて返す
+func (s *taxRateUsecase) findWithCategory(id uint) ([]*model.TaxRate, int, error) {
+	rate, err := s.taxRateRepo.GetByID(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	rates, err := s.taxRateRepo.FindByCategory(rate.Category)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i, r := range rates {
+		if r.ID == id {
+			return rates, i, nil
+		}
+	}
+	return nil, 0, commonErrors.ErrNotFound
+}
+
+// replaceRates 税区分の税率の期間が重複も途切れもなく続いていることを検証してから置き換える.
+// 検証した税率が読み込んだ時点の read から他の操作で変更されている場合は ErrConflict を返す
+func (s *taxRateUsecase) replaceRates(category model.TaxCategory, read, rates []*model.TaxRate) ([]*model.TaxRate, error) {
+	if err := model.ValidateTaxRatePeriods(rates); err != nil {
+		return nil, err
+	}
+	return s.taxRateRepo.ReplaceCategory(category, read, rates)
+}
+
+// copyTaxRates 読み込んだ税率を変更せずに残しておくためにコピーする
+func copyTaxRates(rates []*model.TaxRate) []*model.TaxRate {
+	copied := make([]*model.TaxRate, len(rates))
+	for i, rate := range rates {
+		r := *rate
+		copied[i] = &r
+	}
+	return copied
+}
+
+// truncateToDate 日時を日付（UTCの0時）に切り捨てる
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func taxRatesToDto(rates []*model.TaxRate) []TaxRateDto {
+	dtos := make([]TaxRateDto, len(rates))
+	for i, rate := range rates {
+		dtos[i] = taxRateToDto(rate)
+	}
+	return dtos
+}
+
+func taxRateToDto(rate *model.TaxRate) TaxRateDto {
+	return TaxRateDto{
+		ID:        rate.ID,
+		Category:  string(rate.Category),
+		StartDate: rate.StartDate,
+		EndDate:   rate.EndDate,
+		Rate:      rate.Rate,
+	}
+}
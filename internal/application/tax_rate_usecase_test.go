@@ -0,0 +1,423 @@
+package application_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/shopspring/decimal"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+func (r *inMemoryInvoiceRepository) FindUnpaidIssuedFrom(issueDateFrom time.Time) ([]*model.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []*model.Invoice
+	for _, invoice := range r.invoices {
+		if invoice.Status != model.StatusPaid && !invoice.IssueDate.Before(issueDateFrom) {
+			copied := *invoice
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, nil
+}
+
+// inMemoryTaxRateRepository 税率の管理で使うメソッドだけを実装したインメモリの税率リポジトリ
+type inMemoryTaxRateRepository struct {
+	repository.TaxRate // 使わないメソッドは実装しない
+
+	rates  []*model.TaxRate
+	nextID uint
+
+	// beforeReplace 読み込んでから置き換えるまでの間に行われる他の操作
+	beforeReplace func(r *inMemoryTaxRateRepository)
+}
+
+func newInMemoryTaxRateRepository(rates ...*model.TaxRate) *inMemoryTaxRateRepository {
+	r := &inMemoryTaxRateRepository{rates: rates, nextID: 1}
+	for _, rate := range rates {
+		if rate.ID >= r.nextID {
+			r.nextID = rate.ID + 1
+		}
+	}
+	return r
+}
+
+func (r *inMemoryTaxRateRepository) GetByID(id uint) (*model.TaxRate, error) {
+	for _, rate := range r.rates {
+		if rate.ID == id {
+			copied := *rate
+			return &copied, nil
+		}
+	}
+	return nil, commonErrors.ErrNotFound
+}
+
+func (r *inMemoryTaxRateRepository) FindByCategory(category model.TaxCategory) ([]*model.TaxRate, error) {
+	var found []*model.TaxRate
+	for _, rate := range r.rates {
+		if rate.Category == category {
+			copied := *rate
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].StartDate.Before(found[j].StartDate) })
+	return found, nil
+}
+
+func (r *inMemoryTaxRateRepository) ReplaceCategory(category model.TaxCategory, read, rates []*model.TaxRate) ([]*model.TaxRate, error) {
+	if r.beforeReplace != nil {
+		r.beforeReplace(r)
+	}
+	current, _ := r.FindByCategory(category)
+	if !cmp.Equal(current, read, cmpopts.EquateEmpty()) {
+		return nil, commonErrors.ErrConflict
+	}
+
+	var replaced []*model.TaxRate
+	for _, rate := range r.rates {
+		if rate.Category != category {
+			replaced = append(replaced, rate)
+		}
+	}
+	saved := make([]*model.TaxRate, len(rates))
+	for i, rate := range rates {
+		copied := *rate
+		if copied.ID == 0 {
+			copied.ID = r.nextID
+			r.nextID++
+		}
+		replaced = append(replaced, &copied)
+		saved[i] = &copied
+	}
+	r.rates = replaced
+	return saved, nil
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func datePtr(year int, month time.Month, day int) *time.Time {
+	d := date(year, month, day)
+	return &d
+}
+
+// seedTaxRates 8%から10%に変わった標準税率と、10%から12%に変わる予定の標準税率
+func seedTaxRates() []*model.TaxRate {
+	return []*model.TaxRate{
+		{ID: 1, Category: model.TaxCategoryStandard, StartDate: date(2014, 4, 1), EndDate: datePtr(2019, 9, 30), Rate: 0.08},
+		{ID: 2, Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), EndDate: datePtr(2029, 9, 30), Rate: 0.1},
+		{ID: 3, Category: model.TaxCategoryStandard, StartDate: date(2029, 10, 1), Rate: 0.12},
+		{ID: 4, Category: model.TaxCategoryReduced, StartDate: date(2019, 10, 1), Rate: 0.08},
+	}
+}
+
+func Test_TaxRateUsecase_CreateTaxRate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		dto     application.CreateTaxRateDto
+		want    []*model.TaxRate
+		wantErr error
+	}{
+		{
+			name: "現在の税率を前日で終了して追加",
+			dto:  application.CreateTaxRateDto{Category: "reduced", StartDate: date(2026, 4, 1), Rate: 0.1, Now: now},
+			want: []*model.TaxRate{
+				{ID: 4, Category: model.TaxCategoryReduced, StartDate: date(2019, 10, 1), EndDate: datePtr(2026, 3, 31), Rate: 0.08},
+				{ID: 5, Category: model.TaxCategoryReduced, StartDate: date(2026, 4, 1), Rate: 0.1},
+			},
+		},
+		{
+			name:    "開始日が今日",
+			dto:     application.CreateTaxRateDto{Category: "reduced", StartDate: date(2025, 6, 1), Rate: 0.1, Now: now},
+			wantErr: application.ErrTaxRateInEffect,
+		},
+		{
+			name:    "最新の税率より前に追加すると期間が重複する",
+			dto:     application.CreateTaxRateDto{Category: "standard", StartDate: date(2026, 4, 1), Rate: 0.11, Now: now},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name:    "税率のない税区分",
+			dto:     application.CreateTaxRateDto{Category: "exempt", StartDate: date(2026, 4, 1), Rate: 0.1, Now: now},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newInMemoryTaxRateRepository(seedTaxRates()...)
+			usecase := application.NewTaxRateUsecase(repo, nil)
+
+			_, err := usecase.CreateTaxRate(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := repo.FindByCategory(model.TaxCategory(tt.dto.Category))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("tax rates mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_TaxRateUsecase_UpdateTaxRate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		dto     application.UpdateTaxRateDto
+		want    []*model.TaxRate
+		wantErr error
+	}{
+		{
+			name: "開始日を変更すると直前の税率の終了日も変わる",
+			dto:  application.UpdateTaxRateDto{ID: 3, StartDate: date(2030, 4, 1), Rate: 0.15, Now: now},
+			want: []*model.TaxRate{
+				{ID: 1, Category: model.TaxCategoryStandard, StartDate: date(2014, 4, 1), EndDate: datePtr(2019, 9, 30), Rate: 0.08},
+				{ID: 2, Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), EndDate: datePtr(2030, 3, 31), Rate: 0.1},
+				{ID: 3, Category: model.TaxCategoryStandard, StartDate: date(2030, 4, 1), Rate: 0.15},
+			},
+		},
+		{
+			name:    "適用中の税率",
+			dto:     application.UpdateTaxRateDto{ID: 2, StartDate: date(2029, 10, 1), Rate: 0.1, Now: now},
+			wantErr: application.ErrTaxRateInEffect,
+		},
+		{
+			name:    "存在しない税率",
+			dto:     application.UpdateTaxRateDto{ID: 99, StartDate: date(2029, 10, 1), Rate: 0.1, Now: now},
+			wantErr: commonErrors.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newInMemoryTaxRateRepository(seedTaxRates()...)
+			usecase := application.NewTaxRateUsecase(repo, nil)
+
+			_, err := usecase.UpdateTaxRate(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := repo.FindByCategory(model.TaxCategoryStandard)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("tax rates mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_TaxRateUsecase_DeleteTaxRate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		dto     application.DeleteTaxRateDto
+		want    []*model.TaxRate
+		wantErr error
+	}{
+		{
+			name: "直前の税率を延長する",
+			dto:  application.DeleteTaxRateDto{ID: 3, Now: now},
+			want: []*model.TaxRate{
+				{ID: 1, Category: model.TaxCategoryStandard, StartDate: date(2014, 4, 1), EndDate: datePtr(2019, 9, 30), Rate: 0.08},
+				{ID: 2, Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), Rate: 0.1},
+			},
+		},
+		{
+			name:    "適用中の税率",
+			dto:     application.DeleteTaxRateDto{ID: 2, Now: now},
+			wantErr: application.ErrTaxRateInEffect,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newInMemoryTaxRateRepository(seedTaxRates()...)
+			usecase := application.NewTaxRateUsecase(repo, nil)
+
+			err := usecase.DeleteTaxRate(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := repo.FindByCategory(model.TaxCategoryStandard)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("tax rates mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_TaxRateUsecase_ConcurrentChange(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// 読み込んだ後に他の操作で適用前の標準税率が変更される
+	changeRate := func(r *inMemoryTaxRateRepository) {
+		r.beforeReplace = nil
+		for _, rate := range r.rates {
+			if rate.ID == 3 {
+				rate.Rate = 0.13
+			}
+		}
+	}
+	want := []*model.TaxRate{
+		{ID: 1, Category: model.TaxCategoryStandard, StartDate: date(2014, 4, 1), EndDate: datePtr(2019, 9, 30), Rate: 0.08},
+		{ID: 2, Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), EndDate: datePtr(2029, 9, 30), Rate: 0.1},
+		{ID: 3, Category: model.TaxCategoryStandard, StartDate: date(2029, 10, 1), Rate: 0.13},
+	}
+
+	tests := []struct {
+		name   string
+		change func(usecase application.TaxRateUsecase) error
+	}{
+		{
+			name: "登録",
+			change: func(usecase application.TaxRateUsecase) error {
+				_, err := usecase.CreateTaxRate(application.CreateTaxRateDto{Category: "standard", StartDate: date(2031, 4, 1), Rate: 0.15, Now: now})
+				return err
+			},
+		},
+		{
+			name: "変更",
+			change: func(usecase application.TaxRateUsecase) error {
+				_, err := usecase.UpdateTaxRate(application.UpdateTaxRateDto{ID: 3, StartDate: date(2030, 4, 1), Rate: 0.15, Now: now})
+				return err
+			},
+		},
+		{
+			name: "削除",
+			change: func(usecase application.TaxRateUsecase) error {
+				return usecase.DeleteTaxRate(application.DeleteTaxRateDto{ID: 3, Now: now})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newInMemoryTaxRateRepository(seedTaxRates()...)
+			repo.beforeReplace = changeRate
+			usecase := application.NewTaxRateUsecase(repo, nil)
+
+			err := tt.change(usecase)
+
+			if !errors.Is(err, commonErrors.ErrConflict) {
+				t.Errorf("error = %v, want %v", err, commonErrors.ErrConflict)
+			}
+			// 他の操作で変更した税率は上書きされない
+			got, _ := repo.FindByCategory(model.TaxCategoryStandard)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("tax rates mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_TaxRateUsecase_PreviewTaxRateChange(t *testing.T) {
+	newInvoice := func(id uint, issueDate time.Time, status model.InvoiceStatus, taxRate float64, items ...*model.InvoiceLineItem) *model.Invoice {
+		return &model.Invoice{
+			ID:           id,
+			Organization: &model.Organization{ID: 1, Name: "株式会社サンプル"},
+			Client:       &model.Client{ID: 1, Name: "取引先A"},
+			IssueDate:    issueDate,
+			DueDate:      issueDate.AddDate(0, 1, 0),
+			TaxRate:      taxRate,
+			TotalAmount:  decimal.NewFromInt(10440),
+			Status:       status,
+			LineItems:    items,
+		}
+	}
+	invoiceRepo := newInMemoryInvoiceRepository(
+		newInvoice(1, date(2026, 3, 31), model.StatusPending, 0.1),
+		newInvoice(2, date(2026, 4, 1), model.StatusPending, 0.1),
+		newInvoice(3, date(2026, 4, 1), model.StatusPaid, 0.1),
+		newInvoice(4, date(2026, 4, 1), model.StatusProcessing, 0.1,
+			&model.InvoiceLineItem{TaxCategory: model.TaxCategoryReduced, TaxRate: 0.08}),
+		newInvoice(5, date(2029, 10, 1), model.StatusPending, 0.12),
+	)
+	taxRateRepo := newInMemoryTaxRateRepository(seedTaxRates()...)
+	usecase := application.NewTaxRateUsecase(taxRateRepo, invoiceRepo)
+
+	tests := []struct {
+		name    string
+		dto     application.PreviewTaxRateChangeDto
+		wantEnd *time.Time
+		wantIDs []uint
+		wantErr error
+	}{
+		{
+			name:    "以降の税率の適用開始日より前の支払済みでない請求書",
+			dto:     application.PreviewTaxRateChangeDto{Category: "standard", StartDate: date(2026, 4, 1), Rate: 0.11},
+			wantEnd: datePtr(2029, 9, 30),
+			wantIDs: []uint{2, 4},
+		},
+		{
+			name:    "軽減税率の明細がある請求書",
+			dto:     application.PreviewTaxRateChangeDto{Category: "reduced", StartDate: date(2026, 4, 1), Rate: 0.1},
+			wantIDs: []uint{4},
+		},
+		{
+			name:    "税率のない税区分",
+			dto:     application.PreviewTaxRateChangeDto{Category: "exempt", StartDate: date(2026, 4, 1), Rate: 0.1},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := usecase.PreviewTaxRateChange(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantEnd, got.EndDate); diff != "" {
+				t.Errorf("end date mismatch (-want +got):\n%s", diff)
+			}
+			var gotIDs []uint
+			for _, invoice := range got.Invoices {
+				gotIDs = append(gotIDs, invoice.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ids mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/zengin"
+)
+
+// TransferFileError 振込データを作成できない理由を表すエラー
+type TransferFileError struct {
+	InvoiceID uint   // 原因となった請求書ID（振込依頼人の出金口座が原因の場合は0）
+	Reason    string // 理由
+}
+
+func (e *TransferFileError) Error() string {
+	if e.InvoiceID == 0 {
+		return fmt.Sprintf("organization bank account: %s", e.Reason)
+	}
+	return fmt.Sprintf("invoice %d: %s", e.InvoiceID, e.Reason)
+}
+
+type ExportTransferFileDto struct {
+	Principal    Principal
+	InvoiceIDs   []uint
+	TransferDate time.Time // 取組日（振込指定日）
+}
+
+type TransferFileDto struct {
+	FileName    string
+	Content     []byte // Shift_JIS の全銀フォーマット
+	Count       int
+	TotalAmount int64
+}
+
+// zenginAccountTypes 預金種目と全銀フォーマットのコードの対応
+var zenginAccountTypes = map[model.AccountType]zengin.AccountType{
+	model.AccountTypeOrdinary: zengin.AccountTypeOrdinary,
+	model.AccountTypeChecking: zengin.AccountTypeChecking,
+}
+
+// ExportTransferFile 処理中の請求書から全銀協 総合振込フォーマットの振込データを作成する.
+// 支払方法が振込ファイルの組織に限る（支払ゲートウェイの組織は支払処理ワーカーが支払うため、二重に振り込まないよう拒否する）.
+// 請求書のステータスは変更しない
+func (s *invoiceUsecase) ExportTransferFile(dto ExportTransferFileDto) (*TransferFileDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionExportTransferFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requirePaymentMethod(organizationID, model.PaymentMethodTransferFile); err != nil {
+		return nil, err
+	}
+
+	// 同じ請求書を二重に振り込まないよう重複を拒否する
+	seen := make(map[uint]bool, len(dto.InvoiceIDs))
+	for _, id := range dto.InvoiceIDs {
+		if seen[id] {
+			return nil, &TransferFileError{InvoiceID: id, Reason: "duplicated"}
+		}
+		seen[id] = true
+	}
+
+	invoices, err := s.invoiceRepo.FindByIDs(organizationID, dto.InvoiceIDs)
+	if err != nil {
+		return nil, err
+	}
+	// 他組織の請求書を含め、取得できなかったものがあれば存在しないものとして扱う
+	if len(invoices) != len(dto.InvoiceIDs) {
+		return nil, commonErrors.ErrNotFound
+	}
+
+	account, err := s.organizationRepo.GetBankAccount(organizationID)
+	if err != nil {
+		if errors.Is(err, commonErrors.ErrNotFound) {
+			return nil, &TransferFileError{Reason: "not registered"}
+		}
+		return nil, err
+	}
+
+	file := &zengin.TransferFile{
+		Header: zengin.TransferHeader{
+			RequesterCode: account.RequesterCode,
+			RequesterName: account.RequesterName,
+			TransferDate:  dto.TransferDate,
+			BankCode:      account.BankCode,
+			BankName:      account.BankName,
+			BranchCode:    account.BranchCode,
+			BranchName:    account.BranchName,
+			AccountType:   zenginAccountTypes[account.AccountType],
+			AccountNumber: account.AccountNumber,
+		},
+		Records: make([]zengin.TransferRecord, len(invoices)),
+	}
+	for i, invoice := range invoices {
+		record, err := toTransferRecord(invoice)
+		if err != nil {
+			return nil, err
+		}
+		file.Records[i] = *record
+	}
+
+	content, err := file.Encode()
+	if err != nil {
+		var fieldErr *zengin.FieldError
+		if errors.As(err, &fieldErr) {
+			transferErr := &TransferFileError{Reason: fmt.Sprintf("%s %s", fieldErr.Field, fieldErr.Reason)}
+			if fieldErr.Record > 0 {
+				transferErr.InvoiceID = invoices[fieldErr.Record-1].ID
+			}
+			return nil, transferErr
+		}
+		return nil, &TransferFileError{Reason: err.Error()}
+	}
+
+	return &TransferFileDto{
+		FileName:    fmt.Sprintf("zengin_transfer_%s.txt", dto.TransferDate.Format("20060102")),
+		Content:     content,
+		Count:       len(file.Records),
+		TotalAmount: file.TotalAmount(),
+	}, nil
+}
+
+// requirePaymentMethod 組織の支払方法が method でなければ ErrPaymentMethodMismatch を返す
+func (s *invoiceUsecase) requirePaymentMethod(organizationID uint, method model.PaymentMethod) error {
+	organization, err := s.organizationRepo.GetByID(organizationID)
+	if err != nil {
+		return err
+	}
+	return organization.RequirePaymentMethod(method)
+}
+
+// toTransferRecord 請求書を振込データに変換する. 振込先は取引先の口座、振込金額は支払金額から源泉徴収税額を差し引いた額とする
+func toTransferRecord(invoice *model.Invoice) (*zengin.TransferRecord, error) {
+	if invoice.Status != model.StatusProcessing {
+		return nil, &TransferFileError{InvoiceID: invoice.ID, Reason: fmt.Sprintf("status must be %s but %s", model.StatusProcessing, invoice.Status)}
+	}
+	account := invoice.Client.BankAccount
+	if account == nil {
+		return nil, &TransferFileError{InvoiceID: invoice.ID, Reason: "bank account is not registered"}
+	}
+	// 振込金額は源泉徴収税額を差し引いた額
+	amount := invoice.TransferAmount()
+	if !amount.Equal(amount.Truncate(0)) {
+		return nil, &TransferFileError{InvoiceID: invoice.ID, Reason: "amount must be a whole yen"}
+	}
+
+	return &zengin.TransferRecord{
+		BankCode:      account.BankCode,
+		BankName:      account.BankName,
+		BranchCode:    account.BranchCode,
+		BranchName:    account.BranchName,
+		AccountType:   zenginAccountTypes[account.AccountType],
+		AccountNumber: account.AccountNumber,
+		AccountName:   account.AccountName,
+		Amount:        amount.IntPart(),
+		CustomerCode:  fmt.Sprint(invoice.ID),
+	}, nil
+}
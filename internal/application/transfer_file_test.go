@@ -0,0 +1,188 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/zengin"
+)
+
+func (r *inMemoryInvoiceRepository) FindByIDs(organizationID uint, ids []uint) ([]*model.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []*model.Invoice
+	for _, id := range ids {
+		if invoice, ok := r.invoices[id]; ok && invoice.Organization.ID == organizationID {
+			copied := *invoice
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+// inMemoryOrganizationRepository 組織・出金口座・設定を扱うインメモリの組織リポジトリ
+type inMemoryOrganizationRepository struct {
+	repository.Organization // 使わないメソッドは実装しない
+
+	organizations map[uint]*model.Organization
+	bankAccounts  map[uint]*model.OrganizationBankAccount
+	settings      map[uint][]*model.OrganizationSettings // 組織ごとの設定（版数の昇順）
+}
+
+func (r *inMemoryOrganizationRepository) GetBankAccount(organizationID uint) (*model.OrganizationBankAccount, error) {
+	account, ok := r.bankAccounts[organizationID]
+	if !ok {
+		return nil, commonErrors.ErrNotFound
+	}
+	return account, nil
+}
+
+func Test_InvoiceUsecase_ExportTransferFile(t *testing.T) {
+	transferDate := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	bankAccount := &model.ClientBankAccount{
+		ID:            1,
+		ClientID:      1,
+		BankCode:      "0001",
+		BankName:      "ミズホ",
+		BranchCode:    "100",
+		BranchName:    "ホンテン",
+		AccountType:   model.AccountTypeOrdinary,
+		AccountNumber: "1234567",
+		AccountName:   "トリヒキサキエー",
+	}
+
+	newRepo := func() *inMemoryInvoiceRepository {
+		return newInMemoryInvoiceRepository(
+			newPaymentTestInvoice(1, 10000, transferDate, model.StatusProcessing, bankAccount),
+			newPaymentTestInvoice(2, 20000, transferDate, model.StatusProcessing, bankAccount),
+			newPaymentTestInvoice(3, 30000, transferDate, model.StatusPending, bankAccount),
+			newPaymentTestInvoice(4, 40000, transferDate, model.StatusProcessing, nil),
+			&model.Invoice{
+				ID:           5,
+				Organization: &model.Organization{ID: 2},
+				Client:       &model.Client{ID: 3, OrganizationID: 2, BankAccount: bankAccount},
+				Amount:       decimal.NewFromInt(50000),
+				Status:       model.StatusProcessing,
+			},
+		)
+	}
+	organizationRepo := &inMemoryOrganizationRepository{
+		organizations: map[uint]*model.Organization{
+			1: {ID: 1, PaymentMethod: model.PaymentMethodTransferFile},
+			2: {ID: 2, PaymentMethod: model.PaymentMethodTransferFile},
+			3: {ID: 3, PaymentMethod: model.PaymentMethodGateway},
+		},
+		bankAccounts: map[uint]*model.OrganizationBankAccount{
+			1: {
+				OrganizationID: 1,
+				RequesterCode:  "1234567890",
+				RequesterName:  "カ)サンプル",
+				BankCode:       "0001",
+				BranchCode:     "001",
+				AccountType:    model.AccountTypeOrdinary,
+				AccountNumber:  "7654321",
+			},
+		},
+	}
+	principal := application.Principal{Subject: "auth0|user1", OrganizationID: 1, Scopes: clientScopes}
+
+	tests := []struct {
+		name      string
+		principal application.Principal
+		ids       []uint
+		want      *application.TransferFileDto
+		wantErr   error
+		wantTrErr *application.TransferFileError
+	}{
+		{
+			name:      "処理中の請求書から振込データを作成",
+			principal: principal,
+			ids:       []uint{1, 2},
+			want:      &application.TransferFileDto{FileName: "zengin_transfer_20240120.txt", Count: 2, TotalAmount: 30000},
+		},
+		{
+			name:      "他組織の請求書は存在しないものとして扱う",
+			principal: principal,
+			ids:       []uint{1, 5},
+			wantErr:   commonErrors.ErrNotFound,
+		},
+		{
+			name:      "同じ請求書を重複して指定できない",
+			principal: principal,
+			ids:       []uint{1, 1},
+			wantTrErr: &application.TransferFileError{InvoiceID: 1, Reason: "duplicated"},
+		},
+		{
+			name:      "処理中でない請求書は含められない",
+			principal: principal,
+			ids:       []uint{1, 3},
+			wantTrErr: &application.TransferFileError{InvoiceID: 3, Reason: "status must be processing but pending"},
+		},
+		{
+			name:      "振込先口座が未登録",
+			principal: principal,
+			ids:       []uint{4},
+			wantTrErr: &application.TransferFileError{InvoiceID: 4, Reason: "bank account is not registered"},
+		},
+		{
+			name:      "出金口座が未登録",
+			principal: application.Principal{Subject: "auth0|user2", OrganizationID: 2, Scopes: clientScopes},
+			ids:       []uint{5},
+			wantTrErr: &application.TransferFileError{Reason: "not registered"},
+		},
+		{
+			name:      "支払方法が支払ゲートウェイの組織は作成できない",
+			principal: application.Principal{Subject: "auth0|user3", OrganizationID: 3, Scopes: clientScopes},
+			ids:       []uint{1},
+			wantErr:   model.ErrPaymentMethodMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := application.NewInvoiceUsecase(newRepo(), nil, organizationRepo, newInMemoryUserRepository(), nil, nil)
+			got, err := usecase.ExportTransferFile(application.ExportTransferFileDto{
+				Principal:    tt.principal,
+				InvoiceIDs:   tt.ids,
+				TransferDate: transferDate,
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if tt.wantTrErr != nil {
+				var transferErr *application.TransferFileError
+				if !errors.As(err, &transferErr) {
+					t.Fatalf("error = %v, want TransferFileError", err)
+				}
+				if diff := cmp.Diff(tt.wantTrErr, transferErr); diff != "" {
+					t.Errorf("error mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// ヘッダー・トレーラー・エンドとデータレコードが CRLF 区切りで並ぶ
+			if len(got.Content) != (len(tt.ids)+3)*(zengin.RecordLength+len("\r\n")) {
+				t.Errorf("content length = %d", len(got.Content))
+			}
+			got.Content = nil
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
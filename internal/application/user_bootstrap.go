@@ -0,0 +1,109 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+// ConsoleOperator サーバーのコンソールから操作した場合のロールの変更者
+const ConsoleOperator = "console"
+
+// ErrBootstrapOrganizationMismatch 招待するユーザーが指定した組織に所属していない
+var ErrBootstrapOrganizationMismatch = errors.New("user does not belong to the organization")
+
+// UserBootstrapUsecase ログインできるユーザーがいない状態から招待を発行する.
+// 操作主体を確認しないため、HTTP からは呼び出さずサーバーのコンソール（cmd/invite）からだけ使う
+type UserBootstrapUsecase interface {
+	IssueInvitation(dto IssueInvitationDto) (*InvitationDto, error)
+}
+
+type userBootstrapUsecase struct {
+	userRepo         repository.User
+	organizationRepo repository.Organization
+}
+
+func NewUserBootstrapUsecase(userRepo repository.User, organizationRepo repository.Organization) UserBootstrapUsecase {
+	return &userBootstrapUsecase{
+		userRepo:         userRepo,
+		organizationRepo: organizationRepo,
+	}
+}
+
+// IssueInvitationDto コンソールからの招待. 登録済みのメールアドレスは招待トークンを再発行し、
+// 未登録の場合は組織の所有者として招待する
+type IssueInvitationDto struct {
+	OrganizationID uint   // 未登録のユーザーを招待する組織. 登録済みのユーザーの場合は省略（0）できる
+	Name           string // 未登録のユーザーの名前
+	Email          string
+	Now            time.Time
+}
+
+// IssueInvitation 招待トークンを発行する. パスワードは招待を承諾したときに本人が設定する
+func (s *userBootstrapUsecase) IssueInvitation(dto IssueInvitationDto) (*InvitationDto, error) {
+	user, err := s.userRepo.GetByEmail(dto.Email)
+	switch {
+	case err == nil:
+		if dto.OrganizationID != 0 && !user.BelongsTo(dto.OrganizationID) {
+			return nil, fmt.Errorf("%w: user %s, organization %d", ErrBootstrapOrganizationMismatch, dto.Email, dto.OrganizationID)
+		}
+		return s.reinvite(user, dto.Now)
+	case errors.Is(err, commonErrors.ErrNotFound):
+		return s.inviteOwner(dto)
+	default:
+		return nil, err
+	}
+}
+
+func (s *userBootstrapUsecase) reinvite(user *model.User, now time.Time) (*InvitationDto, error) {
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := user.Invite(tokenHash, now); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.userRepo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	return userToInvitationDto(updated, token), nil
+}
+
+func (s *userBootstrapUsecase) inviteOwner(dto IssueInvitationDto) (*InvitationDto, error) {
+	if _, err := s.organizationRepo.GetByID(dto.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		OrganizationID: dto.OrganizationID,
+		Name:           dto.Name,
+		Email:          dto.Email,
+		Role:           model.RoleOwner,
+	}
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := user.Invite(tokenHash, dto.Now); err != nil {
+		return nil, err
+	}
+
+	created, err := s.userRepo.Create(user, &model.UserRoleChange{
+		ToRole:    model.RoleOwner,
+		ChangedBy: ConsoleOperator,
+		ChangedAt: dto.Now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userToInvitationDto(created, token), nil
+}
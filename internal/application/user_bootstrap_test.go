@@ -0,0 +1,104 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+func Test_UserBootstrapUsecase_IssueInvitation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	deactivatedAt := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		dto      application.IssueInvitationDto
+		wantUser application.UserDto
+		wantErr  error
+	}{
+		{
+			name: "パスワードを無効にした登録済みのユーザーを招待し直す",
+			dto:  application.IssueInvitationDto{Email: "ichiro.sato@example.com", Now: now},
+			wantUser: application.UserDto{
+				ID: 1, Name: "佐藤 一郎", Email: "ichiro.sato@example.com", Role: "owner", Status: "invited",
+			},
+		},
+		{
+			name: "未登録のユーザーは組織の所有者として招待する",
+			dto:  application.IssueInvitationDto{OrganizationID: 2, Name: "鈴木 四郎", Email: "shiro.suzuki@example.com", Now: now},
+			wantUser: application.UserDto{
+				ID: 3, Name: "鈴木 四郎", Email: "shiro.suzuki@example.com", Role: "owner", Status: "invited",
+			},
+		},
+		{
+			name:    "登録済みのユーザーが指定した組織に所属していない",
+			dto:     application.IssueInvitationDto{OrganizationID: 2, Email: "ichiro.sato@example.com", Now: now},
+			wantErr: application.ErrBootstrapOrganizationMismatch,
+		},
+		{
+			name:    "無効化したユーザー",
+			dto:     application.IssueInvitationDto{Email: "jiro.tanaka@example.com", Now: now},
+			wantErr: model.ErrUserDeactivated,
+		},
+		{
+			name:    "存在しない組織",
+			dto:     application.IssueInvitationDto{OrganizationID: 9, Name: "鈴木 四郎", Email: "shiro.suzuki@example.com", Now: now},
+			wantErr: commonErrors.ErrNotFound,
+		},
+		{
+			name:    "未登録のユーザーで組織を省略",
+			dto:     application.IssueInvitationDto{Name: "鈴木 四郎", Email: "shiro.suzuki@example.com", Now: now},
+			wantErr: commonErrors.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := newInMemoryUserRepository(
+				&model.User{ID: 1, OrganizationID: 1, Name: "佐藤 一郎", Email: "ichiro.sato@example.com", Role: model.RoleOwner},
+				&model.User{ID: 2, OrganizationID: 1, Name: "田中 二郎", Email: "jiro.tanaka@example.com", Role: model.RoleAdmin, DeactivatedAt: &deactivatedAt},
+			)
+			organizationRepo := &inMemoryOrganizationRepository{organizations: map[uint]*model.Organization{
+				1: {ID: 1, Name: "株式会社サンプル"},
+				2: {ID: 2, Name: "株式会社テスト"},
+			}}
+			usecase := application.NewUserBootstrapUsecase(userRepo, organizationRepo)
+
+			got, err := usecase.IssueInvitation(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantUser, got.User); diff != "" {
+				t.Errorf("user mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(now.Add(model.InvitationValidity), got.ExpiresAt); diff != "" {
+				t.Errorf("expires at mismatch (-want +got):\n%s", diff)
+			}
+
+			// 発行した招待トークンでパスワードを設定できる
+			accepted, err := application.NewUserUsecase(userRepo).AcceptInvitation(application.AcceptInvitationDto{
+				Token:    got.Token,
+				Password: "correct horse battery",
+				Now:      now,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if accepted.Status != "active" {
+				t.Errorf("status = %q, want %q", accepted.Status, "active")
+			}
+		})
+	}
+}
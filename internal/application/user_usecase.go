@@ -0,0 +1,388 @@
+package application
+
+import (
+	"errors"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/password"
+)
+
+// ErrSelfDeactivation 操作主体が自分自身を無効化しようとした
+var ErrSelfDeactivation = errors.New("cannot deactivate yourself")
+
+// ErrSelfRoleChange 操作主体が自分自身のロールを変更しようとした
+var ErrSelfRoleChange = errors.New("cannot change your own role")
+
+// UserUsecase ユーザーの管理. 操作主体の所属組織のユーザーに限る.
+// 所有者の招待・所有者に関する変更は、所有者のユーザーに限る
+type UserUsecase interface {
+	InviteUser(dto InviteUserDto) (*InvitationDto, error)
+	ListUsers(dto ListUsersDto) ([]*UserDto, error)
+	GetUser(dto GetUserDto) (*UserDto, error)
+	UpdateUser(dto UpdateUserDto) (*UserDto, error)
+	DeactivateUser(dto DeactivateUserDto) (*UserDto, error)
+	ReinviteUser(dto ReinviteUserDto) (*InvitationDto, error)
+	AcceptInvitation(dto AcceptInvitationDto) (*UserDto, error)
+	ChangeUserRole(dto ChangeUserRoleDto) (*UserDto, error)
+	ListRoleChanges(dto GetUserDto) ([]*UserRoleChangeDto, error)
+}
+
+type userUsecase struct {
+	userRepo repository.User
+}
+
+func NewUserUsecase(userRepo repository.User) UserUsecase {
+	return &userUsecase{
+		userRepo: userRepo,
+	}
+}
+
+type InviteUserDto struct {
+	Principal Principal
+	Name      string
+	Email     string
+	Role      string // 省略した場合（空文字）は閲覧者
+	Now       time.Time
+}
+
+type ListUsersDto struct {
+	Principal          Principal
+	IncludeDeactivated bool // 無効化したユーザーも含める
+}
+
+type GetUserDto struct {
+	Principal Principal
+	ID        uint
+}
+
+type UpdateUserDto struct {
+	Principal Principal
+	ID        uint
+	Name      string
+	Email     string
+}
+
+type DeactivateUserDto struct {
+	Principal Principal
+	ID        uint
+	Now       time.Time
+}
+
+type ReinviteUserDto struct {
+	Principal Principal
+	ID        uint
+	Now       time.Time
+}
+
+type ChangeUserRoleDto struct {
+	Principal Principal
+	ID        uint
+	Role      string
+	Now       time.Time
+}
+
+// AcceptInvitationDto 招待の承諾. 招待トークンで本人を確認するため操作主体は不要
+type AcceptInvitationDto struct {
+	Token    string
+	Password string
+	Now      time.Time
+}
+
+type UserDto struct {
+	ID            uint
+	Name          string
+	Email         string
+	Role          string
+	Status        string
+	DeactivatedAt *time.Time // 無効化していない場合はnil
+}
+
+type UserRoleChangeDto struct {
+	ID        uint
+	FromRole  string // 招待時の割り当ては空文字
+	ToRole    string
+	ChangedBy string
+	ChangedAt time.Time
+}
+
+type InvitationDto struct {
+	User      UserDto
+	Token     string // 招待トークン（この応答でのみ返す）
+	ExpiresAt time.Time
+}
+
+// InviteUser ユーザーを招待する. パスワードは招待を承諾したときに本人が設定する
+func (s *userUsecase) InviteUser(dto InviteUserDto) (*InvitationDto, error) {
+	a, err := authorizeActor(s.userRepo, dto.Principal, model.PermissionWriteUser)
+	if err != nil {
+		return nil, err
+	}
+
+	role := model.Role(dto.Role)
+	if role == "" {
+		role = model.DefaultRole
+	}
+	user := &model.User{
+		OrganizationID: a.organizationID,
+		Name:           dto.Name,
+		Email:          dto.Email,
+		Role:           role,
+	}
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+	if err := checkOwnerOperation(a, role); err != nil {
+		return nil, err
+	}
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := user.Invite(tokenHash, dto.Now); err != nil {
+		return nil, err
+	}
+
+	created, err := s.userRepo.Create(user, &model.UserRoleChange{
+		ToRole:    role,
+		ChangedBy: dto.Principal.Subject,
+		ChangedAt: dto.Now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userToInvitationDto(created, token), nil
+}
+
+func (s *userUsecase) ListUsers(dto ListUsersDto) ([]*UserDto, error) {
+	organizationID, err := authorize(s.userRepo, dto.Principal, model.PermissionReadUser)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.FindByOrganizationID(organizationID, dto.IncludeDeactivated)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*UserDto, len(users))
+	for i, user := range users {
+		result[i] = userToDto(user)
+	}
+	return result, nil
+}
+
+func (s *userUsecase) GetUser(dto GetUserDto) (*UserDto, error) {
+	_, user, err := s.findUser(dto.Principal, dto.ID, model.PermissionReadUser)
+	if err != nil {
+		return nil, err
+	}
+	return userToDto(user), nil
+}
+
+// UpdateUser ユーザーの氏名・メールアドレスを更新する. 無効化したユーザーは更新できない
+func (s *userUsecase) UpdateUser(dto UpdateUserDto) (*UserDto, error) {
+	a, user, err := s.findUser(dto.Principal, dto.ID, model.PermissionWriteUser)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOwnerOperation(a, user.Role); err != nil {
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		return nil, model.ErrUserDeactivated
+	}
+
+	user.Name = dto.Name
+	user.Email = dto.Email
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.userRepo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	return userToDto(updated), nil
+}
+
+// DeactivateUser ユーザーを無効化する. 以降はそのユーザーのトークンで操作できない
+func (s *userUsecase) DeactivateUser(dto DeactivateUserDto) (*UserDto, error) {
+	if dto.Principal.UserID != 0 && dto.Principal.UserID == dto.ID {
+		return nil, ErrSelfDeactivation
+	}
+	a, user, err := s.findUser(dto.Principal, dto.ID, model.PermissionWriteUser)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOwnerOperation(a, user.Role); err != nil {
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		return userToDto(user), nil
+	}
+
+	user.Deactivate(dto.Now)
+	updated, err := s.userRepo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	return userToDto(updated), nil
+}
+
+// ReinviteUser 招待トークンを再発行する. 以前の招待は無効になる.
+// パスワードを無効にしたユーザーや、招待の有効期限が切れたユーザーに使う
+func (s *userUsecase) ReinviteUser(dto ReinviteUserDto) (*InvitationDto, error) {
+	a, user, err := s.findUser(dto.Principal, dto.ID, model.PermissionWriteUser)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOwnerOperation(a, user.Role); err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := user.Invite(tokenHash, dto.Now); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.userRepo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	return userToInvitationDto(updated, token), nil
+}
+
+// AcceptInvitation 招待を承諾してパスワードを設定する
+func (s *userUsecase) AcceptInvitation(dto AcceptInvitationDto) (*UserDto, error) {
+	if err := model.ValidatePassword(dto.Password); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByInvitationTokenHash(hashInvitationToken(dto.Token))
+	if err != nil {
+		if errors.Is(err, commonErrors.ErrNotFound) {
+			return nil, model.ErrInvalidInvitation
+		}
+		return nil, err
+	}
+
+	passwordHash, err := password.Hash(dto.Password)
+	if err != nil {
+		return nil, err
+	}
+	if err := user.AcceptInvitation(passwordHash, dto.Now); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.userRepo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	return userToDto(updated), nil
+}
+
+// ChangeUserRole ユーザーのロールを変更し、変更履歴を記録する. 自分自身のロールは変更できない
+func (s *userUsecase) ChangeUserRole(dto ChangeUserRoleDto) (*UserDto, error) {
+	if dto.Principal.UserID != 0 && dto.Principal.UserID == dto.ID {
+		return nil, ErrSelfRoleChange
+	}
+	a, user, err := s.findUser(dto.Principal, dto.ID, model.PermissionWriteUserRole)
+	if err != nil {
+		return nil, err
+	}
+
+	role := model.Role(dto.Role)
+	if err := checkOwnerOperation(a, user.Role); err != nil {
+		return nil, err
+	}
+	if err := checkOwnerOperation(a, role); err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return userToDto(user), nil
+	}
+
+	change, err := user.ChangeRole(role, dto.Principal.Subject, dto.Now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateRole(a.organizationID, change); err != nil {
+		return nil, err
+	}
+	return userToDto(user), nil
+}
+
+// ListRoleChanges ユーザーのロールの変更履歴を古い順に取得する
+func (s *userUsecase) ListRoleChanges(dto GetUserDto) ([]*UserRoleChangeDto, error) {
+	_, user, err := s.findUser(dto.Principal, dto.ID, model.PermissionReadUser)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.userRepo.FindRoleChanges(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*UserRoleChangeDto, len(changes))
+	for i, change := range changes {
+		result[i] = &UserRoleChangeDto{
+			ID:        change.ID,
+			FromRole:  string(change.FromRole),
+			ToRole:    string(change.ToRole),
+			ChangedBy: change.ChangedBy,
+			ChangedAt: change.ChangedAt,
+		}
+	}
+	return result, nil
+}
+
+// findUser 操作主体が権限をもつことを確認し、所属組織のユーザーを取得する. 他組織のユーザーは ErrNotFound とする
+func (s *userUsecase) findUser(principal Principal, id uint, permission model.Permission) (*actor, *model.User, error) {
+	a, err := authorizeActor(s.userRepo, principal, permission)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.BelongsTo(a.organizationID) {
+		return nil, nil, commonErrors.ErrNotFound
+	}
+	return a, user, nil
+}
+
+// checkOwnerOperation 所有者に関する操作（所有者の任命・解任、所有者の変更）は所有者のユーザーに限る
+func checkOwnerOperation(a *actor, role model.Role) error {
+	if role == model.RoleOwner && a.role() != model.RoleOwner {
+		return commonErrors.ErrForbidden
+	}
+	return nil
+}
+
+func userToDto(user *model.User) *UserDto {
+	return &UserDto{
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          string(user.Role),
+		Status:        string(user.Status()),
+		DeactivatedAt: user.DeactivatedAt,
+	}
+}
+
+func userToInvitationDto(user *model.User, token string) *InvitationDto {
+	return &InvitationDto{
+		User:      *userToDto(user),
+		Token:     token,
+		ExpiresAt: *user.InvitationExpiresAt,
+	}
+}
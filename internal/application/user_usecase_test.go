@@ -0,0 +1,462 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/password"
+)
+
+// inMemoryUserRepository インメモリのユーザーリポジトリ
+type inMemoryUserRepository struct {
+	users       map[uint]*model.User
+	roleChanges []*model.UserRoleChange
+}
+
+func (r *inMemoryUserRepository) GetByID(id uint) (*model.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, commonErrors.ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *inMemoryUserRepository) GetByEmail(email string) (*model.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, commonErrors.ErrNotFound
+}
+
+func (r *inMemoryUserRepository) GetByInvitationTokenHash(tokenHash string) (*model.User, error) {
+	for _, user := range r.users {
+		if user.InvitationTokenHash != "" && user.InvitationTokenHash == tokenHash {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, commonErrors.ErrNotFound
+}
+
+func (r *inMemoryUserRepository) FindByOrganizationID(organizationID uint, includeDeactivated bool) ([]*model.User, error) {
+	var found []*model.User
+	for id := uint(1); id <= uint(len(r.users)); id++ {
+		user, ok := r.users[id]
+		if !ok || !user.BelongsTo(organizationID) || (user.IsDeactivated() && !includeDeactivated) {
+			continue
+		}
+		found = append(found, user)
+	}
+	return found, nil
+}
+
+func (r *inMemoryUserRepository) Create(user *model.User, roleChange *model.UserRoleChange) (*model.User, error) {
+	for _, stored := range r.users {
+		if stored.Email == user.Email {
+			return nil, commonErrors.ErrConflict
+		}
+	}
+	created := *user
+	created.ID = uint(len(r.users) + 1)
+	r.users[created.ID] = &created
+	roleChange.UserID = created.ID
+	r.recordRoleChange(roleChange)
+	return &created, nil
+}
+
+func (r *inMemoryUserRepository) UpdateRole(organizationID uint, change *model.UserRoleChange) error {
+	stored, ok := r.users[change.UserID]
+	if !ok || !stored.BelongsTo(organizationID) {
+		return commonErrors.ErrNotFound
+	}
+	if stored.Role != change.FromRole {
+		return commonErrors.ErrConflict
+	}
+	stored.Role = change.ToRole
+	r.recordRoleChange(change)
+	return nil
+}
+
+func (r *inMemoryUserRepository) FindRoleChanges(userID uint) ([]*model.UserRoleChange, error) {
+	var found []*model.UserRoleChange
+	for _, change := range r.roleChanges {
+		if change.UserID == userID {
+			found = append(found, change)
+		}
+	}
+	return found, nil
+}
+
+func (r *inMemoryUserRepository) recordRoleChange(change *model.UserRoleChange) {
+	change.ID = uint(len(r.roleChanges) + 1)
+	recorded := *change
+	r.roleChanges = append(r.roleChanges, &recorded)
+}
+
+func (r *inMemoryUserRepository) Update(user *model.User) (*model.User, error) {
+	stored, ok := r.users[user.ID]
+	if !ok || !stored.BelongsTo(user.OrganizationID) {
+		return nil, commonErrors.ErrNotFound
+	}
+	for _, other := range r.users {
+		if other.ID != user.ID && other.Email == user.Email {
+			return nil, commonErrors.ErrConflict
+		}
+	}
+	updated := *user
+	r.users[user.ID] = &updated
+	return &updated, nil
+}
+
+func newUserUsecaseForTest() (application.UserUsecase, *inMemoryUserRepository) {
+	deactivatedAt := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	userRepo := newInMemoryUserRepository(
+		&model.User{ID: 1, OrganizationID: 1, Name: "佐藤 一郎", Email: "ichiro.sato@example.com", Role: model.RoleAdmin, PasswordHash: "$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$a2V5"},
+		&model.User{ID: 2, OrganizationID: 1, Name: "田中 二郎", Email: "jiro.tanaka@example.com", Role: model.RoleViewer, DeactivatedAt: &deactivatedAt},
+		&model.User{ID: 3, OrganizationID: 2, Name: "高橋 三郎", Email: "saburo.takahashi@example.com", Role: model.RoleViewer},
+	)
+	return application.NewUserUsecase(userRepo), userRepo
+}
+
+func Test_UserUsecase_InviteAndAccept(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	principal := application.Principal{OrganizationID: 1, Scopes: clientScopes}
+	usecase, userRepo := newUserUsecaseForTest()
+
+	invitation, err := usecase.InviteUser(application.InviteUserDto{
+		Principal: principal,
+		Name:      "伊藤 四郎",
+		Email:     "shiro.ito@example.com",
+		Now:       now,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := application.UserDto{ID: 4, Name: "伊藤 四郎", Email: "shiro.ito@example.com", Role: "viewer", Status: "invited"}
+	if diff := cmp.Diff(want, invitation.User); diff != "" {
+		t.Errorf("user mismatch (-want +got):\n%s", diff)
+	}
+	if invitation.Token == "" || !invitation.ExpiresAt.Equal(now.Add(model.InvitationValidity)) {
+		t.Errorf("invitation = %+v, want a token valid for %s", invitation, model.InvitationValidity)
+	}
+	// 招待時のロールの割り当ても履歴に記録する
+	if len(userRepo.roleChanges) != 1 || userRepo.roleChanges[0].FromRole != "" || userRepo.roleChanges[0].ToRole != model.RoleViewer {
+		t.Errorf("role changes = %+v, want the initial assignment", userRepo.roleChanges)
+	}
+	// 保存するのはトークンのハッシュだけ
+	if stored := userRepo.users[4]; stored.InvitationTokenHash == "" || stored.InvitationTokenHash == invitation.Token {
+		t.Errorf("stored token hash = %q, want the hash of the token", stored.InvitationTokenHash)
+	}
+
+	t.Run("要件を満たさないパスワード", func(t *testing.T) {
+		_, err := usecase.AcceptInvitation(application.AcceptInvitationDto{Token: invitation.Token, Password: "password123", Now: now})
+		if !errors.Is(err, model.ErrWeakPassword) {
+			t.Errorf("error = %v, want %v", err, model.ErrWeakPassword)
+		}
+	})
+
+	t.Run("不正なトークン", func(t *testing.T) {
+		_, err := usecase.AcceptInvitation(application.AcceptInvitationDto{Token: "unknown", Password: "correct horse battery", Now: now})
+		if !errors.Is(err, model.ErrInvalidInvitation) {
+			t.Errorf("error = %v, want %v", err, model.ErrInvalidInvitation)
+		}
+	})
+
+	t.Run("有効期限切れ", func(t *testing.T) {
+		_, err := usecase.AcceptInvitation(application.AcceptInvitationDto{
+			Token:    invitation.Token,
+			Password: "correct horse battery",
+			Now:      now.Add(model.InvitationValidity),
+		})
+		if !errors.Is(err, model.ErrInvalidInvitation) {
+			t.Errorf("error = %v, want %v", err, model.ErrInvalidInvitation)
+		}
+	})
+
+	t.Run("承諾するとパスワードをハッシュで保存する", func(t *testing.T) {
+		got, err := usecase.AcceptInvitation(application.AcceptInvitationDto{Token: invitation.Token, Password: "correct horse battery", Now: now.Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Status != "active" {
+			t.Errorf("status = %s, want active", got.Status)
+		}
+		ok, err := password.Verify(userRepo.users[4].PasswordHash, "correct horse battery")
+		if err != nil || !ok {
+			t.Errorf("stored password hash does not match: %v", err)
+		}
+
+		// 同じトークンは再利用できない
+		_, err = usecase.AcceptInvitation(application.AcceptInvitationDto{Token: invitation.Token, Password: "another password!", Now: now.Add(time.Hour)})
+		if !errors.Is(err, model.ErrInvalidInvitation) {
+			t.Errorf("error = %v, want %v", err, model.ErrInvalidInvitation)
+		}
+	})
+}
+
+func Test_UserUsecase_InviteUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		dto     application.InviteUserDto
+		wantErr error
+	}{
+		{
+			name:    "メールアドレスが不正",
+			dto:     application.InviteUserDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, Name: "伊藤 四郎", Email: "shiro.ito"},
+			wantErr: model.ErrInvalidUser,
+		},
+		{
+			name:    "メールアドレスが登録済み",
+			dto:     application.InviteUserDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, Name: "佐藤 一郎", Email: "ichiro.sato@example.com"},
+			wantErr: commonErrors.ErrConflict,
+		},
+		{
+			name:    "ロールが不正",
+			dto:     application.InviteUserDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, Name: "伊藤 四郎", Email: "shiro.ito@example.com", Role: "superuser"},
+			wantErr: model.ErrInvalidUser,
+		},
+		{
+			name:    "所有者として招待できるのは所有者だけ",
+			dto:     application.InviteUserDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, Name: "伊藤 四郎", Email: "shiro.ito@example.com", Role: "owner"},
+			wantErr: commonErrors.ErrForbidden,
+		},
+		{
+			name:    "組織を特定できない",
+			dto:     application.InviteUserDto{Name: "伊藤 四郎", Email: "shiro.ito@example.com"},
+			wantErr: commonErrors.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase, _ := newUserUsecaseForTest()
+
+			_, err := usecase.InviteUser(tt.dto)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_UserUsecase_ListUsers(t *testing.T) {
+	usecase, _ := newUserUsecaseForTest()
+	principal := application.Principal{OrganizationID: 1, Scopes: clientScopes}
+
+	got, err := usecase.ListUsers(application.ListUsersDto{Principal: principal})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []*application.UserDto{{ID: 1, Name: "佐藤 一郎", Email: "ichiro.sato@example.com", Role: "admin", Status: "active"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("users mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = usecase.ListUsers(application.ListUsersDto{Principal: principal, IncludeDeactivated: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[1].Status != "deactivated" {
+		t.Errorf("users = %+v, want the deactivated user included", got)
+	}
+}
+
+func Test_UserUsecase_UpdateUser(t *testing.T) {
+	principal := application.Principal{OrganizationID: 1, Scopes: clientScopes}
+
+	tests := []struct {
+		name    string
+		dto     application.UpdateUserDto
+		want    *application.UserDto
+		wantErr error
+	}{
+		{
+			name: "氏名・メールアドレスを更新",
+			dto:  application.UpdateUserDto{Principal: principal, ID: 1, Name: "佐藤 一郎", Email: "i.sato@example.com"},
+			want: &application.UserDto{ID: 1, Name: "佐藤 一郎", Email: "i.sato@example.com", Role: "admin", Status: "active"},
+		},
+		{
+			name:    "他のユーザーのメールアドレス",
+			dto:     application.UpdateUserDto{Principal: principal, ID: 1, Name: "佐藤 一郎", Email: "jiro.tanaka@example.com"},
+			wantErr: commonErrors.ErrConflict,
+		},
+		{
+			name:    "無効化したユーザー",
+			dto:     application.UpdateUserDto{Principal: principal, ID: 2, Name: "田中 二郎", Email: "jiro.tanaka@example.com"},
+			wantErr: model.ErrUserDeactivated,
+		},
+		{
+			name:    "他組織のユーザー",
+			dto:     application.UpdateUserDto{Principal: principal, ID: 3, Name: "高橋 三郎", Email: "saburo.takahashi@example.com"},
+			wantErr: commonErrors.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase, _ := newUserUsecaseForTest()
+
+			got, err := usecase.UpdateUser(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("user mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_UserUsecase_DeactivateUser(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("無効化すると招待も取り消す", func(t *testing.T) {
+		usecase, userRepo := newUserUsecaseForTest()
+		invitation, err := usecase.ReinviteUser(application.ReinviteUserDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, ID: 1, Now: now})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, err := usecase.DeactivateUser(application.DeactivateUserDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, ID: 1, Now: now})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Status != "deactivated" || !got.DeactivatedAt.Equal(now) {
+			t.Errorf("user = %+v, want deactivated at %v", got, now)
+		}
+		if userRepo.users[1].InvitationTokenHash != "" {
+			t.Error("invitation is not revoked")
+		}
+		_, err = usecase.AcceptInvitation(application.AcceptInvitationDto{Token: invitation.Token, Password: "correct horse battery", Now: now})
+		if !errors.Is(err, model.ErrInvalidInvitation) {
+			t.Errorf("error = %v, want %v", err, model.ErrInvalidInvitation)
+		}
+	})
+
+	t.Run("自分自身は無効化できない", func(t *testing.T) {
+		usecase, _ := newUserUsecaseForTest()
+		_, err := usecase.DeactivateUser(application.DeactivateUserDto{Principal: application.Principal{UserID: 1}, ID: 1, Now: now})
+		if !errors.Is(err, application.ErrSelfDeactivation) {
+			t.Errorf("error = %v, want %v", err, application.ErrSelfDeactivation)
+		}
+	})
+
+	t.Run("無効化したユーザーは再招待できない", func(t *testing.T) {
+		usecase, _ := newUserUsecaseForTest()
+		_, err := usecase.ReinviteUser(application.ReinviteUserDto{Principal: application.Principal{OrganizationID: 1, Scopes: clientScopes}, ID: 2, Now: now})
+		if !errors.Is(err, model.ErrUserDeactivated) {
+			t.Errorf("error = %v, want %v", err, model.ErrUserDeactivated)
+		}
+	})
+}
+
+func Test_UserUsecase_ChangeUserRole(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	owner := application.Principal{Subject: "auth0|user4", UserID: 4}
+	admin := application.Principal{Subject: "auth0|user1", UserID: 1}
+
+	tests := []struct {
+		name     string
+		dto      application.ChangeUserRoleDto
+		wantRole string
+		wantErr  error
+	}{
+		{
+			name:     "管理者が経理担当を承認者に変更",
+			dto:      application.ChangeUserRoleDto{Principal: admin, ID: 5, Role: "approver", Now: now},
+			wantRole: "approver",
+		},
+		{
+			name:     "所有者が経理担当を所有者に任命",
+			dto:      application.ChangeUserRoleDto{Principal: owner, ID: 5, Role: "owner", Now: now},
+			wantRole: "owner",
+		},
+		{
+			name:    "管理者は所有者を任命できない",
+			dto:     application.ChangeUserRoleDto{Principal: admin, ID: 5, Role: "owner", Now: now},
+			wantErr: commonErrors.ErrForbidden,
+		},
+		{
+			name:    "管理者は所有者のロールを変更できない",
+			dto:     application.ChangeUserRoleDto{Principal: admin, ID: 4, Role: "viewer", Now: now},
+			wantErr: commonErrors.ErrForbidden,
+		},
+		{
+			name:    "経理担当はロールを変更できない",
+			dto:     application.ChangeUserRoleDto{Principal: application.Principal{Subject: "auth0|user5", UserID: 5}, ID: 1, Role: "viewer", Now: now},
+			wantErr: commonErrors.ErrForbidden,
+		},
+		{
+			name:    "自分自身のロールは変更できない",
+			dto:     application.ChangeUserRoleDto{Principal: owner, ID: 4, Role: "admin", Now: now},
+			wantErr: application.ErrSelfRoleChange,
+		},
+		{
+			name:    "ロールが不正",
+			dto:     application.ChangeUserRoleDto{Principal: admin, ID: 5, Role: "superuser", Now: now},
+			wantErr: model.ErrInvalidRole,
+		},
+		{
+			name:    "無効化したユーザー",
+			dto:     application.ChangeUserRoleDto{Principal: admin, ID: 2, Role: "approver", Now: now},
+			wantErr: model.ErrUserDeactivated,
+		},
+		{
+			name:    "他組織のユーザー",
+			dto:     application.ChangeUserRoleDto{Principal: admin, ID: 3, Role: "approver", Now: now},
+			wantErr: commonErrors.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase, userRepo := newUserUsecaseForTest()
+			userRepo.users[4] = &model.User{ID: 4, OrganizationID: 1, Name: "伊藤 四郎", Email: "shiro.ito@example.com", Role: model.RoleOwner, PasswordHash: "hash"}
+			userRepo.users[5] = &model.User{ID: 5, OrganizationID: 1, Name: "渡辺 五郎", Email: "goro.watanabe@example.com", Role: model.RoleAccountant, PasswordHash: "hash"}
+
+			got, err := usecase.ChangeUserRole(tt.dto)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				if len(userRepo.roleChanges) != 0 {
+					t.Errorf("role changes = %+v, want none", userRepo.roleChanges)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Role != tt.wantRole || string(userRepo.users[tt.dto.ID].Role) != tt.wantRole {
+				t.Errorf("role = %s, want %s", got.Role, tt.wantRole)
+			}
+
+			// 変更は履歴に記録する
+			changes, err := usecase.ListRoleChanges(application.GetUserDto{Principal: admin, ID: tt.dto.ID})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := []*application.UserRoleChangeDto{{ID: 1, FromRole: "accountant", ToRole: tt.wantRole, ChangedBy: tt.dto.Principal.Subject, ChangedAt: now}}
+			if diff := cmp.Diff(want, changes); diff != "" {
+				t.Errorf("role changes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
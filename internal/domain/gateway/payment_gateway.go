@@ -0,0 +1,30 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+// PaymentRequest 支払（振込）依頼
+type PaymentRequest struct {
+	// IdempotencyKey 同じ支払依頼の再送を識別するキー. ゲートウェイは同じキーの依頼を二重に実行せず、最初の結果を返す
+	IdempotencyKey string
+	InvoiceID      uint                     // 請求書ID
+	Amount         decimal.Decimal          // 振込金額
+	BankAccount    *model.ClientBankAccount // 振込先口座
+}
+
+// PaymentResult 支払（振込）依頼の結果
+type PaymentResult struct {
+	Succeeded     bool   // 支払に成功したか
+	TransactionID string // ゲートウェイ側の取引ID
+	Message       string // ゲートウェイの応答メッセージ
+}
+
+// PaymentGateway 支払（振込）を外部に依頼する.
+// 依頼自体が失敗した場合（通信エラー等）は error を返し、支払が拒否された場合は Succeeded=false の結果を返す
+type PaymentGateway interface {
+	Submit(ctx context.Context, req PaymentRequest) (*PaymentResult, error)
+}
@@ -0,0 +1,32 @@
+package gateway
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidToken トークンの署名・発行者・有効期限が不正
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenClaims アクセストークンに含める操作主体の情報
+type TokenClaims struct {
+	Subject        string   // 操作主体の識別子
+	UserID         uint     // ユーザーID
+	OrganizationID uint     // 所属組織ID
+	Scopes         []string // 付与するスコープ
+}
+
+// IssuedTokens 発行したアクセストークンとリフレッシュトークン
+type IssuedTokens struct {
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	RefreshToken         string
+}
+
+// TokenIssuer アクセストークンを発行する.
+// リフレッシュトークンはアクセストークンとして使えないように発行し、VerifyRefreshToken でのみ検証する
+type TokenIssuer interface {
+	Issue(claims TokenClaims, now time.Time) (*IssuedTokens, error)
+	// VerifyRefreshToken リフレッシュトークンを検証してユーザーIDを返す. 不正な場合は ErrInvalidToken
+	VerifyRefreshToken(token string, now time.Time) (uint, error)
+}
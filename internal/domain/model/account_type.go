@@ -0,0 +1,18 @@
+package model
+
+// AccountType 預金種目
+type AccountType string
+
+const (
+	AccountTypeOrdinary AccountType = "ordinary" // 普通
+	AccountTypeChecking AccountType = "checking" // 当座
+)
+
+// IsValid 定義済みの預金種目かどうか
+func (t AccountType) IsValid() bool {
+	switch t {
+	case AccountTypeOrdinary, AccountTypeChecking:
+		return true
+	}
+	return false
+}
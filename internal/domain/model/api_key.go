@@ -0,0 +1,105 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidAPIKey APIキーの登録内容が不正
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// ErrAPIKeyRevoked APIキーが失効している
+var ErrAPIKeyRevoked = errors.New("api key is revoked")
+
+const (
+	APIKeyPrefix           = "iak_" // APIキーの先頭の文字列. JWT と区別するために使う
+	APIKeyIDLength         = 12     // APIキーの識別子（プレフィックス）の文字数
+	apiKeyNameMaxLength    = 100    // APIキーの名前の最大文字数
+	apiKeyUsageGranularity = time.Minute
+)
+
+// APIKey 組織のシステム連携用のAPIキー. キーは "iak_{識別子}_{シークレット}" の形式で、
+// 識別子で検索してキー全体のハッシュを照合する. キー自体は保存しない
+type APIKey struct {
+	ID             uint         // APIキーID
+	OrganizationID uint         // 紐づく組織ID
+	Name           string       // 用途を表す名前
+	KeyID          string       // キーの識別子（一意、平文で保存）
+	KeyHash        string       // キー全体のSHA-256（16進数）
+	Scopes         []Permission // 付与した権限
+	ExpiresAt      *time.Time   // 有効期限（無期限の場合はnil）
+	LastUsedAt     *time.Time   // 最後に使われた日時（未使用の場合はnil）
+	RevokedAt      *time.Time   // 失効させた日時（有効な場合はnil）
+	CreatedBy      string       // 発行した操作主体
+	CreatedAt      time.Time    // 発行日時
+}
+
+// APIKeyStatus APIキーの状態. 保存せずに有効期限・失効日時から求める
+type APIKeyStatus string
+
+const (
+	APIKeyStatusActive  APIKeyStatus = "active"  // 有効
+	APIKeyStatusExpired APIKeyStatus = "expired" // 有効期限切れ
+	APIKeyStatusRevoked APIKeyStatus = "revoked" // 失効済み
+)
+
+// Status 指定した時点のAPIキーの状態
+func (k *APIKey) Status(now time.Time) APIKeyStatus {
+	switch {
+	case k.RevokedAt != nil:
+		return APIKeyStatusRevoked
+	case k.ExpiresAt != nil && !now.Before(*k.ExpiresAt):
+		return APIKeyStatusExpired
+	}
+	return APIKeyStatusActive
+}
+
+// BelongsTo APIキーが指定した組織のものかどうか
+func (k *APIKey) BelongsTo(organizationID uint) bool {
+	return k.OrganizationID == organizationID
+}
+
+// Revoke APIキーを失効させる. すでに失効している場合は日時を変更しない
+func (k *APIKey) Revoke(at time.Time) {
+	if k.RevokedAt == nil {
+		k.RevokedAt = &at
+	}
+}
+
+// RecordUse 使用日時を記録する. 毎回の書き込みを避けるため、前回の記録から1分以上経った場合だけ更新し、更新したかどうかを返す
+func (k *APIKey) RecordUse(at time.Time) bool {
+	if k.LastUsedAt != nil && at.Sub(*k.LastUsedAt) < apiKeyUsageGranularity {
+		return false
+	}
+	k.LastUsedAt = &at
+	return true
+}
+
+// Validate APIキーの登録内容を検証する. 有効期限は発行時点より後であること
+func (k *APIKey) Validate(now time.Time) error {
+	var problems []string
+	if strings.TrimSpace(k.Name) == "" || utf8.RuneCountInString(k.Name) > apiKeyNameMaxLength {
+		problems = append(problems, fmt.Sprintf("name is required and must be at most %d characters", apiKeyNameMaxLength))
+	}
+	if len(k.Scopes) == 0 {
+		problems = append(problems, "at least one scope is required")
+	}
+	for _, scope := range k.Scopes {
+		if !scope.IsValid() {
+			problems = append(problems, fmt.Sprintf("scope %q is invalid", scope))
+		} else if !scope.GrantableToAPIKey() {
+			problems = append(problems, fmt.Sprintf("scope %q cannot be granted to api keys", scope))
+		}
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(now) {
+		problems = append(problems, "expiresAt must be in the future")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidAPIKey, strings.Join(problems, ", "))
+	}
+	return nil
+}
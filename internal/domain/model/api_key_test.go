@@ -0,0 +1,48 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func Test_APIKey_Status(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Second)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		key  model.APIKey
+		want model.APIKeyStatus
+	}{
+		{name: "無期限", key: model.APIKey{}, want: model.APIKeyStatusActive},
+		{name: "有効期限前", key: model.APIKey{ExpiresAt: &future}, want: model.APIKeyStatusActive},
+		{name: "有効期限ちょうど", key: model.APIKey{ExpiresAt: &now}, want: model.APIKeyStatusExpired},
+		{name: "失効済み", key: model.APIKey{ExpiresAt: &future, RevokedAt: &past}, want: model.APIKeyStatusRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.Status(now); got != tt.want {
+				t.Errorf("Status() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_APIKey_RecordUse(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	key := model.APIKey{}
+
+	if !key.RecordUse(now) {
+		t.Error("first use should be recorded")
+	}
+	if key.RecordUse(now.Add(59 * time.Second)) {
+		t.Error("use within a minute should not be recorded")
+	}
+	if !key.RecordUse(now.Add(time.Minute)) || !key.LastUsedAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("lastUsedAt = %v, want %v", key.LastUsedAt, now.Add(time.Minute))
+	}
+}
@@ -1,11 +1,96 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+// ErrClientNotInOrganization 取引先が請求元企業に属していない
+var ErrClientNotInOrganization = errors.New("client does not belong to the organization")
+
+// ErrInvalidClient 取引先の登録内容が不正
+var ErrInvalidClient = errors.New("invalid client")
+
+// ErrClientArchived 取引先がアーカイブされている
+var ErrClientArchived = errors.New("client is archived")
+
+// clientTextMaxLength 取引先名・代表者名・住所の最大文字数
+const clientTextMaxLength = 255
+
 type Client struct {
 	ID             uint   // クライアントID
 	OrganizationID uint   // 紐づく組織ID
 	Name           string // 法人名
-	Representative string // 代表者名
-	PhoneNumber    string // 電話番号
-	PostalCode     string // 郵便番号
-	Address        string // 住所
+	// RegistrationNumber 適格請求書発行事業者の登録番号（"T" + 13桁）. 未登録の場合は空文字
+	RegistrationNumber  string
+	Representative      string              // 代表者名
+	PhoneNumber         string              // 電話番号（未登録の場合は空文字）
+	PostalCode          string              // 郵便番号
+	Address             string              // 住所
+	PayeeType           PayeeType           // 支払先区分（法人・個人）
+	WithholdingCategory WithholdingCategory // 源泉徴収の対象区分
+	ArchivedAt          *time.Time          // アーカイブした日時（アーカイブしていない場合はnil）
+
+	BankAccount *ClientBankAccount // 振込先口座（取得していない場合はnil）
+}
+
+// RequiresWithholding 支払時に源泉徴収が必要かどうか. 個人で報酬・料金等に該当する場合に必要
+func (c *Client) RequiresWithholding() bool {
+	return c.PayeeType == PayeeTypeIndividual && c.WithholdingCategory == WithholdingCategoryRemuneration
+}
+
+// BelongsTo 取引先が指定した組織に属しているかどうか
+func (c *Client) BelongsTo(organizationID uint) bool {
+	return c.OrganizationID == organizationID
+}
+
+// IsArchived 取引先がアーカイブされているかどうか. アーカイブした取引先には請求書を作成できない
+func (c *Client) IsArchived() bool {
+	return c.ArchivedAt != nil
+}
+
+// Archive 取引先をアーカイブする. すでにアーカイブしている場合は日時を変更しない
+func (c *Client) Archive(at time.Time) {
+	if c.ArchivedAt == nil {
+		c.ArchivedAt = &at
+	}
+}
+
+// Validate 取引先の登録内容を検証する. 電話番号は任意、郵便番号・住所は必須
+func (c *Client) Validate() error {
+	var problems []string
+	if strings.TrimSpace(c.Name) == "" || utf8.RuneCountInString(c.Name) > clientTextMaxLength {
+		problems = append(problems, fmt.Sprintf("name is required and must be at most %d characters", clientTextMaxLength))
+	}
+	if strings.TrimSpace(c.Representative) == "" || utf8.RuneCountInString(c.Representative) > clientTextMaxLength {
+		problems = append(problems, fmt.Sprintf("representative is required and must be at most %d characters", clientTextMaxLength))
+	}
+	if c.RegistrationNumber != "" && !validation.ValidRegistrationNumber(c.RegistrationNumber) {
+		problems = append(problems, "registration number is invalid")
+	}
+	if c.PhoneNumber != "" && !validation.ValidPhoneNumber(c.PhoneNumber) {
+		problems = append(problems, "phone number is invalid")
+	}
+	if !validation.ValidPostalCode(c.PostalCode) {
+		problems = append(problems, "postal code is invalid")
+	}
+	if strings.TrimSpace(c.Address) == "" || utf8.RuneCountInString(c.Address) > clientTextMaxLength {
+		problems = append(problems, fmt.Sprintf("address is required and must be at most %d characters", clientTextMaxLength))
+	}
+	if !c.PayeeType.IsValid() {
+		problems = append(problems, "payee type is invalid")
+	}
+	if !c.WithholdingCategory.IsValid() {
+		problems = append(problems, "withholding category is invalid")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidClient, strings.Join(problems, ", "))
+	}
+	return nil
 }
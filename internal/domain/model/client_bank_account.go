@@ -1,10 +1,72 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+// ErrInvalidBankAccount 振込先口座の登録内容が不正
+var ErrInvalidBankAccount = errors.New("invalid bank account")
+
+// bankNameMaxLength 銀行名・支店名の最大文字数
+const bankNameMaxLength = 255
+
 type ClientBankAccount struct {
-    ID         int    // 銀行口座ID
-    ClientID   int    // 紐づく取引先ID
-    BankName   string // 銀行名
-    BranchName string // 支店名
-    AccountNumber string // 口座番号
-    AccountName   string // 口座名
-}
\ No newline at end of file
+	ID            uint        // 銀行口座ID
+	ClientID      uint        // 紐づく取引先ID
+	BankCode      string      // 金融機関コード
+	BankName      string      // 銀行名
+	BranchCode    string      // 支店コード
+	BranchName    string      // 支店名
+	AccountType   AccountType // 預金種目
+	AccountNumber string      // 口座番号
+	AccountName   string      // 口座名
+	IsDefault     bool        // 支払に使う既定の口座かどうか（取引先ごとに1つ）
+}
+
+// accountNumberVisibleDigits マスクせずに表示する口座番号の末尾桁数
+const accountNumberVisibleDigits = 3
+
+// MaskedAccountNumber 末尾以外をマスクした口座番号を返す（例: 1234567 -> ****567）
+func (a *ClientBankAccount) MaskedAccountNumber() string {
+	n := len(a.AccountNumber)
+	if n <= accountNumberVisibleDigits {
+		return strings.Repeat("*", n)
+	}
+	return strings.Repeat("*", n-accountNumberVisibleDigits) + a.AccountNumber[n-accountNumberVisibleDigits:]
+}
+
+// Validate 振込先口座の登録内容を検証する. 口座名義は全銀フォーマットで使用できる半角カナに限る
+func (a *ClientBankAccount) Validate() error {
+	var problems []string
+	if !validation.ValidBankCode(a.BankCode) {
+		problems = append(problems, "bank code must be 4 digits")
+	}
+	if strings.TrimSpace(a.BankName) == "" || utf8.RuneCountInString(a.BankName) > bankNameMaxLength {
+		problems = append(problems, fmt.Sprintf("bank name is required and must be at most %d characters", bankNameMaxLength))
+	}
+	if !validation.ValidBranchCode(a.BranchCode) {
+		problems = append(problems, "branch code must be 3 digits")
+	}
+	if strings.TrimSpace(a.BranchName) == "" || utf8.RuneCountInString(a.BranchName) > bankNameMaxLength {
+		problems = append(problems, fmt.Sprintf("branch name is required and must be at most %d characters", bankNameMaxLength))
+	}
+	if !a.AccountType.IsValid() {
+		problems = append(problems, "account type must be ordinary or checking")
+	}
+	if !validation.ValidAccountNumber(a.AccountNumber) {
+		problems = append(problems, "account number must be 7 digits")
+	}
+	if !validation.ValidAccountName(a.AccountName) {
+		problems = append(problems, "account name must be half-width kana of at most 30 characters")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidBankAccount, strings.Join(problems, ", "))
+	}
+	return nil
+}
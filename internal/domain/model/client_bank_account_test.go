@@ -0,0 +1,111 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func Test_ClientBankAccount_MaskedAccountNumber(t *testing.T) {
+	tests := []struct {
+		name          string
+		accountNumber string
+		want          string
+	}{
+		{
+			name:          "7桁の口座番号",
+			accountNumber: "1234567",
+			want:          "****567",
+		},
+		{
+			name:          "表示桁数と同じ長さの場合はすべてマスク",
+			accountNumber: "123",
+			want:          "***",
+		},
+		{
+			name:          "空文字",
+			accountNumber: "",
+			want:          "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &model.ClientBankAccount{AccountNumber: tt.accountNumber}
+
+			got := account.MaskedAccountNumber()
+			if got != tt.want {
+				t.Errorf("MaskedAccountNumber() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ClientBankAccount_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*model.ClientBankAccount)
+		wantErr error
+	}{
+		{
+			name:   "正常",
+			modify: func(a *model.ClientBankAccount) {},
+		},
+		{
+			name:   "当座預金",
+			modify: func(a *model.ClientBankAccount) { a.AccountType = model.AccountTypeChecking },
+		},
+		{
+			name:    "金融機関コードが3桁",
+			modify:  func(a *model.ClientBankAccount) { a.BankCode = "001" },
+			wantErr: model.ErrInvalidBankAccount,
+		},
+		{
+			name:    "支店コードが数字でない",
+			modify:  func(a *model.ClientBankAccount) { a.BranchCode = "1A0" },
+			wantErr: model.ErrInvalidBankAccount,
+		},
+		{
+			name:    "口座番号が8桁",
+			modify:  func(a *model.ClientBankAccount) { a.AccountNumber = "12345678" },
+			wantErr: model.ErrInvalidBankAccount,
+		},
+		{
+			name:    "預金種目が不正",
+			modify:  func(a *model.ClientBankAccount) { a.AccountType = "savings" },
+			wantErr: model.ErrInvalidBankAccount,
+		},
+		{
+			name:    "口座名義が全角カナ",
+			modify:  func(a *model.ClientBankAccount) { a.AccountName = "トリヒキサキエー" },
+			wantErr: model.ErrInvalidBankAccount,
+		},
+		{
+			name:    "銀行名が空",
+			modify:  func(a *model.ClientBankAccount) { a.BankName = "" },
+			wantErr: model.ErrInvalidBankAccount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &model.ClientBankAccount{
+				ClientID:      1,
+				BankCode:      "0001",
+				BankName:      "みずほ銀行",
+				BranchCode:    "100",
+				BranchName:    "本店",
+				AccountType:   model.AccountTypeOrdinary,
+				AccountNumber: "1234567",
+				AccountName:   "ﾄﾘﾋｷｻｷｴｰ",
+			}
+			tt.modify(account)
+
+			err := account.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package model_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func validClient() *model.Client {
+	return &model.Client{
+		OrganizationID:      1,
+		Name:                "取引先A",
+		RegistrationNumber:  "T9234567890123",
+		Representative:      "佐藤 一郎",
+		PhoneNumber:         "03-1234-5678",
+		PostalCode:          "100-0001",
+		Address:             "東京都千代田区丸の内1-1-1",
+		PayeeType:           model.PayeeTypeCorporation,
+		WithholdingCategory: model.WithholdingCategoryNone,
+	}
+}
+
+func Test_Client_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*model.Client)
+		wantErr error
+	}{
+		{
+			name:   "正常",
+			modify: func(c *model.Client) {},
+		},
+		{
+			name:   "電話番号・登録番号は任意",
+			modify: func(c *model.Client) { c.PhoneNumber = ""; c.RegistrationNumber = "" },
+		},
+		{
+			name:    "取引先名が空",
+			modify:  func(c *model.Client) { c.Name = " " },
+			wantErr: model.ErrInvalidClient,
+		},
+		{
+			name:    "電話番号が不正",
+			modify:  func(c *model.Client) { c.PhoneNumber = "1234" },
+			wantErr: model.ErrInvalidClient,
+		},
+		{
+			name:    "郵便番号が不正",
+			modify:  func(c *model.Client) { c.PostalCode = "1000" },
+			wantErr: model.ErrInvalidClient,
+		},
+		{
+			name:    "住所が空",
+			modify:  func(c *model.Client) { c.Address = "" },
+			wantErr: model.ErrInvalidClient,
+		},
+		{
+			name:    "住所が長すぎる",
+			modify:  func(c *model.Client) { c.Address = strings.Repeat("東", 256) },
+			wantErr: model.ErrInvalidClient,
+		},
+		{
+			name:    "登録番号が不正",
+			modify:  func(c *model.Client) { c.RegistrationNumber = "T8000012050002" },
+			wantErr: model.ErrInvalidClient,
+		},
+		{
+			name:    "支払先区分が不正",
+			modify:  func(c *model.Client) { c.PayeeType = "government" },
+			wantErr: model.ErrInvalidClient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := validClient()
+			tt.modify(client)
+
+			err := client.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Client_Archive(t *testing.T) {
+	client := validClient()
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	client.Archive(first)
+	client.Archive(first.AddDate(0, 0, 1))
+
+	if !client.IsArchived() {
+		t.Fatal("client is not archived")
+	}
+	if !client.ArchivedAt.Equal(first) {
+		t.Errorf("archived at = %v, want %v", client.ArchivedAt, first)
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// DuplicateInvoiceDateTolerance 重複の疑いがあるとみなす発行日・支払期日のずれ（日数）
+const DuplicateInvoiceDateTolerance = 3
+
+// IsLikelyDuplicateOf 同じ請求書を二重に登録した疑いがあるかどうか.
+// 同じ請求元企業・同じ取引先で支払金額が一致し、発行日と支払期日のずれがいずれも DuplicateInvoiceDateTolerance 日以内の場合に重複とみなす.
+// 取引先の請求書番号を保持するようになった場合は、番号が一致することも条件に加える
+func (i *Invoice) IsLikelyDuplicateOf(other *Invoice) bool {
+	if i.ID != 0 && i.ID == other.ID {
+		return false
+	}
+	if i.Organization.ID != other.Organization.ID || i.Client.ID != other.Client.ID {
+		return false
+	}
+	if !i.Amount.Equal(other.Amount) {
+		return false
+	}
+	return daysApart(i.IssueDate, other.IssueDate) <= DuplicateInvoiceDateTolerance &&
+		daysApart(i.DueDate, other.DueDate) <= DuplicateInvoiceDateTolerance
+}
+
+// daysApart 2つの日付が何日離れているか（時刻は無視する）
+func daysApart(a, b time.Time) int {
+	a = time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	b = time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(a.Sub(b).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
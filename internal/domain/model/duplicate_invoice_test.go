@@ -0,0 +1,55 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func Test_Invoice_IsLikelyDuplicateOf(t *testing.T) {
+	issueDate := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	dueDate := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	newInvoice := func(id, organizationID, clientID uint, amount int64, issueDate, dueDate time.Time) *model.Invoice {
+		return &model.Invoice{
+			ID:           id,
+			Organization: &model.Organization{ID: organizationID},
+			Client:       &model.Client{ID: clientID, OrganizationID: organizationID},
+			Amount:       decimal.NewFromInt(amount),
+			IssueDate:    issueDate,
+			DueDate:      dueDate,
+		}
+	}
+	base := newInvoice(0, 1, 1, 10000, issueDate, dueDate)
+
+	tests := []struct {
+		name  string
+		other *model.Invoice
+		want  bool
+	}{
+		{name: "同じ内容", other: newInvoice(1, 1, 1, 10000, issueDate, dueDate), want: true},
+		{name: "発行日・支払期日が許容範囲内でずれている", other: newInvoice(1, 1, 1, 10000, issueDate.AddDate(0, 0, 3), dueDate.AddDate(0, 0, -3)), want: true},
+		{name: "時刻は無視する", other: newInvoice(1, 1, 1, 10000, issueDate.Add(-time.Hour).AddDate(0, 0, 3), dueDate), want: true},
+		{name: "発行日が許容範囲外", other: newInvoice(1, 1, 1, 10000, issueDate.AddDate(0, 0, 4), dueDate), want: false},
+		{name: "支払期日が許容範囲外", other: newInvoice(1, 1, 1, 10000, issueDate, dueDate.AddDate(0, 0, -4)), want: false},
+		{name: "支払金額が違う", other: newInvoice(1, 1, 1, 10001, issueDate, dueDate), want: false},
+		{name: "取引先が違う", other: newInvoice(1, 1, 2, 10000, issueDate, dueDate), want: false},
+		{name: "請求元企業が違う", other: newInvoice(1, 2, 1, 10000, issueDate, dueDate), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.IsLikelyDuplicateOf(tt.other); got != tt.want {
+				t.Errorf("IsLikelyDuplicateOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("同じ請求書どうしは重複とみなさない", func(t *testing.T) {
+		invoice := newInvoice(1, 1, 1, 10000, issueDate, dueDate)
+		if invoice.IsLikelyDuplicateOf(invoice) {
+			t.Error("expected an invoice not to be a duplicate of itself")
+		}
+	})
+}
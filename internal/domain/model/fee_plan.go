@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// FeePlan 手数料プラン. OrganizationID が0のプランは全組織に適用する標準プラン
+type FeePlan struct {
+	ID             uint       // 手数料プランID
+	OrganizationID uint       // 適用する組織ID（標準プランは0）
+	Name           string     // プラン名
+	Rate           float64    // 手数料率
+	StartDate      time.Time  // 適用開始日
+	EndDate        *time.Time // 適用終了日（nilなら現在も有効）
+}
+
+// AppliesTo 指定した日付がプランの適用期間に含まれるかどうか
+func (p *FeePlan) AppliesTo(date time.Time) bool {
+	return !p.StartDate.After(date) && (p.EndDate == nil || !p.EndDate.Before(date))
+}
+
+// IsAvailableTo 組織が選択できるプランかどうか. 標準プランと組織自身のプランに限る
+func (p *FeePlan) IsAvailableTo(organizationID uint) bool {
+	return p.OrganizationID == 0 || p.OrganizationID == organizationID
+}
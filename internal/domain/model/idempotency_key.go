@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidIdempotencyKey Idempotency-Key ヘッダーの値が不正
+var ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+
+const (
+	IdempotencyKeyRetention   = 24 * time.Hour  // 応答を保存して再送に同じ応答を返す期間
+	IdempotencyKeyLockTimeout = 5 * time.Minute // 処理中の予約の有効期間. 処理中に停止した場合もこの期間を過ぎれば再送を処理する
+	idempotencyKeyMaxLength   = 255
+)
+
+// IdempotencyKey 冪等キーによる予約と保存した応答. 操作主体ごとにキーは一意で、
+// 同じキーの再送には保存した応答を返し、異なるリクエストへの再利用は拒否する
+type IdempotencyKey struct {
+	ID          uint
+	Scope       string          // キーの名前空間（操作主体）
+	Key         string          // Idempotency-Key ヘッダーの値
+	RequestHash string          // メソッド・パス・ボディのSHA-256（16進数）
+	Response    *StoredResponse // 保存した応答（処理中はnil）
+	ExpiresAt   time.Time       // 予約・応答の有効期限
+	CreatedAt   time.Time
+}
+
+// StoredResponse 再送に返すために保存した応答
+type StoredResponse struct {
+	StatusCode  int
+	ContentType string
+	Headers     map[string][]string // Content-Type 以外でハンドラーが設定したヘッダー（ETag・Location など）
+	Body        []byte
+}
+
+// NewIdempotencyKey 処理を始めるリクエストの予約を作成する
+func NewIdempotencyKey(scope, key, requestHash string, now time.Time) (*IdempotencyKey, error) {
+	if err := ValidateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+	return &IdempotencyKey{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(IdempotencyKeyLockTimeout),
+	}, nil
+}
+
+// ValidateIdempotencyKey Idempotency-Key ヘッダーの値を検証する. 空白を除く表示可能な ASCII 文字で255文字以内とする
+func ValidateIdempotencyKey(key string) error {
+	if key == "" || len(key) > idempotencyKeyMaxLength {
+		return fmt.Errorf("%w: must be 1 to %d characters", ErrInvalidIdempotencyKey, idempotencyKeyMaxLength)
+	}
+	if strings.IndexFunc(key, func(r rune) bool { return r <= ' ' || r > '~' }) >= 0 {
+		return fmt.Errorf("%w: must consist of printable ASCII characters", ErrInvalidIdempotencyKey)
+	}
+	return nil
+}
+
+// IsCompleted 応答を保存済みかどうか
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.Response != nil
+}
+
+// IsExpired 予約・応答の有効期限を過ぎたかどうか. 有効期限を過ぎたキーは新しいリクエストとして扱う
+func (k *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
+
+// Matches 予約したリクエストと同じリクエストかどうか
+func (k *IdempotencyKey) Matches(requestHash string) bool {
+	return k.RequestHash == requestHash
+}
+
+// Complete 応答を保存し、保存期間を応答の時点から数え直す
+func (k *IdempotencyKey) Complete(response StoredResponse, now time.Time) {
+	k.Response = &response
+	k.ExpiresAt = now.Add(IdempotencyKeyRetention)
+}
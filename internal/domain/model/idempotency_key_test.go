@@ -0,0 +1,32 @@
+package model_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func Test_ValidateIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{name: "UUID", key: "5f0c8a3e-7d4b-4c1a-9e2f-1b6d3a8c9e07"},
+		{name: "255文字", key: strings.Repeat("a", 255)},
+		{name: "空", key: "", wantErr: model.ErrInvalidIdempotencyKey},
+		{name: "256文字", key: strings.Repeat("a", 256), wantErr: model.ErrInvalidIdempotencyKey},
+		{name: "空白を含む", key: "order 1", wantErr: model.ErrInvalidIdempotencyKey},
+		{name: "ASCII以外を含む", key: "注文1", wantErr: model.ErrInvalidIdempotencyKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := model.ValidateIdempotencyKey(tt.key); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -16,6 +17,42 @@ const (
 	StatusError      InvoiceStatus = "error"
 )
 
+// invoiceStatusTransitions 遷移元ステータスごとに許可される遷移先
+var invoiceStatusTransitions = map[InvoiceStatus][]InvoiceStatus{
+	StatusPending:    {StatusProcessing},
+	StatusProcessing: {StatusPaid, StatusError},
+	StatusError:      {StatusPending}, // 再処理のために未処理へ戻す
+}
+
+// IsValid 定義済みのステータスかどうか
+func (s InvoiceStatus) IsValid() bool {
+	switch s {
+	case StatusPending, StatusProcessing, StatusPaid, StatusError:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo 指定したステータスへ遷移できるかどうか
+func (s InvoiceStatus) CanTransitionTo(next InvoiceStatus) bool {
+	for _, allowed := range invoiceStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidStatusTransitionError 許可されていないステータス遷移を表すエラー
+type InvalidStatusTransitionError struct {
+	From InvoiceStatus
+	To   InvoiceStatus
+}
+
+func (e *InvalidStatusTransitionError) Error() string {
+	return fmt.Sprintf("invalid status transition: %s -> %s", e.From, e.To)
+}
+
 type Invoice struct {
 	ID           uint            // 請求書ID
 	Organization *Organization   // 請求元企業
@@ -24,17 +61,34 @@ type Invoice struct {
 	Amount       decimal.Decimal // 支払金額
 	Fee          decimal.Decimal // 手数料
 	FeeRate      float64         // 手数料率
-	Tax          decimal.Decimal // 消費税
-	TaxRate      float64         // 消費税率
-	TotalAmount  decimal.Decimal // 請求金額
-	DueDate      time.Time       // 支払期日
-	Status       InvoiceStatus   // ステータス
+	FeePlanID    uint            // 適用した手数料プランID（プランを適用していない場合は0）
+	// SettingsVersion 作成時に適用した組織の設定の版数（設定を登録していない場合は0）
+	SettingsVersion uint
+	Tax             decimal.Decimal // 消費税
+	TaxRate         float64         // 消費税率
+	TotalAmount     decimal.Decimal // 請求金額
+	// WithholdingTax 源泉徴収税額. 取引先への振込金額は支払金額から差し引いた額になる
+	WithholdingTax decimal.Decimal
+	DueDate        time.Time          // 支払期日
+	Status         InvoiceStatus      // ステータス
+	LineItems      []*InvoiceLineItem // 明細行（明細を指定せずに作成した請求書は空）
+	// Version 楽観的排他制御用の版数. 作成時は1で、保存した内容を更新するたびに1増える
+	Version uint
 }
 
 const DefaultFeeRate = 0.04
 
+// NewInvoice 請求書を生成する. 取引先が請求元企業に属していない場合は ErrClientNotInOrganization、
+// アーカイブされている場合は ErrClientArchived を返す
 func NewInvoice(org *Organization, client *Client, amount int64, issueDate, dueDate time.Time, feeRate float64) (*Invoice, error) {
-	var rate float64
+	if !client.BelongsTo(org.ID) {
+		return nil, ErrClientNotInOrganization
+	}
+	if client.IsArchived() {
+		return nil, ErrClientArchived
+	}
+
+	rate := feeRate
 	if !validation.ValidRate(feeRate) {
 		rate = DefaultFeeRate
 	}
@@ -46,22 +100,25 @@ func NewInvoice(org *Organization, client *Client, amount int64, issueDate, dueD
 		IssueDate:    issueDate,
 		DueDate:      dueDate,
 		Status:       StatusPending,
+		Version:      1,
 	}, nil
 }
 
-// Calculate 手数料、消費税、請求金額を計算してセットする
+// Calculate 手数料、消費税、請求金額を計算してセットする.
+// 手数料・消費税の円未満の端数は請求元企業の端数処理で処理し、保存・表示・振込の金額を一致させる
 func (i *Invoice) Calculate(taxRate float64) {
 	// 支払金額 (Amount) を Decimal に変換
 	amount := i.Amount
 	feeRate := decimal.NewFromFloat(i.FeeRate)
 	taxRateDecimal := decimal.NewFromFloat(taxRate)
+	rounding := i.roundingPolicy()
 
 	// 手数料を計算: Fee = Amount * FeeRate
-	fee := amount.Mul(feeRate)
+	fee := rounding.Round(amount.Mul(feeRate))
 	i.Fee = fee
 
 	// 消費税を計算: Tax = Fee * TaxRate
-	tax := fee.Mul(taxRateDecimal)
+	tax := rounding.Round(fee.Mul(taxRateDecimal))
 	i.Tax = tax
 
 	// 請求金額を計算: TotalAmount = Amount + Fee + Tax
@@ -70,6 +127,119 @@ func (i *Invoice) Calculate(taxRate float64) {
 
 	// 消費税率をセット
 	i.TaxRate = taxRate
+
+	// 源泉徴収税額を計算（取引先への振込金額から差し引く. 請求金額には影響しない）
+	i.WithholdingTax = decimal.Zero
+	if i.Client != nil && i.Client.RequiresWithholding() {
+		i.WithholdingTax = calculateWithholding(i.withholdingBase())
+	}
+}
+
+// withholdingBase 源泉徴収の対象となる金額.
+// 明細がある場合は消費税額が区分されているため税抜の金額、ない場合は支払金額とする
+func (i *Invoice) withholdingBase() decimal.Decimal {
+	if len(i.LineItems) == 0 {
+		return i.Amount
+	}
+	base := decimal.Zero
+	for _, item := range i.LineItems {
+		base = base.Add(item.Amount)
+	}
+	return base
+}
+
+// TransferAmount 取引先への振込金額（支払金額から源泉徴収税額を差し引いた額）
+func (i *Invoice) TransferAmount() decimal.Decimal {
+	return i.Amount.Sub(i.WithholdingTax)
+}
+
+// SetLineItems 明細行をセットし、明細から支払金額を計算する.
+// 支払金額は明細金額の合計に税率ごとの消費税額を加えた額とする
+func (i *Invoice) SetLineItems(items []*InvoiceLineItem) error {
+	if len(items) == 0 {
+		return fmt.Errorf("%w: at least one line item is required", ErrInvalidLineItem)
+	}
+	for n, item := range items {
+		item.LineNumber = n + 1
+	}
+
+	amount := decimal.Zero
+	for _, summary := range summarizeTax(items, i.roundingPolicy()) {
+		amount = amount.Add(summary.TaxableAmount).Add(summary.Tax)
+	}
+	if !amount.IsPositive() {
+		return fmt.Errorf("%w: total amount of line items must be positive", ErrInvalidLineItem)
+	}
+
+	i.LineItems = items
+	i.Amount = amount
+	return nil
+}
+
+// TaxSummaries 明細を税区分・税率ごとに集計する. 明細がない場合は空
+func (i *Invoice) TaxSummaries() []TaxSummary {
+	return summarizeTax(i.LineItems, i.roundingPolicy())
+}
+
+// roundingPolicy 請求元企業の端数処理. 未設定の場合は DefaultRoundingPolicy
+func (i *Invoice) roundingPolicy() RoundingPolicy {
+	if i.Organization == nil || !i.Organization.RoundingPolicy.IsValid() {
+		return DefaultRoundingPolicy
+	}
+	return i.Organization.RoundingPolicy
+}
+
+// IsAffectedByTaxRate 税率が発行日に適用され、請求書に適用済みの税率と異なるかどうか.
+// 手数料の消費税には標準税率を、明細にはそれぞれの税区分の税率を適用している
+func (i *Invoice) IsAffectedByTaxRate(rate *TaxRate) bool {
+	if !rate.AppliesTo(i.IssueDate) {
+		return false
+	}
+	if rate.Category == TaxCategoryStandard && i.TaxRate != rate.Rate {
+		return true
+	}
+	for _, item := range i.LineItems {
+		if item.TaxCategory == rate.Category && item.TaxRate != rate.Rate {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingQualifiedInvoiceFields 適格請求書の記載事項のうち不足しているものを返す
+func (i *Invoice) MissingQualifiedInvoiceFields() []string {
+	var missing []string
+	switch {
+	case i.Organization.RegistrationNumber == "":
+		missing = append(missing, "registration number of the organization is not registered")
+	case !validation.ValidRegistrationNumber(i.Organization.RegistrationNumber):
+		missing = append(missing, "registration number of the organization is invalid")
+	}
+	switch {
+	case i.Client.RegistrationNumber == "":
+		missing = append(missing, "registration number of the client is not registered")
+	case !validation.ValidRegistrationNumber(i.Client.RegistrationNumber):
+		missing = append(missing, "registration number of the client is invalid")
+	}
+	if i.Organization.Name == "" {
+		missing = append(missing, "name of the organization is empty")
+	}
+	if i.Client.Name == "" {
+		missing = append(missing, "name of the client is empty")
+	}
+	if len(i.LineItems) == 0 {
+		missing = append(missing, "line items are required to show taxable amounts and taxes per tax rate")
+	}
+	return missing
+}
+
+// TransitionTo ステータスを遷移させる. 許可されていない遷移の場合は InvalidStatusTransitionError を返す
+func (i *Invoice) TransitionTo(next InvoiceStatus) error {
+	if !i.Status.CanTransitionTo(next) {
+		return &InvalidStatusTransitionError{From: i.Status, To: next}
+	}
+	i.Status = next
+	return nil
 }
 
 // truncateDecimalToInt 小数点以下を切り捨てて int で返す
@@ -98,3 +268,13 @@ func (i *Invoice) TaxAsInt() int64 {
 func (i *Invoice) FeeAsInt() int64 {
 	return truncateDecimalToInt(i.Fee)
 }
+
+// WithholdingTaxAsInt 小数点以下を切り捨てて int で返す
+func (i *Invoice) WithholdingTaxAsInt() int64 {
+	return truncateDecimalToInt(i.WithholdingTax)
+}
+
+// TransferAmountAsInt 小数点以下を切り捨てて int で返す
+func (i *Invoice) TransferAmountAsInt() int64 {
+	return truncateDecimalToInt(i.TransferAmount())
+}
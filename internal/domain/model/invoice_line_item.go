@@ -0,0 +1,149 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidLineItem 明細行の内容が不正
+var ErrInvalidLineItem = errors.New("invalid line item")
+
+// TaxCategory 明細行の税区分
+type TaxCategory string
+
+const (
+	TaxCategoryStandard   TaxCategory = "standard"    // 標準税率
+	TaxCategoryReduced    TaxCategory = "reduced"     // 軽減税率
+	TaxCategoryExempt     TaxCategory = "exempt"      // 非課税
+	TaxCategoryNonTaxable TaxCategory = "non_taxable" // 不課税
+)
+
+// taxCategoryOrder 税区分ごとの集計の並び順
+var taxCategoryOrder = map[TaxCategory]int{
+	TaxCategoryStandard:   0,
+	TaxCategoryReduced:    1,
+	TaxCategoryExempt:     2,
+	TaxCategoryNonTaxable: 3,
+}
+
+// IsValid 定義済みの税区分かどうか
+func (c TaxCategory) IsValid() bool {
+	switch c {
+	case TaxCategoryStandard, TaxCategoryReduced, TaxCategoryExempt, TaxCategoryNonTaxable:
+		return true
+	}
+	return false
+}
+
+// IsTaxable 消費税がかかる税区分かどうか
+func (c TaxCategory) IsTaxable() bool {
+	return c == TaxCategoryStandard || c == TaxCategoryReduced
+}
+
+type InvoiceLineItem struct {
+	ID          uint            // 明細ID
+	LineNumber  int             // 行番号（1から）
+	Description string          // 品目
+	Quantity    decimal.Decimal // 数量
+	UnitPrice   decimal.Decimal // 単価（税抜）
+	Amount      decimal.Decimal // 金額（税抜）
+	TaxCategory TaxCategory     // 税区分
+	TaxRate     float64         // 適用した消費税率（非課税・不課税は0）
+}
+
+// NewInvoiceLineItem 明細行を生成する. 金額は数量×単価の円未満を請求元企業の端数処理 policy で処理する.
+// 非課税・不課税の場合、消費税率は0とする
+func NewInvoiceLineItem(description string, quantity, unitPrice decimal.Decimal, category TaxCategory, taxRate float64, policy RoundingPolicy) (*InvoiceLineItem, error) {
+	if !category.IsValid() {
+		return nil, fmt.Errorf("%w: unknown tax category %q", ErrInvalidLineItem, category)
+	}
+	if !quantity.IsPositive() {
+		return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidLineItem)
+	}
+	if !category.IsTaxable() {
+		taxRate = 0
+	} else if taxRate <= 0 {
+		return nil, fmt.Errorf("%w: tax rate of %s must be positive", ErrInvalidLineItem, category)
+	}
+
+	return &InvoiceLineItem{
+		Description: description,
+		Quantity:    quantity,
+		UnitPrice:   unitPrice,
+		Amount:      policy.Round(quantity.Mul(unitPrice)),
+		TaxCategory: category,
+		TaxRate:     taxRate,
+	}, nil
+}
+
+// UnitPriceAsInt 小数点以下を切り捨てて int で返す
+func (l *InvoiceLineItem) UnitPriceAsInt() int64 {
+	return truncateDecimalToInt(l.UnitPrice)
+}
+
+// AmountAsInt 小数点以下を切り捨てて int で返す
+func (l *InvoiceLineItem) AmountAsInt() int64 {
+	return truncateDecimalToInt(l.Amount)
+}
+
+// QualifiedInvoiceError 適格請求書の記載事項が不足している
+type QualifiedInvoiceError struct {
+	Missing []string
+}
+
+func (e *QualifiedInvoiceError) Error() string {
+	return fmt.Sprintf("not a qualified invoice: %s", strings.Join(e.Missing, ", "))
+}
+
+// TaxSummary 税区分・税率ごとの対価の額と消費税額
+type TaxSummary struct {
+	TaxCategory   TaxCategory
+	TaxRate       float64
+	TaxableAmount decimal.Decimal // 明細の金額（税抜）の合計
+	Tax           decimal.Decimal // 消費税額（税率ごとに1回だけ円未満を端数処理する）
+}
+
+// summarizeTax 明細を税区分・税率ごとに集計する. 並び順は標準税率、軽減税率、非課税、不課税で、同じ税区分は税率の高い順
+func summarizeTax(items []*InvoiceLineItem, rounding RoundingPolicy) []TaxSummary {
+	type key struct {
+		category TaxCategory
+		rate     float64
+	}
+	index := make(map[key]int)
+	var summaries []TaxSummary
+	for _, item := range items {
+		k := key{category: item.TaxCategory, rate: item.TaxRate}
+		n, ok := index[k]
+		if !ok {
+			n = len(summaries)
+			index[k] = n
+			summaries = append(summaries, TaxSummary{TaxCategory: item.TaxCategory, TaxRate: item.TaxRate, TaxableAmount: decimal.Zero})
+		}
+		summaries[n].TaxableAmount = summaries[n].TaxableAmount.Add(item.Amount)
+	}
+
+	for n := range summaries {
+		summaries[n].Tax = rounding.Round(summaries[n].TaxableAmount.Mul(decimal.NewFromFloat(summaries[n].TaxRate)))
+	}
+	sort.SliceStable(summaries, func(a, b int) bool {
+		if summaries[a].TaxCategory != summaries[b].TaxCategory {
+			return taxCategoryOrder[summaries[a].TaxCategory] < taxCategoryOrder[summaries[b].TaxCategory]
+		}
+		return summaries[a].TaxRate > summaries[b].TaxRate
+	})
+	return summaries
+}
+
+// TaxableAmountAsInt 小数点以下を切り捨てて int で返す
+func (s TaxSummary) TaxableAmountAsInt() int64 {
+	return truncateDecimalToInt(s.TaxableAmount)
+}
+
+// TaxAsInt 小数点以下を切り捨てて int で返す
+func (s TaxSummary) TaxAsInt() int64 {
+	return truncateDecimalToInt(s.Tax)
+}
@@ -0,0 +1,334 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func Test_NewInvoiceLineItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		quantity  decimal.Decimal
+		unitPrice decimal.Decimal
+		category  model.TaxCategory
+		taxRate   float64
+		policy    model.RoundingPolicy
+		want      *model.InvoiceLineItem
+		wantErr   error
+	}{
+		{
+			name:      "標準税率",
+			quantity:  decimal.NewFromInt(3),
+			unitPrice: decimal.NewFromInt(1000),
+			category:  model.TaxCategoryStandard,
+			taxRate:   0.1,
+			want: &model.InvoiceLineItem{
+				Description: "品目",
+				Quantity:    decimal.NewFromInt(3),
+				UnitPrice:   decimal.NewFromInt(1000),
+				Amount:      decimal.NewFromInt(3000),
+				TaxCategory: model.TaxCategoryStandard,
+				TaxRate:     0.1,
+			},
+		},
+		{
+			name:      "金額の円未満は端数処理で切り捨て",
+			quantity:  decimal.NewFromFloat(1.5),
+			unitPrice: decimal.NewFromInt(333),
+			category:  model.TaxCategoryReduced,
+			taxRate:   0.08,
+			policy:    model.RoundingTruncate,
+			want: &model.InvoiceLineItem{
+				Description: "品目",
+				Quantity:    decimal.NewFromFloat(1.5),
+				UnitPrice:   decimal.NewFromInt(333),
+				Amount:      decimal.NewFromInt(499),
+				TaxCategory: model.TaxCategoryReduced,
+				TaxRate:     0.08,
+			},
+		},
+		{
+			name:      "金額の円未満は端数処理で四捨五入",
+			quantity:  decimal.NewFromFloat(1.5),
+			unitPrice: decimal.NewFromInt(333),
+			category:  model.TaxCategoryReduced,
+			taxRate:   0.08,
+			policy:    model.RoundingHalfUp,
+			want: &model.InvoiceLineItem{
+				Description: "品目",
+				Quantity:    decimal.NewFromFloat(1.5),
+				UnitPrice:   decimal.NewFromInt(333),
+				Amount:      decimal.NewFromInt(500),
+				TaxCategory: model.TaxCategoryReduced,
+				TaxRate:     0.08,
+			},
+		},
+		{
+			name:      "マイナスの単価（値引き）の切り捨ては0に近い方へ",
+			quantity:  decimal.NewFromFloat(1.5),
+			unitPrice: decimal.NewFromInt(-333),
+			category:  model.TaxCategoryStandard,
+			taxRate:   0.1,
+			policy:    model.RoundingTruncate,
+			want: &model.InvoiceLineItem{
+				Description: "品目",
+				Quantity:    decimal.NewFromFloat(1.5),
+				UnitPrice:   decimal.NewFromInt(-333),
+				Amount:      decimal.NewFromInt(-499),
+				TaxCategory: model.TaxCategoryStandard,
+				TaxRate:     0.1,
+			},
+		},
+		{
+			name:      "非課税の場合、税率は0",
+			quantity:  decimal.NewFromInt(1),
+			unitPrice: decimal.NewFromInt(1000),
+			category:  model.TaxCategoryExempt,
+			taxRate:   0.1,
+			want: &model.InvoiceLineItem{
+				Description: "品目",
+				Quantity:    decimal.NewFromInt(1),
+				UnitPrice:   decimal.NewFromInt(1000),
+				Amount:      decimal.NewFromInt(1000),
+				TaxCategory: model.TaxCategoryExempt,
+				TaxRate:     0,
+			},
+		},
+		{
+			name:      "未定義の税区分",
+			quantity:  decimal.NewFromInt(1),
+			unitPrice: decimal.NewFromInt(1000),
+			category:  model.TaxCategory("free"),
+			wantErr:   model.ErrInvalidLineItem,
+		},
+		{
+			name:      "数量が0",
+			quantity:  decimal.Zero,
+			unitPrice: decimal.NewFromInt(1000),
+			category:  model.TaxCategoryStandard,
+			taxRate:   0.1,
+			wantErr:   model.ErrInvalidLineItem,
+		},
+		{
+			name:      "課税の税区分で税率が0",
+			quantity:  decimal.NewFromInt(1),
+			unitPrice: decimal.NewFromInt(1000),
+			category:  model.TaxCategoryStandard,
+			wantErr:   model.ErrInvalidLineItem,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := model.NewInvoiceLineItem("品目", tt.quantity, tt.unitPrice, tt.category, tt.taxRate, tt.policy)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("line item mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Invoice_SetLineItems(t *testing.T) {
+	newItem := func(quantity, unitPrice int64, category model.TaxCategory, taxRate float64) *model.InvoiceLineItem {
+		item, err := model.NewInvoiceLineItem("品目", decimal.NewFromInt(quantity), decimal.NewFromInt(unitPrice), category, taxRate, model.DefaultRoundingPolicy)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return item
+	}
+
+	tests := []struct {
+		name       string
+		policy     model.RoundingPolicy
+		items      []*model.InvoiceLineItem
+		wantAmount decimal.Decimal
+		wantErr    error
+	}{
+		{
+			name: "標準税率と軽減税率",
+			items: []*model.InvoiceLineItem{
+				newItem(2, 5000, model.TaxCategoryStandard, 0.1),
+				newItem(10, 500, model.TaxCategoryReduced, 0.08),
+			},
+			wantAmount: decimal.NewFromInt(16400), // 15000 + 1000 + 400
+		},
+		{
+			name: "消費税は税率ごとに合計してから切り捨てる",
+			items: []*model.InvoiceLineItem{
+				newItem(1, 105, model.TaxCategoryStandard, 0.1),
+				newItem(1, 105, model.TaxCategoryStandard, 0.1),
+			},
+			wantAmount: decimal.NewFromInt(231), // 210 + floor(21.0)（明細ごとに切り捨てると 230）
+		},
+		{
+			name:   "消費税は請求元企業の端数処理に従う",
+			policy: model.RoundingCeil,
+			items: []*model.InvoiceLineItem{
+				newItem(1, 1005, model.TaxCategoryStandard, 0.1),
+			},
+			wantAmount: decimal.NewFromInt(1106), // 1005 + ceil(100.5)
+		},
+		{
+			name: "非課税・不課税には消費税がかからない",
+			items: []*model.InvoiceLineItem{
+				newItem(1, 1000, model.TaxCategoryStandard, 0.1),
+				newItem(1, 3000, model.TaxCategoryExempt, 0),
+				newItem(1, 2000, model.TaxCategoryNonTaxable, 0),
+			},
+			wantAmount: decimal.NewFromInt(6100),
+		},
+		{
+			name:    "明細なし",
+			items:   []*model.InvoiceLineItem{},
+			wantErr: model.ErrInvalidLineItem,
+		},
+		{
+			name: "合計が0以下",
+			items: []*model.InvoiceLineItem{
+				newItem(1, 1000, model.TaxCategoryExempt, 0),
+				newItem(1, -1000, model.TaxCategoryExempt, 0),
+			},
+			wantErr: model.ErrInvalidLineItem,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := &model.Invoice{Organization: &model.Organization{RoundingPolicy: tt.policy}}
+
+			err := invoice.SetLineItems(tt.items)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !invoice.Amount.Equal(tt.wantAmount) {
+				t.Errorf("amount = %s, want %s", invoice.Amount, tt.wantAmount)
+			}
+			for i, item := range invoice.LineItems {
+				if item.LineNumber != i+1 {
+					t.Errorf("line number = %d, want %d", item.LineNumber, i+1)
+				}
+			}
+		})
+	}
+}
+
+func Test_Invoice_TaxSummaries(t *testing.T) {
+	newItem := func(unitPrice int64, category model.TaxCategory, taxRate float64) *model.InvoiceLineItem {
+		item, err := model.NewInvoiceLineItem("品目", decimal.NewFromInt(1), decimal.NewFromInt(unitPrice), category, taxRate, model.DefaultRoundingPolicy)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return item
+	}
+
+	invoice := &model.Invoice{}
+	if err := invoice.SetLineItems([]*model.InvoiceLineItem{
+		newItem(3000, model.TaxCategoryExempt, 0),
+		newItem(1080, model.TaxCategoryReduced, 0.08),
+		newItem(105, model.TaxCategoryStandard, 0.1),
+		newItem(1001, model.TaxCategoryReduced, 0.08),
+		newItem(105, model.TaxCategoryStandard, 0.1),
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []model.TaxSummary{
+		{TaxCategory: model.TaxCategoryStandard, TaxRate: 0.1, TaxableAmount: decimal.NewFromInt(210), Tax: decimal.NewFromInt(21)},
+		{TaxCategory: model.TaxCategoryReduced, TaxRate: 0.08, TaxableAmount: decimal.NewFromInt(2081), Tax: decimal.NewFromInt(166)},
+		{TaxCategory: model.TaxCategoryExempt, TaxRate: 0, TaxableAmount: decimal.NewFromInt(3000), Tax: decimal.NewFromInt(0)},
+	}
+	if diff := cmp.Diff(want, invoice.TaxSummaries()); diff != "" {
+		t.Errorf("tax summaries mismatch (-want +got):\n%s", diff)
+	}
+	if !invoice.Amount.Equal(decimal.NewFromInt(5478)) {
+		t.Errorf("amount = %s, want 5478", invoice.Amount)
+	}
+}
+
+func Test_Invoice_MissingQualifiedInvoiceFields(t *testing.T) {
+	lineItems := []*model.InvoiceLineItem{{LineNumber: 1, TaxCategory: model.TaxCategoryStandard, TaxRate: 0.1}}
+
+	tests := []struct {
+		name    string
+		invoice *model.Invoice
+		want    []string
+	}{
+		{
+			name: "記載事項がそろっている",
+			invoice: &model.Invoice{
+				Organization: &model.Organization{Name: "株式会社サンプル", RegistrationNumber: "T7000012050002"},
+				Client:       &model.Client{Name: "取引先A", RegistrationNumber: "T7000012050002"},
+				LineItems:    lineItems,
+			},
+		},
+		{
+			name: "登録番号が未登録で明細がない",
+			invoice: &model.Invoice{
+				Organization: &model.Organization{Name: "株式会社サンプル"},
+				Client:       &model.Client{Name: "取引先A"},
+			},
+			want: []string{
+				"registration number of the organization is not registered",
+				"registration number of the client is not registered",
+				"line items are required to show taxable amounts and taxes per tax rate",
+			},
+		},
+		{
+			name: "取引先の登録番号が未登録",
+			invoice: &model.Invoice{
+				Organization: &model.Organization{Name: "株式会社サンプル", RegistrationNumber: "T7000012050002"},
+				Client:       &model.Client{Name: "取引先A"},
+				LineItems:    lineItems,
+			},
+			want: []string{"registration number of the client is not registered"},
+		},
+		{
+			name: "取引先の登録番号のチェックデジットが不正",
+			invoice: &model.Invoice{
+				Organization: &model.Organization{Name: "株式会社サンプル", RegistrationNumber: "T7000012050002"},
+				Client:       &model.Client{Name: "取引先A", RegistrationNumber: "T8000012050002"},
+				LineItems:    lineItems,
+			},
+			want: []string{"registration number of the client is invalid"},
+		},
+		{
+			name: "登録番号のチェックデジットが不正",
+			invoice: &model.Invoice{
+				Organization: &model.Organization{Name: "株式会社サンプル", RegistrationNumber: "T8000012050002"},
+				Client:       &model.Client{Name: "取引先A", RegistrationNumber: "T7000012050002"},
+				LineItems:    lineItems,
+			},
+			want: []string{"registration number of the organization is invalid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.invoice.MissingQualifiedInvoiceFields()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("missing fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// InvoicePayment 請求書の支払処理の記録
+type InvoicePayment struct {
+	ID            uint            // 支払記録ID
+	InvoiceID     uint            // 請求書ID
+	AttemptID     uint            // 支払処理の試行ID（消込で記録した場合は0）
+	Amount        decimal.Decimal // 振込金額
+	Succeeded     bool            // 支払に成功したか
+	TransactionID string          // 支払ゲートウェイの取引ID
+	Response      string          // 支払ゲートウェイの応答
+	ProcessedAt   time.Time       // 処理日時
+}
+
+// PaymentAttempt 支払処理が請求書を処理中にしてから支払済み・エラーにするまでの1回の試行.
+// 処理中にした変更履歴のIDで識別する
+type PaymentAttempt struct {
+	ID      uint            // 処理中にした変更履歴のID
+	Invoice *Invoice        // 処理中の請求書
+	Payment *InvoicePayment // 記録済みの支払結果（支払結果を記録する前に中断した場合はnil）
+}
+
+// IdempotencyKey 支払ゲートウェイに渡す冪等キー. 同じ試行の再送は同じキーになり、ゲートウェイは二重に支払わない
+func (a *PaymentAttempt) IdempotencyKey() string {
+	return fmt.Sprintf("invoice-%d-attempt-%d", a.Invoice.ID, a.ID)
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// InvoiceStatusHistory 請求書ステータスの変更履歴
+type InvoiceStatusHistory struct {
+	ID         uint          // 履歴ID
+	InvoiceID  uint          // 請求書ID
+	FromStatus InvoiceStatus // 変更前ステータス
+	ToStatus   InvoiceStatus // 変更後ステータス
+	ChangedBy  string        // 変更者（トークンのsubject等）
+	ChangedAt  time.Time     // 変更日時
+}
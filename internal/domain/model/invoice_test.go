@@ -1,7 +1,9 @@
 package model_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/shopspring/decimal"
@@ -13,6 +15,7 @@ func Test_Invoice_Calculate(t *testing.T) {
 		amount  int64
 		feeRate float64
 		taxRate float64
+		policy  model.RoundingPolicy // 未指定の場合は既定の端数処理（切り捨て）
 		want    model.Invoice
 	}{
 		{
@@ -99,13 +102,208 @@ func Test_Invoice_Calculate(t *testing.T) {
 				TotalAmount: decimal.NewFromInt(-1044),
 			},
 		},
+		{
+			name:    "切り捨て: 手数料400.04円, 消費税40.0円",
+			amount:  10001,
+			feeRate: 0.04,
+			taxRate: 0.1,
+			policy:  model.RoundingTruncate,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10001),
+				FeeRate:     0.04,
+				Fee:         decimal.NewFromInt(400),
+				Tax:         decimal.NewFromInt(40),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10441),
+			},
+		},
+		{
+			name:    "切り上げ: 手数料400.04円, 消費税40.1円",
+			amount:  10001,
+			feeRate: 0.04,
+			taxRate: 0.1,
+			policy:  model.RoundingCeil,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10001),
+				FeeRate:     0.04,
+				Fee:         decimal.NewFromInt(401),
+				Tax:         decimal.NewFromInt(41),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10443),
+			},
+		},
+		{
+			name:    "四捨五入: 手数料400.04円, 消費税40.0円",
+			amount:  10001,
+			feeRate: 0.04,
+			taxRate: 0.1,
+			policy:  model.RoundingHalfUp,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10001),
+				FeeRate:     0.04,
+				Fee:         decimal.NewFromInt(400),
+				Tax:         decimal.NewFromInt(40),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10441),
+			},
+		},
+		{
+			name:    "銀行丸め: 手数料400.04円, 消費税40.0円",
+			amount:  10001,
+			feeRate: 0.04,
+			taxRate: 0.1,
+			policy:  model.RoundingBankers,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10001),
+				FeeRate:     0.04,
+				Fee:         decimal.NewFromInt(400),
+				Tax:         decimal.NewFromInt(40),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10441),
+			},
+		},
+		{
+			name:    "切り捨て: 手数料500.5円, 消費税50.0円",
+			amount:  10010,
+			feeRate: 0.05,
+			taxRate: 0.1,
+			policy:  model.RoundingTruncate,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10010),
+				FeeRate:     0.05,
+				Fee:         decimal.NewFromInt(500),
+				Tax:         decimal.NewFromInt(50),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10560),
+			},
+		},
+		{
+			name:    "切り上げ: 手数料500.5円, 消費税50.1円",
+			amount:  10010,
+			feeRate: 0.05,
+			taxRate: 0.1,
+			policy:  model.RoundingCeil,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10010),
+				FeeRate:     0.05,
+				Fee:         decimal.NewFromInt(501),
+				Tax:         decimal.NewFromInt(51),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10562),
+			},
+		},
+		{
+			name:    "四捨五入: 手数料500.5円, 消費税50.1円",
+			amount:  10010,
+			feeRate: 0.05,
+			taxRate: 0.1,
+			policy:  model.RoundingHalfUp,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10010),
+				FeeRate:     0.05,
+				Fee:         decimal.NewFromInt(501),
+				Tax:         decimal.NewFromInt(50),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10561),
+			},
+		},
+		{
+			name:    "銀行丸め: 手数料500.5円は偶数の500円, 消費税50.0円",
+			amount:  10010,
+			feeRate: 0.05,
+			taxRate: 0.1,
+			policy:  model.RoundingBankers,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10010),
+				FeeRate:     0.05,
+				Fee:         decimal.NewFromInt(500),
+				Tax:         decimal.NewFromInt(50),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10560),
+			},
+		},
+		{
+			name:    "銀行丸め: 手数料501.5円は偶数の502円, 消費税50.2円",
+			amount:  10030,
+			feeRate: 0.05,
+			taxRate: 0.1,
+			policy:  model.RoundingBankers,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10030),
+				FeeRate:     0.05,
+				Fee:         decimal.NewFromInt(502),
+				Tax:         decimal.NewFromInt(50),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10582),
+			},
+		},
+		{
+			name:    "四捨五入: 手数料501.5円, 消費税50.2円",
+			amount:  10030,
+			feeRate: 0.05,
+			taxRate: 0.1,
+			policy:  model.RoundingHalfUp,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(10030),
+				FeeRate:     0.05,
+				Fee:         decimal.NewFromInt(502),
+				Tax:         decimal.NewFromInt(50),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(10582),
+			},
+		},
+		{
+			name:    "切り捨て: マイナスの手数料-500.5円は-500円",
+			amount:  -10010,
+			feeRate: 0.05,
+			taxRate: 0.1,
+			policy:  model.RoundingTruncate,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(-10010),
+				FeeRate:     0.05,
+				Fee:         decimal.NewFromInt(-500),
+				Tax:         decimal.NewFromInt(-50),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(-10560),
+			},
+		},
+		{
+			name:    "既定の端数処理: マイナスの手数料-500.5円は-500円",
+			amount:  -10010,
+			feeRate: 0.05,
+			taxRate: 0.1,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(-10010),
+				FeeRate:     0.05,
+				Fee:         decimal.NewFromInt(-500),
+				Tax:         decimal.NewFromInt(-50),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(-10560),
+			},
+		},
+		{
+			name:    "四捨五入: マイナスの手数料-500.5円は-501円",
+			amount:  -10010,
+			feeRate: 0.05,
+			taxRate: 0.1,
+			policy:  model.RoundingHalfUp,
+			want: model.Invoice{
+				Amount:      decimal.NewFromInt(-10010),
+				FeeRate:     0.05,
+				Fee:         decimal.NewFromInt(-501),
+				Tax:         decimal.NewFromInt(-50),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(-10561),
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			invoice := model.Invoice{
-				Amount:  decimal.NewFromInt(tt.amount),
-				FeeRate: tt.feeRate,
+				Organization: &model.Organization{RoundingPolicy: tt.policy},
+				Amount:       decimal.NewFromInt(tt.amount),
+				FeeRate:      tt.feeRate,
 			}
 
 			invoice.Calculate(tt.taxRate)
@@ -186,3 +384,242 @@ func Test_Invoice_TotalAmountAsInt(t *testing.T) {
 		})
 	}
 }
+
+func Test_Invoice_TransitionTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    model.InvoiceStatus
+		to      model.InvoiceStatus
+		want    model.InvoiceStatus
+		wantErr bool
+	}{
+		{
+			name: "未処理 -> 処理中",
+			from: model.StatusPending,
+			to:   model.StatusProcessing,
+			want: model.StatusProcessing,
+		},
+		{
+			name: "処理中 -> 支払済み",
+			from: model.StatusProcessing,
+			to:   model.StatusPaid,
+			want: model.StatusPaid,
+		},
+		{
+			name: "処理中 -> エラー",
+			from: model.StatusProcessing,
+			to:   model.StatusError,
+			want: model.StatusError,
+		},
+		{
+			name: "エラー -> 未処理 (再処理)",
+			from: model.StatusError,
+			to:   model.StatusPending,
+			want: model.StatusPending,
+		},
+		{
+			name:    "未処理 -> 支払済みは不可",
+			from:    model.StatusPending,
+			to:      model.StatusPaid,
+			want:    model.StatusPending,
+			wantErr: true,
+		},
+		{
+			name:    "支払済みからは遷移不可",
+			from:    model.StatusPaid,
+			to:      model.StatusPending,
+			want:    model.StatusPaid,
+			wantErr: true,
+		},
+		{
+			name:    "同じステータスへの遷移は不可",
+			from:    model.StatusProcessing,
+			to:      model.StatusProcessing,
+			want:    model.StatusProcessing,
+			wantErr: true,
+		},
+		{
+			name:    "未定義のステータスへの遷移は不可",
+			from:    model.StatusPending,
+			to:      model.InvoiceStatus("canceled"),
+			want:    model.StatusPending,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := &model.Invoice{Status: tt.from}
+
+			err := invoice.TransitionTo(tt.to)
+
+			if tt.wantErr {
+				var transitionErr *model.InvalidStatusTransitionError
+				if !errors.As(err, &transitionErr) {
+					t.Errorf("error = %v, want InvalidStatusTransitionError", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if invoice.Status != tt.want {
+				t.Errorf("status = %s, want %s", invoice.Status, tt.want)
+			}
+		})
+	}
+}
+
+func Test_NewInvoice(t *testing.T) {
+	tests := []struct {
+		name        string
+		org         *model.Organization
+		client      *model.Client
+		feeRate     float64
+		wantFeeRate float64
+		wantErr     error
+	}{
+		{
+			name:        "自組織の取引先",
+			org:         &model.Organization{ID: 1},
+			client:      &model.Client{ID: 1, OrganizationID: 1},
+			feeRate:     0.04,
+			wantFeeRate: 0.04,
+		},
+		{
+			name:        "手数料プランの手数料率を適用",
+			org:         &model.Organization{ID: 1},
+			client:      &model.Client{ID: 1, OrganizationID: 1},
+			feeRate:     0.03,
+			wantFeeRate: 0.03,
+		},
+		{
+			name:        "不正な手数料率は既定値",
+			org:         &model.Organization{ID: 1},
+			client:      &model.Client{ID: 1, OrganizationID: 1},
+			feeRate:     1.5,
+			wantFeeRate: model.DefaultFeeRate,
+		},
+		{
+			name:    "他組織の取引先",
+			org:     &model.Organization{ID: 1},
+			client:  &model.Client{ID: 3, OrganizationID: 2},
+			wantErr: model.ErrClientNotInOrganization,
+		},
+		{
+			name:    "アーカイブした取引先",
+			org:     &model.Organization{ID: 1},
+			client:  &model.Client{ID: 1, OrganizationID: 1, ArchivedAt: &time.Time{}},
+			wantErr: model.ErrClientArchived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := model.NewInvoice(tt.org, tt.client, 10000, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), tt.feeRate)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Status != model.StatusPending {
+				t.Errorf("status = %s, want %s", got.Status, model.StatusPending)
+			}
+			if got.FeeRate != tt.wantFeeRate {
+				t.Errorf("fee rate = %v, want %v", got.FeeRate, tt.wantFeeRate)
+			}
+		})
+	}
+}
+
+func Test_Invoice_Calculate_WithholdingTax(t *testing.T) {
+	individual := &model.Client{PayeeType: model.PayeeTypeIndividual, WithholdingCategory: model.WithholdingCategoryRemuneration}
+
+	tests := []struct {
+		name               string
+		client             *model.Client
+		amount             int64
+		lineItems          []*model.InvoiceLineItem
+		wantWithholding    int64
+		wantTransferAmount int64
+	}{
+		{
+			name:               "個人の報酬: 10.21%",
+			client:             individual,
+			amount:             100_000,
+			wantWithholding:    10_210,
+			wantTransferAmount: 89_790,
+		},
+		{
+			name:               "個人の報酬: 100万円ちょうど",
+			client:             individual,
+			amount:             1_000_000,
+			wantWithholding:    102_100,
+			wantTransferAmount: 897_900,
+		},
+		{
+			name:               "個人の報酬: 100万円を超える部分は20.42%",
+			client:             individual,
+			amount:             1_500_000,
+			wantWithholding:    204_200,
+			wantTransferAmount: 1_295_800,
+		},
+		{
+			name:               "個人の報酬: 円未満は切り捨て",
+			client:             individual,
+			amount:             12_345,
+			wantWithholding:    1_260, // 1260.4245
+			wantTransferAmount: 11_085,
+		},
+		{
+			name:   "個人の報酬: 明細がある場合は税抜の金額に対して計算",
+			client: individual,
+			amount: 110_000,
+			lineItems: []*model.InvoiceLineItem{
+				{LineNumber: 1, Amount: decimal.NewFromInt(100_000), TaxCategory: model.TaxCategoryStandard, TaxRate: 0.1},
+			},
+			wantWithholding:    10_210,
+			wantTransferAmount: 99_790,
+		},
+		{
+			name:               "法人は源泉徴収しない",
+			client:             &model.Client{PayeeType: model.PayeeTypeCorporation, WithholdingCategory: model.WithholdingCategoryRemuneration},
+			amount:             100_000,
+			wantTransferAmount: 100_000,
+		},
+		{
+			name:               "個人でも対象外の区分は源泉徴収しない",
+			client:             &model.Client{PayeeType: model.PayeeTypeIndividual, WithholdingCategory: model.WithholdingCategoryNone},
+			amount:             100_000,
+			wantTransferAmount: 100_000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := model.Invoice{
+				Client:    tt.client,
+				Amount:    decimal.NewFromInt(tt.amount),
+				FeeRate:   0.04,
+				LineItems: tt.lineItems,
+			}
+
+			invoice.Calculate(0.1)
+
+			if got := invoice.WithholdingTaxAsInt(); got != tt.wantWithholding {
+				t.Errorf("WithholdingTax = %d, want %d", got, tt.wantWithholding)
+			}
+			if got := invoice.TransferAmountAsInt(); got != tt.wantTransferAmount {
+				t.Errorf("TransferAmount = %d, want %d", got, tt.wantTransferAmount)
+			}
+			// 請求金額は源泉徴収の影響を受けない
+			wantTotal := invoice.Amount.Add(invoice.Fee).Add(invoice.Tax)
+			if !invoice.TotalAmount.Equal(wantTotal) {
+				t.Errorf("TotalAmount = %s, want %s", invoice.TotalAmount, wantTotal)
+			}
+		})
+	}
+}
@@ -1,10 +1,57 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+// ErrInvalidOrganization 組織のプロフィールが不正
+var ErrInvalidOrganization = errors.New("invalid organization")
+
+// organizationTextMaxLength 法人名・代表者名・住所の最大文字数
+const organizationTextMaxLength = 255
+
 type Organization struct {
-	ID             uint   // 組織ID
-	Name           string // 法人名
-	Representative string // 代表者名
-	PhoneNumber    string // 電話番号
-	PostalCode     string // 郵便番号
-	Address        string // 住所
+	ID   uint   // 組織ID
+	Name string // 法人名
+	// RegistrationNumber 適格請求書発行事業者の登録番号（"T" + 13桁）. 未登録の場合は空文字
+	RegistrationNumber string
+	Representative     string         // 代表者名
+	PhoneNumber        string         // 電話番号
+	PostalCode         string         // 郵便番号
+	Address            string         // 住所
+	RoundingPolicy     RoundingPolicy // 手数料・消費税の端数処理（最新の設定の値）
+	PaymentMethod      PaymentMethod  // 請求書の支払方法（最新の設定の値）
+}
+
+// Validate 組織のプロフィールを検証する. 請求書の記載事項になるため住所まで必須とする
+func (o *Organization) Validate() error {
+	var problems []string
+	if strings.TrimSpace(o.Name) == "" || utf8.RuneCountInString(o.Name) > organizationTextMaxLength {
+		problems = append(problems, fmt.Sprintf("name is required and must be at most %d characters", organizationTextMaxLength))
+	}
+	if strings.TrimSpace(o.Representative) == "" || utf8.RuneCountInString(o.Representative) > organizationTextMaxLength {
+		problems = append(problems, fmt.Sprintf("representative is required and must be at most %d characters", organizationTextMaxLength))
+	}
+	if o.RegistrationNumber != "" && !validation.ValidRegistrationNumber(o.RegistrationNumber) {
+		problems = append(problems, "registration number is invalid")
+	}
+	if o.PhoneNumber != "" && !validation.ValidPhoneNumber(o.PhoneNumber) {
+		problems = append(problems, "phone number is invalid")
+	}
+	if !validation.ValidPostalCode(o.PostalCode) {
+		problems = append(problems, "postal code is invalid")
+	}
+	if strings.TrimSpace(o.Address) == "" || utf8.RuneCountInString(o.Address) > organizationTextMaxLength {
+		problems = append(problems, fmt.Sprintf("address is required and must be at most %d characters", organizationTextMaxLength))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidOrganization, strings.Join(problems, ", "))
+	}
+	return nil
 }
@@ -0,0 +1,15 @@
+package model
+
+// OrganizationBankAccount 振込依頼人（請求元企業）の出金口座
+type OrganizationBankAccount struct {
+	ID             uint        // 出金口座ID
+	OrganizationID uint        // 紐づく組織ID
+	RequesterCode  string      // 振込依頼人コード
+	RequesterName  string      // 振込依頼人名（カナ）
+	BankCode       string      // 金融機関コード
+	BankName       string      // 銀行名
+	BranchCode     string      // 支店コード
+	BranchName     string      // 支店名
+	AccountType    AccountType // 預金種目
+	AccountNumber  string      // 口座番号
+}
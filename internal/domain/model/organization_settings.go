@@ -0,0 +1,83 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+// ErrInvalidOrganizationSettings 組織の設定が不正
+var ErrInvalidOrganizationSettings = errors.New("invalid organization settings")
+
+const (
+	DefaultPaymentTermsDays = 30  // 支払期日を省略した場合の発行日からの日数（既定値）
+	MaxPaymentTermsDays     = 365 // 支払期日までの日数の上限
+)
+
+// OrganizationSettings 組織の設定. 変更のたびに新しい版を作成し、過去の版は変更しない.
+// 請求書には作成時に適用した版数を記録する
+type OrganizationSettings struct {
+	OrganizationID uint // 組織ID
+	Version        uint // 版数（1から始まる. 設定を登録していない場合は0）
+	// DefaultFeePlanID 組織ごとの契約プランがない期間に標準プランの代わりに適用する手数料プラン（指定しない場合は0）
+	DefaultFeePlanID uint
+	RoundingPolicy   RoundingPolicy          // 手数料・消費税の端数処理
+	PaymentTermsDays int                     // 支払期日を省略した場合の発行日からの日数
+	PaymentMethod    PaymentMethod           // 請求書の支払方法
+	Notification     NotificationPreferences // 通知設定
+	CreatedBy        string                  // 設定を変更した操作主体
+	CreatedAt        time.Time               // 設定を変更した日時
+}
+
+// NotificationPreferences 通知設定
+type NotificationPreferences struct {
+	Email          string // 通知先のメールアドレス（通知しない場合は空文字）
+	OnPaid         bool   // 請求書が支払済みになったときに通知する
+	OnPaymentError bool   // 支払に失敗したときに通知する
+	OnOverdue      bool   // 支払期日を過ぎたときに通知する
+}
+
+// DefaultOrganizationSettings 設定を登録していない組織に適用する設定
+func DefaultOrganizationSettings(organizationID uint) *OrganizationSettings {
+	return &OrganizationSettings{
+		OrganizationID:   organizationID,
+		RoundingPolicy:   DefaultRoundingPolicy,
+		PaymentTermsDays: DefaultPaymentTermsDays,
+		PaymentMethod:    DefaultPaymentMethod,
+	}
+}
+
+// DueDate 発行日と支払期日までの日数から支払期日を求める
+func (s *OrganizationSettings) DueDate(issueDate time.Time) time.Time {
+	return issueDate.AddDate(0, 0, s.PaymentTermsDays)
+}
+
+// Validate 組織の設定を検証する. 手数料プランの存在は呼び出し側で確認する
+func (s *OrganizationSettings) Validate() error {
+	var problems []string
+	if !s.RoundingPolicy.IsValid() {
+		problems = append(problems, "rounding policy is invalid")
+	}
+	if s.PaymentTermsDays < 1 || s.PaymentTermsDays > MaxPaymentTermsDays {
+		problems = append(problems, fmt.Sprintf("payment terms must be between 1 and %d days", MaxPaymentTermsDays))
+	}
+	if !s.PaymentMethod.IsValid() {
+		problems = append(problems, "payment method is invalid")
+	}
+	notification := s.Notification
+	if notification.Email != "" {
+		if !validation.ValidEmail(notification.Email) {
+			problems = append(problems, "notification email is invalid")
+		}
+	} else if notification.OnPaid || notification.OnPaymentError || notification.OnOverdue {
+		problems = append(problems, "notification email is required to enable notifications")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidOrganizationSettings, strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func Test_OrganizationSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*model.OrganizationSettings)
+		wantErr error
+	}{
+		{
+			name:   "既定の設定",
+			modify: func(s *model.OrganizationSettings) {},
+		},
+		{
+			name: "通知先と通知する事象を指定",
+			modify: func(s *model.OrganizationSettings) {
+				s.Notification = model.NotificationPreferences{Email: "billing@example.com", OnPaid: true, OnOverdue: true}
+			},
+		},
+		{
+			name:    "端数処理が不正（旧名の floor）",
+			modify:  func(s *model.OrganizationSettings) { s.RoundingPolicy = "floor" },
+			wantErr: model.ErrInvalidOrganizationSettings,
+		},
+		{
+			name:    "支払期日までの日数が0",
+			modify:  func(s *model.OrganizationSettings) { s.PaymentTermsDays = 0 },
+			wantErr: model.ErrInvalidOrganizationSettings,
+		},
+		{
+			name:    "支払期日までの日数が上限を超える",
+			modify:  func(s *model.OrganizationSettings) { s.PaymentTermsDays = model.MaxPaymentTermsDays + 1 },
+			wantErr: model.ErrInvalidOrganizationSettings,
+		},
+		{
+			name:    "メールアドレスが不正",
+			modify:  func(s *model.OrganizationSettings) { s.Notification.Email = "経理 <billing@example.com>" },
+			wantErr: model.ErrInvalidOrganizationSettings,
+		},
+		{
+			name:    "通知先がないのに通知を有効にした",
+			modify:  func(s *model.OrganizationSettings) { s.Notification.OnPaymentError = true },
+			wantErr: model.ErrInvalidOrganizationSettings,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := model.DefaultOrganizationSettings(1)
+			tt.modify(settings)
+
+			err := settings.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_OrganizationSettings_DueDate(t *testing.T) {
+	settings := model.DefaultOrganizationSettings(1)
+	settings.PaymentTermsDays = 45
+
+	got := settings.DueDate(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC))
+	want := time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("due date = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPaymentMethodMismatch 組織の支払方法では行えない操作
+var ErrPaymentMethodMismatch = errors.New("operation is not available for the payment method of the organization")
+
+// PaymentMethod 組織の請求書の支払方法. 同じ請求書を振込ファイルと支払ゲートウェイの両方で支払わないよう、組織ごとにどちらか一方に限定する
+type PaymentMethod string
+
+const (
+	PaymentMethodTransferFile PaymentMethod = "transfer_file" // 全銀協フォーマットの振込ファイルを出力し、入出金明細で消し込む
+	PaymentMethodGateway      PaymentMethod = "gateway"       // 支払処理ワーカーが支払ゲートウェイに依頼する
+
+	DefaultPaymentMethod = PaymentMethodTransferFile
+)
+
+// IsValid 定義済みの支払方法かどうか
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case PaymentMethodTransferFile, PaymentMethodGateway:
+		return true
+	}
+	return false
+}
+
+// RequirePaymentMethod 組織の支払方法が method でなければ ErrPaymentMethodMismatch を返す
+func (o *Organization) RequirePaymentMethod(method PaymentMethod) error {
+	if o.PaymentMethod != method {
+		return fmt.Errorf("%w: payment method is %s", ErrPaymentMethodMismatch, o.PaymentMethod)
+	}
+	return nil
+}
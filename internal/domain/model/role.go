@@ -0,0 +1,118 @@
+package model
+
+import "errors"
+
+// ErrInvalidRole 定義されていないロール
+var ErrInvalidRole = errors.New("invalid role")
+
+// Permission 組織内の操作の権限. 名前はトークンのスコープと同じにする
+type Permission string
+
+const (
+	PermissionReadInvoice        Permission = "read:invoice"         // 請求書の参照
+	PermissionWriteInvoice       Permission = "write:invoice"        // 請求書の作成
+	PermissionWriteInvoiceStatus Permission = "write:invoice_status" // 請求書のステータス変更・入出金明細の消込
+	PermissionExportTransferFile Permission = "export:transfer_file" // 振込データの出力
+	PermissionReadClient         Permission = "read:client"          // 取引先・振込先口座の参照
+	PermissionWriteClient        Permission = "write:client"         // 取引先・振込先口座の変更
+	PermissionReadOrganization   Permission = "read:organization"    // 組織のプロフィール・設定の参照
+	PermissionWriteOrganization  Permission = "write:organization"   // 組織のプロフィール・設定の変更
+	PermissionReadUser           Permission = "read:user"            // ユーザー・ロールの変更履歴の参照
+	PermissionWriteUser          Permission = "write:user"           // ユーザーの招待・更新・無効化
+	PermissionWriteUserRole      Permission = "write:user_role"      // ユーザーのロールの変更
+	PermissionReadAPIKey         Permission = "read:api_key"         // APIキーの参照
+	PermissionWriteAPIKey        Permission = "write:api_key"        // APIキーの発行・失効
+)
+
+// Role 組織内でのユーザーの役割
+type Role string
+
+const (
+	RoleOwner      Role = "owner"      // 所有者. 全ての操作ができ、所有者を任命・解任できる唯一のロール
+	RoleAdmin      Role = "admin"      // 管理者. 所有者に関する操作以外の全ての操作ができる
+	RoleAccountant Role = "accountant" // 経理担当. 請求書の作成・振込データの出力と取引先の管理ができる
+	RoleApprover   Role = "approver"   // 承認者. 請求書のステータスを変更できる
+	RoleViewer     Role = "viewer"     // 閲覧者. 参照のみ
+
+	DefaultRole = RoleViewer
+)
+
+var readPermissions = []Permission{
+	PermissionReadInvoice,
+	PermissionReadClient,
+	PermissionReadOrganization,
+	PermissionReadUser,
+}
+
+// rolePermissions ロールごとの権限
+var rolePermissions = map[Role][]Permission{
+	RoleOwner: allPermissions(),
+	RoleAdmin: allPermissions(),
+	RoleAccountant: append([]Permission{
+		PermissionWriteInvoice,
+		PermissionExportTransferFile,
+		PermissionWriteClient,
+	}, readPermissions...),
+	RoleApprover: append([]Permission{
+		PermissionWriteInvoiceStatus,
+	}, readPermissions...),
+	RoleViewer: readPermissions,
+}
+
+func allPermissions() []Permission {
+	return []Permission{
+		PermissionReadInvoice,
+		PermissionWriteInvoice,
+		PermissionWriteInvoiceStatus,
+		PermissionExportTransferFile,
+		PermissionReadClient,
+		PermissionWriteClient,
+		PermissionReadOrganization,
+		PermissionWriteOrganization,
+		PermissionReadUser,
+		PermissionWriteUser,
+		PermissionWriteUserRole,
+		PermissionReadAPIKey,
+		PermissionWriteAPIKey,
+	}
+}
+
+// IsValid 定義済みの権限かどうか
+func (p Permission) IsValid() bool {
+	for _, permission := range allPermissions() {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantableToAPIKey APIキーに付与できる権限かどうか. APIキーからAPIキーやユーザーを管理できないようにする
+func (p Permission) GrantableToAPIKey() bool {
+	switch p {
+	case PermissionReadAPIKey, PermissionWriteAPIKey, PermissionWriteUser, PermissionWriteUserRole:
+		return false
+	}
+	return true
+}
+
+// IsValid 定義済みのロールかどうか
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permissions ロールに与えられた権限
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// Can ロールが権限を持つかどうか
+func (r Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+// RoundingPolicy 手数料・消費税の円未満の端数処理
+type RoundingPolicy string
+
+const (
+	RoundingTruncate RoundingPolicy = "truncate" // 切り捨て（0に近い方へ. マイナスの金額も絶対値を切り捨てる）
+	RoundingCeil     RoundingPolicy = "ceil"     // 切り上げ（正の無限大の方へ）
+	RoundingHalfUp   RoundingPolicy = "half_up"  // 四捨五入
+	RoundingBankers  RoundingPolicy = "bankers"  // 銀行丸め（偶数丸め）
+
+	DefaultRoundingPolicy = RoundingTruncate
+)
+
+// IsValid 定義済みの端数処理かどうか
+func (p RoundingPolicy) IsValid() bool {
+	switch p {
+	case RoundingTruncate, RoundingCeil, RoundingHalfUp, RoundingBankers:
+		return true
+	}
+	return false
+}
+
+// Round 円未満の端数を処理する. 未設定の場合は DefaultRoundingPolicy で処理する
+func (p RoundingPolicy) Round(d decimal.Decimal) decimal.Decimal {
+	switch p {
+	case RoundingCeil:
+		return d.Ceil()
+	case RoundingHalfUp:
+		return d.Round(0)
+	case RoundingBankers:
+		return d.RoundBank(0)
+	default:
+		// 従来の truncateDecimalToInt・移行時の TRUNCATE と同じく0に近い方へ丸める
+		return d.Truncate(0)
+	}
+}
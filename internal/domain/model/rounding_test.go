@@ -0,0 +1,37 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func Test_RoundingPolicy_Round(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy model.RoundingPolicy
+		amount string
+		want   int64
+	}{
+		{name: "切り捨て", policy: model.RoundingTruncate, amount: "500.5", want: 500},
+		{name: "切り捨て: マイナスの金額は0に近い方へ", policy: model.RoundingTruncate, amount: "-500.5", want: -500},
+		{name: "切り捨て: マイナスの金額の端数が大きくても0に近い方へ", policy: model.RoundingTruncate, amount: "-500.9", want: -500},
+		{name: "切り上げ", policy: model.RoundingCeil, amount: "500.1", want: 501},
+		{name: "切り上げ: マイナスの金額は正の無限大の方へ", policy: model.RoundingCeil, amount: "-500.9", want: -500},
+		{name: "四捨五入", policy: model.RoundingHalfUp, amount: "500.5", want: 501},
+		{name: "四捨五入: マイナスの金額は絶対値を四捨五入", policy: model.RoundingHalfUp, amount: "-500.5", want: -501},
+		{name: "銀行丸め", policy: model.RoundingBankers, amount: "500.5", want: 500},
+		{name: "銀行丸め: マイナスの金額も偶数に丸める", policy: model.RoundingBankers, amount: "-501.5", want: -502},
+		{name: "未設定は切り捨て", policy: "", amount: "-500.5", want: -500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Round(decimal.RequireFromString(tt.amount))
+			if !got.Equal(decimal.NewFromInt(tt.want)) {
+				t.Errorf("Round(%s) = %s, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidTaxRatePeriod 税率の内容または適用期間が不正（期間の重複・途切れを含む）
+var ErrInvalidTaxRatePeriod = errors.New("invalid tax rate period")
+
+// TaxRate 税区分ごとの消費税率と適用期間
+type TaxRate struct {
+	ID        uint        // 税率ID
+	Category  TaxCategory // 税区分（標準税率・軽減税率）
+	StartDate time.Time   // 適用開始日
+	EndDate   *time.Time  // 適用終了日（nilなら現在も有効）
+	Rate      float64     // 税率（例: 0.1 = 10%）
+}
+
+// AppliesTo 指定した日付が適用期間に含まれるかどうか
+func (r *TaxRate) AppliesTo(date time.Time) bool {
+	return !date.Before(r.StartDate) && (r.EndDate == nil || !date.After(*r.EndDate))
+}
+
+// ValidateTaxRatePeriods 同じ税区分の税率が開始日の昇順に、重複も途切れもなく続いていることを検証する.
+// 最後の税率だけが終了日を持たない（以降ずっと有効な）こと
+func ValidateTaxRatePeriods(rates []*TaxRate) error {
+	if len(rates) == 0 {
+		return fmt.Errorf("%w: at least one tax rate is required", ErrInvalidTaxRatePeriod)
+	}
+
+	category := rates[0].Category
+	if !category.IsTaxable() {
+		return fmt.Errorf("%w: tax category %q has no tax rate", ErrInvalidTaxRatePeriod, category)
+	}
+	for i, rate := range rates {
+		if rate.Category != category {
+			return fmt.Errorf("%w: tax categories are mixed", ErrInvalidTaxRatePeriod)
+		}
+		if rate.Rate <= 0 || rate.Rate >= 1 {
+			return fmt.Errorf("%w: rate must be greater than 0 and less than 1: %v", ErrInvalidTaxRatePeriod, rate.Rate)
+		}
+		if r := decimal.NewFromFloat(rate.Rate); !r.Equal(r.Truncate(2)) {
+			return fmt.Errorf("%w: rate must be a whole percentage: %v", ErrInvalidTaxRatePeriod, rate.Rate)
+		}
+		if rate.EndDate != nil && rate.EndDate.Before(rate.StartDate) {
+			return fmt.Errorf("%w: tax rate starting %s ends before it starts", ErrInvalidTaxRatePeriod, rate.StartDate.Format("2006-01-02"))
+		}
+
+		if i == len(rates)-1 {
+			if rate.EndDate != nil {
+				return fmt.Errorf("%w: no tax rate after %s", ErrInvalidTaxRatePeriod, rate.EndDate.Format("2006-01-02"))
+			}
+			break
+		}
+		next := rates[i+1]
+		if rate.EndDate == nil || !next.StartDate.After(*rate.EndDate) {
+			return fmt.Errorf("%w: tax rate starting %s overlaps the one starting %s",
+				ErrInvalidTaxRatePeriod, rate.StartDate.Format("2006-01-02"), next.StartDate.Format("2006-01-02"))
+		}
+		if !next.StartDate.Equal(rate.EndDate.AddDate(0, 0, 1)) {
+			return fmt.Errorf("%w: no tax rate between %s and %s",
+				ErrInvalidTaxRatePeriod, rate.EndDate.Format("2006-01-02"), next.StartDate.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
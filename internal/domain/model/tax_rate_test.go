@@ -0,0 +1,172 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func datePtr(year int, month time.Month, day int) *time.Time {
+	d := date(year, month, day)
+	return &d
+}
+
+func Test_ValidateTaxRatePeriods(t *testing.T) {
+	tests := []struct {
+		name    string
+		rates   []*model.TaxRate
+		wantErr error
+	}{
+		{
+			name: "期間が続いている",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryStandard, StartDate: date(2014, 4, 1), EndDate: datePtr(2019, 9, 30), Rate: 0.08},
+				{Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), Rate: 0.1},
+			},
+		},
+		{
+			name:    "税率がない",
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name: "期間が重複している",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryStandard, StartDate: date(2014, 4, 1), EndDate: datePtr(2019, 10, 1), Rate: 0.08},
+				{Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), Rate: 0.1},
+			},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name: "終了日のない税率のあとに税率がある",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryStandard, StartDate: date(2014, 4, 1), Rate: 0.08},
+				{Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), Rate: 0.1},
+			},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name: "期間が途切れている",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryStandard, StartDate: date(2014, 4, 1), EndDate: datePtr(2019, 9, 29), Rate: 0.08},
+				{Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), Rate: 0.1},
+			},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name: "最後の税率に終了日がある",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), EndDate: datePtr(2029, 9, 30), Rate: 0.1},
+			},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name: "終了日が開始日より前",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), EndDate: datePtr(2019, 9, 30), Rate: 0.08},
+				{Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), Rate: 0.1},
+			},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name: "税区分が混在している",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryStandard, StartDate: date(2014, 4, 1), EndDate: datePtr(2019, 9, 30), Rate: 0.08},
+				{Category: model.TaxCategoryReduced, StartDate: date(2019, 10, 1), Rate: 0.08},
+			},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name: "税率のない税区分",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryExempt, StartDate: date(2019, 10, 1), Rate: 0.1},
+			},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name: "税率が範囲外",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), Rate: 10},
+			},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+		{
+			name: "税率が1%単位でない",
+			rates: []*model.TaxRate{
+				{Category: model.TaxCategoryStandard, StartDate: date(2019, 10, 1), Rate: 0.125},
+			},
+			wantErr: model.ErrInvalidTaxRatePeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := model.ValidateTaxRatePeriods(tt.rates)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Invoice_IsAffectedByTaxRate(t *testing.T) {
+	newRate := &model.TaxRate{Category: model.TaxCategoryStandard, StartDate: date(2029, 10, 1), Rate: 0.12}
+	newReducedRate := &model.TaxRate{Category: model.TaxCategoryReduced, StartDate: date(2029, 10, 1), Rate: 0.1}
+
+	tests := []struct {
+		name    string
+		invoice *model.Invoice
+		rate    *model.TaxRate
+		want    bool
+	}{
+		{
+			name:    "発行日に新しい標準税率が適用される",
+			invoice: &model.Invoice{IssueDate: date(2029, 10, 1), TaxRate: 0.1},
+			rate:    newRate,
+			want:    true,
+		},
+		{
+			name:    "発行日が適用開始日より前",
+			invoice: &model.Invoice{IssueDate: date(2029, 9, 30), TaxRate: 0.1},
+			rate:    newRate,
+			want:    false,
+		},
+		{
+			name:    "適用済みの税率と同じ",
+			invoice: &model.Invoice{IssueDate: date(2029, 10, 1), TaxRate: 0.12},
+			rate:    newRate,
+			want:    false,
+		},
+		{
+			name: "軽減税率の明細がある",
+			invoice: &model.Invoice{IssueDate: date(2029, 10, 1), TaxRate: 0.1, LineItems: []*model.InvoiceLineItem{
+				{TaxCategory: model.TaxCategoryStandard, TaxRate: 0.1},
+				{TaxCategory: model.TaxCategoryReduced, TaxRate: 0.08},
+			}},
+			rate: newReducedRate,
+			want: true,
+		},
+		{
+			name: "軽減税率の明細がない",
+			invoice: &model.Invoice{IssueDate: date(2029, 10, 1), TaxRate: 0.1, LineItems: []*model.InvoiceLineItem{
+				{TaxCategory: model.TaxCategoryStandard, TaxRate: 0.1},
+			}},
+			rate: newReducedRate,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.invoice.IsAffectedByTaxRate(tt.rate); got != tt.want {
+				t.Errorf("IsAffectedByTaxRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
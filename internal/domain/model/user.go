@@ -1,9 +1,155 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+// ErrInvalidUser ユーザーの登録内容が不正
+var ErrInvalidUser = errors.New("invalid user")
+
+// ErrUserDeactivated ユーザーが無効化されている
+var ErrUserDeactivated = errors.New("user is deactivated")
+
+// ErrWeakPassword パスワードが要件を満たしていない
+var ErrWeakPassword = errors.New("password does not meet the requirements")
+
+// ErrInvalidInvitation 招待が存在しない、または有効期限が切れている
+var ErrInvalidInvitation = errors.New("invitation is invalid or expired")
+
+const (
+	userNameMaxLength = 255 // 氏名の最大文字数
+	PasswordMinLength = 12  // パスワードの最小文字数
+	PasswordMaxLength = 128 // パスワードの最大文字数
+
+	// InvitationValidity 招待の有効期間
+	InvitationValidity = 7 * 24 * time.Hour
+)
+
+// UserStatus ユーザーの状態. 保存せずにパスワード・無効化日時から求める
+type UserStatus string
+
+const (
+	UserStatusInvited     UserStatus = "invited"     // 招待中（パスワード未設定）
+	UserStatusActive      UserStatus = "active"      // 有効
+	UserStatusDeactivated UserStatus = "deactivated" // 無効化済み
+)
+
 type User struct {
-	ID             int    // ユーザーID
-	OrganizationID int    // 紐づく企業ID
+	ID             uint   // ユーザーID
+	OrganizationID uint   // 紐づく企業ID
 	Name           string // 氏名
 	Email          string // メールアドレス
-	Password       string // パスワード（ハッシュ化された値を保持）
+	Role           Role   // 組織内のロール
+	// PasswordHash パスワードのハッシュ（argon2id）. 招待中や平文のパスワードを無効化したユーザーは空文字
+	PasswordHash string
+	// InvitationTokenHash 招待トークンのSHA-256（16進数）. 招待中でない場合は空文字
+	InvitationTokenHash string
+	InvitationExpiresAt *time.Time // 招待の有効期限（招待中でない場合はnil）
+	DeactivatedAt       *time.Time // 無効化した日時（無効化していない場合はnil）
+}
+
+// Status ユーザーの状態
+func (u *User) Status() UserStatus {
+	switch {
+	case u.DeactivatedAt != nil:
+		return UserStatusDeactivated
+	case u.PasswordHash == "":
+		return UserStatusInvited
+	}
+	return UserStatusActive
+}
+
+// BelongsTo ユーザーが指定した組織に属しているかどうか
+func (u *User) BelongsTo(organizationID uint) bool {
+	return u.OrganizationID == organizationID
+}
+
+// IsDeactivated ユーザーが無効化されているかどうか. 無効化したユーザーのトークンでは操作できない
+func (u *User) IsDeactivated() bool {
+	return u.DeactivatedAt != nil
+}
+
+// Invite 招待トークンを発行する. 以前の招待は無効になる
+func (u *User) Invite(tokenHash string, now time.Time) error {
+	if u.IsDeactivated() {
+		return ErrUserDeactivated
+	}
+	expiresAt := now.Add(InvitationValidity)
+	u.InvitationTokenHash = tokenHash
+	u.InvitationExpiresAt = &expiresAt
+	return nil
+}
+
+// AcceptInvitation 招待を承諾してパスワードを設定する. パスワードの要件は呼び出し側で検証する
+func (u *User) AcceptInvitation(passwordHash string, now time.Time) error {
+	if u.IsDeactivated() || u.InvitationExpiresAt == nil || !now.Before(*u.InvitationExpiresAt) {
+		return ErrInvalidInvitation
+	}
+	u.PasswordHash = passwordHash
+	u.InvitationTokenHash = ""
+	u.InvitationExpiresAt = nil
+	return nil
+}
+
+// Deactivate ユーザーを無効化し、招待も取り消す. すでに無効化している場合は日時を変更しない
+func (u *User) Deactivate(at time.Time) {
+	if u.DeactivatedAt == nil {
+		u.DeactivatedAt = &at
+	}
+	u.InvitationTokenHash = ""
+	u.InvitationExpiresAt = nil
+}
+
+// ChangeRole ロールを変更し、変更履歴を返す. 無効化したユーザーのロールは変更できない
+func (u *User) ChangeRole(role Role, changedBy string, at time.Time) (*UserRoleChange, error) {
+	if !role.IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRole, role)
+	}
+	if u.IsDeactivated() {
+		return nil, ErrUserDeactivated
+	}
+
+	change := &UserRoleChange{
+		UserID:    u.ID,
+		FromRole:  u.Role,
+		ToRole:    role,
+		ChangedBy: changedBy,
+		ChangedAt: at,
+	}
+	u.Role = role
+	return change, nil
+}
+
+// Validate ユーザーの登録内容を検証する
+func (u *User) Validate() error {
+	var problems []string
+	if strings.TrimSpace(u.Name) == "" || utf8.RuneCountInString(u.Name) > userNameMaxLength {
+		problems = append(problems, fmt.Sprintf("name is required and must be at most %d characters", userNameMaxLength))
+	}
+	if !validation.ValidEmail(u.Email) {
+		problems = append(problems, "email is invalid")
+	}
+	if !u.Role.IsValid() {
+		problems = append(problems, "role is invalid")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidUser, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// ValidatePassword パスワードの要件（文字数）を検証する
+func ValidatePassword(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < PasswordMinLength || length > PasswordMaxLength {
+		return fmt.Errorf("%w: must be between %d and %d characters", ErrWeakPassword, PasswordMinLength, PasswordMaxLength)
+	}
+	return nil
 }
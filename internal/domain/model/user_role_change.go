@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// UserRoleChange ユーザーのロールの変更履歴. 招待時の割り当ても記録する
+type UserRoleChange struct {
+	ID        uint      // 履歴ID
+	UserID    uint      // ユーザーID
+	FromRole  Role      // 変更前のロール（招待時は空文字）
+	ToRole    Role      // 変更後のロール
+	ChangedBy string    // 変更者（トークンのsubject等）
+	ChangedAt time.Time // 変更日時
+}
@@ -0,0 +1,175 @@
+package model_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+func Test_User_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    model.User
+		wantErr error
+	}{
+		{
+			name: "正常",
+			user: model.User{Name: "佐藤 一郎", Email: "ichiro.sato@example.com", Role: model.RoleViewer},
+		},
+		{
+			name:    "氏名が空",
+			user:    model.User{Name: " ", Email: "ichiro.sato@example.com", Role: model.RoleViewer},
+			wantErr: model.ErrInvalidUser,
+		},
+		{
+			name:    "メールアドレスが不正",
+			user:    model.User{Name: "佐藤 一郎", Email: "ichiro.sato", Role: model.RoleViewer},
+			wantErr: model.ErrInvalidUser,
+		},
+		{
+			name:    "ロールが不正",
+			user:    model.User{Name: "佐藤 一郎", Email: "ichiro.sato@example.com", Role: "superuser"},
+			wantErr: model.ErrInvalidUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.user.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "最小文字数", password: strings.Repeat("a", model.PasswordMinLength)},
+		{name: "マルチバイト文字は1文字として数える", password: strings.Repeat("あ", model.PasswordMinLength)},
+		{name: "短すぎる", password: "password123", wantErr: model.ErrWeakPassword},
+		{name: "長すぎる", password: strings.Repeat("a", model.PasswordMaxLength+1), wantErr: model.ErrWeakPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := model.ValidatePassword(tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_User_Invitation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	user := &model.User{Name: "佐藤 一郎", Email: "ichiro.sato@example.com"}
+	if got := user.Status(); got != model.UserStatusInvited {
+		t.Errorf("status = %s, want %s", got, model.UserStatusInvited)
+	}
+	if err := user.Invite("token-hash", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expired := *user
+	if err := expired.AcceptInvitation("hash", now.Add(model.InvitationValidity)); !errors.Is(err, model.ErrInvalidInvitation) {
+		t.Errorf("error = %v, want %v", err, model.ErrInvalidInvitation)
+	}
+
+	if err := user.AcceptInvitation("hash", now.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := user.Status(); got != model.UserStatusActive {
+		t.Errorf("status = %s, want %s", got, model.UserStatusActive)
+	}
+	if user.InvitationTokenHash != "" || user.InvitationExpiresAt != nil {
+		t.Error("invitation is not cleared after accepted")
+	}
+	// 承諾した招待は再利用できない
+	if err := user.AcceptInvitation("other", now.Add(time.Hour)); !errors.Is(err, model.ErrInvalidInvitation) {
+		t.Errorf("error = %v, want %v", err, model.ErrInvalidInvitation)
+	}
+}
+
+func Test_User_Deactivate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	user := &model.User{Name: "佐藤 一郎", Email: "ichiro.sato@example.com"}
+	if err := user.Invite("token-hash", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	user.Deactivate(now)
+	user.Deactivate(now.AddDate(0, 0, 1))
+
+	if got := user.Status(); got != model.UserStatusDeactivated {
+		t.Errorf("status = %s, want %s", got, model.UserStatusDeactivated)
+	}
+	if !user.DeactivatedAt.Equal(now) {
+		t.Errorf("deactivated at = %v, want %v", user.DeactivatedAt, now)
+	}
+	if err := user.AcceptInvitation("hash", now); !errors.Is(err, model.ErrInvalidInvitation) {
+		t.Errorf("error = %v, want %v", err, model.ErrInvalidInvitation)
+	}
+	if err := user.Invite("token-hash", now); !errors.Is(err, model.ErrUserDeactivated) {
+		t.Errorf("error = %v, want %v", err, model.ErrUserDeactivated)
+	}
+}
+
+func Test_User_ChangeRole(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	user := &model.User{ID: 1, Name: "佐藤 一郎", Email: "ichiro.sato@example.com", Role: model.RoleViewer}
+
+	change, err := user.ChangeRole(model.RoleAccountant, "auth0|admin", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := model.UserRoleChange{UserID: 1, FromRole: model.RoleViewer, ToRole: model.RoleAccountant, ChangedBy: "auth0|admin", ChangedAt: now}
+	if *change != want {
+		t.Errorf("change = %+v, want %+v", *change, want)
+	}
+	if user.Role != model.RoleAccountant {
+		t.Errorf("role = %s, want %s", user.Role, model.RoleAccountant)
+	}
+
+	if _, err := user.ChangeRole("superuser", "auth0|admin", now); !errors.Is(err, model.ErrInvalidRole) {
+		t.Errorf("error = %v, want %v", err, model.ErrInvalidRole)
+	}
+	user.Deactivate(now)
+	if _, err := user.ChangeRole(model.RoleAdmin, "auth0|admin", now); !errors.Is(err, model.ErrUserDeactivated) {
+		t.Errorf("error = %v, want %v", err, model.ErrUserDeactivated)
+	}
+}
+
+func Test_Role_Can(t *testing.T) {
+	tests := []struct {
+		role       model.Role
+		permission model.Permission
+		want       bool
+	}{
+		{model.RoleOwner, model.PermissionWriteUserRole, true},
+		{model.RoleAdmin, model.PermissionWriteUserRole, true},
+		{model.RoleAccountant, model.PermissionWriteInvoice, true},
+		{model.RoleAccountant, model.PermissionWriteInvoiceStatus, false},
+		{model.RoleApprover, model.PermissionWriteInvoiceStatus, true},
+		{model.RoleApprover, model.PermissionWriteInvoice, false},
+		{model.RoleViewer, model.PermissionReadInvoice, true},
+		{model.RoleViewer, model.PermissionWriteClient, false},
+		{"superuser", model.PermissionReadInvoice, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"/"+string(tt.permission), func(t *testing.T) {
+			if got := tt.role.Can(tt.permission); got != tt.want {
+				t.Errorf("Can() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+// PayeeType 支払先（取引先）の区分
+type PayeeType string
+
+const (
+	PayeeTypeCorporation PayeeType = "corporation" // 法人
+	PayeeTypeIndividual  PayeeType = "individual"  // 個人
+)
+
+// IsValid 定義済みの支払先区分かどうか
+func (t PayeeType) IsValid() bool {
+	return t == PayeeTypeCorporation || t == PayeeTypeIndividual
+}
+
+// WithholdingCategory 源泉徴収の対象区分
+type WithholdingCategory string
+
+const (
+	WithholdingCategoryNone         WithholdingCategory = "none"         // 源泉徴収の対象外
+	WithholdingCategoryRemuneration WithholdingCategory = "remuneration" // 報酬・料金等（原稿料、デザイン料、講演料など）
+)
+
+// IsValid 定義済みの源泉徴収の対象区分かどうか
+func (c WithholdingCategory) IsValid() bool {
+	return c == WithholdingCategoryNone || c == WithholdingCategoryRemuneration
+}
+
+var (
+	withholdingThreshold          = decimal.NewFromInt(1_000_000) // 税率が変わる支払金額
+	withholdingRate               = decimal.RequireFromString("0.1021")
+	withholdingRateAboveThreshold = decimal.RequireFromString("0.2042")
+)
+
+// calculateWithholding 報酬・料金等の源泉徴収税額を計算する.
+// 100万円以下の部分は10.21%、100万円を超える部分は20.42%とし、円未満は切り捨てる
+func calculateWithholding(base decimal.Decimal) decimal.Decimal {
+	if !base.IsPositive() {
+		return decimal.Zero
+	}
+	if base.LessThanOrEqual(withholdingThreshold) {
+		return base.Mul(withholdingRate).Floor()
+	}
+	return withholdingThreshold.Mul(withholdingRate).
+		Add(base.Sub(withholdingThreshold).Mul(withholdingRateAboveThreshold)).
+		Floor()
+}
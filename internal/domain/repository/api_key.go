@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+type APIKey interface {
+	// GetByID APIキーを取得する. 存在しない場合は ErrNotFound を返す
+	GetByID(id uint) (*model.APIKey, error)
+	// GetByKeyID APIキーを識別子で取得する. 存在しない場合は ErrNotFound を返す
+	GetByKeyID(keyID string) (*model.APIKey, error)
+	// FindByOrganizationID 組織のAPIキーを失効済みも含めてIDの昇順で取得する
+	FindByOrganizationID(organizationID uint) ([]*model.APIKey, error)
+	// Create APIキーを登録する. 識別子が登録済みの場合は ErrConflict を返す
+	Create(key *model.APIKey) (*model.APIKey, error)
+	// Revoke APIキーの失効日時を記録する. 他組織のAPIキーは ErrNotFound を返す
+	Revoke(key *model.APIKey) (*model.APIKey, error)
+	// UpdateLastUsedAt APIキーが最後に使われた日時を記録する
+	UpdateLastUsedAt(id uint, at time.Time) error
+}
@@ -4,4 +4,29 @@ import "github.com/take73/invoice-api-example/internal/domain/model"
 
 type Client interface {
 	GetByID(id uint) (*model.Client, error)
+	Create(client *model.Client) (*model.Client, error)
+	// Update 取引先の登録内容（アーカイブ日時を含む）を更新する. 他組織の取引先は ErrNotFound として扱う
+	Update(client *model.Client) (*model.Client, error)
+	Search(condition ClientSearchCondition) (*ClientPage, error)
+}
+
+// ClientCursor キーセットページネーションの位置. 直前のページの最終行を表す
+type ClientCursor struct {
+	Name string // 最終行の取引先名
+	ID   uint   // 最終行の取引先ID（取引先名が同じ場合のタイブレーク）
+}
+
+// ClientSearchCondition 取引先の検索条件. 取引先名・取引先IDの昇順で返す
+type ClientSearchCondition struct {
+	OrganizationID  uint
+	Name            string // 取引先名の部分一致. 空の場合は絞り込まない
+	IncludeArchived bool   // アーカイブした取引先も含める
+	After           *ClientCursor
+	Limit           int
+}
+
+// ClientPage 取引先の検索結果の1ページ
+type ClientPage struct {
+	Clients    []*model.Client
+	NextCursor *ClientCursor // 次のページがない場合はnil
 }
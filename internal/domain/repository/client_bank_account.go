@@ -0,0 +1,18 @@
+package repository
+
+import "github.com/take73/invoice-api-example/internal/domain/model"
+
+type ClientBankAccount interface {
+	// FindByClientID 取引先の振込先口座を登録順に取得する
+	FindByClientID(clientID uint) ([]*model.ClientBankAccount, error)
+	// GetByID 取引先の振込先口座を取得する. 他の取引先の口座は ErrNotFound として扱う
+	GetByID(clientID, id uint) (*model.ClientBankAccount, error)
+	// Create 振込先口座を登録する. 取引先の最初の口座、または IsDefault を指定した口座を既定の口座にする
+	Create(account *model.ClientBankAccount) (*model.ClientBankAccount, error)
+	// Update 振込先口座の登録内容を更新する. 既定の口座かどうかは変更しない
+	Update(account *model.ClientBankAccount) (*model.ClientBankAccount, error)
+	// Delete 振込先口座を削除する. 既定の口座を削除した場合は残りの最も古い口座を既定にする
+	Delete(clientID, id uint) error
+	// SetDefault 支払に使う既定の口座を変更する
+	SetDefault(clientID, id uint) (*model.ClientBankAccount, error)
+}
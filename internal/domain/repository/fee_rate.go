@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
+
+type FeeRate interface {
+	// GetPlanByDate 指定した日付に組織へ適用される手数料プランを取得する.
+	// 組織ごとのプランを標準プランより優先し、どちらもない場合は ErrNotFound を返す
+	GetPlanByDate(organizationID uint, date time.Time) (*model.FeePlan, error)
+	// GetPlanByID 手数料プランをIDで取得する. 存在しない場合は ErrNotFound を返す
+	GetPlanByID(id uint) (*model.FeePlan, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import "github.com/take73/invoice-api-example/internal/domain/model"
+
+type IdempotencyKey interface {
+	// Get 操作主体のキーを取得する. 存在しない場合は ErrNotFound を返す
+	Get(scope, key string) (*model.IdempotencyKey, error)
+	// Create キーを予約する. 同じ操作主体のキーが登録済みの場合は ErrConflict を返す
+	Create(key *model.IdempotencyKey) (*model.IdempotencyKey, error)
+	// Complete 予約したキーに応答と有効期限を保存する. 予約が削除されている場合は ErrNotFound を返す
+	Complete(key *model.IdempotencyKey) error
+	// Delete キーの予約・保存した応答を削除する
+	Delete(id uint) error
+}
@@ -3,10 +3,87 @@ package repository
 import (
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/take73/invoice-api-example/internal/domain/model"
 )
 
 type Invoice interface {
 	Create(invoice *model.Invoice) (*model.Invoice, error)
-	FindByDueDateRange(startDate, endDate time.Time) ([]*model.Invoice, error)
+	// 参照・更新系はすべて組織IDで絞り込み、他組織の請求書は ErrNotFound として扱う
+	FindByID(organizationID, id uint) (*model.Invoice, error)
+	// FindByIDs 複数の請求書を取引先の振込先口座とあわせて取得する. 存在しないIDは結果に含めない
+	FindByIDs(organizationID uint, ids []uint) ([]*model.Invoice, error)
+	// FindByStatusAndDueDate 指定したステータスで支払期日が期間内の請求書を、取引先の振込先口座とあわせて取得する
+	FindByStatusAndDueDate(organizationID uint, status model.InvoiceStatus, dueDateFrom, dueDateTo time.Time) ([]*model.Invoice, error)
+	Search(condition InvoiceSearchCondition) (*InvoicePage, error)
+	// FindByClientAndAmount 取引先と支払金額が一致し、発行日が期間内の請求書を取得する（重複の検出用）
+	FindByClientAndAmount(organizationID, clientID uint, amount decimal.Decimal, issueDateFrom, issueDateTo time.Time) ([]*model.Invoice, error)
+	// FindSharingClientAndAmount 発行日が期間内の請求書のうち、取引先と支払金額が同じ請求書が他にもあるものを取得する（重複の一覧用）.
+	// 期間がゼロ値の場合は絞り込みに使わない
+	FindSharingClientAndAmount(organizationID uint, issueDateFrom, issueDateTo time.Time) ([]*model.Invoice, error)
+	// UpdateStatus 請求書のステータスを history.FromStatus から history.ToStatus に更新して版数を1増やし、履歴を記録する.
+	// 請求書の版数が version でない場合（読み込んだ後に更新された場合）は更新せずに ErrConflict を返す
+	UpdateStatus(organizationID, version uint, history *model.InvoiceStatusHistory) error
+	// RecordPayment 支払結果を記録し、あわせて history の通りにステータスを更新する. version は UpdateStatus と同じ
+	RecordPayment(organizationID, version uint, history *model.InvoiceStatusHistory, payment *model.InvoicePayment) error
+	// CreatePayment 支払結果だけを記録する. 請求書の版数・ステータスによらず記録する（支払を依頼した後の記録用）.
+	// 同じ試行の支払結果が記録済みの場合は ErrConflict を返す
+	CreatePayment(payment *model.InvoicePayment) error
+	// FindStalledPaymentAttempts changedBy が請求書を処理中にしたまま startedBefore までに支払済み・エラーにならなかった試行を、
+	// 記録済みの支払結果とあわせて組織横断で取得する（中断した支払処理の再開用）. 取引先の振込先口座も含む
+	FindStalledPaymentAttempts(changedBy string, startedBefore time.Time, limit int) ([]*model.PaymentAttempt, error)
+
+	// FindPendingDueBy 支払方法が支払ゲートウェイの組織の、支払期日が dueBy 以前の未処理の請求書を組織横断で取得する（支払バッチ用）.
+	// 取引先の振込先口座も含む
+	FindPendingDueBy(dueBy time.Time, limit int) ([]*model.Invoice, error)
+	// FindUnpaidIssuedFrom 発行日が issueDateFrom 以降の支払済みでない請求書を、明細とあわせて組織横断で取得する（税率変更の影響確認用）
+	FindUnpaidIssuedFrom(issueDateFrom time.Time) ([]*model.Invoice, error)
+}
+
+// InvoiceSortKey 請求書一覧の並び替えキー
+type InvoiceSortKey string
+
+const (
+	InvoiceSortByDueDate     InvoiceSortKey = "due_date"
+	InvoiceSortByIssueDate   InvoiceSortKey = "issue_date"
+	InvoiceSortByTotalAmount InvoiceSortKey = "total_amount"
+	InvoiceSortByCreatedAt   InvoiceSortKey = "created_at"
+)
+
+// IsValid 定義済みの並び替えキーかどうか
+func (k InvoiceSortKey) IsValid() bool {
+	switch k {
+	case InvoiceSortByDueDate, InvoiceSortByIssueDate, InvoiceSortByTotalAmount, InvoiceSortByCreatedAt:
+		return true
+	}
+	return false
+}
+
+// InvoiceCursor キーセットページネーションの位置. 直前のページの最終行を表す
+type InvoiceCursor struct {
+	SortValue string // 最終行の並び替えキーの値
+	ID        uint   // 最終行の請求書ID（並び替えキーが同値の場合のタイブレーク）
+}
+
+// InvoiceSearchCondition 請求書の検索条件. ゼロ値の条件は絞り込みに使わない
+type InvoiceSearchCondition struct {
+	OrganizationID uint
+	DueDateFrom    time.Time
+	DueDateTo      time.Time
+	IssueDateFrom  time.Time
+	IssueDateTo    time.Time
+	Statuses       []model.InvoiceStatus
+	ClientID       uint
+	MinAmount      *decimal.Decimal // 支払金額の下限
+	MaxAmount      *decimal.Decimal // 支払金額の上限
+	SortKey        InvoiceSortKey
+	Descending     bool
+	After          *InvoiceCursor // 指定した位置より後ろを取得する
+	Limit          int
+}
+
+// InvoicePage 請求書の検索結果の1ページ
+type InvoicePage struct {
+	Invoices   []*model.Invoice
+	NextCursor *InvoiceCursor // 次のページがない場合はnil
 }
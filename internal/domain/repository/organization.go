@@ -4,5 +4,19 @@ import "github.com/take73/invoice-api-example/internal/domain/model"
 
 type Organization interface {
 	GetByID(id uint) (*model.Organization, error)
+	// GetByUserID ユーザーの所属組織を取得する. 存在しないユーザー・無効化したユーザーは ErrNotFound を返す
 	GetByUserID(userID uint) (*model.Organization, error)
+	// GetBankAccount 振込依頼人としての出金口座を取得する. 未登録の場合は ErrNotFound を返す
+	GetBankAccount(organizationID uint) (*model.OrganizationBankAccount, error)
+	// UpdateProfile 組織のプロフィール（法人名・代表者名・連絡先・登録番号）を更新する
+	UpdateProfile(organization *model.Organization) (*model.Organization, error)
+
+	// GetSettings 最新の設定を取得する. 設定を登録していない場合は ErrNotFound を返す
+	GetSettings(organizationID uint) (*model.OrganizationSettings, error)
+	// GetSettingsByVersion 指定した版の設定を取得する. 存在しない場合は ErrNotFound を返す
+	GetSettingsByVersion(organizationID, version uint) (*model.OrganizationSettings, error)
+	// FindSettingsVersions 設定の全ての版を版数の降順で取得する
+	FindSettingsVersions(organizationID uint) ([]*model.OrganizationSettings, error)
+	// CreateSettings 設定の新しい版を登録する. 同じ版数がすでに登録されている場合は ErrConflict を返す
+	CreateSettings(settings *model.OrganizationSettings) (*model.OrganizationSettings, error)
 }
@@ -1,7 +1,25 @@
 package repository
 
-import "time"
+import (
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+)
 
 type TaxRate interface {
+	// GetRateByDate 指定した日付に適用される標準税率を取得する
 	GetRateByDate(date time.Time) (float64, error)
+	// GetRateByCategory 指定した日付に適用される税区分（標準税率・軽減税率）の税率を取得する
+	GetRateByCategory(date time.Time, category model.TaxCategory) (float64, error)
+
+	// GetByID 税率をIDで取得する. 存在しない場合は ErrNotFound を返す
+	GetByID(id uint) (*model.TaxRate, error)
+	// FindByCategory 税区分の税率を適用開始日の昇順で取得する
+	FindByCategory(category model.TaxCategory) ([]*model.TaxRate, error)
+	// FindByDate 指定した日付に適用される税率を税区分ごとに取得する
+	FindByDate(date time.Time) ([]*model.TaxRate, error)
+	// ReplaceCategory 税区分の税率を rates の通りに置き換える.
+	// IDが0の税率は追加、既存のIDは更新し、rates に含まれない既存の税率は削除する.
+	// 税区分の税率が読み込んだ時点の read から変わっている場合は ErrConflict を返す
+	ReplaceCategory(category model.TaxCategory, read, rates []*model.TaxRate) ([]*model.TaxRate, error)
 }
@@ -0,0 +1,24 @@
+package repository
+
+import "github.com/take73/invoice-api-example/internal/domain/model"
+
+type User interface {
+	// GetByID ユーザーを取得する. 存在しない場合は ErrNotFound を返す
+	GetByID(id uint) (*model.User, error)
+	// GetByEmail ユーザーをメールアドレスで取得する. 存在しない場合は ErrNotFound を返す
+	GetByEmail(email string) (*model.User, error)
+	// GetByInvitationTokenHash 招待トークンのハッシュでユーザーを取得する. 存在しない場合は ErrNotFound を返す
+	GetByInvitationTokenHash(tokenHash string) (*model.User, error)
+	// FindByOrganizationID 組織のユーザーをユーザーIDの昇順で取得する
+	FindByOrganizationID(organizationID uint, includeDeactivated bool) ([]*model.User, error)
+	// Create ユーザーを登録し、ロールの割り当てを履歴に記録する. メールアドレスが登録済みの場合は ErrConflict を返す
+	Create(user *model.User, roleChange *model.UserRoleChange) (*model.User, error)
+	// Update ユーザーの登録内容（パスワード・招待・無効化日時を含む）を更新する.
+	// 他組織のユーザーは ErrNotFound、メールアドレスが他のユーザーと重複する場合は ErrConflict を返す
+	Update(user *model.User) (*model.User, error)
+	// UpdateRole ユーザーのロールを change.FromRole から change.ToRole に更新し、履歴を記録する.
+	// 他組織のユーザーは ErrNotFound、ロールが change.FromRole でなくなっている場合は ErrConflict を返す
+	UpdateRole(organizationID uint, change *model.UserRoleChange) error
+	// FindRoleChanges ユーザーのロールの変更履歴を古い順に取得する
+	FindRoleChanges(userID uint) ([]*model.UserRoleChange, error)
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"log"
+	"os"
+	"sync"
+)
+
+const (
+	ProviderAuth0 = "auth0" // Auth0 が発行したトークンを検証する（既定）
+	ProviderLocal = "local" // ローカル認証（/auth/login）で発行したトークンを検証する
+
+	defaultLocalIssuer   = "http://localhost:1323/"
+	defaultLocalAudience = "invoice-api-example"
+)
+
+// Provider 環境変数 AUTH_PROVIDER で指定したトークンの発行元. 未指定の場合は Auth0
+func Provider() string {
+	if os.Getenv("AUTH_PROVIDER") == ProviderLocal {
+		return ProviderLocal
+	}
+	return ProviderAuth0
+}
+
+// LocalConfig ローカル認証の設定
+type LocalConfig struct {
+	Issuer   string      // トークンの発行者（iss）
+	Audience string      // アクセストークンの対象（aud）
+	Key      *SigningKey // 署名鍵
+}
+
+var (
+	loadLocalConfigOnce sync.Once
+	localConfig         *LocalConfig
+	localConfigErr      error
+)
+
+// LoadLocalConfig 環境変数からローカル認証の設定を読み込む.
+// 発行と検証で同じ鍵を使うため、読み込みはプロセスで1回だけ行う.
+// LOCAL_AUTH_SIGNING_KEY_FILE を指定しない場合は一時的な鍵を生成する
+func LoadLocalConfig() (*LocalConfig, error) {
+	loadLocalConfigOnce.Do(func() {
+		var key *SigningKey
+		if path := os.Getenv("LOCAL_AUTH_SIGNING_KEY_FILE"); path != "" {
+			key, localConfigErr = LoadSigningKey(path)
+		} else {
+			log.Print("LOCAL_AUTH_SIGNING_KEY_FILE is not set; tokens are signed with an ephemeral key")
+			key, localConfigErr = GenerateSigningKey()
+		}
+		if localConfigErr != nil {
+			return
+		}
+
+		localConfig = &LocalConfig{
+			Issuer:   envOrDefault("LOCAL_AUTH_ISSUER", defaultLocalIssuer),
+			Audience: envOrDefault("LOCAL_AUTH_AUDIENCE", defaultLocalAudience),
+			Key:      key,
+		}
+	})
+	return localConfig, localConfigErr
+}
+
+func envOrDefault(name, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/gateway"
+	jose "gopkg.in/go-jose/go-jose.v2"
+	"gopkg.in/go-jose/go-jose.v2/jwt"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute    // アクセストークンの有効期間
+	RefreshTokenTTL = 30 * 24 * time.Hour // リフレッシュトークンの有効期間
+
+	refreshTokenUse = "refresh"
+	allowedSkew     = time.Minute
+)
+
+// accessTokenClaims アクセストークンのクレーム. Auth0 のトークンと同じ名前にする
+type accessTokenClaims struct {
+	jwt.Claims
+	Scope          string `json:"scope"`
+	OrganizationID uint   `json:"org_id"`
+	UserID         uint   `json:"user_id"`
+}
+
+// refreshTokenClaims リフレッシュトークンのクレーム. aud を発行者自身にし、
+// token_use を持つトークンはミドルウェアが拒否するため、アクセストークンとしては使えない
+type refreshTokenClaims struct {
+	jwt.Claims
+	UserID   uint   `json:"user_id"`
+	TokenUse string `json:"token_use"`
+}
+
+// LocalIssuer ローカル認証のトークンを発行する
+type LocalIssuer struct {
+	config *LocalConfig
+	signer jose.Signer
+}
+
+func NewLocalIssuer(config *LocalConfig) (*LocalIssuer, error) {
+	signer, err := config.Key.Signer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer: %w", err)
+	}
+	return &LocalIssuer{config: config, signer: signer}, nil
+}
+
+// Issue アクセストークンとリフレッシュトークンを発行する
+func (i *LocalIssuer) Issue(claims gateway.TokenClaims, now time.Time) (*gateway.IssuedTokens, error) {
+	accessExpiresAt := now.Add(AccessTokenTTL)
+	accessID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := jwt.Signed(i.signer).Claims(accessTokenClaims{
+		Claims: jwt.Claims{
+			ID:       accessID,
+			Issuer:   i.config.Issuer,
+			Subject:  claims.Subject,
+			Audience: jwt.Audience{i.config.Audience},
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(accessExpiresAt),
+		},
+		Scope:          strings.Join(claims.Scopes, " "),
+		OrganizationID: claims.OrganizationID,
+		UserID:         claims.UserID,
+	}).CompactSerialize()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refreshID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := jwt.Signed(i.signer).Claims(refreshTokenClaims{
+		Claims: jwt.Claims{
+			ID:       refreshID,
+			Issuer:   i.config.Issuer,
+			Subject:  claims.Subject,
+			Audience: jwt.Audience{i.config.Issuer},
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(now.Add(RefreshTokenTTL)),
+		},
+		UserID:   claims.UserID,
+		TokenUse: refreshTokenUse,
+	}).CompactSerialize()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign refresh token: %w", err)
+	}
+
+	return &gateway.IssuedTokens{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessExpiresAt,
+		RefreshToken:         refreshToken,
+	}, nil
+}
+
+// VerifyRefreshToken リフレッシュトークンを検証してユーザーIDを返す
+func (i *LocalIssuer) VerifyRefreshToken(token string, now time.Time) (uint, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil || len(parsed.Headers) != 1 || parsed.Headers[0].Algorithm != string(i.config.Key.Algorithm) {
+		return 0, gateway.ErrInvalidToken
+	}
+
+	var claims refreshTokenClaims
+	if err := parsed.Claims(i.config.Key.PublicKey(), &claims); err != nil {
+		return 0, gateway.ErrInvalidToken
+	}
+	expected := jwt.Expected{
+		Issuer:   i.config.Issuer,
+		Audience: jwt.Audience{i.config.Issuer},
+		Time:     now,
+	}
+	if err := claims.ValidateWithLeeway(expected, allowedSkew); err != nil {
+		return 0, fmt.Errorf("%w: %v", gateway.ErrInvalidToken, err)
+	}
+	if claims.TokenUse != refreshTokenUse || claims.UserID == 0 {
+		return 0, gateway.ErrInvalidToken
+	}
+
+	return claims.UserID, nil
+}
+
+// newTokenID トークンID（jti）を生成する
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
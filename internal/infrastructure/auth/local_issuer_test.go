@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/take73/invoice-api-example/internal/domain/gateway"
+)
+
+type testClaims struct {
+	Scope          string `json:"scope"`
+	OrganizationID uint   `json:"org_id"`
+	UserID         uint   `json:"user_id"`
+}
+
+func (c *testClaims) Validate(ctx context.Context) error { return nil }
+
+func newTestRSAKey(t *testing.T) *SigningKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("failed to marshal RSA key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "signing_key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write RSA key: %v", err)
+	}
+
+	key, err := LoadSigningKey(path)
+	if err != nil {
+		t.Fatalf("failed to load RSA key: %v", err)
+	}
+	return key
+}
+
+func Test_LocalIssuer(t *testing.T) {
+	ed25519Key, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  *SigningKey
+	}{
+		{name: "EdDSA", key: ed25519Key},
+		{name: "RS256", key: newTestRSAKey(t)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &LocalConfig{Issuer: "http://localhost:1323/", Audience: "invoice-api-example", Key: tt.key}
+			issuer, err := NewLocalIssuer(config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := string(tt.key.Algorithm); got != tt.name {
+				t.Errorf("algorithm = %s, want %s", got, tt.name)
+			}
+
+			now := time.Now()
+			tokens, err := issuer.Issue(gateway.TokenClaims{
+				Subject:        "local|1",
+				UserID:         1,
+				OrganizationID: 2,
+				Scopes:         []string{"read:invoice", "write:invoice"},
+			}, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tokens.AccessTokenExpiresAt.Equal(now.Add(AccessTokenTTL)) {
+				t.Errorf("expires at = %v, want %v", tokens.AccessTokenExpiresAt, now.Add(AccessTokenTTL))
+			}
+
+			// アクセストークンはミドルウェアと同じ検証を通る
+			jwtValidator, err := validator.New(
+				func(context.Context) (interface{}, error) { return tt.key.PublicKey(), nil },
+				validator.SignatureAlgorithm(tt.key.Algorithm),
+				config.Issuer,
+				[]string{config.Audience},
+				validator.WithCustomClaims(func() validator.CustomClaims { return &testClaims{} }),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			validated, err := jwtValidator.ValidateToken(context.Background(), tokens.AccessToken)
+			if err != nil {
+				t.Fatalf("access token is not valid: %v", err)
+			}
+			claims := validated.(*validator.ValidatedClaims)
+			custom := claims.CustomClaims.(*testClaims)
+			if claims.RegisteredClaims.Subject != "local|1" || custom.Scope != "read:invoice write:invoice" || custom.OrganizationID != 2 || custom.UserID != 1 {
+				t.Errorf("claims = %+v %+v", claims.RegisteredClaims, custom)
+			}
+
+			// リフレッシュトークンはアクセストークンとして使えない
+			if _, err := jwtValidator.ValidateToken(context.Background(), tokens.RefreshToken); err == nil {
+				t.Error("refresh token is accepted as an access token")
+			}
+
+			userID, err := issuer.VerifyRefreshToken(tokens.RefreshToken, now.Add(time.Hour))
+			if err != nil || userID != 1 {
+				t.Errorf("VerifyRefreshToken() = %d, %v, want 1", userID, err)
+			}
+
+			// アクセストークン・期限切れ・他の鍵で署名したリフレッシュトークンは拒否する
+			if _, err := issuer.VerifyRefreshToken(tokens.AccessToken, now); !errors.Is(err, gateway.ErrInvalidToken) {
+				t.Errorf("access token: error = %v, want %v", err, gateway.ErrInvalidToken)
+			}
+			if _, err := issuer.VerifyRefreshToken(tokens.RefreshToken, now.Add(RefreshTokenTTL+time.Hour)); !errors.Is(err, gateway.ErrInvalidToken) {
+				t.Errorf("expired token: error = %v, want %v", err, gateway.ErrInvalidToken)
+			}
+			otherKey, _ := GenerateSigningKey()
+			other, _ := NewLocalIssuer(&LocalConfig{Issuer: config.Issuer, Audience: config.Audience, Key: otherKey})
+			if _, err := other.VerifyRefreshToken(tokens.RefreshToken, now); !errors.Is(err, gateway.ErrInvalidToken) {
+				t.Errorf("other key: error = %v, want %v", err, gateway.ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func Test_SigningKey_JWKS(t *testing.T) {
+	key, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	jwks := key.JWKS()
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("keys = %d, want 1", len(jwks.Keys))
+	}
+	jwk := jwks.Keys[0]
+	if jwk.KeyID != key.ID || jwk.Algorithm != "EdDSA" || !jwk.IsPublic() {
+		t.Errorf("jwk = %+v, want the public key with kid %s", jwk, key.ID)
+	}
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	jose "gopkg.in/go-jose/go-jose.v2"
+)
+
+// SigningKey ローカル認証でトークンに署名する鍵. RSA鍵は RS256、Ed25519鍵は EdDSA で署名する
+type SigningKey struct {
+	ID        string                  // 鍵ID（kid）. 公開鍵の JWK Thumbprint（RFC 7638）
+	Algorithm jose.SignatureAlgorithm // 署名アルゴリズム
+	private   crypto.Signer
+}
+
+// NewSigningKey 秘密鍵から署名鍵を作成する. 対応するのは RSA（2048ビット以上）と Ed25519
+func NewSigningKey(private crypto.Signer) (*SigningKey, error) {
+	var algorithm jose.SignatureAlgorithm
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key must be at least 2048 bits, got %d", key.N.BitLen())
+		}
+		algorithm = jose.RS256
+	case ed25519.PrivateKey:
+		algorithm = jose.EdDSA
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", private)
+	}
+
+	public := jose.JSONWebKey{Key: private.Public()}
+	thumbprint, err := public.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute key ID: %w", err)
+	}
+
+	return &SigningKey{
+		ID:        base64.RawURLEncoding.EncodeToString(thumbprint),
+		Algorithm: algorithm,
+		private:   private,
+	}, nil
+}
+
+// GenerateSigningKey 一時的な Ed25519 の署名鍵を生成する. プロセスを再起動すると発行済みのトークンは検証できなくなる
+func GenerateSigningKey() (*SigningKey, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return NewSigningKey(private)
+}
+
+// LoadSigningKey PEM形式の秘密鍵ファイル（PKCS#8、またはPKCS#1のRSA鍵）から署名鍵を読み込む
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+
+	var private any
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", private)
+	}
+	return NewSigningKey(signer)
+}
+
+// PublicKey 検証に使う公開鍵
+func (k *SigningKey) PublicKey() crypto.PublicKey {
+	return k.private.Public()
+}
+
+// JWKS 公開鍵を JSON Web Key Set で返す
+func (k *SigningKey) JWKS() jose.JSONWebKeySet {
+	return jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       k.private.Public(),
+		KeyID:     k.ID,
+		Algorithm: string(k.Algorithm),
+		Use:       "sig",
+	}}}
+}
+
+// Signer トークンに署名する. ヘッダーに鍵ID（kid）を含める
+func (k *SigningKey) Signer() (jose.Signer, error) {
+	return jose.NewSigner(
+		jose.SigningKey{Algorithm: k.Algorithm, Key: jose.JSONWebKey{Key: k.private, KeyID: k.ID}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+}
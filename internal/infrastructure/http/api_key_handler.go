@@ -0,0 +1,174 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+type APIKeyHandler struct {
+	usecase application.APIKeyUsecase
+	now     func() time.Time // 有効期限の判定・失効日時に使う現在時刻
+}
+
+func NewAPIKeyHandler(usecase application.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{usecase: usecase, now: time.Now}
+}
+
+// CreateAPIKeyRequest APIキーの発行. 付与できる権限の検証はドメインモデルで行う
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required"` // 必須, 用途を表す名前
+	Scopes    []string   `json:"scopes"`                   // 必須, 付与する権限（例: read:invoice）
+	ExpiresAt *time.Time `json:"expiresAt"`                // 任意, 有効期限（省略した場合は無期限）
+}
+
+type APIKeyItem struct {
+	ID         uint       `json:"id"`         // APIキーID
+	Name       string     `json:"name"`       // 用途を表す名前
+	KeyID      string     `json:"keyId"`      // キーの識別子（キーの先頭部分）
+	Scopes     []string   `json:"scopes"`     // 付与した権限
+	Status     string     `json:"status"`     // 状態（active / expired / revoked）
+	ExpiresAt  *time.Time `json:"expiresAt"`  // 有効期限（無期限の場合は null）
+	LastUsedAt *time.Time `json:"lastUsedAt"` // 最後に使われた日時（1分単位、未使用の場合は null）
+	RevokedAt  *time.Time `json:"revokedAt"`  // 失効日時（有効な場合は null）
+	CreatedBy  string     `json:"createdBy"`  // 発行した操作主体
+	CreatedAt  time.Time  `json:"createdAt"`  // 発行日時
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys []APIKeyItem `json:"apiKeys"`
+}
+
+type IssuedAPIKeyResponse struct {
+	APIKey APIKeyItem `json:"apiKey"`
+	Key    string     `json:"key"` // APIキー（この応答でのみ返す）
+}
+
+// CreateAPIKey APIキーを発行する. キーは応答でのみ返し、再表示はできない
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	var req CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	issued, err := h.usecase.CreateAPIKey(application.CreateAPIKeyDto{
+		Principal: principal,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		Now:       h.now(),
+	})
+	if err != nil {
+		return apiKeyErrorResponse(c, err, "could not create api key")
+	}
+
+	return c.JSON(http.StatusCreated, IssuedAPIKeyResponse{
+		APIKey: newAPIKeyItem(&issued.APIKey),
+		Key:    issued.Key,
+	})
+}
+
+// ListAPIKeys 組織のAPIキーを失効済みも含めて返す. キー自体は返さない
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	keys, err := h.usecase.ListAPIKeys(application.ListAPIKeysDto{Principal: principal, Now: h.now()})
+	if err != nil {
+		return apiKeyErrorResponse(c, err, "could not list api keys")
+	}
+
+	response := ListAPIKeysResponse{APIKeys: make([]APIKeyItem, len(keys))}
+	for i, key := range keys {
+		response.APIKeys[i] = newAPIKeyItem(key)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *APIKeyHandler) GetAPIKey(c echo.Context) error {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	key, err := h.usecase.GetAPIKey(application.GetAPIKeyDto{Principal: principal, ID: id, Now: h.now()})
+	if err != nil {
+		return apiKeyErrorResponse(c, err, "could not get api key")
+	}
+
+	return c.JSON(http.StatusOK, newAPIKeyItem(key))
+}
+
+// RevokeAPIKey APIキーを失効させる. 失効させたキーは元に戻せない
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	key, err := h.usecase.RevokeAPIKey(application.RevokeAPIKeyDto{Principal: principal, ID: id, Now: h.now()})
+	if err != nil {
+		return apiKeyErrorResponse(c, err, "could not revoke api key")
+	}
+
+	return c.JSON(http.StatusOK, newAPIKeyItem(key))
+}
+
+// apiKeyErrorResponse APIキーの管理のエラーをレスポンスに変換する
+func apiKeyErrorResponse(c echo.Context, err error, message string) error {
+	switch {
+	case isAuthorizationError(err):
+		return authorizationErrorResponse(c, err)
+	case errors.Is(err, commonErrors.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "api key not found"})
+	case errors.Is(err, model.ErrInvalidAPIKey):
+		log.Printf("Invalid api key: %v", err)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Failed to manage api key Error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
+
+func newAPIKeyItem(key *application.APIKeyDto) APIKeyItem {
+	return APIKeyItem{
+		ID:         key.ID,
+		Name:       key.Name,
+		KeyID:      key.KeyID,
+		Scopes:     key.Scopes,
+		Status:     key.Status,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/middleware"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+func Test_APIKeyHandler_CreateAPIKey(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.CreateAPIKeyDto{
+		Principal: testPrincipal,
+		Name:      "会計システム連携",
+		Scopes:    []string{"read:invoice"},
+		Now:       testNow,
+	}
+	payload := func() map[string]interface{} {
+		return map[string]interface{}{
+			"name":   "会計システム連携",
+			"scopes": []string{"read:invoice"},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockAPIKeyUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockAPIKeyUsecase) {
+				mockUsecase.On("CreateAPIKey", dto).Return(&application.IssuedAPIKeyDto{
+					APIKey: application.APIKeyDto{ID: 1, Name: "会計システム連携", KeyID: "0123456789ab", Scopes: []string{"read:invoice"}, Status: "active", CreatedBy: "auth0|user1", CreatedAt: testNow},
+					Key:    "iak_0123456789ab_secret",
+				}, nil)
+			},
+			payload:        payload(),
+			expectedStatus: http.StatusCreated,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response IssuedAPIKeyResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "iak_0123456789ab_secret", response.Key)
+				assert.Equal(t, "0123456789ab", response.APIKey.KeyID)
+				assert.Equal(t, []string{"read:invoice"}, response.APIKey.Scopes)
+				assert.Nil(t, response.APIKey.ExpiresAt)
+			},
+		},
+		{
+			name:      "名前がない",
+			setupMock: func(mockUsecase *testutils.MockAPIKeyUsecase) {}, // Mock is not called in this case
+			payload: func() map[string]interface{} {
+				p := payload()
+				delete(p, "name")
+				return p
+			}(),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "付与できない権限",
+			setupMock: func(mockUsecase *testutils.MockAPIKeyUsecase) {
+				mockUsecase.On("CreateAPIKey", dto).Return(nil, fmt.Errorf("%w: scope \"write:api_key\" cannot be granted to api keys", model.ErrInvalidAPIKey))
+			},
+			payload:        payload(),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "操作主体がもたない権限",
+			setupMock: func(mockUsecase *testutils.MockAPIKeyUsecase) {
+				mockUsecase.On("CreateAPIKey", dto).Return(nil, commonErrors.ErrForbidden)
+			},
+			payload:        payload(),
+			expectedStatus: http.StatusForbidden,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "insufficient permission", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockAPIKeyUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewAPIKeyHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			err := handler.CreateAPIKey(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_APIKeyHandler_RevokeAPIKey(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name           string
+		id             string
+		setupMock      func(*testutils.MockAPIKeyUsecase)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			id:   "1",
+			setupMock: func(mockUsecase *testutils.MockAPIKeyUsecase) {
+				mockUsecase.On("RevokeAPIKey", application.RevokeAPIKeyDto{Principal: testPrincipal, ID: 1, Now: testNow}).
+					Return(&application.APIKeyDto{ID: 1, Status: "revoked", RevokedAt: &testNow}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "他組織のAPIキー",
+			id:   "2",
+			setupMock: func(mockUsecase *testutils.MockAPIKeyUsecase) {
+				mockUsecase.On("RevokeAPIKey", application.RevokeAPIKeyDto{Principal: testPrincipal, ID: 2, Now: testNow}).
+					Return(nil, commonErrors.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "IDが不正",
+			id:             "abc",
+			setupMock:      func(mockUsecase *testutils.MockAPIKeyUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockAPIKeyUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewAPIKeyHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/api-keys/:id/revoke")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			c.Set("user", testClaims)
+
+			err := handler.RevokeAPIKey(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+// stubTokenVerifier JWT の検証の代わりに固定のクレームを返す
+type stubTokenVerifier struct {
+	claims *middleware.CustomClaims
+}
+
+func (v stubTokenVerifier) Verify(ctx context.Context, token string) (*middleware.CustomClaims, error) {
+	return v.claims, nil
+}
+
+func Test_APIKeyVerifier(t *testing.T) {
+	mockUsecase := &testutils.MockAPIKeyUsecase{}
+	mockUsecase.On("AuthenticateAPIKey", application.AuthenticateAPIKeyDto{Key: "iak_0123456789ab_secret", Now: testNow}).
+		Return(&application.Principal{Subject: "api_key|1", OrganizationID: 1, Scopes: []string{"read:invoice", "write:invoice"}}, nil)
+	mockUsecase.On("AuthenticateAPIKey", application.AuthenticateAPIKeyDto{Key: "iak_0123456789ab_revoked", Now: testNow}).
+		Return(nil, application.ErrInvalidCredentials)
+
+	verifier := NewAPIKeyVerifier(mockUsecase, stubTokenVerifier{claims: testClaims}).(*apiKeyVerifier)
+	verifier.now = func() time.Time { return testNow }
+
+	// APIキーはユーザーに紐づかないクライアントとしてキーの組織・スコープをクレームにする
+	claims, err := verifier.Verify(context.Background(), "iak_0123456789ab_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, &middleware.CustomClaims{Subject: "api_key|1", Scope: "read:invoice write:invoice", OrganizationID: 1}, claims)
+
+	_, err = verifier.Verify(context.Background(), "iak_0123456789ab_revoked")
+	assert.ErrorIs(t, err, application.ErrInvalidCredentials)
+
+	// APIキーの形式でないトークンは JWT として検証する
+	claims, err = verifier.Verify(context.Background(), "eyJhbGciOiJFZERTQSJ9.payload.signature")
+	assert.NoError(t, err)
+	assert.Equal(t, testClaims, claims)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/middleware"
+)
+
+// apiKeyVerifier APIキーを検証する TokenVerifier. APIキーの形式でないトークンは JWT として jwtVerifier で検証する
+type apiKeyVerifier struct {
+	usecase     application.APIKeyUsecase
+	jwtVerifier middleware.TokenVerifier
+	now         func() time.Time
+}
+
+// NewAPIKeyVerifier APIキーと JWT のどちらも受け付ける TokenVerifier を作成する.
+// APIキーはユーザーに紐づかないクライアントとして、キーの組織・スコープをクレームにする
+func NewAPIKeyVerifier(usecase application.APIKeyUsecase, jwtVerifier middleware.TokenVerifier) middleware.TokenVerifier {
+	return &apiKeyVerifier{usecase: usecase, jwtVerifier: jwtVerifier, now: time.Now}
+}
+
+func (v *apiKeyVerifier) Verify(ctx context.Context, token string) (*middleware.CustomClaims, error) {
+	if !strings.HasPrefix(token, model.APIKeyPrefix) {
+		return v.jwtVerifier.Verify(ctx, token)
+	}
+
+	principal, err := v.usecase.AuthenticateAPIKey(application.AuthenticateAPIKeyDto{Key: token, Now: v.now()})
+	if err != nil {
+		if !errors.Is(err, application.ErrInvalidCredentials) {
+			log.Printf("Failed to authenticate api key Error: %v", err)
+		}
+		return nil, err
+	}
+	return &middleware.CustomClaims{
+		Subject:        principal.Subject,
+		Scope:          strings.Join(principal.Scopes, " "),
+		OrganizationID: principal.OrganizationID,
+	}, nil
+}
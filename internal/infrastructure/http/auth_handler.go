@@ -0,0 +1,115 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	jose "gopkg.in/go-jose/go-jose.v2"
+)
+
+type AuthHandler struct {
+	usecase application.AuthUsecase
+	jwks    jose.JSONWebKeySet // トークンの検証に使う公開鍵
+	now     func() time.Time   // トークンの発行日時に使う現在時刻
+}
+
+func NewAuthHandler(usecase application.AuthUsecase, jwks jose.JSONWebKeySet) *AuthHandler {
+	return &AuthHandler{usecase: usecase, jwks: jwks, now: time.Now}
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required"`    // 必須, メールアドレス
+	Password string `json:"password" validate:"required"` // 必須, パスワード
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"` // 必須, ログインで取得したリフレッシュトークン
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`  // アクセストークン（Authorization: Bearer で指定する）
+	RefreshToken string `json:"refreshToken"` // アクセストークンの再発行に使うトークン
+	TokenType    string `json:"tokenType"`    // 常に Bearer
+	ExpiresIn    int    `json:"expiresIn"`    // アクセストークンの有効期間（秒）
+	Scope        string `json:"scope"`        // 付与したスコープ（空白区切り）
+}
+
+// Login メールアドレス・パスワードでトークンを発行する
+func (h *AuthHandler) Login(c echo.Context) error {
+	var req LoginRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	now := h.now()
+	token, err := h.usecase.Login(application.LoginDto{
+		Email:    req.Email,
+		Password: req.Password,
+		Now:      now,
+	})
+	if err != nil {
+		return authErrorResponse(c, err, "could not log in")
+	}
+
+	return c.JSON(http.StatusOK, newTokenResponse(token, now))
+}
+
+// RefreshToken リフレッシュトークンでトークンを再発行する
+func (h *AuthHandler) RefreshToken(c echo.Context) error {
+	var req RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	now := h.now()
+	token, err := h.usecase.Refresh(application.RefreshTokenDto{
+		RefreshToken: req.RefreshToken,
+		Now:          now,
+	})
+	if err != nil {
+		return authErrorResponse(c, err, "could not refresh token")
+	}
+
+	return c.JSON(http.StatusOK, newTokenResponse(token, now))
+}
+
+// JWKS トークンの検証に使う公開鍵（JSON Web Key Set）
+func (h *AuthHandler) JWKS(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.jwks)
+}
+
+// authErrorResponse ローカル認証のエラーをレスポンスに変換する
+func authErrorResponse(c echo.Context, err error, message string) error {
+	if errors.Is(err, application.ErrInvalidCredentials) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+	}
+	log.Printf("Failed to issue token Error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
+
+func newTokenResponse(token *application.TokenDto, now time.Time) TokenResponse {
+	return TokenResponse{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(token.ExpiresAt.Sub(now).Seconds()),
+		Scope:        strings.Join(token.Scopes, " "),
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+	jose "gopkg.in/go-jose/go-jose.v2"
+)
+
+func Test_AuthHandler_Login(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.LoginDto{Email: "ichiro.sato@example.com", Password: "correct horse battery", Now: testNow}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockAuthUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockAuthUsecase) {
+				mockUsecase.On("Login", dto).Return(&application.TokenDto{
+					AccessToken:  "access-token",
+					RefreshToken: "refresh-token",
+					ExpiresAt:    testNow.Add(15 * time.Minute),
+					Scopes:       []string{"read:invoice", "write:invoice"},
+				}, nil)
+			},
+			payload:        map[string]interface{}{"email": "ichiro.sato@example.com", "password": "correct horse battery"},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response TokenResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, TokenResponse{
+					AccessToken:  "access-token",
+					RefreshToken: "refresh-token",
+					TokenType:    "Bearer",
+					ExpiresIn:    900,
+					Scope:        "read:invoice write:invoice",
+				}, response)
+			},
+		},
+		{
+			name: "パスワードが違う",
+			setupMock: func(mockUsecase *testutils.MockAuthUsecase) {
+				mockUsecase.On("Login", dto).Return(nil, application.ErrInvalidCredentials)
+			},
+			payload:        map[string]interface{}{"email": "ichiro.sato@example.com", "password": "correct horse battery"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "パスワードがない",
+			setupMock:      func(mockUsecase *testutils.MockAuthUsecase) {}, // Mock is not called in this case
+			payload:        map[string]interface{}{"email": "ichiro.sato@example.com"},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockAuthUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewAuthHandler(mockUsecase, jose.JSONWebKeySet{})
+			handler.now = func() time.Time { return testNow }
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Login(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_AuthHandler_RefreshToken(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	mockUsecase := &testutils.MockAuthUsecase{}
+	mockUsecase.On("Refresh", application.RefreshTokenDto{RefreshToken: "expired-token", Now: testNow}).Return(nil, application.ErrInvalidCredentials)
+	handler := NewAuthHandler(mockUsecase, jose.JSONWebKeySet{})
+	handler.now = func() time.Time { return testNow }
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader([]byte(`{"refreshToken":"expired-token"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.RefreshToken(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
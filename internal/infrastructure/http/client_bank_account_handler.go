@@ -0,0 +1,276 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+type ClientBankAccountHandler struct {
+	usecase application.ClientBankAccountUsecase
+}
+
+func NewClientBankAccountHandler(usecase application.ClientBankAccountUsecase) *ClientBankAccountHandler {
+	return &ClientBankAccountHandler{usecase: usecase}
+}
+
+// BankAccountRequest 振込先口座の登録内容. コード・口座名義の形式はドメインモデルで検証する
+type BankAccountRequest struct {
+	BankCode      string `json:"bankCode" validate:"required"`                            // 必須, 金融機関コード（4桁）
+	BankName      string `json:"bankName" validate:"required"`                            // 必須, 銀行名
+	BranchCode    string `json:"branchCode" validate:"required"`                          // 必須, 支店コード（3桁）
+	BranchName    string `json:"branchName" validate:"required"`                          // 必須, 支店名
+	AccountType   string `json:"accountType" validate:"required,oneof=ordinary checking"` // 必須, 預金種目（普通・当座）
+	AccountNumber string `json:"accountNumber" validate:"required"`                       // 必須, 口座番号（7桁）
+	AccountName   string `json:"accountName" validate:"required"`                         // 必須, 口座名義（半角カナ）
+}
+
+type CreateBankAccountRequest struct {
+	BankAccountRequest
+	IsDefault bool `json:"isDefault"` // 既定の口座にする（取引先の最初の口座は常に既定）
+}
+
+type ClientBankAccountItem struct {
+	ID            uint   `json:"id"`            // 銀行口座ID
+	ClientID      uint   `json:"clientId"`      // 取引先ID
+	BankCode      string `json:"bankCode"`      // 金融機関コード
+	BankName      string `json:"bankName"`      // 銀行名
+	BranchCode    string `json:"branchCode"`    // 支店コード
+	BranchName    string `json:"branchName"`    // 支店名
+	AccountType   string `json:"accountType"`   // 預金種目
+	AccountNumber string `json:"accountNumber"` // 口座番号（末尾以外はマスク）
+	AccountName   string `json:"accountName"`   // 口座名義
+	IsDefault     bool   `json:"isDefault"`     // 支払に使う既定の口座かどうか
+}
+
+type ListBankAccountsResponse struct {
+	Data []ClientBankAccountItem `json:"data"`
+}
+
+func (h *ClientBankAccountHandler) ListBankAccounts(c echo.Context) error {
+	clientID, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	accounts, err := h.usecase.ListBankAccounts(application.ListBankAccountsDto{Principal: principal, ClientID: clientID})
+	if err != nil {
+		return bankAccountErrorResponse(c, err, "could not list bank accounts")
+	}
+
+	response := ListBankAccountsResponse{Data: make([]ClientBankAccountItem, len(accounts))}
+	for i := range accounts {
+		response.Data[i] = newClientBankAccountItem(&accounts[i])
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *ClientBankAccountHandler) GetBankAccount(c echo.Context) error {
+	clientID, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	id, ok := parseIDParam(c, "accountId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	account, err := h.usecase.GetBankAccount(application.GetBankAccountDto{Principal: principal, ClientID: clientID, ID: id})
+	if err != nil {
+		return bankAccountErrorResponse(c, err, "could not get bank account")
+	}
+
+	return c.JSON(http.StatusOK, newClientBankAccountItem(account))
+}
+
+func (h *ClientBankAccountHandler) CreateBankAccount(c echo.Context) error {
+	clientID, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	var req CreateBankAccountRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	account, err := h.usecase.CreateBankAccount(application.CreateBankAccountDto{
+		Principal:           principal,
+		ClientID:            clientID,
+		BankAccountInputDto: req.toInputDto(),
+		IsDefault:           req.IsDefault,
+	})
+	if err != nil {
+		return bankAccountErrorResponse(c, err, "could not create bank account")
+	}
+
+	return c.JSON(http.StatusCreated, newClientBankAccountItem(account))
+}
+
+// UpdateBankAccount 振込先口座の登録内容を置き換える. 既定の口座かどうかは変わらない
+func (h *ClientBankAccountHandler) UpdateBankAccount(c echo.Context) error {
+	clientID, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	id, ok := parseIDParam(c, "accountId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	var req BankAccountRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	account, err := h.usecase.UpdateBankAccount(application.UpdateBankAccountDto{
+		Principal:           principal,
+		ClientID:            clientID,
+		ID:                  id,
+		BankAccountInputDto: req.toInputDto(),
+	})
+	if err != nil {
+		return bankAccountErrorResponse(c, err, "could not update bank account")
+	}
+
+	return c.JSON(http.StatusOK, newClientBankAccountItem(account))
+}
+
+func (h *ClientBankAccountHandler) DeleteBankAccount(c echo.Context) error {
+	clientID, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	id, ok := parseIDParam(c, "accountId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	if err := h.usecase.DeleteBankAccount(application.DeleteBankAccountDto{Principal: principal, ClientID: clientID, ID: id}); err != nil {
+		return bankAccountErrorResponse(c, err, "could not delete bank account")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetDefaultBankAccount 支払に使う既定の口座を変更する
+func (h *ClientBankAccountHandler) SetDefaultBankAccount(c echo.Context) error {
+	clientID, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	id, ok := parseIDParam(c, "accountId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	account, err := h.usecase.SetDefaultBankAccount(application.SetDefaultBankAccountDto{Principal: principal, ClientID: clientID, ID: id})
+	if err != nil {
+		return bankAccountErrorResponse(c, err, "could not set default bank account")
+	}
+
+	return c.JSON(http.StatusOK, newClientBankAccountItem(account))
+}
+
+// bankAccountErrorResponse 振込先口座の管理のエラーをレスポンスに変換する
+func bankAccountErrorResponse(c echo.Context, err error, message string) error {
+	switch {
+	case isAuthorizationError(err):
+		return authorizationErrorResponse(c, err)
+	case errors.Is(err, commonErrors.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "client or bank account not found"})
+	case errors.Is(err, model.ErrClientArchived):
+		log.Printf("Client archived: %v", err)
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, model.ErrInvalidBankAccount):
+		log.Printf("Invalid bank account: %v", err)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Failed to manage bank account Error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
+
+// parseIDParam パスパラメータのIDを取得する. 正の整数でない場合は false を返す
+func parseIDParam(c echo.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		log.Printf("Invalid %s: %s", name, c.Param(name))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (req *BankAccountRequest) toInputDto() application.BankAccountInputDto {
+	return application.BankAccountInputDto{
+		BankCode:      req.BankCode,
+		BankName:      req.BankName,
+		BranchCode:    req.BranchCode,
+		BranchName:    req.BranchName,
+		AccountType:   req.AccountType,
+		AccountNumber: req.AccountNumber,
+		AccountName:   req.AccountName,
+	}
+}
+
+func newClientBankAccountItem(account *application.ClientBankAccountDto) ClientBankAccountItem {
+	return ClientBankAccountItem{
+		ID:            account.ID,
+		ClientID:      account.ClientID,
+		BankCode:      account.BankCode,
+		BankName:      account.BankName,
+		BranchCode:    account.BranchCode,
+		BranchName:    account.BranchName,
+		AccountType:   account.AccountType,
+		AccountNumber: account.MaskedAccountNumber,
+		AccountName:   account.AccountName,
+		IsDefault:     account.IsDefault,
+	}
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+// testBankAccountInput 振込先口座の登録内容のテストデータ
+var testBankAccountInput = application.BankAccountInputDto{
+	BankCode:      "0001",
+	BankName:      "みずほ銀行",
+	BranchCode:    "100",
+	BranchName:    "本店",
+	AccountType:   "ordinary",
+	AccountNumber: "1234567",
+	AccountName:   "ﾄﾘﾋｷｻｷｴｰ",
+}
+
+func testBankAccountPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"bankCode":      "0001",
+		"bankName":      "みずほ銀行",
+		"branchCode":    "100",
+		"branchName":    "本店",
+		"accountType":   "ordinary",
+		"accountNumber": "1234567",
+		"accountName":   "ﾄﾘﾋｷｻｷｴｰ",
+	}
+}
+
+func Test_ClientBankAccountHandler_CreateBankAccount(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.CreateBankAccountDto{Principal: testPrincipal, ClientID: 1, BankAccountInputDto: testBankAccountInput, IsDefault: true}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockClientBankAccountUsecase)
+		clientID       string
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockClientBankAccountUsecase) {
+				mockUsecase.On("CreateBankAccount", dto).Return(&application.ClientBankAccountDto{
+					ID: 4, ClientID: 1, BankCode: "0001", AccountType: "ordinary", MaskedAccountNumber: "****567", IsDefault: true,
+				}, nil)
+			},
+			clientID: "1",
+			payload: func() map[string]interface{} {
+				payload := testBankAccountPayload()
+				payload["isDefault"] = true
+				return payload
+			}(),
+			expectedStatus: http.StatusCreated,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response ClientBankAccountItem
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, uint(4), response.ID)
+				assert.Equal(t, "****567", response.AccountNumber)
+				assert.True(t, response.IsDefault)
+			},
+		},
+		{
+			name:      "預金種目が不正",
+			setupMock: func(mockUsecase *testutils.MockClientBankAccountUsecase) {}, // Mock is not called in this case
+			clientID:  "1",
+			payload: func() map[string]interface{} {
+				payload := testBankAccountPayload()
+				payload["accountType"] = "savings"
+				return payload
+			}(),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "口座番号の形式が不正",
+			setupMock: func(mockUsecase *testutils.MockClientBankAccountUsecase) {
+				mockUsecase.On("CreateBankAccount", dto).Return(nil, fmt.Errorf("%w: account number must be 7 digits", model.ErrInvalidBankAccount))
+			},
+			clientID: "1",
+			payload: func() map[string]interface{} {
+				payload := testBankAccountPayload()
+				payload["isDefault"] = true
+				return payload
+			}(),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid bank account: account number must be 7 digits", response["error"])
+			},
+		},
+		{
+			name:           "取引先IDが不正",
+			setupMock:      func(mockUsecase *testutils.MockClientBankAccountUsecase) {}, // Mock is not called in this case
+			clientID:       "abc",
+			payload:        testBankAccountPayload(),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockClientBankAccountUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewClientBankAccountHandler(mockUsecase)
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPost, "/clients/"+tt.clientID+"/bank-accounts", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.clientID)
+			c.Set("user", testClaims)
+
+			err := handler.CreateBankAccount(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_ClientBankAccountHandler_ListBankAccounts(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	mockUsecase := &testutils.MockClientBankAccountUsecase{}
+	mockUsecase.On("ListBankAccounts", application.ListBankAccountsDto{Principal: testPrincipal, ClientID: 1}).Return([]application.ClientBankAccountDto{
+		{ID: 1, ClientID: 1, MaskedAccountNumber: "****567", IsDefault: true},
+		{ID: 4, ClientID: 1, MaskedAccountNumber: "****321"},
+	}, nil)
+	handler := NewClientBankAccountHandler(mockUsecase)
+
+	req := httptest.NewRequest(http.MethodGet, "/clients/1/bank-accounts", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set("user", testClaims)
+
+	err := handler.ListBankAccounts(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response ListBankAccountsResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Len(t, response.Data, 2)
+	assert.Equal(t, "****321", response.Data[1].AccountNumber)
+	mockUsecase.AssertExpectations(t)
+}
+
+func Test_ClientBankAccountHandler_SetDefaultBankAccount(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.SetDefaultBankAccountDto{Principal: testPrincipal, ClientID: 1, ID: 4}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockClientBankAccountUsecase)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockClientBankAccountUsecase) {
+				mockUsecase.On("SetDefaultBankAccount", dto).Return(&application.ClientBankAccountDto{ID: 4, ClientID: 1, IsDefault: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "他の取引先の口座",
+			setupMock: func(mockUsecase *testutils.MockClientBankAccountUsecase) {
+				mockUsecase.On("SetDefaultBankAccount", dto).Return(nil, commonErrors.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "アーカイブした取引先",
+			setupMock: func(mockUsecase *testutils.MockClientBankAccountUsecase) {
+				mockUsecase.On("SetDefaultBankAccount", dto).Return(nil, model.ErrClientArchived)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockClientBankAccountUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewClientBankAccountHandler(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodPost, "/clients/1/bank-accounts/4/default", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id", "accountId")
+			c.SetParamValues("1", "4")
+			c.Set("user", testClaims)
+
+			err := handler.SetDefaultBankAccount(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
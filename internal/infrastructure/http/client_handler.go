@@ -0,0 +1,252 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+type ClientHandler struct {
+	usecase application.ClientUsecase
+	now     func() time.Time // アーカイブ日時に使う現在時刻
+}
+
+func NewClientHandler(usecase application.ClientUsecase) *ClientHandler {
+	return &ClientHandler{usecase: usecase, now: time.Now}
+}
+
+// ClientRequest 取引先の登録内容. 形式の詳細なチェックはドメインモデルで行う
+type ClientRequest struct {
+	Name                string `json:"name" validate:"required"`                                         // 必須, 取引先名
+	RegistrationNumber  string `json:"registrationNumber"`                                               // 適格請求書発行事業者の登録番号
+	Representative      string `json:"representative" validate:"required"`                               // 必須, 代表者名
+	PhoneNumber         string `json:"phoneNumber"`                                                      // 電話番号
+	PostalCode          string `json:"postalCode" validate:"required"`                                   // 必須, 郵便番号
+	Address             string `json:"address" validate:"required"`                                      // 必須, 住所
+	PayeeType           string `json:"payeeType" validate:"omitempty,oneof=corporation individual"`      // 支払先の区分（既定: corporation）
+	WithholdingCategory string `json:"withholdingCategory" validate:"omitempty,oneof=none remuneration"` // 源泉徴収の区分（既定: none）
+}
+
+type ListClientsRequest struct {
+	Name            string `query:"name"`                                     // 取引先名（部分一致）
+	IncludeArchived bool   `query:"includeArchived"`                          // アーカイブした取引先も含める
+	Cursor          string `query:"cursor"`                                   // 前のページの nextCursor
+	Limit           int    `query:"limit" validate:"omitempty,min=1,max=100"` // 1ページあたりの件数
+}
+
+type ClientItem struct {
+	ID                  uint       `json:"id"`                  // 取引先ID
+	Name                string     `json:"name"`                // 取引先名
+	RegistrationNumber  string     `json:"registrationNumber"`  // 適格請求書発行事業者の登録番号
+	Representative      string     `json:"representative"`      // 代表者名
+	PhoneNumber         string     `json:"phoneNumber"`         // 電話番号
+	PostalCode          string     `json:"postalCode"`          // 郵便番号
+	Address             string     `json:"address"`             // 住所
+	PayeeType           string     `json:"payeeType"`           // 支払先の区分
+	WithholdingCategory string     `json:"withholdingCategory"` // 源泉徴収の区分
+	ArchivedAt          *time.Time `json:"archivedAt"`          // アーカイブ日時（アーカイブしていない場合は null）
+}
+
+type ListClientsResponse struct {
+	Clients    []ClientItem `json:"clients"`
+	NextCursor string       `json:"nextCursor,omitempty"` // 次のページを取得するためのカーソル（最終ページでは省略）
+}
+
+func (h *ClientHandler) CreateClient(c echo.Context) error {
+	var req ClientRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	client, err := h.usecase.CreateClient(application.CreateClientDto{
+		Principal:      principal,
+		ClientInputDto: req.toInputDto(),
+	})
+	if err != nil {
+		return clientErrorResponse(c, err, "could not create client")
+	}
+
+	return c.JSON(http.StatusCreated, newClientItem(client))
+}
+
+// ListClients 取引先の一覧. 取引先名・IDの順に並べ、カーソルで次のページを取得する
+func (h *ClientHandler) ListClients(c echo.Context) error {
+	var req ListClientsRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	result, err := h.usecase.ListClients(application.ListClientsDto{
+		Principal:       principal,
+		Name:            req.Name,
+		IncludeArchived: req.IncludeArchived,
+		Cursor:          req.Cursor,
+		Limit:           req.Limit,
+	})
+	if err != nil {
+		return clientErrorResponse(c, err, "could not list clients")
+	}
+
+	response := ListClientsResponse{
+		Clients:    make([]ClientItem, len(result.Clients)),
+		NextCursor: result.NextCursor,
+	}
+	for i, client := range result.Clients {
+		response.Clients[i] = newClientItem(client)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *ClientHandler) GetClient(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		log.Printf("Invalid client id: %s", c.Param("id"))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	client, err := h.usecase.GetClient(application.GetClientDto{Principal: principal, ID: uint(id)})
+	if err != nil {
+		return clientErrorResponse(c, err, "could not get client")
+	}
+
+	return c.JSON(http.StatusOK, newClientItem(client))
+}
+
+// UpdateClient 取引先の登録内容を置き換える. アーカイブした取引先は更新できない
+func (h *ClientHandler) UpdateClient(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		log.Printf("Invalid client id: %s", c.Param("id"))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	var req ClientRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	client, err := h.usecase.UpdateClient(application.UpdateClientDto{
+		Principal:      principal,
+		ID:             uint(id),
+		ClientInputDto: req.toInputDto(),
+	})
+	if err != nil {
+		return clientErrorResponse(c, err, "could not update client")
+	}
+
+	return c.JSON(http.StatusOK, newClientItem(client))
+}
+
+// ArchiveClient 取引先をアーカイブする. 以降はその取引先への請求書を作成できない
+func (h *ClientHandler) ArchiveClient(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		log.Printf("Invalid client id: %s", c.Param("id"))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	client, err := h.usecase.ArchiveClient(application.ArchiveClientDto{Principal: principal, ID: uint(id), Now: h.now()})
+	if err != nil {
+		return clientErrorResponse(c, err, "could not archive client")
+	}
+
+	return c.JSON(http.StatusOK, newClientItem(client))
+}
+
+// clientErrorResponse 取引先の管理のエラーをレスポンスに変換する
+func clientErrorResponse(c echo.Context, err error, message string) error {
+	switch {
+	case isAuthorizationError(err):
+		return authorizationErrorResponse(c, err)
+	case errors.Is(err, commonErrors.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "client not found"})
+	case errors.Is(err, application.ErrInvalidCursor):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+	case errors.Is(err, model.ErrClientArchived):
+		log.Printf("Client archived: %v", err)
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, model.ErrInvalidClient):
+		log.Printf("Invalid client: %v", err)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Failed to manage client Error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
+
+func (req *ClientRequest) toInputDto() application.ClientInputDto {
+	return application.ClientInputDto{
+		Name:                req.Name,
+		RegistrationNumber:  req.RegistrationNumber,
+		Representative:      req.Representative,
+		PhoneNumber:         req.PhoneNumber,
+		PostalCode:          req.PostalCode,
+		Address:             req.Address,
+		PayeeType:           req.PayeeType,
+		WithholdingCategory: req.WithholdingCategory,
+	}
+}
+
+func newClientItem(client *application.ClientDetailDto) ClientItem {
+	return ClientItem{
+		ID:                  client.ID,
+		Name:                client.Name,
+		RegistrationNumber:  client.RegistrationNumber,
+		Representative:      client.Representative,
+		PhoneNumber:         client.PhoneNumber,
+		PostalCode:          client.PostalCode,
+		Address:             client.Address,
+		PayeeType:           client.PayeeType,
+		WithholdingCategory: client.WithholdingCategory,
+		ArchivedAt:          client.ArchivedAt,
+	}
+}
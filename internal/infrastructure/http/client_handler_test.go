@@ -0,0 +1,299 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+// testClientInput 取引先の登録内容のテストデータ
+var testClientInput = application.ClientInputDto{
+	Name:           "株式会社取引先",
+	Representative: "取引 太郎",
+	PhoneNumber:    "03-1234-5678",
+	PostalCode:     "100-0001",
+	Address:        "東京都千代田区千代田1-1",
+}
+
+func testClientPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"name":           "株式会社取引先",
+		"representative": "取引 太郎",
+		"phoneNumber":    "03-1234-5678",
+		"postalCode":     "100-0001",
+		"address":        "東京都千代田区千代田1-1",
+	}
+}
+
+func Test_ClientHandler_CreateClient(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.CreateClientDto{Principal: testPrincipal, ClientInputDto: testClientInput}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockClientUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockClientUsecase) {
+				mockUsecase.On("CreateClient", dto).Return(&application.ClientDetailDto{
+					ClientDto:           application.ClientDto{ID: 4, Name: "株式会社取引先"},
+					PayeeType:           "corporation",
+					WithholdingCategory: "none",
+				}, nil)
+			},
+			payload:        testClientPayload(),
+			expectedStatus: http.StatusCreated,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, float64(4), response["id"])
+				assert.Equal(t, "corporation", response["payeeType"])
+				assert.Nil(t, response["archivedAt"])
+			},
+		},
+		{
+			name:      "取引先名がない",
+			setupMock: func(mockUsecase *testutils.MockClientUsecase) {}, // Mock is not called in this case
+			payload: func() map[string]interface{} {
+				payload := testClientPayload()
+				delete(payload, "name")
+				return payload
+			}(),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "電話番号の形式が不正",
+			setupMock: func(mockUsecase *testutils.MockClientUsecase) {
+				mockUsecase.On("CreateClient", dto).Return(nil, fmt.Errorf("%w: phone number is invalid", model.ErrInvalidClient))
+			},
+			payload:        testClientPayload(),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid client: phone number is invalid", response["error"])
+			},
+		},
+		{
+			name: "組織を特定できない",
+			setupMock: func(mockUsecase *testutils.MockClientUsecase) {
+				mockUsecase.On("CreateClient", dto).Return(nil, commonErrors.ErrUnauthorized)
+			},
+			payload:        testClientPayload(),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockClientUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewClientHandler(mockUsecase)
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPost, "/clients", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			err := handler.CreateClient(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_ClientHandler_ListClients(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockClientUsecase)
+		query          string
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "検索条件とカーソルを渡す",
+			setupMock: func(mockUsecase *testutils.MockClientUsecase) {
+				mockUsecase.On("ListClients", application.ListClientsDto{
+					Principal: testPrincipal, Name: "商事", IncludeArchived: true, Cursor: "abc", Limit: 2,
+				}).Return(&application.ClientListDto{
+					Clients: []*application.ClientDetailDto{
+						{ClientDto: application.ClientDto{ID: 1, Name: "A商事"}},
+						{ClientDto: application.ClientDto{ID: 2, Name: "B商事"}},
+					},
+					NextCursor: "def",
+				}, nil)
+			},
+			query:          "name=商事&includeArchived=true&cursor=abc&limit=2",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response ListClientsResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Len(t, response.Clients, 2)
+				assert.Equal(t, "def", response.NextCursor)
+			},
+		},
+		{
+			name:           "件数が上限を超える",
+			setupMock:      func(mockUsecase *testutils.MockClientUsecase) {}, // Mock is not called in this case
+			query:          "limit=101",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "不正なカーソル",
+			setupMock: func(mockUsecase *testutils.MockClientUsecase) {
+				mockUsecase.On("ListClients", application.ListClientsDto{Principal: testPrincipal, Cursor: "!!"}).Return(nil, application.ErrInvalidCursor)
+			},
+			query:          "cursor=!!",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockClientUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewClientHandler(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodGet, "/clients?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			err := handler.ListClients(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_ClientHandler_UpdateClient(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.UpdateClientDto{Principal: testPrincipal, ID: 1, ClientInputDto: testClientInput}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockClientUsecase)
+		id             string
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockClientUsecase) {
+				mockUsecase.On("UpdateClient", dto).Return(&application.ClientDetailDto{ClientDto: application.ClientDto{ID: 1}}, nil)
+			},
+			id:             "1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "他組織の取引先",
+			setupMock: func(mockUsecase *testutils.MockClientUsecase) {
+				mockUsecase.On("UpdateClient", dto).Return(nil, commonErrors.ErrNotFound)
+			},
+			id:             "1",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "アーカイブした取引先",
+			setupMock: func(mockUsecase *testutils.MockClientUsecase) {
+				mockUsecase.On("UpdateClient", dto).Return(nil, model.ErrClientArchived)
+			},
+			id:             "1",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "IDが不正",
+			setupMock:      func(mockUsecase *testutils.MockClientUsecase) {}, // Mock is not called in this case
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockClientUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewClientHandler(mockUsecase)
+
+			reqBody, _ := json.Marshal(testClientPayload())
+			req := httptest.NewRequest(http.MethodPut, "/clients/"+tt.id, bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			c.Set("user", testClaims)
+
+			err := handler.UpdateClient(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_ClientHandler_ArchiveClient(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	mockUsecase := &testutils.MockClientUsecase{}
+	mockUsecase.On("ArchiveClient", application.ArchiveClientDto{Principal: testPrincipal, ID: 1, Now: testNow}).Return(&application.ClientDetailDto{
+		ClientDto:  application.ClientDto{ID: 1},
+		ArchivedAt: &testNow,
+	}, nil)
+	handler := NewClientHandler(mockUsecase)
+	handler.now = func() time.Time { return testNow }
+
+	req := httptest.NewRequest(http.MethodPost, "/clients/1/archive", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set("user", testClaims)
+
+	err := handler.ArchiveClient(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "2025-06-01T12:00:00Z", response["archivedAt"])
+	mockUsecase.AssertExpectations(t)
+}
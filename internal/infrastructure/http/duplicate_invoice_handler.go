@@ -0,0 +1,82 @@
+package http
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/shared/types"
+)
+
+// DuplicateInvoiceErrorResponse 重複の疑いがある請求書があるため作成しなかった場合のレスポンス
+type DuplicateInvoiceErrorResponse struct {
+	Error      string        `json:"error"`
+	Duplicates []InvoiceItem `json:"duplicates"` // 重複の疑いがある既存の請求書
+}
+
+func newDuplicateInvoiceErrorResponse(err *application.DuplicateInvoiceError) DuplicateInvoiceErrorResponse {
+	response := DuplicateInvoiceErrorResponse{
+		Error:      "likely duplicate invoice exists. set confirmDuplicate to create it anyway",
+		Duplicates: make([]InvoiceItem, len(err.Candidates)),
+	}
+	for i, candidate := range err.Candidates {
+		response.Duplicates[i] = newInvoiceItem(candidate)
+	}
+	return response
+}
+
+type ReportDuplicateInvoicesRequest struct {
+	IssueStartDate types.CustomDate `query:"issueStartDate"` // 発行日（開始）
+	IssueEndDate   types.CustomDate `query:"issueEndDate"`   // 発行日（終了）
+}
+
+type DuplicateInvoiceReportResponse struct {
+	Groups []DuplicateInvoiceGroup `json:"groups"`
+}
+
+// DuplicateInvoiceGroup 互いに重複の疑いがある請求書のまとまり
+type DuplicateInvoiceGroup struct {
+	Invoices []InvoiceItem `json:"invoices"` // 発行日の昇順
+}
+
+// ReportDuplicateInvoices 組織の請求書のうち重複の疑いがあるものを一覧する
+func (h *InvoiceHandler) ReportDuplicateInvoices(c echo.Context) error {
+	var req ReportDuplicateInvoicesRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if !req.IssueStartDate.IsZero() && !req.IssueEndDate.IsZero() && req.IssueStartDate.After(req.IssueEndDate.Time) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	report, err := h.usecase.ReportDuplicateInvoices(application.ReportDuplicateInvoicesDto{
+		Principal:      principal,
+		IssueStartDate: req.IssueStartDate.Time,
+		IssueEndDate:   req.IssueEndDate.Time,
+	})
+	if err != nil {
+		switch {
+		case isAuthorizationError(err):
+			return authorizationErrorResponse(c, err)
+		}
+		log.Printf("Failed to report duplicate invoices Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not report duplicate invoices"})
+	}
+
+	response := DuplicateInvoiceReportResponse{Groups: make([]DuplicateInvoiceGroup, len(report.Groups))}
+	for i, group := range report.Groups {
+		response.Groups[i].Invoices = make([]InvoiceItem, len(group.Invoices))
+		for j, invoice := range group.Invoices {
+			response.Groups[i].Invoices[j] = newInvoiceItem(invoice)
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+func Test_InvoiceHandler_ReportDuplicateInvoices(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	newInvoice := func(id uint, day int) *application.InvoiceDto {
+		return &application.InvoiceDto{
+			ID:             id,
+			OrganizationID: 1,
+			ClientID:       1,
+			IssueDate:      time.Date(2024, 4, day, 0, 0, 0, 0, time.UTC),
+			Amount:         10000,
+			DueDate:        time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+			Status:         "pending",
+		}
+	}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockInvoiceUsecase)
+		query          string
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ReportDuplicateInvoices", application.ReportDuplicateInvoicesDto{
+					Principal: testPrincipal,
+				}).Return(&application.DuplicateInvoiceReportDto{Groups: []application.DuplicateInvoiceGroupDto{
+					{Invoices: []*application.InvoiceDto{newInvoice(1, 1), newInvoice(2, 3)}},
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response DuplicateInvoiceReportResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Len(t, response.Groups, 1)
+				assert.Len(t, response.Groups[0].Invoices, 2)
+				assert.Equal(t, uint(1), response.Groups[0].Invoices[0].ID)
+				assert.Equal(t, "2024-04-03", response.Groups[0].Invoices[1].IssueDate.Format("2006-01-02"))
+			},
+		},
+		{
+			name: "発行日で絞り込む, 重複がない場合は空の一覧",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ReportDuplicateInvoices", application.ReportDuplicateInvoicesDto{
+					Principal:      testPrincipal,
+					IssueStartDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+					IssueEndDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+				}).Return(&application.DuplicateInvoiceReportDto{Groups: []application.DuplicateInvoiceGroupDto{}}, nil)
+			},
+			query:          "issueStartDate=2024-04-01&issueEndDate=2024-04-30",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"groups":[]}`, rec.Body.String())
+			},
+		},
+		{
+			name:           "発行日の開始が終了より後の場合, validation failed",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			query:          "issueStartDate=2024-05-01&issueEndDate=2024-04-30",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name:           "発行日のformatが不正の場合, invalid request",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			query:          "issueStartDate=2024/04/01",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid request", response["error"])
+			},
+		},
+		{
+			name: "権限がない場合, forbidden",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ReportDuplicateInvoices", application.ReportDuplicateInvoicesDto{
+					Principal: testPrincipal,
+				}).Return(nil, commonErrors.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "insufficient permission", response["error"])
+			},
+		},
+		{
+			name: "usecaseでエラーが発生した場合, could not report duplicate invoices",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ReportDuplicateInvoices", application.ReportDuplicateInvoicesDto{
+					Principal: testPrincipal,
+				}).Return(nil, errors.New("unexpected error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "could not report duplicate invoices", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 新しいモックインスタンスを作成
+			mockUsecase := &testutils.MockInvoiceUsecase{}
+			tt.setupMock(mockUsecase)
+
+			// ハンドラを新規作成
+			handler := NewInvoiceHandler(mockUsecase)
+
+			// リクエストのセットアップ
+			req := httptest.NewRequest(http.MethodGet, "/invoice/duplicates?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			// ハンドラの実行
+			err := handler.ReportDuplicateInvoices(c)
+			assert.NoError(t, err)
+
+			// ステータスコードとレスポンスボディの検証
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			tt.expectedBody(t, rec)
+
+			// モックの呼び出しを検証
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
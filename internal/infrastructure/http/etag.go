@@ -0,0 +1,53 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+var (
+	// errIfMatchRequired 更新に必要な If-Match ヘッダーがない
+	errIfMatchRequired = errors.New("If-Match header is required")
+	// errInvalidETag If-Match ヘッダーの値がこのAPIの返したETagの形式でない
+	errInvalidETag = errors.New("invalid If-Match header")
+)
+
+// formatETag 版数を強いETag（"版数"）で表す
+func formatETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// parseETag formatETag で表した版数を取り出す. 弱いETag（W/）・複数の指定・"*" は受け付けない
+func parseETag(value string) (uint, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 3 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errInvalidETag
+	}
+	version, err := strconv.ParseUint(value[1:len(value)-1], 10, 32)
+	if err != nil || version == 0 {
+		return 0, errInvalidETag
+	}
+	return uint(version), nil
+}
+
+// setETag レスポンスの ETag ヘッダーに版数を設定する
+func setETag(c echo.Context, version uint) {
+	c.Response().Header().Set(HeaderETag, formatETag(version))
+}
+
+// ifMatchVersion If-Match ヘッダーから更新の前提とする版数を取得する
+func ifMatchVersion(c echo.Context) (uint, error) {
+	header := c.Request().Header.Get(HeaderIfMatch)
+	if header == "" {
+		return 0, errIfMatchRequired
+	}
+	return parseETag(header)
+}
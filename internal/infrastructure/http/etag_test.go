@@ -0,0 +1,33 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseETag(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantVersion uint
+		wantErr     error
+	}{
+		{name: "強いETag", value: `"3"`, wantVersion: 3},
+		{name: "前後の空白は無視する", value: ` "12" `, wantVersion: 12},
+		{name: "弱いETag", value: `W/"3"`, wantErr: errInvalidETag},
+		{name: "*", value: "*", wantErr: errInvalidETag},
+		{name: "複数の指定", value: `"1", "2"`, wantErr: errInvalidETag},
+		{name: "引用符がない", value: "3", wantErr: errInvalidETag},
+		{name: "数値でない", value: `"abc"`, wantErr: errInvalidETag},
+		{name: "版数が0", value: `"0"`, wantErr: errInvalidETag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := parseETag(tt.value)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
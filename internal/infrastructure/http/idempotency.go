@@ -0,0 +1,229 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/middleware"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed" // 保存した応答を返した場合に true
+)
+
+// Idempotency Idempotency-Key ヘッダーをもつ変更系のリクエスト（POST / PUT / PATCH / DELETE）の再送を検出する.
+// キーは操作主体ごとに区別するため、認証のミドルウェアの後に設定する.
+// 5xx の応答は保存せず、同じキーで再送すると処理し直す
+func Idempotency(usecase application.IdempotencyUsecase) echo.MiddlewareFunc {
+	return idempotency(usecase, time.Now, true, principalScope)
+}
+
+// IdempotencyWithoutReplay 招待トークン・APIキーなど秘密を含む応答を返すエンドポイント用の Idempotency.
+// キーは同じく予約するが、成功した応答のボディ・ヘッダーは保存せず、同じリクエストの再送は処理し直さずに 409 Conflict とする
+func IdempotencyWithoutReplay(usecase application.IdempotencyUsecase) echo.MiddlewareFunc {
+	return idempotency(usecase, time.Now, false, principalScope)
+}
+
+// IdempotencyByInvitationToken 認証しない招待の承諾用の IdempotencyWithoutReplay.
+// 操作主体の代わりにボディの招待トークンのハッシュでキーを区別する. 承諾した後の再送は 409 Conflict とする
+func IdempotencyByInvitationToken(usecase application.IdempotencyUsecase) echo.MiddlewareFunc {
+	return idempotency(usecase, time.Now, false, invitationTokenScope)
+}
+
+// idempotencyScope キーの名前空間を返す. 名前空間を決められないリクエストは false を返し、再送を検出しない
+type idempotencyScope func(c echo.Context) (string, bool)
+
+// principalScope 操作主体（組織・sub）ごとの名前空間
+func principalScope(c echo.Context) (string, bool) {
+	claims, ok := c.Get("user").(*middleware.CustomClaims)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%d/%s", claims.OrganizationID, claims.Subject), true
+}
+
+// invitationTokenScope ボディの招待トークンごとの名前空間. トークン自体は保存しないよう SHA-256 にする
+func invitationTokenScope(c echo.Context) (string, bool) {
+	req := c.Request()
+	if req.Body == nil {
+		return "", false
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", false
+	}
+
+	var payload struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Token == "" {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(payload.Token))
+	return "invitation/" + hex.EncodeToString(sum[:]), true
+}
+
+// errIdempotentResponseNotStored 秘密を含むため保存しなかった応答の再送
+var errIdempotentResponseNotStored = errors.New("request with the same idempotency key was already processed. its response is not stored because it contains a secret")
+
+func idempotency(usecase application.IdempotencyUsecase, now func() time.Time, replaySuccess bool, scopeOf idempotencyScope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" || !isMutatingMethod(c.Request().Method) {
+				return next(c)
+			}
+			scope, ok := scopeOf(c)
+			if !ok {
+				return next(c)
+			}
+
+			requestHash, err := hashRequest(c.Request())
+			if err != nil {
+				log.Printf("Failed to read request body Error: %v", err)
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+			}
+
+			reserved, err := usecase.Begin(application.BeginIdempotentRequestDto{
+				Scope:       scope,
+				Key:         key,
+				RequestHash: requestHash,
+				Now:         now(),
+			})
+			if err != nil {
+				return idempotencyErrorResponse(c, err)
+			}
+			if reserved.Response != nil {
+				if !replaySuccess && isSuccessStatus(reserved.Response.StatusCode) {
+					return c.JSON(http.StatusConflict, map[string]string{"error": errIdempotentResponseNotStored.Error()})
+				}
+				for name, values := range reserved.Response.Headers {
+					c.Response().Header()[name] = values
+				}
+				c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				return c.Blob(reserved.Response.StatusCode, reserved.Response.ContentType, reserved.Response.Body)
+			}
+
+			headersBefore := c.Response().Header().Clone()
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			err = next(c)
+			c.Response().Writer = recorder.ResponseWriter
+
+			if err != nil || c.Response().Status >= http.StatusInternalServerError {
+				if releaseErr := usecase.Release(application.ReleaseIdempotentRequestDto{ID: reserved.ID}); releaseErr != nil {
+					log.Printf("Failed to release idempotency key Error: %v", releaseErr)
+				}
+				return err
+			}
+
+			response := application.IdempotentResponseDto{
+				StatusCode:  c.Response().Status,
+				ContentType: c.Response().Header().Get(echo.HeaderContentType),
+				Headers:     handlerHeaders(headersBefore, c.Response().Header()),
+				Body:        recorder.body.Bytes(),
+			}
+			if !replaySuccess && isSuccessStatus(response.StatusCode) {
+				// 秘密を含むため、処理したことだけを記録する
+				response.Headers = nil
+				response.Body = nil
+			}
+			// 応答は送信済みのため、保存に失敗してもリクエストは成功とする. 再送は予約の有効期間を過ぎると処理し直す
+			if completeErr := usecase.Complete(application.CompleteIdempotentRequestDto{
+				ID:       reserved.ID,
+				Response: response,
+				Now:      now(),
+			}); completeErr != nil {
+				log.Printf("Failed to store idempotent response Error: %v", completeErr)
+			}
+			return nil
+		}
+	}
+}
+
+// isMutatingMethod 変更系のメソッドかどうか
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isSuccessStatus 2xx の応答かどうか
+func isSuccessStatus(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+// hashRequest メソッド・パス（クエリを含む）・ボディのSHA-256を16進数で返す. ボディは後続のハンドラーのために読み直せるようにする
+func hashRequest(req *http.Request) (string, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", req.Method, req.URL.RequestURI())
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// handlerHeaders ハンドラーが設定した応答のヘッダーを返す. Content-Type は別に保存し、Content-Length は再送時に計算し直す
+func handlerHeaders(before, after http.Header) map[string][]string {
+	headers := map[string][]string{}
+	for name, values := range after {
+		if name == echo.HeaderContentType || name == echo.HeaderContentLength {
+			continue
+		}
+		if slices.Equal(before[name], values) {
+			continue
+		}
+		headers[name] = values
+	}
+	return headers
+}
+
+// responseRecorder 保存するために応答のボディを記録する
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// idempotencyErrorResponse 冪等キーの予約のエラーをレスポンスに変換する
+func idempotencyErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, model.ErrInvalidIdempotencyKey):
+		log.Printf("Invalid idempotency key: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, application.ErrIdempotencyKeyReused):
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, application.ErrIdempotencyKeyInProgress):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Failed to check idempotency key Error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not check idempotency key"})
+}
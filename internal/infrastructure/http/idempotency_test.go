@@ -0,0 +1,331 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+)
+
+func Test_Idempotency(t *testing.T) {
+	const body = `{"clientId":1}`
+	// リクエストのハッシュは Test_hashRequest で確認するため、ここでは形式だけを比較する
+	matchBegin := mock.MatchedBy(func(dto application.BeginIdempotentRequestDto) bool {
+		return dto.Scope == "1/auth0|user1" && dto.Key == "order-1" && len(dto.RequestHash) == 64 && dto.Now.Equal(testNow)
+	})
+
+	tests := []struct {
+		name            string
+		method          string
+		key             string
+		withoutReplay   bool // 秘密を含む応答を返すエンドポイントの場合
+		handlerStatus   int
+		setupMock       func(*testutils.MockIdempotencyUsecase)
+		expectedStatus  int
+		expectedCalls   int // ハンドラーが呼ばれた回数
+		expectedReplay  bool
+		expectedMessage string
+	}{
+		{
+			name:           "キーを指定しない場合は検出しない",
+			method:         http.MethodPost,
+			handlerStatus:  http.StatusCreated,
+			setupMock:      func(mockUsecase *testutils.MockIdempotencyUsecase) {},
+			expectedStatus: http.StatusCreated,
+			expectedCalls:  1,
+		},
+		{
+			name:           "参照系のメソッドは検出しない",
+			method:         http.MethodGet,
+			key:            "order-1",
+			handlerStatus:  http.StatusOK,
+			setupMock:      func(mockUsecase *testutils.MockIdempotencyUsecase) {},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+		{
+			name:          "初回のリクエストは処理して応答を保存する",
+			method:        http.MethodPost,
+			key:           "order-1",
+			handlerStatus: http.StatusCreated,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(&application.IdempotentRequestDto{ID: 1}, nil)
+				mockUsecase.On("Complete", mock.MatchedBy(func(dto application.CompleteIdempotentRequestDto) bool {
+					return dto.ID == 1 && dto.Response.StatusCode == http.StatusCreated &&
+						dto.Response.ContentType == echo.MIMEApplicationJSON && string(dto.Response.Body) == "{\"id\":10}\n" &&
+						// ハンドラーが設定したヘッダーだけを保存する
+						reflect.DeepEqual(dto.Response.Headers, map[string][]string{"Etag": {`"1"`}, "Location": {"/invoice/10"}})
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedCalls:  1,
+		},
+		{
+			name:          "再送には保存した応答を返す",
+			method:        http.MethodPost,
+			key:           "order-1",
+			handlerStatus: http.StatusCreated,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(&application.IdempotentRequestDto{ID: 1, Response: &application.IdempotentResponseDto{
+					StatusCode:  http.StatusCreated,
+					ContentType: echo.MIMEApplicationJSON,
+					Headers:     map[string][]string{"Etag": {`"1"`}, "Location": {"/invoice/10"}},
+					Body:        []byte("{\"id\":10}\n"),
+				}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedCalls:  0,
+			expectedReplay: true,
+		},
+		{
+			name:          "秘密を含む応答はボディ・ヘッダーを保存しない",
+			method:        http.MethodPost,
+			key:           "order-1",
+			withoutReplay: true,
+			handlerStatus: http.StatusCreated,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(&application.IdempotentRequestDto{ID: 1}, nil)
+				mockUsecase.On("Complete", mock.MatchedBy(func(dto application.CompleteIdempotentRequestDto) bool {
+					return dto.ID == 1 && dto.Response.StatusCode == http.StatusCreated &&
+						dto.Response.Body == nil && dto.Response.Headers == nil
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedCalls:  1,
+		},
+		{
+			name:          "秘密を含む応答を返したリクエストの再送は処理せず拒否する",
+			method:        http.MethodPost,
+			key:           "order-1",
+			withoutReplay: true,
+			handlerStatus: http.StatusCreated,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(&application.IdempotentRequestDto{ID: 1, Response: &application.IdempotentResponseDto{
+					StatusCode: http.StatusCreated, ContentType: echo.MIMEApplicationJSON,
+				}}, nil)
+			},
+			expectedStatus:  http.StatusConflict,
+			expectedCalls:   0,
+			expectedMessage: errIdempotentResponseNotStored.Error(),
+		},
+		{
+			name:          "秘密を含まない失敗の応答は再送に返す",
+			method:        http.MethodPost,
+			key:           "order-1",
+			withoutReplay: true,
+			handlerStatus: http.StatusCreated,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(&application.IdempotentRequestDto{ID: 1, Response: &application.IdempotentResponseDto{
+					StatusCode: http.StatusUnprocessableEntity, ContentType: echo.MIMEApplicationJSON, Body: []byte(`{"error":"invalid user"}`),
+				}}, nil)
+			},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCalls:   0,
+			expectedMessage: "invalid user",
+		},
+		{
+			name:          "5xx の応答は保存せず予約を取り消す",
+			method:        http.MethodPost,
+			key:           "order-1",
+			handlerStatus: http.StatusInternalServerError,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(&application.IdempotentRequestDto{ID: 1}, nil)
+				mockUsecase.On("Release", application.ReleaseIdempotentRequestDto{ID: 1}).Return(nil)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCalls:  1,
+		},
+		{
+			name:          "異なるリクエストへの再利用",
+			method:        http.MethodPost,
+			key:           "order-1",
+			handlerStatus: http.StatusCreated,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(nil, application.ErrIdempotencyKeyReused)
+			},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: application.ErrIdempotencyKeyReused.Error(),
+		},
+		{
+			name:          "同じキーのリクエストを処理中",
+			method:        http.MethodPost,
+			key:           "order-1",
+			handlerStatus: http.StatusCreated,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(nil, application.ErrIdempotencyKeyInProgress)
+			},
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: application.ErrIdempotencyKeyInProgress.Error(),
+		},
+		{
+			name:          "不正なキー",
+			method:        http.MethodPost,
+			key:           "order-1",
+			handlerStatus: http.StatusCreated,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(nil, model.ErrInvalidIdempotencyKey)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockIdempotencyUsecase{}
+			tt.setupMock(mockUsecase)
+
+			calls := 0
+			e := echo.New()
+			setClaims := func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set("user", testClaims)
+					return next(c)
+				}
+			}
+			e.Add(tt.method, "/invoice", func(c echo.Context) error {
+				calls++
+				// ハンドラーがボディを読めること
+				var req map[string]int
+				if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil || req["clientId"] != 1 {
+					return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+				}
+				c.Response().Header().Set(HeaderETag, `"1"`)
+				c.Response().Header().Set(echo.HeaderLocation, "/invoice/10")
+				return c.JSON(tt.handlerStatus, map[string]int{"id": 10})
+			}, setClaims, idempotency(mockUsecase, func() time.Time { return testNow }, !tt.withoutReplay, principalScope))
+
+			req := httptest.NewRequest(tt.method, "/invoice", bytes.NewReader([]byte(body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.key != "" {
+				req.Header.Set(HeaderIdempotencyKey, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedCalls, calls)
+			if tt.expectedReplay {
+				assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+				assert.Equal(t, "{\"id\":10}\n", rec.Body.String())
+				assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+				assert.Equal(t, `"1"`, rec.Header().Get(HeaderETag))
+				assert.Equal(t, "/invoice/10", rec.Header().Get(echo.HeaderLocation))
+			}
+			if tt.expectedMessage != "" {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedMessage, response["error"])
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IdempotencyByInvitationToken(t *testing.T) {
+	const body = `{"token":"invitation-token","password":"correct horse battery"}`
+	// 招待トークン自体ではなく SHA-256 で区別する
+	matchBegin := mock.MatchedBy(func(dto application.BeginIdempotentRequestDto) bool {
+		return dto.Scope == "invitation/"+hashInvitationTokenForTest("invitation-token") && dto.Key == "accept-1" && len(dto.RequestHash) == 64
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*testutils.MockIdempotencyUsecase)
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{
+			name: "認証なしでも招待トークンごとにキーを予約する",
+			body: body,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(&application.IdempotentRequestDto{ID: 1}, nil)
+				mockUsecase.On("Complete", mock.MatchedBy(func(dto application.CompleteIdempotentRequestDto) bool {
+					return dto.ID == 1 && dto.Response.StatusCode == http.StatusOK && dto.Response.Body == nil
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+		{
+			name: "承諾した後の再送は処理せず拒否する",
+			body: body,
+			setupMock: func(mockUsecase *testutils.MockIdempotencyUsecase) {
+				mockUsecase.On("Begin", matchBegin).Return(&application.IdempotentRequestDto{ID: 1, Response: &application.IdempotentResponseDto{
+					StatusCode: http.StatusOK, ContentType: echo.MIMEApplicationJSON,
+				}}, nil)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCalls:  0,
+		},
+		{
+			name:           "招待トークンがない場合は検出せずハンドラーで検証する",
+			body:           `{"password":"correct horse battery"}`,
+			setupMock:      func(mockUsecase *testutils.MockIdempotencyUsecase) {},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockIdempotencyUsecase{}
+			tt.setupMock(mockUsecase)
+
+			calls := 0
+			e := echo.New()
+			e.POST("/users/invitations/accept", func(c echo.Context) error {
+				calls++
+				// ハンドラーがボディを読めること
+				var req map[string]string
+				if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil || req["password"] == "" {
+					return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+				}
+				return c.JSON(http.StatusOK, map[string]int{"id": 1})
+			}, idempotency(mockUsecase, func() time.Time { return testNow }, false, invitationTokenScope))
+
+			req := httptest.NewRequest(http.MethodPost, "/users/invitations/accept", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(HeaderIdempotencyKey, "accept-1")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedCalls, calls)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func hashInvitationTokenForTest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func Test_hashRequest(t *testing.T) {
+	hash := func(method, target, body string) string {
+		t.Helper()
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		h, err := hashRequest(req)
+		assert.NoError(t, err)
+		return h
+	}
+
+	base := hash(http.MethodPost, "/invoice", `{"clientId":1}`)
+	assert.Equal(t, base, hash(http.MethodPost, "/invoice", `{"clientId":1}`))
+	assert.NotEqual(t, base, hash(http.MethodPost, "/invoice", `{"clientId":2}`))
+	assert.NotEqual(t, base, hash(http.MethodPut, "/invoice", `{"clientId":1}`))
+	assert.NotEqual(t, base, hash(http.MethodPost, "/clients", `{"clientId":1}`))
+}
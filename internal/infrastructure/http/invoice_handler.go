@@ -4,9 +4,13 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/middleware"
 	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 	"github.com/take73/invoice-api-example/internal/shared/types"
 )
@@ -19,16 +23,29 @@ func NewInvoiceHandler(usecase application.InvoiceUsecase) *InvoiceHandler {
 	return &InvoiceHandler{usecase: usecase}
 }
 
+// CreateInvoiceRequest 請求元企業はトークンの操作主体から解決するため、リクエストには含めない
 type CreateInvoiceRequest struct {
-	UserID    uint             `json:"userId" validate:"required,gt=0"`           // 必須, 0より大きい
-	ClientID  uint             `json:"clientId" validate:"required,gt=0"`         // 必須, 0より大きい
-	IssueDate types.CustomDate `json:"issueDate" validate:"required_custom_date"` // 必須
-	Amount    int64            `json:"amount"`                                    // TODO: 現状マイナスを許容しているので要確認
-	DueDate   types.CustomDate `json:"dueDate" validate:"required_custom_date"`   // 必須
+	ClientID  uint              `json:"clientId" validate:"required,gt=0"`         // 必須, 0より大きい
+	IssueDate types.CustomDate  `json:"issueDate" validate:"required_custom_date"` // 必須
+	Amount    int64             `json:"amount"`                                    // TODO: 現状マイナスを許容しているので要確認
+	DueDate   types.CustomDate  `json:"dueDate"`                                   // 省略した場合は組織の設定の支払期日までの日数から求める
+	LineItems []LineItemRequest `json:"lineItems" validate:"max=100,dive"`         // 明細（指定した場合は明細から支払金額を計算する）
+	// RequireQualifiedInvoice true の場合、適格請求書の記載事項が不足していれば作成しない
+	RequireQualifiedInvoice bool `json:"requireQualifiedInvoice"`
+	// ConfirmDuplicate true の場合、重複の疑いがある請求書があっても作成する
+	ConfirmDuplicate bool `json:"confirmDuplicate"`
+}
+
+type LineItemRequest struct {
+	Description string  `json:"description" validate:"required,max=255"`                                   // 品目
+	Quantity    float64 `json:"quantity" validate:"gt=0"`                                                  // 数量
+	UnitPrice   int64   `json:"unitPrice"`                                                                 // 単価（税抜）
+	TaxCategory string  `json:"taxCategory" validate:"required,oneof=standard reduced exempt non_taxable"` // 税区分
 }
 
 type CreateInvoiceResponse struct {
 	InvoiceItem
+	Warnings []string `json:"warnings,omitempty"` // 適格請求書の記載事項のうち不足しているもの
 }
 
 func (h *InvoiceHandler) CreateInvoice(c echo.Context) error {
@@ -44,72 +61,191 @@ func (h *InvoiceHandler) CreateInvoice(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
 	}
 
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
 	invoice := application.CreateInvoiceDto{
-		UserID:    req.UserID,
+		Principal: principal,
 		ClientID:  req.ClientID,
 		IssueDate: req.IssueDate.Time,
 		Amount:    req.Amount,
 		DueDate:   req.DueDate.Time,
+
+		RequireQualifiedInvoice: req.RequireQualifiedInvoice,
+		ConfirmDuplicate:        req.ConfirmDuplicate,
+	}
+	for _, item := range req.LineItems {
+		invoice.LineItems = append(invoice.LineItems, application.CreateInvoiceLineItemDto{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TaxCategory: item.TaxCategory,
+		})
 	}
 
 	// 登録処理
 	createdInvoice, err := h.usecase.CreateInvoice(invoice)
 	if err != nil {
+		if isAuthorizationError(err) {
+			return authorizationErrorResponse(c, err)
+		}
 		if errors.Is(err, commonErrors.ErrNotFound) {
 			log.Printf("Related entity not found: %v", err)
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "related company or client not found"})
 		}
+		if errors.Is(err, model.ErrClientNotInOrganization) {
+			log.Printf("Client of another organization: %v", err)
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "client does not belong to the organization"})
+		}
+		if errors.Is(err, model.ErrClientArchived) {
+			log.Printf("Client archived: %v", err)
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		var qualifiedErr *model.QualifiedInvoiceError
+		if errors.As(err, &qualifiedErr) {
+			log.Printf("Not a qualified invoice: %v", err)
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		var duplicateErr *application.DuplicateInvoiceError
+		if errors.As(err, &duplicateErr) {
+			log.Printf("Likely duplicate invoice: %v", err)
+			return c.JSON(http.StatusConflict, newDuplicateInvoiceErrorResponse(duplicateErr))
+		}
+		if errors.Is(err, model.ErrInvalidLineItem) || errors.Is(err, application.ErrLineItemAmountMismatch) {
+			log.Printf("Invalid line items: %v", err)
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
 		log.Printf("Failed to create invoice Error: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not create invoice"})
 	}
 
 	response := CreateInvoiceResponse{
-		InvoiceItem: InvoiceItem{
-			ID:               createdInvoice.ID,
-			OrganizationID:   createdInvoice.OrganizationID,
-			OrganizationName: createdInvoice.OrganizationName,
-			ClientID:         createdInvoice.ClientID,
-			ClientName:       createdInvoice.ClientName,
-			IssueDate:        types.CustomDate{Time: createdInvoice.IssueDate},
-			Amount:           createdInvoice.Amount,
-			Fee:              createdInvoice.Fee,
-			FeeRate:          createdInvoice.FeeRate,
-			Tax:              createdInvoice.Tax,
-			TaxRate:          createdInvoice.TaxRate,
-			TotalAmount:      createdInvoice.TotalAmount,
-			DueDate:          types.CustomDate{Time: createdInvoice.DueDate},
-			Status:           createdInvoice.Status,
-		},
+		InvoiceItem: newInvoiceItem(createdInvoice),
+		Warnings:    createdInvoice.Warnings,
 	}
 
+	setETag(c, createdInvoice.Version)
 	return c.JSON(http.StatusOK, response)
 }
 
 type ListInvoiceRequest struct {
-	StartDate types.CustomDate `query:"startDate"`
-	EndDate   types.CustomDate `query:"endDate"`
+	StartDate      types.CustomDate `query:"startDate"`                                                                   // 支払期日（開始）
+	EndDate        types.CustomDate `query:"endDate"`                                                                     // 支払期日（終了）
+	IssueStartDate types.CustomDate `query:"issueStartDate"`                                                              // 発行日（開始）
+	IssueEndDate   types.CustomDate `query:"issueEndDate"`                                                                // 発行日（終了）
+	Statuses       []string         `query:"status" validate:"dive,oneof=pending processing paid error"`                  // ステータス（複数指定可）
+	ClientID       uint             `query:"clientId"`                                                                    // 請求先取引先ID
+	MinAmount      string           `query:"minAmount"`                                                                   // 支払金額の下限
+	MaxAmount      string           `query:"maxAmount"`                                                                   // 支払金額の上限
+	Sort           string           `query:"sort" validate:"omitempty,oneof=due_date issue_date total_amount created_at"` // 並び替えキー
+	Order          string           `query:"order" validate:"omitempty,oneof=asc desc"`                                   // 並び順
+	Cursor         string           `query:"cursor"`                                                                      // 前のページの nextCursor
+	Limit          int              `query:"limit" validate:"omitempty,min=1,max=100"`                                    // 1ページあたりの件数
 }
 
 // 一旦postとgetで使いまわし
 type InvoiceItem struct {
-	ID               uint             `json:"id"`               // 請求書ID
-	OrganizationID   uint             `json:"organizationId"`   // 請求元企業
-	OrganizationName string           `json:"organizationName"` // 請求元企業名
-	ClientID         uint             `json:"clientId"`         // 請求先取引先ID
-	ClientName       string           `json:"clientName"`       // 請求先取引先名
-	IssueDate        types.CustomDate `json:"issueDate"`        // 発行日
-	Amount           int64            `json:"amount"`           // 請求金額
-	Fee              int64            `json:"fee"`              // 手数料
-	FeeRate          float64          `json:"feeRate"`          // 手数料率
-	Tax              int64            `json:"tax"`              // 消費税
-	TaxRate          float64          `json:"taxRate"`          // 消費税率
-	TotalAmount      int64            `json:"totalAmount"`      // 合計金額
-	DueDate          types.CustomDate `json:"dueDate"`          // 支払期日
-	Status           string           `json:"status"`           // ステータス
+	ID               uint             `json:"id"`                        // 請求書ID
+	OrganizationID   uint             `json:"organizationId"`            // 請求元企業
+	OrganizationName string           `json:"organizationName"`          // 請求元企業名
+	ClientID         uint             `json:"clientId"`                  // 請求先取引先ID
+	ClientName       string           `json:"clientName"`                // 請求先取引先名
+	IssueDate        types.CustomDate `json:"issueDate"`                 // 発行日
+	Amount           int64            `json:"amount"`                    // 請求金額
+	Fee              int64            `json:"fee"`                       // 手数料
+	FeeRate          float64          `json:"feeRate"`                   // 手数料率
+	FeePlanID        uint             `json:"feePlanId,omitempty"`       // 適用した手数料プランのID（プランがなく既定の手数料率を適用した場合は省略）
+	SettingsVersion  uint             `json:"settingsVersion,omitempty"` // 作成時に適用した組織の設定の版（設定を登録する前に作成した請求書では省略）
+	Tax              int64            `json:"tax"`                       // 消費税
+	TaxRate          float64          `json:"taxRate"`                   // 消費税率
+	TotalAmount      int64            `json:"totalAmount"`               // 合計金額（支払金額 + 手数料 + 消費税. 源泉徴収税額は差し引かない）
+	WithholdingTax   int64            `json:"withholdingTax"`            // 源泉徴収税額
+	TransferAmount   int64            `json:"transferAmount"`            // 取引先への振込金額（支払金額 - 源泉徴収税額）
+	DueDate          types.CustomDate `json:"dueDate"`                   // 支払期日
+	Status           string           `json:"status"`                    // ステータス
+	LineItems        []LineItem       `json:"lineItems,omitempty"`       // 明細（明細を指定せずに作成した請求書では省略）
+	TaxSummaries     []TaxSummary     `json:"taxSummaries,omitempty"`    // 税区分・税率ごとの集計（明細を指定せずに作成した請求書では省略）
+}
+
+type TaxSummary struct {
+	TaxCategory   string  `json:"taxCategory"`   // 税区分
+	TaxRate       float64 `json:"taxRate"`       // 消費税率
+	TaxableAmount int64   `json:"taxableAmount"` // 対価の額（税抜）
+	Tax           int64   `json:"tax"`           // 消費税額
+}
+
+type LineItem struct {
+	LineNumber  int     `json:"lineNumber"`  // 行番号
+	Description string  `json:"description"` // 品目
+	Quantity    float64 `json:"quantity"`    // 数量
+	UnitPrice   int64   `json:"unitPrice"`   // 単価（税抜）
+	Amount      int64   `json:"amount"`      // 金額（税抜）
+	TaxCategory string  `json:"taxCategory"` // 税区分
+	TaxRate     float64 `json:"taxRate"`     // 消費税率
+}
+
+// newInvoiceItem DTOからレスポンスデータへ変換する
+func newInvoiceItem(invoice *application.InvoiceDto) InvoiceItem {
+	return InvoiceItem{
+		ID:               invoice.ID,
+		OrganizationID:   invoice.OrganizationID,
+		OrganizationName: invoice.OrganizationName,
+		ClientID:         invoice.ClientID,
+		ClientName:       invoice.ClientName,
+		IssueDate:        types.CustomDate{Time: invoice.IssueDate},
+		Amount:           invoice.Amount,
+		Fee:              invoice.Fee,
+		FeeRate:          invoice.FeeRate,
+		FeePlanID:        invoice.FeePlanID,
+		SettingsVersion:  invoice.SettingsVersion,
+		Tax:              invoice.Tax,
+		TaxRate:          invoice.TaxRate,
+		TotalAmount:      invoice.TotalAmount,
+		WithholdingTax:   invoice.WithholdingTax,
+		TransferAmount:   invoice.TransferAmount,
+		DueDate:          types.CustomDate{Time: invoice.DueDate},
+		Status:           invoice.Status,
+		LineItems:        newLineItems(invoice.LineItems),
+		TaxSummaries:     newTaxSummaries(invoice.TaxSummaries),
+	}
+}
+
+// newTaxSummaries 税区分・税率ごとの集計のDTOからレスポンスデータへ変換する
+func newTaxSummaries(dtos []application.TaxSummaryDto) []TaxSummary {
+	var summaries []TaxSummary
+	for _, dto := range dtos {
+		summaries = append(summaries, TaxSummary{
+			TaxCategory:   dto.TaxCategory,
+			TaxRate:       dto.TaxRate,
+			TaxableAmount: dto.TaxableAmount,
+			Tax:           dto.Tax,
+		})
+	}
+	return summaries
+}
+
+// newLineItems 明細のDTOからレスポンスデータへ変換する
+func newLineItems(dtos []application.InvoiceLineItemDto) []LineItem {
+	var items []LineItem
+	for _, dto := range dtos {
+		items = append(items, LineItem{
+			LineNumber:  dto.LineNumber,
+			Description: dto.Description,
+			Quantity:    dto.Quantity,
+			UnitPrice:   dto.UnitPrice,
+			Amount:      dto.Amount,
+			TaxCategory: dto.TaxCategory,
+			TaxRate:     dto.TaxRate,
+		})
+	}
+	return items
 }
 
 type ListInvoiceResponse struct {
-	Invoices []InvoiceItem `json:"invoices" `
+	Invoices   []InvoiceItem `json:"invoices" `
+	NextCursor string        `json:"nextCursor,omitempty"` // 次のページを取得するためのカーソル（最終ページでは省略）
 }
 
 func (h *InvoiceHandler) ListInvoice(c echo.Context) error {
@@ -119,40 +255,266 @@ func (h *InvoiceHandler) ListInvoice(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
 
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	minAmount, err := parseOptionalInt(req.MinAmount)
+	if err != nil {
+		log.Printf("Invalid minAmount: %s", req.MinAmount)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	maxAmount, err := parseOptionalInt(req.MaxAmount)
+	if err != nil {
+		log.Printf("Invalid maxAmount: %s", req.MaxAmount)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
 	dto := application.ListInvoiceDto{
-		StartDate: req.StartDate.Time,
-		EndDate:   req.EndDate.Time,
+		Principal:      principal,
+		StartDate:      req.StartDate.Time,
+		EndDate:        req.EndDate.Time,
+		IssueStartDate: req.IssueStartDate.Time,
+		IssueEndDate:   req.IssueEndDate.Time,
+		Statuses:       req.Statuses,
+		ClientID:       req.ClientID,
+		MinAmount:      minAmount,
+		MaxAmount:      maxAmount,
+		Sort:           req.Sort,
+		Order:          req.Order,
+		Cursor:         req.Cursor,
+		Limit:          req.Limit,
 	}
 
-	invoices, err := h.usecase.ListInvoice(dto)
+	result, err := h.usecase.ListInvoice(dto)
 	if err != nil {
+		if isAuthorizationError(err) {
+			return authorizationErrorResponse(c, err)
+		}
+		if errors.Is(err, application.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+		}
 		log.Printf("Failed to list invoices Error: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not list invoices"})
 	}
 
 	// DTOからレスポンスデータへの変換
 	response := ListInvoiceResponse{
-		Invoices: make([]InvoiceItem, len(invoices)),
-	}
-
-	for i, invoice := range invoices {
-		response.Invoices[i] = InvoiceItem{
-			ID:               invoice.ID,
-			OrganizationID:   invoice.OrganizationID,
-			OrganizationName: invoice.OrganizationName,
-			ClientID:         invoice.ClientID,
-			ClientName:       invoice.ClientName,
-			IssueDate:        types.CustomDate{Time: invoice.IssueDate},
-			Amount:           invoice.Amount,
-			Fee:              invoice.Fee,
-			FeeRate:          invoice.FeeRate,
-			Tax:              invoice.Tax,
-			TaxRate:          invoice.TaxRate,
-			TotalAmount:      invoice.TotalAmount,
-			DueDate:          types.CustomDate{Time: invoice.DueDate},
-			Status:           invoice.Status,
+		Invoices:   make([]InvoiceItem, len(result.Invoices)),
+		NextCursor: result.NextCursor,
+	}
+
+	for i, invoice := range result.Invoices {
+		response.Invoices[i] = newInvoiceItem(invoice)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// parseOptionalInt 空文字の場合はnilを返す
+func parseOptionalInt(s string) (*int64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+type OrganizationDetail struct {
+	ID                 uint   `json:"id"`                 // 企業ID
+	Name               string `json:"name"`               // 法人名
+	RegistrationNumber string `json:"registrationNumber"` // 適格請求書発行事業者の登録番号（未登録の場合は空文字）
+	Representative     string `json:"representative"`     // 代表者名
+	PhoneNumber        string `json:"phoneNumber"`        // 電話番号
+	PostalCode         string `json:"postalCode"`         // 郵便番号
+	Address            string `json:"address"`            // 住所
+}
+
+type ClientDetail struct {
+	ID                 uint   `json:"id"`                 // 取引先ID
+	Name               string `json:"name"`               // 法人名
+	RegistrationNumber string `json:"registrationNumber"` // 適格請求書発行事業者の登録番号（未登録の場合は空文字）
+	Representative     string `json:"representative"`     // 代表者名
+	PhoneNumber        string `json:"phoneNumber"`        // 電話番号
+	PostalCode         string `json:"postalCode"`         // 郵便番号
+	Address            string `json:"address"`            // 住所
+}
+
+type BankAccountItem struct {
+	ID            uint   `json:"id"`            // 銀行口座ID
+	BankName      string `json:"bankName"`      // 銀行名
+	BranchName    string `json:"branchName"`    // 支店名
+	AccountNumber string `json:"accountNumber"` // 口座番号（末尾以外はマスク）
+	AccountName   string `json:"accountName"`   // 口座名義
+}
+
+type GetInvoiceResponse struct {
+	InvoiceItem
+	Organization OrganizationDetail `json:"organization"` // 請求元企業
+	Client       ClientDetail       `json:"client"`       // 請求先取引先
+	BankAccount  *BankAccountItem   `json:"bankAccount"`  // 振込先口座（未登録の場合はnull）
+}
+
+func (h *InvoiceHandler) GetInvoice(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		log.Printf("Invalid invoice id: %s", c.Param("id"))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	invoice, err := h.usecase.GetInvoice(application.GetInvoiceDto{Principal: principal, ID: uint(id)})
+	if err != nil {
+		if isAuthorizationError(err) {
+			return authorizationErrorResponse(c, err)
 		}
+		if errors.Is(err, commonErrors.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "invoice not found"})
+		}
+		log.Printf("Failed to get invoice Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not get invoice"})
 	}
 
+	response := GetInvoiceResponse{
+		InvoiceItem:  newInvoiceItem(&invoice.InvoiceDto),
+		Organization: newOrganizationDetail(&invoice.Organization),
+		Client: ClientDetail{
+			ID:                 invoice.Client.ID,
+			Name:               invoice.Client.Name,
+			RegistrationNumber: invoice.Client.RegistrationNumber,
+			Representative:     invoice.Client.Representative,
+			PhoneNumber:        invoice.Client.PhoneNumber,
+			PostalCode:         invoice.Client.PostalCode,
+			Address:            invoice.Client.Address,
+		},
+	}
+	if account := invoice.BankAccount; account != nil {
+		response.BankAccount = &BankAccountItem{
+			ID:            account.ID,
+			BankName:      account.BankName,
+			BranchName:    account.BranchName,
+			AccountNumber: account.MaskedAccountNumber,
+			AccountName:   account.AccountName,
+		}
+	}
+
+	setETag(c, invoice.Version)
 	return c.JSON(http.StatusOK, response)
 }
+
+type ChangeInvoiceStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=pending processing paid error"` // 遷移先ステータス
+}
+
+type ChangeInvoiceStatusResponse struct {
+	InvoiceItem
+}
+
+func (h *InvoiceHandler) ChangeInvoiceStatus(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		log.Printf("Invalid invoice id: %s", c.Param("id"))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	var req ChangeInvoiceStatusRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	// 取得した時点から更新されていないことを前提に変更する
+	version, err := ifMatchVersion(c)
+	if errors.Is(err, errIfMatchRequired) {
+		return c.JSON(http.StatusPreconditionRequired, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("Invalid If-Match: %s", c.Request().Header.Get(HeaderIfMatch))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	dto := application.ChangeInvoiceStatusDto{
+		Principal: principal,
+		ID:        uint(id),
+		Status:    req.Status,
+		Version:   version,
+	}
+
+	invoice, err := h.usecase.ChangeInvoiceStatus(dto)
+	if err != nil {
+		var transitionErr *model.InvalidStatusTransitionError
+		switch {
+		case isAuthorizationError(err):
+			return authorizationErrorResponse(c, err)
+		case errors.Is(err, commonErrors.ErrNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "invoice not found"})
+		case errors.As(err, &transitionErr):
+			log.Printf("Invalid status transition: %v", err)
+			return c.JSON(http.StatusConflict, map[string]string{"error": transitionErr.Error()})
+		case errors.Is(err, application.ErrVersionMismatch):
+			log.Printf("Invoice was modified: %v", err)
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Failed to change invoice status Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not change invoice status"})
+	}
+
+	setETag(c, invoice.Version)
+	return c.JSON(http.StatusOK, ChangeInvoiceStatusResponse{InvoiceItem: newInvoiceItem(invoice)})
+}
+
+// isAuthorizationError 操作主体の認証・認可に失敗したエラーかどうか
+func isAuthorizationError(err error) bool {
+	return errors.Is(err, commonErrors.ErrUnauthenticated) ||
+		errors.Is(err, commonErrors.ErrUnauthorized) ||
+		errors.Is(err, commonErrors.ErrForbidden)
+}
+
+// authorizationErrorResponse 操作主体の認証・認可のエラーをレスポンスに変換する.
+// トークンのユーザーが無効になった場合は 401、組織を解決できない・権限がない場合は 403 とする
+func authorizationErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, commonErrors.ErrUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "user of the token is not found, deactivated, or no longer belongs to the organization"})
+	case errors.Is(err, commonErrors.ErrUnauthorized):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "no organization associated with the token"})
+	}
+	return c.JSON(http.StatusForbidden, map[string]string{"error": "insufficient permission"})
+}
+
+// principalFromContext 認証ミドルウェアが格納したクレームから操作主体を取得する
+func principalFromContext(c echo.Context) (application.Principal, bool) {
+	claims, ok := c.Get("user").(*middleware.CustomClaims)
+	if !ok {
+		return application.Principal{}, false
+	}
+	return application.Principal{
+		Subject:        claims.Subject,
+		UserID:         claims.UserID,
+		OrganizationID: claims.OrganizationID,
+		Scopes:         strings.Fields(claims.Scope),
+	}, true
+}
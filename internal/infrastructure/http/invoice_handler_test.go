@@ -12,11 +12,19 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/middleware"
 	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
 	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 	"github.com/take73/invoice-api-example/internal/shared/validation"
 )
 
+// testClaims 認証ミドルウェアが格納するクレーム（組織1のユーザー）
+var testClaims = &middleware.CustomClaims{Subject: "auth0|user1", Scope: "read:invoice write:invoice", OrganizationID: 1}
+
+// testPrincipal testClaims から変換される操作主体
+var testPrincipal = application.Principal{Subject: "auth0|user1", OrganizationID: 1, Scopes: []string{"read:invoice", "write:invoice"}}
+
 func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 	e := echo.New()
 	e.Validator = validation.NewCustomValidator()
@@ -32,7 +40,7 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 			name: "success",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
 				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
-					UserID:    1,
+					Principal: testPrincipal,
 					ClientID:  1,
 					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
 					Amount:    10000,
@@ -52,10 +60,10 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 					TotalAmount:      10440,
 					DueDate:          time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
 					Status:           "pending",
+					Version:          1,
 				}, nil)
 			},
 			payload: map[string]interface{}{
-				"userId":    1,
 				"clientId":  1,
 				"issueDate": "2023-12-01",
 				"amount":    10000,
@@ -69,49 +77,13 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 				assert.Equal(t, uint(1), response.ID)
 				assert.Equal(t, "Test Organization", response.OrganizationName)
 				assert.Equal(t, "Test Client", response.ClientName)
-			},
-		},
-		{
-			name:      "userIdが-1の場合, invalid request",
-			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {}, // Mock is not called in this case
-			payload: map[string]interface{}{
-				"userId":    -1,
-				"clientId":  1,
-				"issueDate": "2023-12-01",
-				"amount":    10000,
-				"dueDate":   "2023-12-15",
-			},
-			expectedStatus: http.StatusBadRequest,
-			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				var response map[string]string
-				err := json.Unmarshal(rec.Body.Bytes(), &response)
-				assert.NoError(t, err)
-				assert.Equal(t, "invalid request", response["error"])
-			},
-		},
-		{
-			name:      "userIdが0の場合, validation failed",
-			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {}, // Mock is not called in this case
-			payload: map[string]interface{}{
-				"userId":    0,
-				"clientId":  1,
-				"issueDate": "2023-12-01",
-				"amount":    10000,
-				"dueDate":   "2023-12-15",
-			},
-			expectedStatus: http.StatusBadRequest,
-			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				var response map[string]string
-				err := json.Unmarshal(rec.Body.Bytes(), &response)
-				assert.NoError(t, err)
-				assert.Equal(t, "validation failed", response["error"])
+				assert.Equal(t, `"1"`, rec.Header().Get(HeaderETag))
 			},
 		},
 		{
 			name:      "clientIdが-1の場合, invalid request",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {}, // Mock is not called in this case
 			payload: map[string]interface{}{
-				"userId":    1,
 				"clientId":  -1,
 				"issueDate": "2023-12-01",
 				"amount":    10000,
@@ -129,7 +101,6 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 			name:      "clientIdが0の場合, validation failed",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {}, // Mock is not called in this case
 			payload: map[string]interface{}{
-				"userId":    1,
 				"clientId":  0,
 				"issueDate": "2023-12-01",
 				"amount":    10000,
@@ -147,7 +118,6 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 			name:      "issueDateのformatが不正の場合, invalid request",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {}, // Mock is not called in this case
 			payload: map[string]interface{}{
-				"userId":    1,
 				"clientId":  1,
 				"issueDate": "2023/12/01",
 				"amount":    10000,
@@ -165,7 +135,6 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 			name:      "issueDateがない場合, validation failed",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {}, // Mock is not called in this case
 			payload: map[string]interface{}{
-				"userId":   1,
 				"clientId": 1,
 				"amount":   10000,
 				"dueDate":  "2023-12-15",
@@ -182,7 +151,7 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 			name: "amountが0の場合, success",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
 				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
-					UserID:    1,
+					Principal: testPrincipal,
 					ClientID:  1,
 					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
 					Amount:    0,
@@ -205,7 +174,6 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 				}, nil)
 			},
 			payload: map[string]interface{}{
-				"userId":    1,
 				"clientId":  1,
 				"issueDate": "2023-12-01",
 				"amount":    0,
@@ -225,7 +193,6 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 			name:      "dueDateのformatが不正の場合, invalid request",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {}, // Mock is not called in this case
 			payload: map[string]interface{}{
-				"userId":    1,
 				"clientId":  1,
 				"issueDate": "2023-12-01",
 				"amount":    10000,
@@ -240,27 +207,42 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 			},
 		},
 		{
-			name:      "dueDateがない場合, validation failed",
-			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {}, // Mock is not called in this case
+			name: "dueDateがない場合, 組織の設定から求めた支払期日で作成",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  1,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+				}).Return(&application.InvoiceDto{
+					ID:              1,
+					ClientID:        1,
+					IssueDate:       time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:          10000,
+					SettingsVersion: 2,
+					DueDate:         time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+					Status:          "pending",
+				}, nil)
+			},
 			payload: map[string]interface{}{
-				"userId":    1,
 				"clientId":  1,
 				"issueDate": "2023-12-01",
 				"amount":    10000,
 			},
-			expectedStatus: http.StatusBadRequest,
+			expectedStatus: http.StatusOK,
 			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				var response map[string]string
+				var response CreateInvoiceResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Equal(t, "validation failed", response["error"])
+				assert.Equal(t, "2023-12-31", response.DueDate.Format("2006-01-02"))
+				assert.Equal(t, uint(2), response.SettingsVersion)
 			},
 		},
 		{
 			name: "usecaseでNoFoundエラーが発生した場合, related company or client not found",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
 				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
-					UserID:    1,
+					Principal: testPrincipal,
 					ClientID:  1,
 					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
 					Amount:    10000,
@@ -268,7 +250,6 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 				}).Return(nil, commonErrors.ErrNotFound)
 			},
 			payload: map[string]interface{}{
-				"userId":    1,
 				"clientId":  1,
 				"issueDate": "2023-12-01",
 				"amount":    10000,
@@ -283,172 +264,1065 @@ func Test_InvoiceHandler_CreateInvoice(t *testing.T) {
 			},
 		},
 		{
-			name: "usecaseでエラーが発生した場合, could not create invoice",
+			name: "リクエストボディのuserIdは無視し、トークンの操作主体で登録する",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
 				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
-					UserID:    1,
+					Principal: testPrincipal,
 					ClientID:  1,
 					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
 					Amount:    10000,
 					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
-				}).Return(nil, errors.New("unexpected error"))
+				}).Return(&application.InvoiceDto{
+					ID:               1,
+					OrganizationID:   1,
+					OrganizationName: "Test Organization",
+					ClientID:         1,
+					ClientName:       "Test Client",
+					IssueDate:        time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:           10000,
+					DueDate:          time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+					Status:           "pending",
+				}, nil)
 			},
 			payload: map[string]interface{}{
-				"userId":    1,
+				"userId":    3, // 他組織のユーザー
 				"clientId":  1,
 				"issueDate": "2023-12-01",
 				"amount":    10000,
 				"dueDate":   "2023-12-15",
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusOK,
 			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				var response map[string]string
+				var response CreateInvoiceResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Equal(t, "could not create invoice", response["error"])
+				assert.Equal(t, uint(1), response.OrganizationID)
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// 新しいモックインスタンスを作成
-			mockUsecase := &testutils.MockInvoiceUsecase{}
-			tt.setupMock(mockUsecase)
-
-			// ハンドラを新規作成
-			handler := NewInvoiceHandler(mockUsecase)
-
-			// リクエストのセットアップ
-			reqBody, _ := json.Marshal(tt.payload)
-			req := httptest.NewRequest(http.MethodPost, "/invoice", bytes.NewReader(reqBody))
-			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-			rec := httptest.NewRecorder()
-			c := e.NewContext(req, rec)
-
-			// ハンドラの実行
-			err := handler.CreateInvoice(c)
-			assert.NoError(t, err)
-			assert.Equal(t, tt.expectedStatus, rec.Code)
-
-			// レスポンスの検証
-			if tt.expectedBody != nil {
-				tt.expectedBody(t, rec)
-			}
-
-			// モックのアサーション
-			mockUsecase.AssertExpectations(t)
-		})
-	}
-}
-
-func Test_InvoiceHandler_ListInvoice(t *testing.T) {
-	e := echo.New()
-	e.Validator = validation.NewCustomValidator()
-
-	tests := []struct {
-		name           string
-		setupMock      func(*testutils.MockInvoiceUsecase)
-		queryParams    string
-		expectedStatus int
-		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
-	}{
 		{
-			name: "success",
+			name: "トークンに組織が紐づかない場合, forbidden",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
-				mockUsecase.On("ListInvoice", application.ListInvoiceDto{
-					StartDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
-					EndDate:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
-				}).Return([]*application.InvoiceDto{
-					{
-						ID:               1,
-						OrganizationID:   1,
-						OrganizationName: "Test Organization",
-						ClientID:         1,
-						ClientName:       "Test Client",
-						IssueDate:        time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
-						Amount:           10000,
-						Fee:              400,
-						FeeRate:          0.04,
-						Tax:              40,
-						TaxRate:          0.1,
-						TotalAmount:      10440,
-						DueDate:          time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
-						Status:           "pending",
-					},
-				}, nil)
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  1,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+				}).Return(nil, commonErrors.ErrUnauthorized)
 			},
-			queryParams:    "?startDate=2023-12-01&endDate=2023-12-31",
-			expectedStatus: http.StatusOK,
+			payload: map[string]interface{}{
+				"clientId":  1,
+				"issueDate": "2023-12-01",
+				"amount":    10000,
+				"dueDate":   "2023-12-15",
+			},
+			expectedStatus: http.StatusForbidden,
 			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				var response ListInvoiceResponse
+				var response map[string]string
 				err := json.Unmarshal(rec.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Len(t, response.Invoices, 1)
-				assert.Equal(t, uint(1), response.Invoices[0].ID)
-				assert.Equal(t, "Test Organization", response.Invoices[0].OrganizationName)
-				assert.Equal(t, "Test Client", response.Invoices[0].ClientName)
+				assert.Equal(t, "no organization associated with the token", response["error"])
 			},
 		},
 		{
-			name:           "startDateのformatが不正の場合, invalid request",
-			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
-			queryParams:    "?startDate=2023/12/01&endDate=2023-12-31",
-			expectedStatus: http.StatusBadRequest,
+			name: "トークンのユーザーが無効化されている場合, unauthorized",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  1,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+				}).Return(nil, commonErrors.ErrUnauthenticated)
+			},
+			payload: map[string]interface{}{
+				"clientId":  1,
+				"issueDate": "2023-12-01",
+				"amount":    10000,
+				"dueDate":   "2023-12-15",
+			},
+			expectedStatus: http.StatusUnauthorized,
 			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				var response map[string]string
 				err := json.Unmarshal(rec.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Equal(t, "invalid request", response["error"])
+				assert.Equal(t, "user of the token is not found, deactivated, or no longer belongs to the organization", response["error"])
 			},
 		},
 		{
-			name:           "endDateのformatが不正の場合, invalid request",
-			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
-			queryParams:    "?startDate=2023-12-01&endDate=2023/12/31",
-			expectedStatus: http.StatusBadRequest,
+			name: "他組織の取引先を指定した場合, unprocessable entity",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  3,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+				}).Return(nil, model.ErrClientNotInOrganization)
+			},
+			payload: map[string]interface{}{
+				"clientId":  3,
+				"issueDate": "2023-12-01",
+				"amount":    10000,
+				"dueDate":   "2023-12-15",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
 			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				var response map[string]string
 				err := json.Unmarshal(rec.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Equal(t, "invalid request", response["error"])
+				assert.Equal(t, "client does not belong to the organization", response["error"])
 			},
 		},
 		{
-			name: "ListInvoiceでエラーが発生した場合, could not list invoices",
+			name: "アーカイブした取引先を指定した場合, unprocessable entity",
 			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
-				mockUsecase.On("ListInvoice", application.ListInvoiceDto{
-					StartDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
-					EndDate:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
-				}).Return([]*application.InvoiceDto{}, errors.New("unexpected error"))
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  2,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+				}).Return(nil, model.ErrClientArchived)
 			},
-			queryParams:    "?startDate=2023-12-01&endDate=2023-12-31",
-			expectedStatus: http.StatusInternalServerError,
+			payload: map[string]interface{}{
+				"clientId":  2,
+				"issueDate": "2023-12-01",
+				"amount":    10000,
+				"dueDate":   "2023-12-15",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
 			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				var response map[string]string
 				err := json.Unmarshal(rec.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Equal(t, "could not list invoices", response["error"])
+				assert.Equal(t, "client is archived", response["error"])
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// 新しいモックインスタンスを作成
-			mockUsecase := &testutils.MockInvoiceUsecase{}
-			tt.setupMock(mockUsecase)
-
-			// ハンドラを新規作成
-			handler := NewInvoiceHandler(mockUsecase)
-
-			// リクエストのセットアップ
-			req := httptest.NewRequest(http.MethodGet, "/invoices"+tt.queryParams, nil)
-			rec := httptest.NewRecorder()
-			c := e.NewContext(req, rec)
-
-			// ハンドラの実行
-			err := handler.ListInvoice(c)
+		{
+			name: "明細を指定した場合, 明細を含めて返す",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  1,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+					LineItems: []application.CreateInvoiceLineItemDto{
+						{Description: "コンサルティング", Quantity: 2, UnitPrice: 5000, TaxCategory: "standard"},
+						{Description: "お弁当", Quantity: 10, UnitPrice: 500, TaxCategory: "reduced"},
+					},
+				}).Return(&application.InvoiceDto{
+					ID:               1,
+					OrganizationID:   1,
+					OrganizationName: "Test Organization",
+					ClientID:         1,
+					ClientName:       "Test Client",
+					IssueDate:        time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:           16400,
+					Fee:              656,
+					FeeRate:          0.04,
+					Tax:              65,
+					TaxRate:          0.1,
+					TotalAmount:      17121,
+					DueDate:          time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+					Status:           "pending",
+					LineItems: []application.InvoiceLineItemDto{
+						{LineNumber: 1, Description: "コンサルティング", Quantity: 2, UnitPrice: 5000, Amount: 10000, TaxCategory: "standard", TaxRate: 0.1},
+						{LineNumber: 2, Description: "お弁当", Quantity: 10, UnitPrice: 500, Amount: 5000, TaxCategory: "reduced", TaxRate: 0.08},
+					},
+					TaxSummaries: []application.TaxSummaryDto{
+						{TaxCategory: "standard", TaxRate: 0.1, TaxableAmount: 10000, Tax: 1000},
+						{TaxCategory: "reduced", TaxRate: 0.08, TaxableAmount: 5000, Tax: 400},
+					},
+				}, nil)
+			},
+			payload: map[string]interface{}{
+				"clientId":  1,
+				"issueDate": "2023-12-01",
+				"dueDate":   "2023-12-15",
+				"lineItems": []map[string]interface{}{
+					{"description": "コンサルティング", "quantity": 2, "unitPrice": 5000, "taxCategory": "standard"},
+					{"description": "お弁当", "quantity": 10, "unitPrice": 500, "taxCategory": "reduced"},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response CreateInvoiceResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, int64(16400), response.Amount)
+				assert.Equal(t, []LineItem{
+					{LineNumber: 1, Description: "コンサルティング", Quantity: 2, UnitPrice: 5000, Amount: 10000, TaxCategory: "standard", TaxRate: 0.1},
+					{LineNumber: 2, Description: "お弁当", Quantity: 10, UnitPrice: 500, Amount: 5000, TaxCategory: "reduced", TaxRate: 0.08},
+				}, response.LineItems)
+				assert.Equal(t, []TaxSummary{
+					{TaxCategory: "standard", TaxRate: 0.1, TaxableAmount: 10000, Tax: 1000},
+					{TaxCategory: "reduced", TaxRate: 0.08, TaxableAmount: 5000, Tax: 400},
+				}, response.TaxSummaries)
+				assert.Empty(t, response.Warnings)
+			},
+		},
+		{
+			name: "適格請求書の記載事項が不足している場合, 警告を返す",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  1,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+				}).Return(&application.InvoiceDto{
+					ID:        1,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+					Status:    "pending",
+					Warnings:  []string{"line items are required to show taxable amounts and taxes per tax rate"},
+				}, nil)
+			},
+			payload: map[string]interface{}{
+				"clientId":  1,
+				"issueDate": "2023-12-01",
+				"amount":    10000,
+				"dueDate":   "2023-12-15",
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response CreateInvoiceResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"line items are required to show taxable amounts and taxes per tax rate"}, response.Warnings)
+			},
+		},
+		{
+			name: "適格請求書を必須とし記載事項が不足している場合, unprocessable entity",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal:               testPrincipal,
+					ClientID:                1,
+					IssueDate:               time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:                  10000,
+					DueDate:                 time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+					RequireQualifiedInvoice: true,
+				}).Return(nil, &model.QualifiedInvoiceError{Missing: []string{"registration number of the organization is not registered"}})
+			},
+			payload: map[string]interface{}{
+				"clientId":                1,
+				"issueDate":               "2023-12-01",
+				"amount":                  10000,
+				"dueDate":                 "2023-12-15",
+				"requireQualifiedInvoice": true,
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "not a qualified invoice: registration number of the organization is not registered", response["error"])
+			},
+		},
+		{
+			name: "重複の疑いがある請求書がある場合, conflict",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  1,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+				}).Return(nil, &application.DuplicateInvoiceError{Candidates: []*application.InvoiceDto{
+					{ID: 7, OrganizationID: 1, ClientID: 1, IssueDate: time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC), Amount: 10000, DueDate: time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC), Status: "pending"},
+				}})
+			},
+			payload: map[string]interface{}{
+				"clientId":  1,
+				"issueDate": "2023-12-01",
+				"amount":    10000,
+				"dueDate":   "2023-12-15",
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response DuplicateInvoiceErrorResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "likely duplicate invoice exists. set confirmDuplicate to create it anyway", response.Error)
+				assert.Len(t, response.Duplicates, 1)
+				assert.Equal(t, uint(7), response.Duplicates[0].ID)
+				assert.Equal(t, "2023-11-30", response.Duplicates[0].IssueDate.Format("2006-01-02"))
+			},
+		},
+		{
+			name: "confirmDuplicateを指定した場合, 重複の確認を省略して作成",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal:        testPrincipal,
+					ClientID:         1,
+					IssueDate:        time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:           10000,
+					DueDate:          time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+					ConfirmDuplicate: true,
+				}).Return(&application.InvoiceDto{ID: 8, OrganizationID: 1, ClientID: 1, IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), Amount: 10000, DueDate: time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC), Status: "pending"}, nil)
+			},
+			payload: map[string]interface{}{
+				"clientId":         1,
+				"issueDate":        "2023-12-01",
+				"amount":           10000,
+				"dueDate":          "2023-12-15",
+				"confirmDuplicate": true,
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response CreateInvoiceResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, uint(8), response.ID)
+			},
+		},
+		{
+			name:      "明細の税区分が不正な場合, validation failed",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {}, // Mock is not called in this case
+			payload: map[string]interface{}{
+				"clientId":  1,
+				"issueDate": "2023-12-01",
+				"dueDate":   "2023-12-15",
+				"lineItems": []map[string]interface{}{
+					{"description": "コンサルティング", "quantity": 1, "unitPrice": 5000, "taxCategory": "free"},
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name: "支払金額が明細の合計と一致しない場合, unprocessable entity",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  1,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+					LineItems: []application.CreateInvoiceLineItemDto{
+						{Description: "コンサルティング", Quantity: 2, UnitPrice: 5000, TaxCategory: "standard"},
+					},
+				}).Return(nil, application.ErrLineItemAmountMismatch)
+			},
+			payload: map[string]interface{}{
+				"clientId":  1,
+				"issueDate": "2023-12-01",
+				"amount":    10000,
+				"dueDate":   "2023-12-15",
+				"lineItems": []map[string]interface{}{
+					{"description": "コンサルティング", "quantity": 2, "unitPrice": 5000, "taxCategory": "standard"},
+				},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "amount does not match the total of line items", response["error"])
+			},
+		},
+		{
+			name: "usecaseでエラーが発生した場合, could not create invoice",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("CreateInvoice", application.CreateInvoiceDto{
+					Principal: testPrincipal,
+					ClientID:  1,
+					IssueDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:    10000,
+					DueDate:   time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+				}).Return(nil, errors.New("unexpected error"))
+			},
+			payload: map[string]interface{}{
+				"clientId":  1,
+				"issueDate": "2023-12-01",
+				"amount":    10000,
+				"dueDate":   "2023-12-15",
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "could not create invoice", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 新しいモックインスタンスを作成
+			mockUsecase := &testutils.MockInvoiceUsecase{}
+			tt.setupMock(mockUsecase)
+
+			// ハンドラを新規作成
+			handler := NewInvoiceHandler(mockUsecase)
+
+			// リクエストのセットアップ
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPost, "/invoice", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			// ハンドラの実行
+			err := handler.CreateInvoice(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			// レスポンスの検証
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+
+			// モックのアサーション
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_InvoiceHandler_ListInvoice(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockInvoiceUsecase)
+		queryParams    string
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ListInvoice", application.ListInvoiceDto{
+					Principal: testPrincipal,
+					StartDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					EndDate:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+				}).Return(&application.InvoiceListDto{
+					Invoices: []*application.InvoiceDto{
+						{
+							ID:               1,
+							OrganizationID:   1,
+							OrganizationName: "Test Organization",
+							ClientID:         1,
+							ClientName:       "Test Client",
+							IssueDate:        time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+							Amount:           10000,
+							Fee:              400,
+							FeeRate:          0.04,
+							Tax:              40,
+							TaxRate:          0.1,
+							TotalAmount:      10440,
+							DueDate:          time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+							Status:           "pending",
+						},
+					},
+				}, nil)
+			},
+			queryParams:    "?startDate=2023-12-01&endDate=2023-12-31",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response ListInvoiceResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Len(t, response.Invoices, 1)
+				assert.Equal(t, uint(1), response.Invoices[0].ID)
+				assert.Equal(t, "Test Organization", response.Invoices[0].OrganizationName)
+				assert.Equal(t, "Test Client", response.Invoices[0].ClientName)
+			},
+		},
+		{
+			name: "絞り込み・並び替え・カーソルを指定, nextCursorを返す",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				minAmount, maxAmount := int64(1000), int64(50000)
+				mockUsecase.On("ListInvoice", application.ListInvoiceDto{
+					Principal:      testPrincipal,
+					IssueStartDate: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC),
+					IssueEndDate:   time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC),
+					Statuses:       []string{"pending", "error"},
+					ClientID:       2,
+					MinAmount:      &minAmount,
+					MaxAmount:      &maxAmount,
+					Sort:           "total_amount",
+					Order:          "desc",
+					Cursor:         "abc",
+					Limit:          20,
+				}).Return(&application.InvoiceListDto{
+					Invoices:   []*application.InvoiceDto{},
+					NextCursor: "next",
+				}, nil)
+			},
+			queryParams:    "?issueStartDate=2023-11-01&issueEndDate=2023-11-30&status=pending&status=error&clientId=2&minAmount=1000&maxAmount=50000&sort=total_amount&order=desc&cursor=abc&limit=20",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response ListInvoiceResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Len(t, response.Invoices, 0)
+				assert.Equal(t, "next", response.NextCursor)
+			},
+		},
+		{
+			name:           "sortが未定義の値の場合, validation failed",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			queryParams:    "?sort=amount",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name:           "statusが未定義の値の場合, validation failed",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			queryParams:    "?status=pending&status=canceled",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name:           "limitが上限を超える場合, validation failed",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			queryParams:    "?limit=101",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name:           "minAmountが数値でない場合, invalid request",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			queryParams:    "?minAmount=abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid request", response["error"])
+			},
+		},
+		{
+			name: "カーソルが不正な場合, invalid cursor",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ListInvoice", application.ListInvoiceDto{
+					Principal: testPrincipal,
+					Cursor:    "broken",
+				}).Return(nil, application.ErrInvalidCursor)
+			},
+			queryParams:    "?cursor=broken",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid cursor", response["error"])
+			},
+		},
+		{
+			name:           "startDateのformatが不正の場合, invalid request",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			queryParams:    "?startDate=2023/12/01&endDate=2023-12-31",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid request", response["error"])
+			},
+		},
+		{
+			name:           "endDateのformatが不正の場合, invalid request",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			queryParams:    "?startDate=2023-12-01&endDate=2023/12/31",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid request", response["error"])
+			},
+		},
+		{
+			name: "トークンに組織が紐づかない場合, forbidden",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ListInvoice", application.ListInvoiceDto{
+					Principal: testPrincipal,
+					StartDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					EndDate:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+				}).Return(nil, commonErrors.ErrUnauthorized)
+			},
+			queryParams:    "?startDate=2023-12-01&endDate=2023-12-31",
+			expectedStatus: http.StatusForbidden,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "no organization associated with the token", response["error"])
+			},
+		},
+		{
+			name: "ListInvoiceでエラーが発生した場合, could not list invoices",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ListInvoice", application.ListInvoiceDto{
+					Principal: testPrincipal,
+					StartDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					EndDate:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+				}).Return(nil, errors.New("unexpected error"))
+			},
+			queryParams:    "?startDate=2023-12-01&endDate=2023-12-31",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "could not list invoices", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 新しいモックインスタンスを作成
+			mockUsecase := &testutils.MockInvoiceUsecase{}
+			tt.setupMock(mockUsecase)
+
+			// ハンドラを新規作成
+			handler := NewInvoiceHandler(mockUsecase)
+
+			// リクエストのセットアップ
+			req := httptest.NewRequest(http.MethodGet, "/invoices"+tt.queryParams, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			// ハンドラの実行
+			err := handler.ListInvoice(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			// レスポンスの検証
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+
+			// モックのアサーション
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_InvoiceHandler_ChangeInvoiceStatus(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockInvoiceUsecase)
+		id             string
+		ifMatch        string // If-Match ヘッダー（空の場合は付与しない）
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ChangeInvoiceStatus", application.ChangeInvoiceStatusDto{
+					ID:        1,
+					Status:    "processing",
+					Principal: testPrincipal,
+					Version:   2,
+				}).Return(&application.InvoiceDto{
+					ID:               1,
+					OrganizationID:   1,
+					OrganizationName: "Test Organization",
+					ClientID:         1,
+					ClientName:       "Test Client",
+					IssueDate:        time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Amount:           10000,
+					Fee:              400,
+					FeeRate:          0.04,
+					Tax:              40,
+					TaxRate:          0.1,
+					TotalAmount:      10440,
+					DueDate:          time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+					Status:           "processing",
+					Version:          3,
+				}, nil)
+			},
+			id:             "1",
+			ifMatch:        `"2"`,
+			payload:        map[string]interface{}{"status": "processing"},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response ChangeInvoiceStatusResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), response.ID)
+				assert.Equal(t, "processing", response.Status)
+				assert.Equal(t, `"3"`, rec.Header().Get(HeaderETag))
+			},
+		},
+		{
+			name:           "idが数値でない場合, invalid request",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			id:             "abc",
+			ifMatch:        `"2"`,
+			payload:        map[string]interface{}{"status": "processing"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid request", response["error"])
+			},
+		},
+		{
+			name:           "statusが未定義の値の場合, validation failed",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			id:             "1",
+			ifMatch:        `"2"`,
+			payload:        map[string]interface{}{"status": "canceled"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name: "請求書が存在しない場合, invoice not found",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ChangeInvoiceStatus", application.ChangeInvoiceStatusDto{
+					ID:        99,
+					Status:    "processing",
+					Principal: testPrincipal,
+					Version:   2,
+				}).Return(nil, commonErrors.ErrNotFound)
+			},
+			id:             "99",
+			ifMatch:        `"2"`,
+			payload:        map[string]interface{}{"status": "processing"},
+			expectedStatus: http.StatusNotFound,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invoice not found", response["error"])
+			},
+		},
+		{
+			name: "許可されていない遷移の場合, conflict",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ChangeInvoiceStatus", application.ChangeInvoiceStatusDto{
+					ID:        1,
+					Status:    "paid",
+					Principal: testPrincipal,
+					Version:   2,
+				}).Return(nil, &model.InvalidStatusTransitionError{From: model.StatusPending, To: model.StatusPaid})
+			},
+			id:             "1",
+			ifMatch:        `"2"`,
+			payload:        map[string]interface{}{"status": "paid"},
+			expectedStatus: http.StatusConflict,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid status transition: pending -> paid", response["error"])
+			},
+		},
+		{
+			name: "取得した後に更新されていた場合, precondition failed",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ChangeInvoiceStatus", application.ChangeInvoiceStatusDto{
+					ID:        1,
+					Status:    "processing",
+					Principal: testPrincipal,
+					Version:   2,
+				}).Return(nil, application.ErrVersionMismatch)
+			},
+			id:             "1",
+			ifMatch:        `"2"`,
+			payload:        map[string]interface{}{"status": "processing"},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invoice has been modified since it was retrieved", response["error"])
+			},
+		},
+		{
+			name:           "If-Matchがない場合, precondition required",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			id:             "1",
+			payload:        map[string]interface{}{"status": "processing"},
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "If-Match header is required", response["error"])
+			},
+		},
+		{
+			name:           "If-Matchが弱いETagの場合, invalid If-Match header",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			id:             "1",
+			ifMatch:        `W/"2"`,
+			payload:        map[string]interface{}{"status": "processing"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid If-Match header", response["error"])
+			},
+		},
+		{
+			name:           "If-Matchが*の場合, invalid If-Match header",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			id:             "1",
+			ifMatch:        "*",
+			payload:        map[string]interface{}{"status": "processing"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid If-Match header", response["error"])
+			},
+		},
+		{
+			name: "usecaseでエラーが発生した場合, could not change invoice status",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ChangeInvoiceStatus", application.ChangeInvoiceStatusDto{
+					ID:        1,
+					Status:    "processing",
+					Principal: testPrincipal,
+					Version:   2,
+				}).Return(nil, errors.New("unexpected error"))
+			},
+			id:             "1",
+			ifMatch:        `"2"`,
+			payload:        map[string]interface{}{"status": "processing"},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "could not change invoice status", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 新しいモックインスタンスを作成
+			mockUsecase := &testutils.MockInvoiceUsecase{}
+			tt.setupMock(mockUsecase)
+
+			// ハンドラを新規作成
+			handler := NewInvoiceHandler(mockUsecase)
+
+			// リクエストのセットアップ
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPatch, "/invoice/"+tt.id+"/status", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.ifMatch != "" {
+				req.Header.Set(HeaderIfMatch, tt.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			c.Set("user", testClaims)
+
+			// ハンドラの実行
+			err := handler.ChangeInvoiceStatus(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			// レスポンスの検証
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+
+			// モックのアサーション
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_InvoiceHandler_GetInvoice(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockInvoiceUsecase)
+		id             string
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("GetInvoice", application.GetInvoiceDto{Principal: testPrincipal, ID: 1}).Return(&application.InvoiceDetailDto{
+					InvoiceDto: application.InvoiceDto{
+						ID:               1,
+						OrganizationID:   1,
+						OrganizationName: "Test Organization",
+						ClientID:         1,
+						ClientName:       "Test Client",
+						IssueDate:        time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+						Amount:           10000,
+						Fee:              400,
+						FeeRate:          0.04,
+						Tax:              40,
+						TaxRate:          0.1,
+						TotalAmount:      10440,
+						DueDate:          time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+						Status:           "pending",
+						Version:          4,
+					},
+					Organization: application.OrganizationDto{ID: 1, Name: "Test Organization", RegistrationNumber: "T7123456789012", Representative: "山田 太郎"},
+					Client:       application.ClientDto{ID: 1, Name: "Test Client", Representative: "取引先担当者A"},
+					BankAccount: &application.BankAccountDto{
+						ID:                  1,
+						BankName:            "みずほ銀行",
+						BranchName:          "本店",
+						MaskedAccountNumber: "****567",
+						AccountName:         "取引先A口座名義",
+					},
+				}, nil)
+			},
+			id:             "1",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response GetInvoiceResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), response.ID)
+				assert.Equal(t, `"4"`, rec.Header().Get(HeaderETag))
+				assert.Equal(t, "山田 太郎", response.Organization.Representative)
+				assert.Equal(t, "T7123456789012", response.Organization.RegistrationNumber)
+				assert.Equal(t, "", response.Client.RegistrationNumber)
+				assert.Equal(t, "取引先担当者A", response.Client.Representative)
+				assert.Equal(t, "****567", response.BankAccount.AccountNumber)
+			},
+		},
+		{
+			name: "振込先口座が未登録の場合, bankAccountはnull",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("GetInvoice", application.GetInvoiceDto{Principal: testPrincipal, ID: 2}).Return(&application.InvoiceDetailDto{
+					InvoiceDto: application.InvoiceDto{ID: 2},
+				}, nil)
+			},
+			id:             "2",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Nil(t, response["bankAccount"])
+			},
+		},
+		{
+			name:           "idが数値でない場合, invalid request",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid request", response["error"])
+			},
+		},
+		{
+			name: "請求書が存在しない場合, invoice not found",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("GetInvoice", application.GetInvoiceDto{Principal: testPrincipal, ID: 99}).Return(nil, commonErrors.ErrNotFound)
+			},
+			id:             "99",
+			expectedStatus: http.StatusNotFound,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invoice not found", response["error"])
+			},
+		},
+		{
+			name: "他組織の請求書の場合, invoice not found",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				// 他組織の請求書はリポジトリで絞り込まれ、存在しない扱いになる
+				mockUsecase.On("GetInvoice", application.GetInvoiceDto{Principal: testPrincipal, ID: 3}).Return(nil, commonErrors.ErrNotFound)
+			},
+			id:             "3",
+			expectedStatus: http.StatusNotFound,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invoice not found", response["error"])
+			},
+		},
+		{
+			name: "usecaseでエラーが発生した場合, could not get invoice",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("GetInvoice", application.GetInvoiceDto{Principal: testPrincipal, ID: 1}).Return(nil, errors.New("unexpected error"))
+			},
+			id:             "1",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "could not get invoice", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 新しいモックインスタンスを作成
+			mockUsecase := &testutils.MockInvoiceUsecase{}
+			tt.setupMock(mockUsecase)
+
+			// ハンドラを新規作成
+			handler := NewInvoiceHandler(mockUsecase)
+
+			// リクエストのセットアップ
+			req := httptest.NewRequest(http.MethodGet, "/invoice/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			c.Set("user", testClaims)
+
+			// ハンドラの実行
+			err := handler.GetInvoice(c)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedStatus, rec.Code)
 
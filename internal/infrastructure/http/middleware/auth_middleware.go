@@ -2,55 +2,49 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
-	"time"
+	"sync"
 
-	"github.com/auth0/go-jwt-middleware/v2/jwks"
 	"github.com/auth0/go-jwt-middleware/v2/validator"
 	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/infrastructure/auth"
 )
 
+// ErrNotAccessToken アクセストークン以外（ローカル認証のリフレッシュトークンなど）が送られた
+var ErrNotAccessToken = errors.New("token is not an access token")
+
 // CustomClaims contains custom data we want from the token.
 type CustomClaims struct {
-	Scope string `json:"scope"`
+	Subject        string `json:"sub"`
+	Scope          string `json:"scope"`
+	OrganizationID uint   `json:"org_id"`              // 所属組織ID（Auth0 Action、またはローカル認証で付与）
+	UserID         uint   `json:"user_id"`             // ユーザーID（ユーザーに紐づくトークンのみ）
+	TokenUse       string `json:"token_use,omitempty"` // トークンの用途. アクセストークンは持たない
 }
 
 // Validate satisfies validator.CustomClaims interface.
+// 署名・発行者が同じでも、用途（token_use）を持つリフレッシュトークンはアクセストークンとして受け付けない
 func (c CustomClaims) Validate(ctx context.Context) error {
+	if c.TokenUse != "" {
+		return fmt.Errorf("%w: token_use is %q", ErrNotAccessToken, c.TokenUse)
+	}
 	return nil
 }
 
 // AuthWithScopes ensures the JWT is valid and contains the required scopes.
+// トークンの検証方法は環境変数から決める（NewTokenVerifierFromEnv を参照）
 func AuthWithScopes(requiredScopes ...string) echo.MiddlewareFunc {
-	issuerURLString := fmt.Sprintf("https://%s/", os.Getenv("AUTH0_DOMAIN"))
-
-	// URLをパース
-	issuerURL, err := url.Parse(issuerURLString)
-	if err != nil {
-		log.Fatalf("Failed to parse issuer URL: %v", err)
-	}
-
-	provider := jwks.NewCachingProvider(issuerURL, 5*time.Minute)
-
-	jwtValidator, err := validator.New(
-		provider.KeyFunc,
-		validator.RS256,
-		issuerURL.String(),
-		[]string{os.Getenv("AUTH0_AUDIENCE")},
-		validator.WithCustomClaims(func() validator.CustomClaims {
-			return &CustomClaims{}
-		}),
-		validator.WithAllowedClockSkew(time.Minute),
-	)
-	if err != nil {
-		log.Fatalf("Failed to set up the jwt validator: %v", err)
-	}
+	return AuthWithVerifier(DefaultVerifier(), requiredScopes...)
+}
 
+// AuthWithVerifier 指定した TokenVerifier でトークンを検証し、必要なスコープを持つか確認する
+func AuthWithVerifier(verifier TokenVerifier, requiredScopes ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// Authorization ヘッダーからトークンを取得
@@ -66,17 +60,14 @@ func AuthWithScopes(requiredScopes ...string) echo.MiddlewareFunc {
 			}
 
 			// Validate JWT
-			validatedToken, err := jwtValidator.ValidateToken(c.Request().Context(), token)
+			claims, err := verifier.Verify(c.Request().Context(), token)
 			if err != nil {
+				if errors.Is(err, ErrInvalidClaims) {
+					return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token claims")
+				}
 				return echo.NewHTTPError(http.StatusUnauthorized, "Failed to validate token")
 			}
 
-			// Extract claims
-			claims, ok := validatedToken.(*validator.ValidatedClaims).CustomClaims.(*CustomClaims)
-			if !ok {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token claims")
-			}
-
 			// スコープのチェック TODO: 独立させてもよさそう
 			if !hasRequiredScopes(claims.Scope, requiredScopes) {
 				return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Insufficient scope: required %v", requiredScopes))
@@ -91,6 +82,47 @@ func AuthWithScopes(requiredScopes ...string) echo.MiddlewareFunc {
 	}
 }
 
+var (
+	defaultVerifierOnce  sync.Once
+	defaultTokenVerifier TokenVerifier
+)
+
+// DefaultVerifier 環境変数から作成した TokenVerifier. 全てのルートで公開鍵のキャッシュを共有する
+func DefaultVerifier() TokenVerifier {
+	defaultVerifierOnce.Do(func() {
+		verifier, err := NewTokenVerifierFromEnv()
+		if err != nil {
+			log.Fatalf("Failed to set up the token verifier: %v", err)
+		}
+		defaultTokenVerifier = verifier
+	})
+	return defaultTokenVerifier
+}
+
+// NewTokenVerifierFromEnv 環境変数からトークンの検証方法を決める.
+//   - AUTH_PROVIDER=local: ローカル認証の署名鍵で検証する
+//   - AUTH0_JWKS_FILE を指定した場合: ファイルに保存した Auth0 の JWKS で検証する（ネットワークを使わない）
+//   - それ以外: Auth0 の JWKS を取得して検証する
+func NewTokenVerifierFromEnv() (TokenVerifier, error) {
+	if auth.Provider() == auth.ProviderLocal {
+		config, err := auth.LoadLocalConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load local auth config: %w", err)
+		}
+		return NewPublicKeyVerifier(config.Key.PublicKey(), string(config.Key.Algorithm), config.Issuer, config.Audience)
+	}
+
+	issuerURL, err := url.Parse(fmt.Sprintf("https://%s/", os.Getenv("AUTH0_DOMAIN")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issuer URL: %w", err)
+	}
+	audience := os.Getenv("AUTH0_AUDIENCE")
+	if path := os.Getenv("AUTH0_JWKS_FILE"); path != "" {
+		return NewStaticJWKSVerifier(path, issuerURL.String(), audience)
+	}
+	return NewRemoteJWKSVerifier(issuerURL, string(validator.RS256), audience)
+}
+
 // hasRequiredScopes checks if the token contains all required scopes.
 func hasRequiredScopes(tokenScopes string, requiredScopes []string) bool {
 	tokenScopeList := strings.Split(tokenScopes, " ")
@@ -4,55 +4,168 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/domain/gateway"
+	"github.com/take73/invoice-api-example/internal/infrastructure/auth"
 	"github.com/take73/invoice-api-example/internal/infrastructure/http/middleware/testutil"
 )
 
-func Test_Middleware_AuthWithScopes(t *testing.T) {
+// serveWithAuth ミドルウェアを通してリクエストを処理し、ハンドラーに渡ったクレームを返す
+func serveWithAuth(t *testing.T, verifier TokenVerifier, scopes []string, authorization string) (*httptest.ResponseRecorder, *CustomClaims) {
+	t.Helper()
 	e := echo.New()
 
+	var claims *CustomClaims
+	e.GET("/secure-data", func(c echo.Context) error {
+		claims, _ = c.Get("user").(*CustomClaims)
+		return c.JSON(http.StatusOK, map[string]string{
+			"message": "Access granted",
+		})
+	}, AuthWithVerifier(verifier, scopes...))
+
+	req := httptest.NewRequest(http.MethodGet, "/secure-data", nil)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec, claims
+}
+
+func Test_Middleware_AuthWithScopes(t *testing.T) {
+	issuer, err := testutil.NewTestIssuer()
+	assert.NoError(t, err)
+	otherIssuer, err := testutil.NewTestIssuer()
+	assert.NoError(t, err)
+
+	verifier, err := NewPublicKeyVerifier(issuer.PublicKey(), issuer.Algorithm(), issuer.Issuer, issuer.Audience)
+	assert.NoError(t, err)
+
+	bearer := func(opts ...testutil.TokenOption) func() (string, error) {
+		return func() (string, error) {
+			token, err := issuer.NewToken(opts...)
+			return "Bearer " + token, err
+		}
+	}
+
 	tests := []struct {
 		name               string
-		scope              string // middlewareに設定するスコープ
-		token              func() (string, error)
+		scopes             []string // middlewareに設定するスコープ
+		authorization      func() (string, error)
 		expectedStatusCode int
 		expectedMessage    string
 	}{
 		{
 			name:               "read:invoiceをもっているトークン",
-			scope:              "read:invoice",
-			token:              func() (string, error) { return testutil.NewClientAToken() },
+			scopes:             []string{"read:invoice"},
+			authorization:      bearer(testutil.WithScopes("read:invoice")),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "Access granted",
+		},
+		{
+			name:               "必要なスコープを全てもっているトークン",
+			scopes:             []string{"read:invoice", "write:invoice"},
+			authorization:      bearer(testutil.WithScopes("write:invoice", "read:invoice", "read:client")),
 			expectedStatusCode: http.StatusOK,
 			expectedMessage:    "Access granted",
 		},
 		{
 			name:               "write:invoiceをもっているトークン",
-			scope:              "read:invoice",
-			token:              func() (string, error) { return testutil.NewClientBToken() },
+			scopes:             []string{"read:invoice"},
+			authorization:      bearer(testutil.WithScopes("write:invoice")),
 			expectedStatusCode: http.StatusForbidden,
 			expectedMessage:    "Insufficient scope: required [read:invoice]",
 		},
+		{
+			name:               "必要なスコープの一部しかもたないトークン",
+			scopes:             []string{"read:invoice", "write:invoice"},
+			authorization:      bearer(testutil.WithScopes("read:invoice")),
+			expectedStatusCode: http.StatusForbidden,
+			expectedMessage:    "Insufficient scope: required [read:invoice write:invoice]",
+		},
 		{
 			name:               "read:invoice,write:invoiceどちらもないトークン",
-			scope:              "read:invoice",
-			token:              func() (string, error) { return testutil.NewClientCToken() },
+			scopes:             []string{"read:invoice"},
+			authorization:      bearer(),
 			expectedStatusCode: http.StatusForbidden,
 			expectedMessage:    "Insufficient scope: required [read:invoice]",
 		},
+		{
+			name:               "スコープを指定しない場合は署名等の検証だけを行う",
+			scopes:             nil,
+			authorization:      bearer(),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "Access granted",
+		},
 		{
 			name:               "tokenなし",
-			scope:              "read:invoice",
-			token:              func() (string, error) { return "", nil },
+			scopes:             []string{"read:invoice"},
+			authorization:      func() (string, error) { return "", nil },
 			expectedStatusCode: http.StatusUnauthorized,
 			expectedMessage:    "Missing Authorization header",
 		},
 		{
-			name:               "擬似的になトークン",
-			scope:              "read:invoice",
-			token:              func() (string, error) { return testutil.GenerateMockJWT() },
+			name:   "Bearerでないヘッダー",
+			scopes: []string{"read:invoice"},
+			authorization: func() (string, error) {
+				token, err := issuer.NewToken(testutil.WithScopes("read:invoice"))
+				return "Token " + token, err
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedMessage:    "Invalid Authorization header format",
+		},
+		{
+			name:               "有効期限切れのトークン",
+			scopes:             []string{"read:invoice"},
+			authorization:      bearer(testutil.WithScopes("read:invoice"), testutil.WithExpiry(time.Now().Add(-2*time.Minute))),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedMessage:    "Failed to validate token",
+		},
+		{
+			name:               "許容する時刻のずれの範囲内で期限切れのトークン",
+			scopes:             []string{"read:invoice"},
+			authorization:      bearer(testutil.WithScopes("read:invoice"), testutil.WithExpiry(time.Now().Add(-30*time.Second))),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "Access granted",
+		},
+		{
+			name:               "対象（aud）が違うトークン",
+			scopes:             []string{"read:invoice"},
+			authorization:      bearer(testutil.WithScopes("read:invoice"), testutil.WithAudience("https://other-api.example.com")),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedMessage:    "Failed to validate token",
+		},
+		{
+			name:               "発行者（iss）が違うトークン",
+			scopes:             []string{"read:invoice"},
+			authorization:      bearer(testutil.WithScopes("read:invoice"), testutil.WithIssuer("https://other-issuer.example.com/")),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedMessage:    "Failed to validate token",
+		},
+		{
+			name:   "他の鍵で署名したトークン",
+			scopes: []string{"read:invoice"},
+			authorization: func() (string, error) {
+				token, err := otherIssuer.NewToken(testutil.WithScopes("read:invoice"))
+				return "Bearer " + token, err
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedMessage:    "Failed to validate token",
+		},
+		{
+			name:   "擬似的になトークン",
+			scopes: []string{"read:invoice"},
+			authorization: func() (string, error) {
+				token, err := testutil.GenerateMockJWT()
+				return "Bearer " + token, err
+			},
 			expectedStatusCode: http.StatusUnauthorized,
 			expectedMessage:    "Failed to validate token",
 		},
@@ -60,31 +173,10 @@ func Test_Middleware_AuthWithScopes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Middleware to test
-			e.Use(AuthWithScopes(tt.scope))
-
-			// Mock handler
-			e.GET("/secure-data", func(c echo.Context) error {
-				return c.JSON(http.StatusOK, map[string]string{
-					"message": "Access granted",
-				})
-			})
-
-			// Create a new HTTP request with the Authorization header
-			req := httptest.NewRequest(http.MethodGet, "/secure-data", nil)
-
-			token, err := tt.token()
+			authorization, err := tt.authorization()
 			assert.NoError(t, err)
 
-			if token != "" {
-				req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
-			}
-
-			rec := httptest.NewRecorder()
-			e.NewContext(req, rec)
-
-			// Serve the request
-			e.ServeHTTP(rec, req)
+			rec, _ := serveWithAuth(t, verifier, tt.scopes, authorization)
 
 			// Assert response status code and message
 			assert.Equal(t, tt.expectedStatusCode, rec.Code)
@@ -97,3 +189,120 @@ func Test_Middleware_AuthWithScopes(t *testing.T) {
 		})
 	}
 }
+
+func Test_Middleware_AuthWithScopes_Claims(t *testing.T) {
+	issuer, err := testutil.NewTestIssuer()
+	assert.NoError(t, err)
+	verifier, err := NewPublicKeyVerifier(issuer.PublicKey(), issuer.Algorithm(), issuer.Issuer, issuer.Audience)
+	assert.NoError(t, err)
+
+	token, err := issuer.NewToken(
+		testutil.WithSubject("auth0|user1"),
+		testutil.WithScopes("read:invoice"),
+		testutil.WithOrganizationID(1),
+		testutil.WithUserID(2),
+	)
+	assert.NoError(t, err)
+
+	rec, claims := serveWithAuth(t, verifier, []string{"read:invoice"}, "Bearer "+token)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, &CustomClaims{Subject: "auth0|user1", Scope: "read:invoice", OrganizationID: 1, UserID: 2}, claims)
+}
+
+func Test_Middleware_LocalRefreshToken(t *testing.T) {
+	// リフレッシュトークンの aud（発行者）がアクセストークンの aud と同じ設定でも、用途で区別する
+	t.Setenv("AUTH_PROVIDER", auth.ProviderLocal)
+	t.Setenv("LOCAL_AUTH_ISSUER", "http://localhost:1323/")
+	t.Setenv("LOCAL_AUTH_AUDIENCE", "http://localhost:1323/")
+	t.Setenv("LOCAL_AUTH_SIGNING_KEY_FILE", "")
+
+	verifier, err := NewTokenVerifierFromEnv()
+	assert.NoError(t, err)
+	config, err := auth.LoadLocalConfig()
+	assert.NoError(t, err)
+	issuer, err := auth.NewLocalIssuer(config)
+	assert.NoError(t, err)
+	tokens, err := issuer.Issue(gateway.TokenClaims{
+		Subject:        "local|1",
+		UserID:         1,
+		OrganizationID: 1,
+		Scopes:         []string{"read:invoice"},
+	}, time.Now())
+	assert.NoError(t, err)
+
+	t.Run("アクセストークン", func(t *testing.T) {
+		rec, claims := serveWithAuth(t, verifier, []string{"read:invoice"}, "Bearer "+tokens.AccessToken)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, &CustomClaims{Subject: "local|1", Scope: "read:invoice", OrganizationID: 1, UserID: 1}, claims)
+	})
+
+	t.Run("リフレッシュトークンはアクセストークンとして使えない", func(t *testing.T) {
+		rec, claims := serveWithAuth(t, verifier, nil, "Bearer "+tokens.RefreshToken)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Nil(t, claims)
+	})
+}
+
+func Test_StaticJWKSVerifier(t *testing.T) {
+	issuer, err := testutil.NewTestIssuer()
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(issuer.JWKS())
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+
+	verifier, err := NewStaticJWKSVerifier(path, issuer.Issuer, issuer.Audience)
+	assert.NoError(t, err)
+
+	token, err := issuer.NewToken(testutil.WithScopes("read:invoice"))
+	assert.NoError(t, err)
+	rec, _ := serveWithAuth(t, verifier, []string{"read:invoice"}, "Bearer "+token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// JWKS にない鍵で署名したトークンは拒否する
+	otherIssuer, err := testutil.NewTestIssuer()
+	assert.NoError(t, err)
+	token, err = otherIssuer.NewToken(testutil.WithScopes("read:invoice"))
+	assert.NoError(t, err)
+	rec, _ = serveWithAuth(t, verifier, []string{"read:invoice"}, "Bearer "+token)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	_, err = NewStaticJWKSVerifier(filepath.Join(t.TempDir(), "missing.json"), issuer.Issuer, issuer.Audience)
+	assert.Error(t, err)
+}
+
+func Test_RemoteJWKSVerifier(t *testing.T) {
+	issuer, err := testutil.NewTestIssuer()
+	assert.NoError(t, err)
+
+	// OpenID Connect Discovery と JWKS を返す発行者
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": server.URL + "/.well-known/jwks.json"})
+	})
+	mux.HandleFunc("/.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(issuer.JWKS())
+	})
+
+	issuer.Issuer = server.URL + "/"
+	issuerURL, err := url.Parse(issuer.Issuer)
+	assert.NoError(t, err)
+	verifier, err := NewRemoteJWKSVerifier(issuerURL, issuer.Algorithm(), issuer.Audience)
+	assert.NoError(t, err)
+
+	token, err := issuer.NewToken(testutil.WithScopes("read:invoice"))
+	assert.NoError(t, err)
+	rec, _ := serveWithAuth(t, verifier, []string{"read:invoice"}, "Bearer "+token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	token, err = issuer.NewToken(testutil.WithScopes("read:invoice"), testutil.WithExpiry(time.Now().Add(-time.Hour)))
+	assert.NoError(t, err)
+	rec, _ = serveWithAuth(t, verifier, []string{"read:invoice"}, "Bearer "+token)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
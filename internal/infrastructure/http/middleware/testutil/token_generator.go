@@ -1,105 +1,122 @@
 package testutil
 
 import (
-	"bytes"
-	"encoding/json"
+	"crypto"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/take73/invoice-api-example/internal/infrastructure/auth"
+	jose "gopkg.in/go-jose/go-jose.v2"
+	joseJWT "gopkg.in/go-jose/go-jose.v2/jwt"
 )
 
-type Auth0Config struct {
-	ClientID     string
-	ClientSecret string
-	Audience     string
-	Domain       string
+const (
+	TestIssuerURL = "https://test-issuer.example.com/"              // テスト用の発行者（iss）
+	TestAudience  = "https://github.com/take73/invoice-api-example" // テスト用の対象（aud）
+)
+
+// TestIssuer ネットワークを使わずにトークンを発行するテスト用の発行者. 生成した Ed25519 鍵で署名する
+type TestIssuer struct {
+	Issuer   string
+	Audience string
+	key      *auth.SigningKey
+	signer   jose.Signer
 }
 
-// reaed:invoiceスコープを持つClientAのトークンを生成
-func NewClientAToken() (string, error) {
-	config := Auth0Config{
-		ClientID:     os.Getenv("AUTH0_CLIENT_ID_A"),
-		ClientSecret: os.Getenv("AUTH0_CLIENT_SECRET_A"),
-		Audience:     os.Getenv("AUTH0_AUDIENCE"),
-		Domain:       os.Getenv("AUTH0_DOMAIN"),
+func NewTestIssuer() (*TestIssuer, error) {
+	key, err := auth.GenerateSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	signer, err := key.Signer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer: %w", err)
 	}
-	return generateAuth0JWT("read:invoice", config)
+	return &TestIssuer{Issuer: TestIssuerURL, Audience: TestAudience, key: key, signer: signer}, nil
 }
 
-// write:invoiceスコープを持つClientBのトークンを生成
-func NewClientBToken() (string, error) {
-	config := Auth0Config{
-		ClientID:     os.Getenv("AUTH0_CLIENT_ID_B"),
-		ClientSecret: os.Getenv("AUTH0_CLIENT_SECRET_B"),
-		Audience:     os.Getenv("AUTH0_AUDIENCE"),
-		Domain:       os.Getenv("AUTH0_DOMAIN"),
-	}
-	return generateAuth0JWT("write:invoice", config)
+// PublicKey 検証に使う公開鍵
+func (i *TestIssuer) PublicKey() crypto.PublicKey {
+	return i.key.PublicKey()
 }
 
-// スコープを持たないClientCのトークンを生成
-func NewClientCToken() (string, error) {
-	config := Auth0Config{
-		ClientID:     os.Getenv("AUTH0_CLIENT_ID_C"),
-		ClientSecret: os.Getenv("AUTH0_CLIENT_SECRET_C"),
-		Audience:     os.Getenv("AUTH0_AUDIENCE"),
-		Domain:       os.Getenv("AUTH0_DOMAIN"),
-	}
-	return generateAuth0JWT("", config)
+// Algorithm 署名アルゴリズム
+func (i *TestIssuer) Algorithm() string {
+	return string(i.key.Algorithm)
 }
 
-func generateAuth0JWT(scope string, config Auth0Config) (string, error) {
-	url := "https://" + config.Domain + "/oauth/token"
+// JWKS 公開鍵の JSON Web Key Set
+func (i *TestIssuer) JWKS() jose.JSONWebKeySet {
+	return i.key.JWKS()
+}
 
-	// Payload
-	payload := map[string]string{
-		"client_id":     config.ClientID,
-		"client_secret": config.ClientSecret,
-		"audience":      config.Audience,
-		"grant_type":    "client_credentials",
-	}
-	if scope != "" {
-		payload["scope"] = scope
-	}
+// tokenClaims テスト用のトークンのクレーム. ミドルウェアの CustomClaims と同じ名前にする
+type tokenClaims struct {
+	joseJWT.Claims
+	Scope          string `json:"scope,omitempty"`
+	OrganizationID uint   `json:"org_id,omitempty"`
+	UserID         uint   `json:"user_id,omitempty"`
+}
 
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal payload: %w", err)
-	}
+// TokenOption 発行するトークンのクレームを変更する
+type TokenOption func(*tokenClaims)
 
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
+// WithScopes スコープを付与する
+func WithScopes(scopes ...string) TokenOption {
+	return func(c *tokenClaims) { c.Scope = strings.Join(scopes, " ") }
+}
 
-	req.Header.Set("Content-Type", "application/json")
+// WithSubject 操作主体の識別子（sub）を指定する
+func WithSubject(subject string) TokenOption {
+	return func(c *tokenClaims) { c.Subject = subject }
+}
 
-	// Make the HTTP request
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer res.Body.Close()
+// WithOrganizationID 組織ID（org_id）を指定する
+func WithOrganizationID(id uint) TokenOption {
+	return func(c *tokenClaims) { c.OrganizationID = id }
+}
 
-	// Read response body
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
-	}
+// WithUserID ユーザーID（user_id）を指定する
+func WithUserID(id uint) TokenOption {
+	return func(c *tokenClaims) { c.UserID = id }
+}
 
-	// Parse response JSON
-	var response struct {
-		AccessToken string `json:"access_token"`
-	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to parse response JSON: %w", err)
+// WithIssuer 発行者（iss）を指定する
+func WithIssuer(issuer string) TokenOption {
+	return func(c *tokenClaims) { c.Issuer = issuer }
+}
+
+// WithAudience 対象（aud）を指定する
+func WithAudience(audience ...string) TokenOption {
+	return func(c *tokenClaims) { c.Audience = joseJWT.Audience(audience) }
+}
+
+// WithExpiry 有効期限（exp）を指定する
+func WithExpiry(expiry time.Time) TokenOption {
+	return func(c *tokenClaims) { c.Expiry = joseJWT.NewNumericDate(expiry) }
+}
+
+// NewToken トークンを発行する. 既定では1時間有効で、スコープ・組織IDを持たない
+func (i *TestIssuer) NewToken(opts ...TokenOption) (string, error) {
+	now := time.Now()
+	claims := tokenClaims{Claims: joseJWT.Claims{
+		Issuer:   i.Issuer,
+		Subject:  "test|client",
+		Audience: joseJWT.Audience{i.Audience},
+		IssuedAt: joseJWT.NewNumericDate(now),
+		Expiry:   joseJWT.NewNumericDate(now.Add(time.Hour)),
+	}}
+	for _, opt := range opts {
+		opt(&claims)
 	}
 
-	return response.AccessToken, nil
+	token, err := joseJWT.Signed(i.signer).Claims(claims).CompactSerialize()
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, nil
 }
 
 // GenerateMockJWT creates a mock JWT with the given scope
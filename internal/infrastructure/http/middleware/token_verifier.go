@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/auth0/go-jwt-middleware/v2/jwks"
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	jose "gopkg.in/go-jose/go-jose.v2"
+)
+
+// ErrInvalidClaims トークンの署名は正しいがクレームを取得できない
+var ErrInvalidClaims = errors.New("invalid token claims")
+
+// TokenVerifier アクセストークンの署名・発行者・対象・有効期限を検証してクレームを返す
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*CustomClaims, error)
+}
+
+// jwtVerifier go-jwt-middleware の validator による TokenVerifier. 検証に使う公開鍵の取得方法だけが実装ごとに異なる
+type jwtVerifier struct {
+	validator *validator.Validator
+}
+
+func newJWTVerifier(keyFunc func(context.Context) (interface{}, error), algorithm string, issuer, audience string) (TokenVerifier, error) {
+	jwtValidator, err := validator.New(
+		keyFunc,
+		validator.SignatureAlgorithm(algorithm),
+		issuer,
+		[]string{audience},
+		validator.WithCustomClaims(func() validator.CustomClaims {
+			return &CustomClaims{}
+		}),
+		validator.WithAllowedClockSkew(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the jwt validator: %w", err)
+	}
+	return &jwtVerifier{validator: jwtValidator}, nil
+}
+
+func (v *jwtVerifier) Verify(ctx context.Context, token string) (*CustomClaims, error) {
+	validatedToken, err := v.validator.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := validatedToken.(*validator.ValidatedClaims).CustomClaims.(*CustomClaims)
+	if !ok {
+		return nil, ErrInvalidClaims
+	}
+	return claims, nil
+}
+
+// NewRemoteJWKSVerifier 発行者の OpenID Connect Discovery で JWKS を取得してトークンを検証する. 公開鍵は5分間キャッシュする
+func NewRemoteJWKSVerifier(issuerURL *url.URL, algorithm, audience string) (TokenVerifier, error) {
+	provider := jwks.NewCachingProvider(issuerURL, 5*time.Minute)
+	return newJWTVerifier(provider.KeyFunc, algorithm, issuerURL.String(), audience)
+}
+
+// NewStaticJWKSVerifier ファイルに保存した JWKS でトークンを検証する. ネットワークを使わない.
+// 署名アルゴリズムは鍵の alg（未指定の場合は鍵の種類）から決め、全ての鍵で同じである必要がある
+func NewStaticJWKSVerifier(path, issuer, audience string) (TokenVerifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+
+	var keySet jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keySet); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+	if len(keySet.Keys) == 0 {
+		return nil, errors.New("JWKS has no keys")
+	}
+
+	algorithm := ""
+	for _, key := range keySet.Keys {
+		keyAlgorithm := key.Algorithm
+		if keyAlgorithm == "" {
+			keyAlgorithm = algorithmForKey(key.Key)
+		}
+		if algorithm != "" && keyAlgorithm != algorithm {
+			return nil, fmt.Errorf("JWKS keys must use the same algorithm, got %q and %q", algorithm, keyAlgorithm)
+		}
+		algorithm = keyAlgorithm
+	}
+
+	return newJWTVerifier(func(context.Context) (interface{}, error) { return &keySet, nil }, algorithm, issuer, audience)
+}
+
+// NewPublicKeyVerifier 同じプロセスで署名したトークンを公開鍵で検証する. ローカル認証やテスト用の発行者に使う
+func NewPublicKeyVerifier(publicKey crypto.PublicKey, algorithm, issuer, audience string) (TokenVerifier, error) {
+	return newJWTVerifier(func(context.Context) (interface{}, error) { return publicKey, nil }, algorithm, issuer, audience)
+}
+
+// algorithmForKey 鍵の種類に対応する署名アルゴリズム
+func algorithmForKey(key interface{}) string {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return string(validator.RS256)
+	case ed25519.PublicKey:
+		return string(validator.EdDSA)
+	}
+	return ""
+}
@@ -0,0 +1,260 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+type OrganizationHandler struct {
+	usecase application.OrganizationUsecase
+	now     func() time.Time // 設定の変更日時に使う現在時刻
+}
+
+func NewOrganizationHandler(usecase application.OrganizationUsecase) *OrganizationHandler {
+	return &OrganizationHandler{usecase: usecase, now: time.Now}
+}
+
+// OrganizationRequest 組織のプロフィール. 形式の詳細なチェックはドメインモデルで行う
+type OrganizationRequest struct {
+	Name               string `json:"name" validate:"required"`           // 必須, 法人名
+	RegistrationNumber string `json:"registrationNumber"`                 // 適格請求書発行事業者の登録番号
+	Representative     string `json:"representative" validate:"required"` // 必須, 代表者名
+	PhoneNumber        string `json:"phoneNumber"`                        // 電話番号
+	PostalCode         string `json:"postalCode" validate:"required"`     // 必須, 郵便番号
+	Address            string `json:"address" validate:"required"`        // 必須, 住所
+}
+
+// OrganizationSettingsRequest 組織の設定. 省略した項目は既定値になる
+type OrganizationSettingsRequest struct {
+	DefaultFeePlanID uint                `json:"defaultFeePlanId"`                                                        // 組織ごとの契約プランがない期間に適用する手数料プラン
+	RoundingPolicy   string              `json:"roundingPolicy" validate:"omitempty,oneof=truncate ceil half_up bankers"` // 端数処理（既定: truncate）
+	PaymentTermsDays int                 `json:"paymentTermsDays" validate:"omitempty,min=1,max=365"`                     // 支払期日までの日数（既定: 30）
+	PaymentMethod    string              `json:"paymentMethod" validate:"omitempty,oneof=transfer_file gateway"`          // 支払方法（既定: transfer_file）
+	Notification     NotificationRequest `json:"notification"`                                                            // 通知設定
+}
+
+type NotificationRequest struct {
+	Email          string `json:"email"`          // 通知先のメールアドレス
+	OnPaid         bool   `json:"onPaid"`         // 支払済みになったときに通知する
+	OnPaymentError bool   `json:"onPaymentError"` // 支払に失敗したときに通知する
+	OnOverdue      bool   `json:"onOverdue"`      // 支払期日を過ぎたときに通知する
+}
+
+type OrganizationSettingsItem struct {
+	Version          uint                 `json:"version"`                    // 版数（設定を登録していない場合は0）
+	DefaultFeePlanID uint                 `json:"defaultFeePlanId,omitempty"` // 既定の手数料プラン（指定していない場合は省略）
+	RoundingPolicy   string               `json:"roundingPolicy"`             // 端数処理
+	PaymentTermsDays int                  `json:"paymentTermsDays"`           // 支払期日までの日数
+	PaymentMethod    string               `json:"paymentMethod"`              // 支払方法
+	Notification     NotificationResponse `json:"notification"`               // 通知設定
+	CreatedBy        string               `json:"createdBy,omitempty"`        // 設定を変更した操作主体
+	CreatedAt        *time.Time           `json:"createdAt"`                  // 設定を変更した日時（設定を登録していない場合は null）
+}
+
+type NotificationResponse struct {
+	Email          string `json:"email"`          // 通知先のメールアドレス
+	OnPaid         bool   `json:"onPaid"`         // 支払済みになったときに通知する
+	OnPaymentError bool   `json:"onPaymentError"` // 支払に失敗したときに通知する
+	OnOverdue      bool   `json:"onOverdue"`      // 支払期日を過ぎたときに通知する
+}
+
+type ListOrganizationSettingsVersionsResponse struct {
+	Versions []OrganizationSettingsItem `json:"versions"` // 新しい版から順に並べる
+}
+
+func (h *OrganizationHandler) GetOrganization(c echo.Context) error {
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	organization, err := h.usecase.GetOrganization(application.GetOrganizationDto{Principal: principal})
+	if err != nil {
+		return organizationErrorResponse(c, err, "could not get organization")
+	}
+
+	return c.JSON(http.StatusOK, newOrganizationDetail(organization))
+}
+
+// UpdateOrganization 組織のプロフィールを置き換える
+func (h *OrganizationHandler) UpdateOrganization(c echo.Context) error {
+	var req OrganizationRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	organization, err := h.usecase.UpdateOrganization(application.UpdateOrganizationDto{
+		Principal:          principal,
+		Name:               req.Name,
+		RegistrationNumber: req.RegistrationNumber,
+		Representative:     req.Representative,
+		PhoneNumber:        req.PhoneNumber,
+		PostalCode:         req.PostalCode,
+		Address:            req.Address,
+	})
+	if err != nil {
+		return organizationErrorResponse(c, err, "could not update organization")
+	}
+
+	return c.JSON(http.StatusOK, newOrganizationDetail(organization))
+}
+
+func (h *OrganizationHandler) GetSettings(c echo.Context) error {
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	settings, err := h.usecase.GetSettings(application.GetOrganizationDto{Principal: principal})
+	if err != nil {
+		return organizationErrorResponse(c, err, "could not get organization settings")
+	}
+
+	return c.JSON(http.StatusOK, newOrganizationSettingsItem(settings))
+}
+
+// UpdateSettings 設定を置き換える. 変更のたびに新しい版を作成する
+func (h *OrganizationHandler) UpdateSettings(c echo.Context) error {
+	var req OrganizationSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	settings, err := h.usecase.UpdateSettings(application.UpdateOrganizationSettingsDto{
+		Principal:        principal,
+		DefaultFeePlanID: req.DefaultFeePlanID,
+		RoundingPolicy:   req.RoundingPolicy,
+		PaymentTermsDays: req.PaymentTermsDays,
+		PaymentMethod:    req.PaymentMethod,
+		Notification: application.NotificationPreferencesDto{
+			Email:          req.Notification.Email,
+			OnPaid:         req.Notification.OnPaid,
+			OnPaymentError: req.Notification.OnPaymentError,
+			OnOverdue:      req.Notification.OnOverdue,
+		},
+		Now: h.now(),
+	})
+	if err != nil {
+		return organizationErrorResponse(c, err, "could not update organization settings")
+	}
+
+	return c.JSON(http.StatusOK, newOrganizationSettingsItem(settings))
+}
+
+func (h *OrganizationHandler) ListSettingsVersions(c echo.Context) error {
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	versions, err := h.usecase.ListSettingsVersions(application.GetOrganizationDto{Principal: principal})
+	if err != nil {
+		return organizationErrorResponse(c, err, "could not list organization settings")
+	}
+
+	response := ListOrganizationSettingsVersionsResponse{Versions: make([]OrganizationSettingsItem, len(versions))}
+	for i, settings := range versions {
+		response.Versions[i] = newOrganizationSettingsItem(settings)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetSettingsVersion 指定した版の設定. 請求書の settingsVersion から作成時の設定を確認する
+func (h *OrganizationHandler) GetSettingsVersion(c echo.Context) error {
+	version, ok := parseIDParam(c, "version")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	settings, err := h.usecase.GetSettingsVersion(application.GetOrganizationSettingsVersionDto{
+		Principal: principal,
+		Version:   version,
+	})
+	if err != nil {
+		return organizationErrorResponse(c, err, "could not get organization settings")
+	}
+
+	return c.JSON(http.StatusOK, newOrganizationSettingsItem(settings))
+}
+
+// organizationErrorResponse 組織のプロフィール・設定の管理のエラーをレスポンスに変換する
+func organizationErrorResponse(c echo.Context, err error, message string) error {
+	switch {
+	case isAuthorizationError(err):
+		return authorizationErrorResponse(c, err)
+	case errors.Is(err, commonErrors.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "organization settings not found"})
+	case errors.Is(err, commonErrors.ErrConflict):
+		log.Printf("Organization settings updated concurrently: %v", err)
+		return c.JSON(http.StatusConflict, map[string]string{"error": "organization settings were updated concurrently"})
+	case errors.Is(err, model.ErrInvalidOrganization), errors.Is(err, model.ErrInvalidOrganizationSettings):
+		log.Printf("Invalid organization: %v", err)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Failed to manage organization Error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
+
+func newOrganizationDetail(organization *application.OrganizationDto) OrganizationDetail {
+	return OrganizationDetail{
+		ID:                 organization.ID,
+		Name:               organization.Name,
+		RegistrationNumber: organization.RegistrationNumber,
+		Representative:     organization.Representative,
+		PhoneNumber:        organization.PhoneNumber,
+		PostalCode:         organization.PostalCode,
+		Address:            organization.Address,
+	}
+}
+
+func newOrganizationSettingsItem(settings *application.OrganizationSettingsDto) OrganizationSettingsItem {
+	return OrganizationSettingsItem{
+		Version:          settings.Version,
+		DefaultFeePlanID: settings.DefaultFeePlanID,
+		RoundingPolicy:   settings.RoundingPolicy,
+		PaymentTermsDays: settings.PaymentTermsDays,
+		PaymentMethod:    settings.PaymentMethod,
+		Notification: NotificationResponse{
+			Email:          settings.Notification.Email,
+			OnPaid:         settings.Notification.OnPaid,
+			OnPaymentError: settings.Notification.OnPaymentError,
+			OnOverdue:      settings.Notification.OnOverdue,
+		},
+		CreatedBy: settings.CreatedBy,
+		CreatedAt: settings.CreatedAt,
+	}
+}
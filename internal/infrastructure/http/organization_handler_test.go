@@ -0,0 +1,284 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+func Test_OrganizationHandler_UpdateOrganization(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.UpdateOrganizationDto{
+		Principal:      testPrincipal,
+		Name:           "株式会社サンプル",
+		Representative: "山田 太郎",
+		PostalCode:     "100-0001",
+		Address:        "東京都千代田区丸の内1-1-1",
+	}
+	payload := func() map[string]interface{} {
+		return map[string]interface{}{
+			"name":           "株式会社サンプル",
+			"representative": "山田 太郎",
+			"postalCode":     "100-0001",
+			"address":        "東京都千代田区丸の内1-1-1",
+		}
+	}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockOrganizationUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockOrganizationUsecase) {
+				mockUsecase.On("UpdateOrganization", dto).Return(&application.OrganizationDto{ID: 1, Name: "株式会社サンプル"}, nil)
+			},
+			payload:        payload(),
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response OrganizationDetail
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, uint(1), response.ID)
+				assert.Equal(t, "株式会社サンプル", response.Name)
+			},
+		},
+		{
+			name:      "住所がない",
+			setupMock: func(mockUsecase *testutils.MockOrganizationUsecase) {}, // Mock is not called in this case
+			payload: func() map[string]interface{} {
+				p := payload()
+				delete(p, "address")
+				return p
+			}(),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "登録番号が不正",
+			setupMock: func(mockUsecase *testutils.MockOrganizationUsecase) {
+				invalid := dto
+				invalid.RegistrationNumber = "T123"
+				mockUsecase.On("UpdateOrganization", invalid).Return(nil, fmt.Errorf("%w: registration number is invalid", model.ErrInvalidOrganization))
+			},
+			payload: func() map[string]interface{} {
+				p := payload()
+				p["registrationNumber"] = "T123"
+				return p
+			}(),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "invalid organization: registration number is invalid", response["error"])
+			},
+		},
+		{
+			name: "組織を特定できない",
+			setupMock: func(mockUsecase *testutils.MockOrganizationUsecase) {
+				mockUsecase.On("UpdateOrganization", dto).Return(nil, commonErrors.ErrUnauthorized)
+			},
+			payload:        payload(),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockOrganizationUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewOrganizationHandler(mockUsecase)
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPut, "/organization", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			err := handler.UpdateOrganization(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_OrganizationHandler_UpdateSettings(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockOrganizationUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockOrganizationUsecase) {
+				mockUsecase.On("UpdateSettings", application.UpdateOrganizationSettingsDto{
+					Principal:        testPrincipal,
+					DefaultFeePlanID: 2,
+					RoundingPolicy:   "half_up",
+					PaymentTermsDays: 45,
+					Notification:     application.NotificationPreferencesDto{Email: "billing@example.com", OnPaid: true},
+					Now:              testNow,
+				}).Return(&application.OrganizationSettingsDto{
+					Version: 2, DefaultFeePlanID: 2, RoundingPolicy: "half_up", PaymentTermsDays: 45,
+					Notification: application.NotificationPreferencesDto{Email: "billing@example.com", OnPaid: true},
+					CreatedBy:    "auth0|user1", CreatedAt: &testNow,
+				}, nil)
+			},
+			payload: map[string]interface{}{
+				"defaultFeePlanId": 2,
+				"roundingPolicy":   "half_up",
+				"paymentTermsDays": 45,
+				"notification":     map[string]interface{}{"email": "billing@example.com", "onPaid": true},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, float64(2), response["version"])
+				assert.Equal(t, "auth0|user1", response["createdBy"])
+				assert.Equal(t, "2025-06-01T12:00:00Z", response["createdAt"])
+			},
+		},
+		{
+			name:           "端数処理が不正",
+			setupMock:      func(mockUsecase *testutils.MockOrganizationUsecase) {}, // Mock is not called in this case
+			payload:        map[string]interface{}{"roundingPolicy": "floor"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "支払期日までの日数が上限を超える",
+			setupMock:      func(mockUsecase *testutils.MockOrganizationUsecase) {}, // Mock is not called in this case
+			payload:        map[string]interface{}{"paymentTermsDays": 366},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "選択できない手数料プラン",
+			setupMock: func(mockUsecase *testutils.MockOrganizationUsecase) {
+				mockUsecase.On("UpdateSettings", application.UpdateOrganizationSettingsDto{
+					Principal:        testPrincipal,
+					DefaultFeePlanID: 99,
+					Now:              testNow,
+				}).Return(nil, fmt.Errorf("%w: default fee plan 99 is not available", model.ErrInvalidOrganizationSettings))
+			},
+			payload:        map[string]interface{}{"defaultFeePlanId": 99},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "同時に更新された",
+			setupMock: func(mockUsecase *testutils.MockOrganizationUsecase) {
+				mockUsecase.On("UpdateSettings", application.UpdateOrganizationSettingsDto{
+					Principal: testPrincipal,
+					Now:       testNow,
+				}).Return(nil, commonErrors.ErrConflict)
+			},
+			payload:        map[string]interface{}{},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockOrganizationUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewOrganizationHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPut, "/organization/settings", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			err := handler.UpdateSettings(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_OrganizationHandler_GetSettingsVersion(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockOrganizationUsecase)
+		version        string
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockOrganizationUsecase) {
+				mockUsecase.On("GetSettingsVersion", application.GetOrganizationSettingsVersionDto{Principal: testPrincipal, Version: 1}).
+					Return(&application.OrganizationSettingsDto{Version: 1, RoundingPolicy: "truncate", PaymentTermsDays: 30}, nil)
+			},
+			version:        "1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "存在しない版",
+			setupMock: func(mockUsecase *testutils.MockOrganizationUsecase) {
+				mockUsecase.On("GetSettingsVersion", application.GetOrganizationSettingsVersionDto{Principal: testPrincipal, Version: 9}).
+					Return(nil, commonErrors.ErrNotFound)
+			},
+			version:        "9",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "版数が不正",
+			setupMock:      func(mockUsecase *testutils.MockOrganizationUsecase) {}, // Mock is not called in this case
+			version:        "0",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockOrganizationUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewOrganizationHandler(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodGet, "/organization/settings/versions/"+tt.version, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("version")
+			c.SetParamValues(tt.version)
+			c.Set("user", testClaims)
+
+			err := handler.GetSettingsVersion(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/shared/bankstatement"
+	"github.com/take73/invoice-api-example/internal/shared/types"
+)
+
+// maxStatementSize 明細ファイルの上限サイズ
+const maxStatementSize = 10 << 20
+
+// ReconcileStatementRequest 明細ファイル自体はリクエストボディでそのまま受け取る
+type ReconcileStatementRequest struct {
+	Format        string `query:"format" validate:"required,oneof=zengin csv"` // 必須, 明細ファイルの形式
+	DateTolerance string `query:"dateTolerance"`                               // 取引日と支払期日のずれとして許容する日数
+	DryRun        bool   `query:"dryRun"`                                      // true の場合はステータスを変更しない
+}
+
+type ReconciliationResponse struct {
+	Matched   []ReconciledLineItem `json:"matched"`
+	Unmatched []ReconciledLineItem `json:"unmatched"`
+	Ambiguous []ReconciledLineItem `json:"ambiguous"`
+	Skipped   int                  `json:"skipped"`
+	DryRun    bool                 `json:"dryRun"`
+}
+
+type ReconciledLineItem struct {
+	Line       int              `json:"line"`             // 明細の行番号
+	Reference  string           `json:"reference"`        // 照会番号など
+	Date       types.CustomDate `json:"date"`             // 取引日
+	Amount     int64            `json:"amount"`           // 取引金額
+	PayeeName  string           `json:"payeeName"`        // 相手方の名義
+	InvoiceIDs []uint           `json:"invoiceIds"`       // 照合した請求書（曖昧な場合は候補）
+	Reason     string           `json:"reason,omitempty"` // 照合できなかった理由
+}
+
+// ReconcileStatement 銀行の入出金明細を取り込み、出金と処理中の請求書を照合して支払済みにする
+func (h *InvoiceHandler) ReconcileStatement(c echo.Context) error {
+	var req ReconcileStatementRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	dateTolerance, err := parseOptionalInt(req.DateTolerance)
+	if err != nil || (dateTolerance != nil && (*dateTolerance < 0 || *dateTolerance > application.MaxReconciliationDateTolerance)) {
+		log.Printf("Invalid dateTolerance: %s", req.DateTolerance)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+	tolerance := application.DefaultReconciliationDateTolerance
+	if dateTolerance != nil {
+		tolerance = int(*dateTolerance)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(c.Request().Body, maxStatementSize+1))
+	if err != nil {
+		log.Printf("Failed to read request body Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if len(content) > maxStatementSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "statement file is too large"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	dto := application.ReconcileStatementDto{
+		Principal:     principal,
+		Format:        bankstatement.Format(req.Format),
+		Content:       content,
+		DateTolerance: tolerance,
+		DryRun:        req.DryRun,
+	}
+
+	report, err := h.usecase.ReconcileStatement(dto)
+	if err != nil {
+		var parseErr *bankstatement.ParseError
+		switch {
+		case isAuthorizationError(err):
+			return authorizationErrorResponse(c, err)
+		case errors.Is(err, model.ErrPaymentMethodMismatch):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "reconciliation is not available because the organization pays invoices through the payment gateway"})
+		case errors.As(err, &parseErr):
+			log.Printf("Invalid statement file: %v", err)
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid statement file: " + parseErr.Error()})
+		}
+		log.Printf("Failed to reconcile statement Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not reconcile statement"})
+	}
+
+	return c.JSON(http.StatusOK, ReconciliationResponse{
+		Matched:   newReconciledLineItems(report.Matched),
+		Unmatched: newReconciledLineItems(report.Unmatched),
+		Ambiguous: newReconciledLineItems(report.Ambiguous),
+		Skipped:   report.Skipped,
+		DryRun:    report.DryRun,
+	})
+}
+
+func newReconciledLineItems(lines []application.ReconciledLineDto) []ReconciledLineItem {
+	items := make([]ReconciledLineItem, len(lines))
+	for i, line := range lines {
+		items[i] = ReconciledLineItem{
+			Line:       line.LineNumber,
+			Reference:  line.Reference,
+			Date:       types.CustomDate{Time: line.Date},
+			Amount:     line.Amount,
+			PayeeName:  line.PayeeName,
+			InvoiceIDs: line.InvoiceIDs,
+			Reason:     line.Reason,
+		}
+	}
+	return items
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	"github.com/take73/invoice-api-example/internal/shared/bankstatement"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+func Test_InvoiceHandler_ReconcileStatement(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	content := "date,type,amount,payee_name\n2024-01-20,debit,10000,ﾄﾘﾋｷｻｷｴ-\n"
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockInvoiceUsecase)
+		query          string
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ReconcileStatement", application.ReconcileStatementDto{
+					Principal:     testPrincipal,
+					Format:        bankstatement.FormatCSV,
+					Content:       []byte(content),
+					DateTolerance: 3,
+				}).Return(&application.ReconciliationReportDto{
+					Matched: []application.ReconciledLineDto{
+						{LineNumber: 1, Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), Amount: 10000, PayeeName: "ﾄﾘﾋｷｻｷｴ-", InvoiceIDs: []uint{1}},
+					},
+					Skipped: 1,
+				}, nil)
+			},
+			query:          "format=csv",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response ReconciliationResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Len(t, response.Matched, 1)
+				assert.Equal(t, []uint{1}, response.Matched[0].InvoiceIDs)
+				assert.Equal(t, "2024-01-20", response.Matched[0].Date.Format("2006-01-02"))
+				assert.Empty(t, response.Unmatched)
+				assert.NotNil(t, response.Unmatched)
+				assert.Equal(t, 1, response.Skipped)
+			},
+		},
+		{
+			name: "許容日数とdryRunを指定",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ReconcileStatement", application.ReconcileStatementDto{
+					Principal:     testPrincipal,
+					Format:        bankstatement.FormatZengin,
+					Content:       []byte(content),
+					DateTolerance: 0,
+					DryRun:        true,
+				}).Return(&application.ReconciliationReportDto{DryRun: true}, nil)
+			},
+			query:          "format=zengin&dateTolerance=0&dryRun=true",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response ReconciliationResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.True(t, response.DryRun)
+			},
+		},
+		{
+			name:           "形式が未定義の値の場合, validation failed",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			query:          "format=ofx",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name:           "許容日数が上限を超える場合, validation failed",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			query:          "format=csv&dateTolerance=32",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name: "明細ファイルが不正な場合, invalid statement file",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ReconcileStatement", application.ReconcileStatementDto{
+					Principal:     testPrincipal,
+					Format:        bankstatement.FormatCSV,
+					Content:       []byte(content),
+					DateTolerance: 3,
+				}).Return(nil, &bankstatement.ParseError{Line: 2, Reason: "invalid amount \"abc\""})
+			},
+			query:          "format=csv",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid statement file: line 2: invalid amount \"abc\"", response["error"])
+			},
+		},
+		{
+			name: "支払方法が支払ゲートウェイの組織の場合, conflict",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ReconcileStatement", application.ReconcileStatementDto{
+					Principal:     testPrincipal,
+					Format:        bankstatement.FormatCSV,
+					Content:       []byte(content),
+					DateTolerance: 3,
+				}).Return(nil, model.ErrPaymentMethodMismatch)
+			},
+			query:          "format=csv",
+			expectedStatus: http.StatusConflict,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "reconciliation is not available because the organization pays invoices through the payment gateway", response["error"])
+			},
+		},
+		{
+			name: "usecaseでエラーが発生した場合, could not reconcile statement",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ReconcileStatement", application.ReconcileStatementDto{
+					Principal:     testPrincipal,
+					Format:        bankstatement.FormatCSV,
+					Content:       []byte(content),
+					DateTolerance: 3,
+				}).Return(nil, errors.New("unexpected error"))
+			},
+			query:          "format=csv",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "could not reconcile statement", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 新しいモックインスタンスを作成
+			mockUsecase := &testutils.MockInvoiceUsecase{}
+			tt.setupMock(mockUsecase)
+
+			// ハンドラを新規作成
+			handler := NewInvoiceHandler(mockUsecase)
+
+			// リクエストのセットアップ
+			req := httptest.NewRequest(http.MethodPost, "/invoice/reconciliation?"+tt.query, strings.NewReader(content))
+			req.Header.Set(echo.HeaderContentType, "text/csv")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			// ハンドラの実行
+			err := handler.ReconcileStatement(c)
+			assert.NoError(t, err)
+
+			// ステータスコードとレスポンスボディの検証
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			tt.expectedBody(t, rec)
+
+			// モックの呼び出しを検証
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -4,12 +4,84 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/take73/invoice-api-example/internal/application"
 	"github.com/take73/invoice-api-example/internal/infrastructure/http/middleware"
+	jose "gopkg.in/go-jose/go-jose.v2"
 )
 
-func RegisterRoutes(e *echo.Echo, invoiceUsecase application.InvoiceUsecase) {
+func RegisterRoutes(e *echo.Echo, invoiceUsecase application.InvoiceUsecase, taxRateUsecase application.TaxRateUsecase, clientUsecase application.ClientUsecase, bankAccountUsecase application.ClientBankAccountUsecase, organizationUsecase application.OrganizationUsecase, userUsecase application.UserUsecase, apiKeyUsecase application.APIKeyUsecase, idempotencyUsecase application.IdempotencyUsecase, authUsecase application.AuthUsecase, jwks jose.JSONWebKeySet) {
 	handler := NewInvoiceHandler(invoiceUsecase)
+	taxRateHandler := NewTaxRateHandler(taxRateUsecase)
+	clientHandler := NewClientHandler(clientUsecase)
+	bankAccountHandler := NewClientBankAccountHandler(bankAccountUsecase)
+	organizationHandler := NewOrganizationHandler(organizationUsecase)
+	userHandler := NewUserHandler(userUsecase)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyUsecase)
+
+	// 組織内の操作の権限はユースケースでロール（ユーザーに紐づかないクライアント・APIキーはスコープ）から判定するため、
+	// ここではトークンの検証だけを行う. 組織内の操作には JWT に加えてAPIキーも使える
+	authenticated := middleware.AuthWithVerifier(NewAPIKeyVerifier(apiKeyUsecase, middleware.DefaultVerifier()))
+	// 変更系のリクエストは Idempotency-Key ヘッダーで再送を検出する. キーは操作主体ごとに区別するため認証の後に置く.
+	// 招待トークン・APIキーを返すエンドポイントは応答を保存せず、再送を処理し直さずに拒否する
+	idempotent := Idempotency(idempotencyUsecase)
+	idempotentWithoutReplay := IdempotencyWithoutReplay(idempotencyUsecase)
+	// 認証しない招待の承諾は、操作主体の代わりに招待トークンごとにキーを区別する
+	idempotentByInvitationToken := IdempotencyByInvitationToken(idempotencyUsecase)
 
 	// ルート設定
-	e.POST("/invoice", handler.CreateInvoice, middleware.AuthWithScopes("write:invoice"))
-	e.GET("/invoice", handler.ListInvoice, middleware.AuthWithScopes("read:invoice"))
+	e.POST("/invoice", handler.CreateInvoice, authenticated, idempotent)
+	e.GET("/invoice", handler.ListInvoice, authenticated)
+	e.POST("/invoice/transfer-file", handler.ExportTransferFile, authenticated, idempotent)
+	e.POST("/invoice/reconciliation", handler.ReconcileStatement, authenticated, idempotent)
+	e.GET("/invoice/duplicates", handler.ReportDuplicateInvoices, authenticated)
+	e.GET("/invoice/:id", handler.GetInvoice, authenticated)
+	e.PATCH("/invoice/:id/status", handler.ChangeInvoiceStatus, authenticated, idempotent)
+
+	// 税率は全組織に共通のため、変更は管理者のスコープに限る
+	e.GET("/tax-rates", taxRateHandler.ListTaxRates, middleware.AuthWithScopes("read:tax_rate"))
+	e.POST("/tax-rates", taxRateHandler.CreateTaxRate, middleware.AuthWithScopes("admin:tax_rate"), idempotent)
+	e.GET("/tax-rates/preview", taxRateHandler.PreviewTaxRateChange, middleware.AuthWithScopes("admin:tax_rate"))
+	e.PUT("/tax-rates/:id", taxRateHandler.UpdateTaxRate, middleware.AuthWithScopes("admin:tax_rate"), idempotent)
+	e.DELETE("/tax-rates/:id", taxRateHandler.DeleteTaxRate, middleware.AuthWithScopes("admin:tax_rate"), idempotent)
+
+	e.POST("/clients", clientHandler.CreateClient, authenticated, idempotent)
+	e.GET("/clients", clientHandler.ListClients, authenticated)
+	e.GET("/clients/:id", clientHandler.GetClient, authenticated)
+	e.PUT("/clients/:id", clientHandler.UpdateClient, authenticated, idempotent)
+	e.POST("/clients/:id/archive", clientHandler.ArchiveClient, authenticated, idempotent)
+	e.GET("/clients/:id/bank-accounts", bankAccountHandler.ListBankAccounts, authenticated)
+	e.POST("/clients/:id/bank-accounts", bankAccountHandler.CreateBankAccount, authenticated, idempotent)
+	e.GET("/clients/:id/bank-accounts/:accountId", bankAccountHandler.GetBankAccount, authenticated)
+	e.PUT("/clients/:id/bank-accounts/:accountId", bankAccountHandler.UpdateBankAccount, authenticated, idempotent)
+	e.DELETE("/clients/:id/bank-accounts/:accountId", bankAccountHandler.DeleteBankAccount, authenticated, idempotent)
+	e.POST("/clients/:id/bank-accounts/:accountId/default", bankAccountHandler.SetDefaultBankAccount, authenticated, idempotent)
+
+	e.GET("/organization", organizationHandler.GetOrganization, authenticated)
+	e.PUT("/organization", organizationHandler.UpdateOrganization, authenticated, idempotent)
+	e.GET("/organization/settings", organizationHandler.GetSettings, authenticated)
+	e.PUT("/organization/settings", organizationHandler.UpdateSettings, authenticated, idempotent)
+	e.GET("/organization/settings/versions", organizationHandler.ListSettingsVersions, authenticated)
+	e.GET("/organization/settings/versions/:version", organizationHandler.GetSettingsVersion, authenticated)
+
+	e.GET("/users", userHandler.ListUsers, authenticated)
+	e.POST("/users", userHandler.InviteUser, authenticated, idempotentWithoutReplay)
+	e.GET("/users/:id", userHandler.GetUser, authenticated)
+	e.PUT("/users/:id", userHandler.UpdateUser, authenticated, idempotent)
+	e.POST("/users/:id/deactivate", userHandler.DeactivateUser, authenticated, idempotent)
+	e.POST("/users/:id/invitation", userHandler.ReinviteUser, authenticated, idempotentWithoutReplay)
+	e.PUT("/users/:id/role", userHandler.ChangeUserRole, authenticated, idempotent)
+	e.GET("/users/:id/role-history", userHandler.ListRoleChanges, authenticated)
+	// 招待されたユーザーはまだトークンを持たないため、招待トークンで本人を確認する
+	e.POST("/users/invitations/accept", userHandler.AcceptInvitation, idempotentByInvitationToken)
+
+	e.GET("/api-keys", apiKeyHandler.ListAPIKeys, authenticated)
+	e.POST("/api-keys", apiKeyHandler.CreateAPIKey, authenticated, idempotentWithoutReplay)
+	e.GET("/api-keys/:id", apiKeyHandler.GetAPIKey, authenticated)
+	e.POST("/api-keys/:id/revoke", apiKeyHandler.RevokeAPIKey, authenticated, idempotent)
+
+	// ローカル認証（AUTH_PROVIDER=local）の場合のみ、トークンを発行する
+	if authUsecase != nil {
+		authHandler := NewAuthHandler(authUsecase, jwks)
+		e.POST("/auth/login", authHandler.Login)
+		e.POST("/auth/refresh", authHandler.RefreshToken)
+		e.GET("/.well-known/jwks.json", authHandler.JWKS)
+	}
 }
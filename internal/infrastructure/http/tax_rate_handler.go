@@ -0,0 +1,269 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/types"
+)
+
+type TaxRateHandler struct {
+	usecase application.TaxRateUsecase
+	now     func() time.Time // 適用前の税率かどうかの判定に使う現在時刻
+}
+
+func NewTaxRateHandler(usecase application.TaxRateUsecase) *TaxRateHandler {
+	return &TaxRateHandler{usecase: usecase, now: time.Now}
+}
+
+type ListTaxRatesRequest struct {
+	Date     types.CustomDate `query:"date"`                                                 // 指定した場合はその日に適用される税率だけを返す
+	Category string           `query:"category" validate:"omitempty,oneof=standard reduced"` // 税区分
+}
+
+type TaxRateRequest struct {
+	StartDate types.CustomDate `json:"startDate" validate:"required_custom_date"` // 必須, 適用開始日（翌日以降）
+	Rate      float64          `json:"rate" validate:"gt=0,lt=1"`                 // 必須, 税率（例: 0.1 = 10%）
+}
+
+type CreateTaxRateRequest struct {
+	Category string `json:"category" validate:"required,oneof=standard reduced"` // 必須, 税区分
+	TaxRateRequest
+}
+
+type PreviewTaxRateChangeRequest struct {
+	Category  string           `query:"category" validate:"required,oneof=standard reduced"` // 必須, 税区分
+	StartDate types.CustomDate `query:"startDate" validate:"required_custom_date"`           // 必須, 適用開始日
+	Rate      float64          `query:"rate" validate:"gt=0,lt=1"`                           // 必須, 変更後の税率
+}
+
+type TaxRateItem struct {
+	ID        uint              `json:"id"`        // 税率ID
+	Category  string            `json:"category"`  // 税区分
+	StartDate types.CustomDate  `json:"startDate"` // 適用開始日
+	EndDate   *types.CustomDate `json:"endDate"`   // 適用終了日（現在も有効な場合は null）
+	Rate      float64           `json:"rate"`      // 税率
+}
+
+type ListTaxRatesResponse struct {
+	Data []TaxRateItem `json:"data"`
+}
+
+type TaxRateChangePreviewResponse struct {
+	Category  string            `json:"category"`  // 税区分
+	StartDate types.CustomDate  `json:"startDate"` // 適用開始日
+	EndDate   *types.CustomDate `json:"endDate"`   // 適用終了日（以降の税率が登録されていない場合は null）
+	Rate      float64           `json:"rate"`      // 変更後の税率
+	Invoices  []AffectedInvoice `json:"invoices"`  // 消費税が変わる支払済みでない請求書
+}
+
+type AffectedInvoice struct {
+	ID               uint             `json:"id"`               // 請求書ID
+	OrganizationID   uint             `json:"organizationId"`   // 請求元企業ID
+	OrganizationName string           `json:"organizationName"` // 請求元企業名
+	ClientID         uint             `json:"clientId"`         // 請求先取引先ID
+	ClientName       string           `json:"clientName"`       // 請求先取引先名
+	IssueDate        types.CustomDate `json:"issueDate"`        // 発行日
+	DueDate          types.CustomDate `json:"dueDate"`          // 支払期日
+	Status           string           `json:"status"`           // ステータス
+	TaxRate          float64          `json:"taxRate"`          // 適用済みの消費税率
+	TotalAmount      int64            `json:"totalAmount"`      // 合計金額
+}
+
+// ListTaxRates 税率の一覧. date を指定した場合はその日に適用される税率を返す
+func (h *TaxRateHandler) ListTaxRates(c echo.Context) error {
+	var req ListTaxRatesRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	var rates []application.TaxRateDto
+	var err error
+	if req.Date.IsZero() {
+		rates, err = h.usecase.ListTaxRates(application.ListTaxRatesDto{Category: req.Category})
+	} else {
+		rates, err = h.usecase.GetTaxRatesByDate(application.GetTaxRatesByDateDto{Date: req.Date.Time})
+	}
+	if err != nil {
+		log.Printf("Failed to list tax rates Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not list tax rates"})
+	}
+
+	response := ListTaxRatesResponse{Data: make([]TaxRateItem, 0, len(rates))}
+	for i := range rates {
+		if req.Category != "" && rates[i].Category != req.Category {
+			continue
+		}
+		response.Data = append(response.Data, newTaxRateItem(&rates[i]))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// CreateTaxRate 将来の税率を登録する. 現在有効な税率は開始日の前日で終了する
+func (h *TaxRateHandler) CreateTaxRate(c echo.Context) error {
+	var req CreateTaxRateRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	rate, err := h.usecase.CreateTaxRate(application.CreateTaxRateDto{
+		Category:  req.Category,
+		StartDate: req.StartDate.Time,
+		Rate:      req.Rate,
+		Now:       h.now(),
+	})
+	if err != nil {
+		return taxRateErrorResponse(c, err, "could not create tax rate")
+	}
+
+	return c.JSON(http.StatusCreated, newTaxRateItem(rate))
+}
+
+// UpdateTaxRate 適用前の税率の開始日・税率を変更する
+func (h *TaxRateHandler) UpdateTaxRate(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		log.Printf("Invalid tax rate id: %s", c.Param("id"))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	var req TaxRateRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	rate, err := h.usecase.UpdateTaxRate(application.UpdateTaxRateDto{
+		ID:        uint(id),
+		StartDate: req.StartDate.Time,
+		Rate:      req.Rate,
+		Now:       h.now(),
+	})
+	if err != nil {
+		return taxRateErrorResponse(c, err, "could not update tax rate")
+	}
+
+	return c.JSON(http.StatusOK, newTaxRateItem(rate))
+}
+
+// DeleteTaxRate 適用前の税率を削除する. 削除した期間は直前の税率を延長して適用する
+func (h *TaxRateHandler) DeleteTaxRate(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		log.Printf("Invalid tax rate id: %s", c.Param("id"))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := h.usecase.DeleteTaxRate(application.DeleteTaxRateDto{ID: uint(id), Now: h.now()}); err != nil {
+		return taxRateErrorResponse(c, err, "could not delete tax rate")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PreviewTaxRateChange 税率を変更した場合に消費税が変わる支払済みでない請求書を返す. 税率は変更しない
+func (h *TaxRateHandler) PreviewTaxRateChange(c echo.Context) error {
+	var req PreviewTaxRateChangeRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	preview, err := h.usecase.PreviewTaxRateChange(application.PreviewTaxRateChangeDto{
+		Category:  req.Category,
+		StartDate: req.StartDate.Time,
+		Rate:      req.Rate,
+	})
+	if err != nil {
+		return taxRateErrorResponse(c, err, "could not preview tax rate change")
+	}
+
+	response := TaxRateChangePreviewResponse{
+		Category:  preview.Category,
+		StartDate: types.CustomDate{Time: preview.StartDate},
+		EndDate:   optionalDate(preview.EndDate),
+		Rate:      preview.Rate,
+		Invoices:  make([]AffectedInvoice, len(preview.Invoices)),
+	}
+	for i, invoice := range preview.Invoices {
+		response.Invoices[i] = AffectedInvoice{
+			ID:               invoice.ID,
+			OrganizationID:   invoice.OrganizationID,
+			OrganizationName: invoice.OrganizationName,
+			ClientID:         invoice.ClientID,
+			ClientName:       invoice.ClientName,
+			IssueDate:        types.CustomDate{Time: invoice.IssueDate},
+			DueDate:          types.CustomDate{Time: invoice.DueDate},
+			Status:           invoice.Status,
+			TaxRate:          invoice.TaxRate,
+			TotalAmount:      invoice.TotalAmount,
+		}
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// taxRateErrorResponse 税率の管理のエラーをレスポンスに変換する
+func taxRateErrorResponse(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, commonErrors.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "tax rate not found"})
+	case errors.Is(err, application.ErrTaxRateInEffect):
+		log.Printf("Tax rate in effect: %v", err)
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, commonErrors.ErrConflict):
+		log.Printf("Tax rate conflict: %v", err)
+		return c.JSON(http.StatusConflict, map[string]string{"error": "tax rates were changed by another operation"})
+	case errors.Is(err, model.ErrInvalidTaxRatePeriod):
+		log.Printf("Invalid tax rate period: %v", err)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Failed to manage tax rate Error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
+
+func newTaxRateItem(rate *application.TaxRateDto) TaxRateItem {
+	return TaxRateItem{
+		ID:        rate.ID,
+		Category:  rate.Category,
+		StartDate: types.CustomDate{Time: rate.StartDate},
+		EndDate:   optionalDate(rate.EndDate),
+		Rate:      rate.Rate,
+	}
+}
+
+// optionalDate 日付がない場合は null として出力する
+func optionalDate(t *time.Time) *types.CustomDate {
+	if t == nil {
+		return nil
+	}
+	return &types.CustomDate{Time: *t}
+}
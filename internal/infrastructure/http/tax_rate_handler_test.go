@@ -0,0 +1,387 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+// testNow 税率の管理のテストで使う現在時刻
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func Test_TaxRateHandler_ListTaxRates(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	end := time.Date(2029, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockTaxRateUsecase)
+		query          string
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "一覧",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("ListTaxRates", application.ListTaxRatesDto{Category: "standard"}).Return([]application.TaxRateDto{
+					{ID: 2, Category: "standard", StartDate: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), EndDate: &end, Rate: 0.1},
+					{ID: 5, Category: "standard", StartDate: time.Date(2029, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.12},
+				}, nil)
+			},
+			query:          "category=standard",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string][]map[string]interface{}
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Len(t, response["data"], 2)
+				assert.Equal(t, "2029-09-30", response["data"][0]["endDate"])
+				assert.Nil(t, response["data"][1]["endDate"])
+			},
+		},
+		{
+			name: "日付を指定",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("GetTaxRatesByDate", application.GetTaxRatesByDateDto{Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}).Return([]application.TaxRateDto{
+					{ID: 2, Category: "standard", StartDate: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.1},
+					{ID: 3, Category: "reduced", StartDate: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.08},
+				}, nil)
+			},
+			query:          "date=2024-01-01&category=reduced",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response ListTaxRatesResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Len(t, response.Data, 1)
+				assert.Equal(t, uint(3), response.Data[0].ID)
+			},
+		},
+		{
+			name:           "税区分が不正",
+			setupMock:      func(mockUsecase *testutils.MockTaxRateUsecase) {}, // Mock is not called in this case
+			query:          "category=exempt",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockTaxRateUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewTaxRateHandler(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodGet, "/tax-rates?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			err := handler.ListTaxRates(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_TaxRateHandler_CreateTaxRate(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.CreateTaxRateDto{
+		Category:  "standard",
+		StartDate: time.Date(2029, 10, 1, 0, 0, 0, 0, time.UTC),
+		Rate:      0.12,
+		Now:       testNow,
+	}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockTaxRateUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("CreateTaxRate", dto).Return(&application.TaxRateDto{
+					ID: 5, Category: "standard", StartDate: dto.StartDate, Rate: 0.12,
+				}, nil)
+			},
+			payload:        map[string]interface{}{"category": "standard", "startDate": "2029-10-01", "rate": 0.12},
+			expectedStatus: http.StatusCreated,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response TaxRateItem
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, uint(5), response.ID)
+				assert.Equal(t, "2029-10-01", response.StartDate.Format("2006-01-02"))
+			},
+		},
+		{
+			name:           "税率が範囲外",
+			setupMock:      func(mockUsecase *testutils.MockTaxRateUsecase) {}, // Mock is not called in this case
+			payload:        map[string]interface{}{"category": "standard", "startDate": "2029-10-01", "rate": 10},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "税区分がない",
+			setupMock:      func(mockUsecase *testutils.MockTaxRateUsecase) {}, // Mock is not called in this case
+			payload:        map[string]interface{}{"startDate": "2029-10-01", "rate": 0.12},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "開始日が過去",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("CreateTaxRate", dto).Return(nil, fmt.Errorf("%w: start date 2029-10-01 is not in the future", application.ErrTaxRateInEffect))
+			},
+			payload:        map[string]interface{}{"category": "standard", "startDate": "2029-10-01", "rate": 0.12},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "期間が重複",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("CreateTaxRate", dto).Return(nil, fmt.Errorf("%w: overlaps", model.ErrInvalidTaxRatePeriod))
+			},
+			payload:        map[string]interface{}{"category": "standard", "startDate": "2029-10-01", "rate": 0.12},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid tax rate period: overlaps", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockTaxRateUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewTaxRateHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPost, "/tax-rates", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			err := handler.CreateTaxRate(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_TaxRateHandler_UpdateTaxRate(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockTaxRateUsecase)
+		id             string
+		payload        map[string]interface{}
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("UpdateTaxRate", application.UpdateTaxRateDto{
+					ID: 5, StartDate: time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC), Rate: 0.12, Now: testNow,
+				}).Return(&application.TaxRateDto{
+					ID: 5, Category: "standard", StartDate: time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC), Rate: 0.12,
+				}, nil)
+			},
+			id:             "5",
+			payload:        map[string]interface{}{"startDate": "2030-04-01", "rate": 0.12},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "存在しない税率",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("UpdateTaxRate", application.UpdateTaxRateDto{
+					ID: 99, StartDate: time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC), Rate: 0.12, Now: testNow,
+				}).Return(nil, commonErrors.ErrNotFound)
+			},
+			id:             "99",
+			payload:        map[string]interface{}{"startDate": "2030-04-01", "rate": 0.12},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "IDが不正",
+			setupMock:      func(mockUsecase *testutils.MockTaxRateUsecase) {}, // Mock is not called in this case
+			id:             "abc",
+			payload:        map[string]interface{}{"startDate": "2030-04-01", "rate": 0.12},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockTaxRateUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewTaxRateHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPut, "/tax-rates/"+tt.id, bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			c.Set("user", testClaims)
+
+			err := handler.UpdateTaxRate(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_TaxRateHandler_DeleteTaxRate(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockTaxRateUsecase)
+		id             string
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("DeleteTaxRate", application.DeleteTaxRateDto{ID: 5, Now: testNow}).Return(nil)
+			},
+			id:             "5",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "適用中の税率",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("DeleteTaxRate", application.DeleteTaxRateDto{ID: 2, Now: testNow}).Return(application.ErrTaxRateInEffect)
+			},
+			id:             "2",
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockTaxRateUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewTaxRateHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			req := httptest.NewRequest(http.MethodDelete, "/tax-rates/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			c.Set("user", testClaims)
+
+			err := handler.DeleteTaxRate(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_TaxRateHandler_PreviewTaxRateChange(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockTaxRateUsecase)
+		query          string
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockTaxRateUsecase) {
+				mockUsecase.On("PreviewTaxRateChange", application.PreviewTaxRateChangeDto{
+					Category: "standard", StartDate: time.Date(2029, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.12,
+				}).Return(&application.TaxRateChangePreviewDto{
+					Category: "standard", StartDate: time.Date(2029, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.12,
+					Invoices: []application.AffectedInvoiceDto{
+						{
+							ID: 1, OrganizationID: 1, ClientID: 1,
+							IssueDate: time.Date(2029, 10, 1, 0, 0, 0, 0, time.UTC),
+							DueDate:   time.Date(2029, 10, 31, 0, 0, 0, 0, time.UTC),
+							Status:    "pending", TaxRate: 0.1, TotalAmount: 10440,
+						},
+					},
+				}, nil)
+			},
+			query:          "category=standard&startDate=2029-10-01&rate=0.12",
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response TaxRateChangePreviewResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Len(t, response.Invoices, 1)
+				assert.Equal(t, uint(1), response.Invoices[0].ID)
+			},
+		},
+		{
+			name:           "開始日がない",
+			setupMock:      func(mockUsecase *testutils.MockTaxRateUsecase) {}, // Mock is not called in this case
+			query:          "category=standard&rate=0.12",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockTaxRateUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewTaxRateHandler(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodGet, "/tax-rates/preview?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			err := handler.PreviewTaxRateChange(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/take73/invoice-api-example/internal/application"
+)
+
+type MockAPIKeyUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyUsecase) CreateAPIKey(dto application.CreateAPIKeyDto) (*application.IssuedAPIKeyDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.IssuedAPIKeyDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyUsecase) ListAPIKeys(dto application.ListAPIKeysDto) ([]*application.APIKeyDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*application.APIKeyDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyUsecase) GetAPIKey(dto application.GetAPIKeyDto) (*application.APIKeyDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.APIKeyDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyUsecase) RevokeAPIKey(dto application.RevokeAPIKeyDto) (*application.APIKeyDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.APIKeyDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyUsecase) AuthenticateAPIKey(dto application.AuthenticateAPIKeyDto) (*application.Principal, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.Principal), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
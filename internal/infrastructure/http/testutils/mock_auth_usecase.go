@@ -0,0 +1,26 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/take73/invoice-api-example/internal/application"
+)
+
+type MockAuthUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuthUsecase) Login(dto application.LoginDto) (*application.TokenDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.TokenDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAuthUsecase) Refresh(dto application.RefreshTokenDto) (*application.TokenDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.TokenDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
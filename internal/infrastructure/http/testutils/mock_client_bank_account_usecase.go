@@ -0,0 +1,55 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/take73/invoice-api-example/internal/application"
+)
+
+type MockClientBankAccountUsecase struct {
+	mock.Mock
+}
+
+func (m *MockClientBankAccountUsecase) ListBankAccounts(dto application.ListBankAccountsDto) ([]application.ClientBankAccountDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).([]application.ClientBankAccountDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockClientBankAccountUsecase) GetBankAccount(dto application.GetBankAccountDto) (*application.ClientBankAccountDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ClientBankAccountDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockClientBankAccountUsecase) CreateBankAccount(dto application.CreateBankAccountDto) (*application.ClientBankAccountDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ClientBankAccountDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockClientBankAccountUsecase) UpdateBankAccount(dto application.UpdateBankAccountDto) (*application.ClientBankAccountDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ClientBankAccountDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockClientBankAccountUsecase) DeleteBankAccount(dto application.DeleteBankAccountDto) error {
+	args := m.Called(dto)
+	return args.Error(0)
+}
+
+func (m *MockClientBankAccountUsecase) SetDefaultBankAccount(dto application.SetDefaultBankAccountDto) (*application.ClientBankAccountDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ClientBankAccountDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,50 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/take73/invoice-api-example/internal/application"
+)
+
+type MockClientUsecase struct {
+	mock.Mock
+}
+
+func (m *MockClientUsecase) CreateClient(dto application.CreateClientDto) (*application.ClientDetailDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ClientDetailDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockClientUsecase) ListClients(dto application.ListClientsDto) (*application.ClientListDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ClientListDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockClientUsecase) GetClient(dto application.GetClientDto) (*application.ClientDetailDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ClientDetailDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockClientUsecase) UpdateClient(dto application.UpdateClientDto) (*application.ClientDetailDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ClientDetailDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockClientUsecase) ArchiveClient(dto application.ArchiveClientDto) (*application.ClientDetailDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ClientDetailDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
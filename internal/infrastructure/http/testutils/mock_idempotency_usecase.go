@@ -0,0 +1,28 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/take73/invoice-api-example/internal/application"
+)
+
+type MockIdempotencyUsecase struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyUsecase) Begin(dto application.BeginIdempotentRequestDto) (*application.IdempotentRequestDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.IdempotentRequestDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockIdempotencyUsecase) Complete(dto application.CompleteIdempotentRequestDto) error {
+	args := m.Called(dto)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyUsecase) Release(dto application.ReleaseIdempotentRequestDto) error {
+	args := m.Called(dto)
+	return args.Error(0)
+}
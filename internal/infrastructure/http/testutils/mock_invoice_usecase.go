@@ -17,10 +17,50 @@ func (m *MockInvoiceUsecase) CreateInvoice(dto application.CreateInvoiceDto) (*a
 	return nil, args.Error(1)
 }
 
-func (m *MockInvoiceUsecase) ListInvoice(dto application.ListInvoiceDto) ([]*application.InvoiceDto, error) {
+func (m *MockInvoiceUsecase) ListInvoice(dto application.ListInvoiceDto) (*application.InvoiceListDto, error) {
 	args := m.Called(dto)
 	if args.Get(0) != nil {
-		return args.Get(0).([]*application.InvoiceDto), args.Error(1)
+		return args.Get(0).(*application.InvoiceListDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInvoiceUsecase) GetInvoice(dto application.GetInvoiceDto) (*application.InvoiceDetailDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.InvoiceDetailDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInvoiceUsecase) ChangeInvoiceStatus(dto application.ChangeInvoiceStatusDto) (*application.InvoiceDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.InvoiceDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInvoiceUsecase) ExportTransferFile(dto application.ExportTransferFileDto) (*application.TransferFileDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.TransferFileDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInvoiceUsecase) ReconcileStatement(dto application.ReconcileStatementDto) (*application.ReconciliationReportDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.ReconciliationReportDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInvoiceUsecase) ReportDuplicateInvoices(dto application.ReportDuplicateInvoicesDto) (*application.DuplicateInvoiceReportDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.DuplicateInvoiceReportDto), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
@@ -0,0 +1,58 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/take73/invoice-api-example/internal/application"
+)
+
+type MockOrganizationUsecase struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationUsecase) GetOrganization(dto application.GetOrganizationDto) (*application.OrganizationDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.OrganizationDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrganizationUsecase) UpdateOrganization(dto application.UpdateOrganizationDto) (*application.OrganizationDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.OrganizationDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrganizationUsecase) GetSettings(dto application.GetOrganizationDto) (*application.OrganizationSettingsDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.OrganizationSettingsDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrganizationUsecase) UpdateSettings(dto application.UpdateOrganizationSettingsDto) (*application.OrganizationSettingsDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.OrganizationSettingsDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrganizationUsecase) ListSettingsVersions(dto application.GetOrganizationDto) ([]*application.OrganizationSettingsDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*application.OrganizationSettingsDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrganizationUsecase) GetSettingsVersion(dto application.GetOrganizationSettingsVersionDto) (*application.OrganizationSettingsDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.OrganizationSettingsDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
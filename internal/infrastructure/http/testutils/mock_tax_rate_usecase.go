@@ -0,0 +1,55 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/take73/invoice-api-example/internal/application"
+)
+
+type MockTaxRateUsecase struct {
+	mock.Mock
+}
+
+func (m *MockTaxRateUsecase) ListTaxRates(dto application.ListTaxRatesDto) ([]application.TaxRateDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).([]application.TaxRateDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaxRateUsecase) GetTaxRatesByDate(dto application.GetTaxRatesByDateDto) ([]application.TaxRateDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).([]application.TaxRateDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaxRateUsecase) CreateTaxRate(dto application.CreateTaxRateDto) (*application.TaxRateDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.TaxRateDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaxRateUsecase) UpdateTaxRate(dto application.UpdateTaxRateDto) (*application.TaxRateDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.TaxRateDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaxRateUsecase) DeleteTaxRate(dto application.DeleteTaxRateDto) error {
+	args := m.Called(dto)
+	return args.Error(0)
+}
+
+func (m *MockTaxRateUsecase) PreviewTaxRateChange(dto application.PreviewTaxRateChangeDto) (*application.TaxRateChangePreviewDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.TaxRateChangePreviewDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,82 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/take73/invoice-api-example/internal/application"
+)
+
+type MockUserUsecase struct {
+	mock.Mock
+}
+
+func (m *MockUserUsecase) InviteUser(dto application.InviteUserDto) (*application.InvitationDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.InvitationDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserUsecase) ListUsers(dto application.ListUsersDto) ([]*application.UserDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*application.UserDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserUsecase) GetUser(dto application.GetUserDto) (*application.UserDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.UserDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserUsecase) UpdateUser(dto application.UpdateUserDto) (*application.UserDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.UserDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserUsecase) DeactivateUser(dto application.DeactivateUserDto) (*application.UserDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.UserDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserUsecase) ReinviteUser(dto application.ReinviteUserDto) (*application.InvitationDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.InvitationDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserUsecase) AcceptInvitation(dto application.AcceptInvitationDto) (*application.UserDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.UserDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserUsecase) ChangeUserRole(dto application.ChangeUserRoleDto) (*application.UserDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).(*application.UserDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserUsecase) ListRoleChanges(dto application.GetUserDto) ([]*application.UserRoleChangeDto, error) {
+	args := m.Called(dto)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*application.UserRoleChangeDto), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
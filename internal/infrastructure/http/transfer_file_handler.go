@@ -0,0 +1,69 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/types"
+)
+
+type ExportTransferFileRequest struct {
+	InvoiceIDs   []uint           `json:"invoiceIds" validate:"required,min=1,max=1000,dive,gt=0"` // 必須, 処理中の請求書ID
+	TransferDate types.CustomDate `json:"transferDate" validate:"required_custom_date"`            // 必須, 取組日
+}
+
+// ExportTransferFile 処理中の請求書から全銀協 総合振込フォーマット（Shift_JIS）のファイルを出力する.
+// 件数と合計金額はレスポンスヘッダーで返す
+func (h *InvoiceHandler) ExportTransferFile(c echo.Context) error {
+	var req ExportTransferFileRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	dto := application.ExportTransferFileDto{
+		Principal:    principal,
+		InvoiceIDs:   req.InvoiceIDs,
+		TransferDate: req.TransferDate.Time,
+	}
+
+	file, err := h.usecase.ExportTransferFile(dto)
+	if err != nil {
+		var transferErr *application.TransferFileError
+		switch {
+		case isAuthorizationError(err):
+			return authorizationErrorResponse(c, err)
+		case errors.Is(err, commonErrors.ErrNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "invoice not found"})
+		case errors.Is(err, model.ErrPaymentMethodMismatch):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "transfer file is not available because the organization pays invoices through the payment gateway"})
+		case errors.As(err, &transferErr):
+			log.Printf("Could not build transfer file: %v", err)
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": transferErr.Error()})
+		}
+		log.Printf("Failed to export transfer file Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not export transfer file"})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", file.FileName))
+	c.Response().Header().Set("X-Transfer-Count", strconv.Itoa(file.Count))
+	c.Response().Header().Set("X-Transfer-Total-Amount", strconv.FormatInt(file.TotalAmount, 10))
+	return c.Blob(http.StatusOK, "text/plain; charset=Shift_JIS", file.Content)
+}
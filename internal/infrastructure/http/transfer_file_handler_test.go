@@ -0,0 +1,185 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+func Test_InvoiceHandler_ExportTransferFile(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	transferDate := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockInvoiceUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ExportTransferFile", application.ExportTransferFileDto{
+					Principal:    testPrincipal,
+					InvoiceIDs:   []uint{1, 2},
+					TransferDate: transferDate,
+				}).Return(&application.TransferFileDto{
+					FileName:    "zengin_transfer_20240120.txt",
+					Content:     []byte("1210...\r\n"),
+					Count:       2,
+					TotalAmount: 30000,
+				}, nil)
+			},
+			payload:        map[string]interface{}{"invoiceIds": []uint{1, 2}, "transferDate": "2024-01-20"},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, "1210...\r\n", rec.Body.String())
+				assert.Equal(t, "text/plain; charset=Shift_JIS", rec.Header().Get(echo.HeaderContentType))
+				assert.Equal(t, `attachment; filename="zengin_transfer_20240120.txt"`, rec.Header().Get(echo.HeaderContentDisposition))
+				assert.Equal(t, "2", rec.Header().Get("X-Transfer-Count"))
+				assert.Equal(t, "30000", rec.Header().Get("X-Transfer-Total-Amount"))
+			},
+		},
+		{
+			name:           "請求書IDが空の場合, validation failed",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			payload:        map[string]interface{}{"invoiceIds": []uint{}, "transferDate": "2024-01-20"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name:           "取組日がない場合, validation failed",
+			setupMock:      func(mockUsecase *testutils.MockInvoiceUsecase) {},
+			payload:        map[string]interface{}{"invoiceIds": []uint{1}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation failed", response["error"])
+			},
+		},
+		{
+			name: "請求書が存在しない場合, invoice not found",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ExportTransferFile", application.ExportTransferFileDto{
+					Principal:    testPrincipal,
+					InvoiceIDs:   []uint{99},
+					TransferDate: transferDate,
+				}).Return(nil, commonErrors.ErrNotFound)
+			},
+			payload:        map[string]interface{}{"invoiceIds": []uint{99}, "transferDate": "2024-01-20"},
+			expectedStatus: http.StatusNotFound,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invoice not found", response["error"])
+			},
+		},
+		{
+			name: "振込データを作成できない場合, unprocessable entity",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ExportTransferFile", application.ExportTransferFileDto{
+					Principal:    testPrincipal,
+					InvoiceIDs:   []uint{1},
+					TransferDate: transferDate,
+				}).Return(nil, &application.TransferFileError{InvoiceID: 1, Reason: "status must be processing but pending"})
+			},
+			payload:        map[string]interface{}{"invoiceIds": []uint{1}, "transferDate": "2024-01-20"},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invoice 1: status must be processing but pending", response["error"])
+			},
+		},
+		{
+			name: "支払方法が支払ゲートウェイの組織の場合, conflict",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ExportTransferFile", application.ExportTransferFileDto{
+					Principal:    testPrincipal,
+					InvoiceIDs:   []uint{1},
+					TransferDate: transferDate,
+				}).Return(nil, model.ErrPaymentMethodMismatch)
+			},
+			payload:        map[string]interface{}{"invoiceIds": []uint{1}, "transferDate": "2024-01-20"},
+			expectedStatus: http.StatusConflict,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "transfer file is not available because the organization pays invoices through the payment gateway", response["error"])
+			},
+		},
+		{
+			name: "usecaseでエラーが発生した場合, could not export transfer file",
+			setupMock: func(mockUsecase *testutils.MockInvoiceUsecase) {
+				mockUsecase.On("ExportTransferFile", application.ExportTransferFileDto{
+					Principal:    testPrincipal,
+					InvoiceIDs:   []uint{1},
+					TransferDate: transferDate,
+				}).Return(nil, errors.New("unexpected error"))
+			},
+			payload:        map[string]interface{}{"invoiceIds": []uint{1}, "transferDate": "2024-01-20"},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "could not export transfer file", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 新しいモックインスタンスを作成
+			mockUsecase := &testutils.MockInvoiceUsecase{}
+			tt.setupMock(mockUsecase)
+
+			// ハンドラを新規作成
+			handler := NewInvoiceHandler(mockUsecase)
+
+			// リクエストのセットアップ
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPost, "/invoice/transfer-file", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			// ハンドラの実行
+			err := handler.ExportTransferFile(c)
+			assert.NoError(t, err)
+
+			// ステータスコードとレスポンスボディの検証
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			tt.expectedBody(t, rec)
+
+			// モックの呼び出しを検証
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
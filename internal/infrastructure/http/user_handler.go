@@ -0,0 +1,382 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+)
+
+type UserHandler struct {
+	usecase application.UserUsecase
+	now     func() time.Time // 招待の有効期限・無効化日時に使う現在時刻
+}
+
+func NewUserHandler(usecase application.UserUsecase) *UserHandler {
+	return &UserHandler{usecase: usecase, now: time.Now}
+}
+
+// UserRequest ユーザーの登録内容. 形式の詳細なチェックはドメインモデルで行う
+type UserRequest struct {
+	Name  string `json:"name" validate:"required"`  // 必須, 氏名
+	Email string `json:"email" validate:"required"` // 必須, メールアドレス
+}
+
+// InviteUserRequest ユーザーの招待. ロールを省略した場合は閲覧者（viewer）として招待する
+type InviteUserRequest struct {
+	UserRequest
+	Role string `json:"role"` // 任意, ロール（owner / admin / accountant / approver / viewer）
+}
+
+// ChangeUserRoleRequest ロールの変更
+type ChangeUserRoleRequest struct {
+	Role string `json:"role" validate:"required"` // 必須, 変更後のロール
+}
+
+type ListUsersRequest struct {
+	IncludeDeactivated bool `query:"includeDeactivated"` // 無効化したユーザーも含める
+}
+
+// AcceptInvitationRequest 招待の承諾. パスワードの要件はドメインモデルで検証する
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`    // 必須, 招待トークン
+	Password string `json:"password" validate:"required"` // 必須, 設定するパスワード
+}
+
+type UserItem struct {
+	ID            uint       `json:"id"`            // ユーザーID
+	Name          string     `json:"name"`          // 氏名
+	Email         string     `json:"email"`         // メールアドレス
+	Role          string     `json:"role"`          // ロール
+	Status        string     `json:"status"`        // 状態（invited / active / deactivated）
+	DeactivatedAt *time.Time `json:"deactivatedAt"` // 無効化日時（無効化していない場合は null）
+}
+
+type ListUsersResponse struct {
+	Users []UserItem `json:"users"`
+}
+
+type UserRoleChangeItem struct {
+	ID        uint      `json:"id"`        // 履歴ID
+	FromRole  *string   `json:"fromRole"`  // 変更前のロール（招待時の割り当ては null）
+	ToRole    string    `json:"toRole"`    // 変更後のロール
+	ChangedBy string    `json:"changedBy"` // 変更した操作主体
+	ChangedAt time.Time `json:"changedAt"` // 変更日時
+}
+
+type ListUserRoleChangesResponse struct {
+	RoleChanges []UserRoleChangeItem `json:"roleChanges"`
+}
+
+type InvitationResponse struct {
+	User                UserItem  `json:"user"`
+	InvitationToken     string    `json:"invitationToken"`     // 招待トークン（この応答でのみ返す）
+	InvitationExpiresAt time.Time `json:"invitationExpiresAt"` // 招待の有効期限
+}
+
+// InviteUser ユーザーを招待する. 招待トークンを本人に伝えてパスワードを設定してもらう
+func (h *UserHandler) InviteUser(c echo.Context) error {
+	var req InviteUserRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	invitation, err := h.usecase.InviteUser(application.InviteUserDto{
+		Principal: principal,
+		Name:      req.Name,
+		Email:     req.Email,
+		Role:      req.Role,
+		Now:       h.now(),
+	})
+	if err != nil {
+		return userErrorResponse(c, err, "could not invite user")
+	}
+
+	return c.JSON(http.StatusCreated, newInvitationResponse(invitation))
+}
+
+func (h *UserHandler) ListUsers(c echo.Context) error {
+	var req ListUsersRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	users, err := h.usecase.ListUsers(application.ListUsersDto{
+		Principal:          principal,
+		IncludeDeactivated: req.IncludeDeactivated,
+	})
+	if err != nil {
+		return userErrorResponse(c, err, "could not list users")
+	}
+
+	response := ListUsersResponse{Users: make([]UserItem, len(users))}
+	for i, user := range users {
+		response.Users[i] = newUserItem(user)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) GetUser(c echo.Context) error {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	user, err := h.usecase.GetUser(application.GetUserDto{Principal: principal, ID: id})
+	if err != nil {
+		return userErrorResponse(c, err, "could not get user")
+	}
+
+	return c.JSON(http.StatusOK, newUserItem(user))
+}
+
+// UpdateUser ユーザーの氏名・メールアドレスを置き換える
+func (h *UserHandler) UpdateUser(c echo.Context) error {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	var req UserRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	user, err := h.usecase.UpdateUser(application.UpdateUserDto{
+		Principal: principal,
+		ID:        id,
+		Name:      req.Name,
+		Email:     req.Email,
+	})
+	if err != nil {
+		return userErrorResponse(c, err, "could not update user")
+	}
+
+	return c.JSON(http.StatusOK, newUserItem(user))
+}
+
+// DeactivateUser ユーザーを無効化する. 無効化したユーザーは元に戻せない
+func (h *UserHandler) DeactivateUser(c echo.Context) error {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	user, err := h.usecase.DeactivateUser(application.DeactivateUserDto{
+		Principal: principal,
+		ID:        id,
+		Now:       h.now(),
+	})
+	if err != nil {
+		return userErrorResponse(c, err, "could not deactivate user")
+	}
+
+	return c.JSON(http.StatusOK, newUserItem(user))
+}
+
+// ReinviteUser 招待トークンを再発行する
+func (h *UserHandler) ReinviteUser(c echo.Context) error {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	invitation, err := h.usecase.ReinviteUser(application.ReinviteUserDto{
+		Principal: principal,
+		ID:        id,
+		Now:       h.now(),
+	})
+	if err != nil {
+		return userErrorResponse(c, err, "could not reinvite user")
+	}
+
+	return c.JSON(http.StatusOK, newInvitationResponse(invitation))
+}
+
+// ChangeUserRole ユーザーのロールを変更する. 変更は履歴に記録する
+func (h *UserHandler) ChangeUserRole(c echo.Context) error {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	var req ChangeUserRoleRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	user, err := h.usecase.ChangeUserRole(application.ChangeUserRoleDto{
+		Principal: principal,
+		ID:        id,
+		Role:      req.Role,
+		Now:       h.now(),
+	})
+	if err != nil {
+		if errors.Is(err, commonErrors.ErrConflict) {
+			log.Printf("User role was changed concurrently: %v", err)
+			return c.JSON(http.StatusConflict, map[string]string{"error": "role was changed by another request"})
+		}
+		return userErrorResponse(c, err, "could not change user role")
+	}
+
+	return c.JSON(http.StatusOK, newUserItem(user))
+}
+
+// ListRoleChanges ユーザーのロールの変更履歴を古い順に返す
+func (h *UserHandler) ListRoleChanges(c echo.Context) error {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	principal, ok := principalFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	changes, err := h.usecase.ListRoleChanges(application.GetUserDto{Principal: principal, ID: id})
+	if err != nil {
+		return userErrorResponse(c, err, "could not list role changes")
+	}
+
+	response := ListUserRoleChangesResponse{RoleChanges: make([]UserRoleChangeItem, len(changes))}
+	for i, change := range changes {
+		item := UserRoleChangeItem{
+			ID:        change.ID,
+			ToRole:    change.ToRole,
+			ChangedBy: change.ChangedBy,
+			ChangedAt: change.ChangedAt,
+		}
+		if change.FromRole != "" {
+			fromRole := change.FromRole
+			item.FromRole = &fromRole
+		}
+		response.RoleChanges[i] = item
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// AcceptInvitation 招待を承諾してパスワードを設定する. 招待トークンで本人を確認するため認証は不要
+func (h *UserHandler) AcceptInvitation(c echo.Context) error {
+	var req AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Failed to bind request Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Printf("Validation failed Error: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "validation failed"})
+	}
+
+	user, err := h.usecase.AcceptInvitation(application.AcceptInvitationDto{
+		Token:    req.Token,
+		Password: req.Password,
+		Now:      h.now(),
+	})
+	if err != nil {
+		return userErrorResponse(c, err, "could not accept invitation")
+	}
+
+	return c.JSON(http.StatusOK, newUserItem(user))
+}
+
+// userErrorResponse ユーザーの管理のエラーをレスポンスに変換する
+func userErrorResponse(c echo.Context, err error, message string) error {
+	switch {
+	case isAuthorizationError(err):
+		return authorizationErrorResponse(c, err)
+	case errors.Is(err, commonErrors.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+	case errors.Is(err, commonErrors.ErrConflict):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "email is already registered"})
+	case errors.Is(err, model.ErrUserDeactivated):
+		log.Printf("User deactivated: %v", err)
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, model.ErrInvalidUser), errors.Is(err, model.ErrWeakPassword),
+		errors.Is(err, model.ErrInvalidInvitation), errors.Is(err, model.ErrInvalidRole),
+		errors.Is(err, application.ErrSelfDeactivation), errors.Is(err, application.ErrSelfRoleChange):
+		log.Printf("Invalid user: %v", err)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Failed to manage user Error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
+
+func newUserItem(user *application.UserDto) UserItem {
+	return UserItem{
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          user.Role,
+		Status:        user.Status,
+		DeactivatedAt: user.DeactivatedAt,
+	}
+}
+
+func newInvitationResponse(invitation *application.InvitationDto) InvitationResponse {
+	return InvitationResponse{
+		User:                newUserItem(&invitation.User),
+		InvitationToken:     invitation.Token,
+		InvitationExpiresAt: invitation.ExpiresAt,
+	}
+}
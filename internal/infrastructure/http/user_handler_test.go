@@ -0,0 +1,403 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/take73/invoice-api-example/internal/application"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/http/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+func Test_UserHandler_InviteUser(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.InviteUserDto{
+		Principal: testPrincipal,
+		Name:      "伊藤 四郎",
+		Email:     "shiro.ito@example.com",
+		Now:       testNow,
+	}
+	payload := func() map[string]interface{} {
+		return map[string]interface{}{
+			"name":  "伊藤 四郎",
+			"email": "shiro.ito@example.com",
+		}
+	}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockUserUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("InviteUser", dto).Return(&application.InvitationDto{
+					User:      application.UserDto{ID: 4, Name: "伊藤 四郎", Email: "shiro.ito@example.com", Status: "invited"},
+					Token:     "invitation-token",
+					ExpiresAt: testNow.Add(model.InvitationValidity),
+				}, nil)
+			},
+			payload:        payload(),
+			expectedStatus: http.StatusCreated,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response InvitationResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, uint(4), response.User.ID)
+				assert.Equal(t, "invited", response.User.Status)
+				assert.Equal(t, "invitation-token", response.InvitationToken)
+				assert.True(t, response.InvitationExpiresAt.Equal(testNow.Add(model.InvitationValidity)))
+			},
+		},
+		{
+			name:      "メールアドレスがない",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {}, // Mock is not called in this case
+			payload: func() map[string]interface{} {
+				p := payload()
+				delete(p, "email")
+				return p
+			}(),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "メールアドレスが登録済み",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("InviteUser", dto).Return(nil, commonErrors.ErrConflict)
+			},
+			payload:        payload(),
+			expectedStatus: http.StatusConflict,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "email is already registered", response["error"])
+			},
+		},
+		{
+			name: "組織を特定できない",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("InviteUser", dto).Return(nil, commonErrors.ErrUnauthorized)
+			},
+			payload:        payload(),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "ロールが権限をもたない",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				withRole := dto
+				withRole.Role = "owner"
+				mockUsecase.On("InviteUser", withRole).Return(nil, commonErrors.ErrForbidden)
+			},
+			payload: func() map[string]interface{} {
+				p := payload()
+				p["role"] = "owner"
+				return p
+			}(),
+			expectedStatus: http.StatusForbidden,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "insufficient permission", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockUserUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewUserHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", testClaims)
+
+			err := handler.InviteUser(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_UserHandler_DeactivateUser(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		id             string
+		setupMock      func(*testutils.MockUserUsecase)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			id:   "2",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("DeactivateUser", application.DeactivateUserDto{Principal: testPrincipal, ID: 2, Now: testNow}).
+					Return(&application.UserDto{ID: 2, Status: "deactivated", DeactivatedAt: &testNow}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "自分自身",
+			id:   "1",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("DeactivateUser", application.DeactivateUserDto{Principal: testPrincipal, ID: 1, Now: testNow}).
+					Return(nil, application.ErrSelfDeactivation)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "他組織のユーザー",
+			id:   "3",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("DeactivateUser", application.DeactivateUserDto{Principal: testPrincipal, ID: 3, Now: testNow}).
+					Return(nil, commonErrors.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "IDが不正",
+			id:             "abc",
+			setupMock:      func(mockUsecase *testutils.MockUserUsecase) {}, // Mock is not called in this case
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockUserUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewUserHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			req := httptest.NewRequest(http.MethodPost, "/users/"+tt.id+"/deactivate", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			c.Set("user", testClaims)
+
+			err := handler.DeactivateUser(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_UserHandler_ChangeUserRole(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	dto := application.ChangeUserRoleDto{Principal: testPrincipal, ID: 2, Role: "approver", Now: testNow}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockUserUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("ChangeUserRole", dto).Return(&application.UserDto{ID: 2, Role: "approver", Status: "active"}, nil)
+			},
+			payload:        map[string]interface{}{"role": "approver"},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response UserItem
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "approver", response.Role)
+			},
+		},
+		{
+			name:           "ロールがない",
+			setupMock:      func(mockUsecase *testutils.MockUserUsecase) {}, // Mock is not called in this case
+			payload:        map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "ロールが不正",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				invalid := dto
+				invalid.Role = "superuser"
+				mockUsecase.On("ChangeUserRole", invalid).Return(nil, model.ErrInvalidRole)
+			},
+			payload:        map[string]interface{}{"role": "superuser"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "権限がない",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("ChangeUserRole", dto).Return(nil, commonErrors.ErrForbidden)
+			},
+			payload:        map[string]interface{}{"role": "approver"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "自分自身",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("ChangeUserRole", dto).Return(nil, application.ErrSelfRoleChange)
+			},
+			payload:        map[string]interface{}{"role": "approver"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "同時にロールが変更された",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("ChangeUserRole", dto).Return(nil, commonErrors.ErrConflict)
+			},
+			payload:        map[string]interface{}{"role": "approver"},
+			expectedStatus: http.StatusConflict,
+			expectedBody: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "role was changed by another request", response["error"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockUserUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewUserHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPut, "/users/2/role", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("2")
+			c.Set("user", testClaims)
+
+			err := handler.ChangeUserRole(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != nil {
+				tt.expectedBody(t, rec)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_UserHandler_ListRoleChanges(t *testing.T) {
+	e := echo.New()
+	mockUsecase := &testutils.MockUserUsecase{}
+	mockUsecase.On("ListRoleChanges", application.GetUserDto{Principal: testPrincipal, ID: 2}).Return([]*application.UserRoleChangeDto{
+		{ID: 1, ToRole: "viewer", ChangedBy: "auth0|user1", ChangedAt: testNow},
+		{ID: 2, FromRole: "viewer", ToRole: "approver", ChangedBy: "auth0|user1", ChangedAt: testNow},
+	}, nil)
+	handler := NewUserHandler(mockUsecase)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/2/role-history", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+	c.Set("user", testClaims)
+
+	err := handler.ListRoleChanges(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response ListUserRoleChangesResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Len(t, response.RoleChanges, 2)
+	// 招待時の割り当ては変更前のロールが null
+	assert.Nil(t, response.RoleChanges[0].FromRole)
+	assert.Equal(t, "viewer", *response.RoleChanges[1].FromRole)
+	assert.Equal(t, "approver", response.RoleChanges[1].ToRole)
+	mockUsecase.AssertExpectations(t)
+}
+
+func Test_UserHandler_AcceptInvitation(t *testing.T) {
+	e := echo.New()
+	e.Validator = validation.NewCustomValidator()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*testutils.MockUserUsecase)
+		payload        map[string]interface{}
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("AcceptInvitation", application.AcceptInvitationDto{Token: "invitation-token", Password: "correct horse battery", Now: testNow}).
+					Return(&application.UserDto{ID: 4, Status: "active"}, nil)
+			},
+			payload:        map[string]interface{}{"token": "invitation-token", "password": "correct horse battery"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "パスワードが短い",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("AcceptInvitation", application.AcceptInvitationDto{Token: "invitation-token", Password: "password123", Now: testNow}).
+					Return(nil, model.ErrWeakPassword)
+			},
+			payload:        map[string]interface{}{"token": "invitation-token", "password": "password123"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "招待が期限切れ",
+			setupMock: func(mockUsecase *testutils.MockUserUsecase) {
+				mockUsecase.On("AcceptInvitation", application.AcceptInvitationDto{Token: "expired-token", Password: "correct horse battery", Now: testNow}).
+					Return(nil, model.ErrInvalidInvitation)
+			},
+			payload:        map[string]interface{}{"token": "expired-token", "password": "correct horse battery"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "トークンがない",
+			setupMock:      func(mockUsecase *testutils.MockUserUsecase) {}, // Mock is not called in this case
+			payload:        map[string]interface{}{"password": "correct horse battery"},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &testutils.MockUserUsecase{}
+			tt.setupMock(mockUsecase)
+			handler := NewUserHandler(mockUsecase)
+			handler.now = func() time.Time { return testNow }
+
+			// 認証を経由しないため、トークンのクレームは設定しない
+			reqBody, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPost, "/users/invitations/accept", bytes.NewReader(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.AcceptInvitation(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/take73/invoice-api-example/internal/domain/gateway"
+)
+
+// GatewayFake 開発・テスト用の Fake 実装（実際には振り込まない）
+const GatewayFake = "fake"
+
+// NewGatewayFromEnv PAYMENT_GATEWAY で指定した支払ゲートウェイを返す.
+// 未指定・未対応の値はエラーにし、支払処理ワーカーが実際には振り込まない Fake で請求書を支払済みにしないようにする
+func NewGatewayFromEnv() (gateway.PaymentGateway, error) {
+	switch name := os.Getenv("PAYMENT_GATEWAY"); name {
+	case GatewayFake:
+		log.Print("PAYMENT_GATEWAY is fake; payments are not actually transferred")
+		return NewFakeGateway(), nil
+	case "":
+		return nil, errors.New("PAYMENT_GATEWAY is not set")
+	default:
+		return nil, fmt.Errorf("unsupported PAYMENT_GATEWAY: %q", name)
+	}
+}
@@ -0,0 +1,59 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/take73/invoice-api-example/internal/domain/gateway"
+)
+
+// FakeGateway ネットワークを使わない決定的な支払ゲートウェイ（開発・テスト用）.
+// 振込先口座が未登録、または振込金額が0以下の依頼は拒否し、それ以外は成功させる.
+// 冪等キーが同じ依頼は実行せず、最初の結果を返す
+type FakeGateway struct {
+	mu       sync.Mutex
+	requests []gateway.PaymentRequest
+	results  map[string]*gateway.PaymentResult // 冪等キーごとの結果
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{results: map[string]*gateway.PaymentResult{}}
+}
+
+// Submit 支払依頼を受け付ける. 取引IDは請求書IDから決定的に生成する
+func (g *FakeGateway) Submit(ctx context.Context, req gateway.PaymentRequest) (*gateway.PaymentResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if res, ok := g.results[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return res, nil
+	}
+	g.requests = append(g.requests, req)
+
+	res := &gateway.PaymentResult{
+		Succeeded:     true,
+		TransactionID: fmt.Sprintf("FAKE-%08d", req.InvoiceID),
+		Message:       "accepted",
+	}
+	switch {
+	case req.BankAccount == nil:
+		res = &gateway.PaymentResult{Succeeded: false, Message: "rejected: bank account is not registered"}
+	case !req.Amount.IsPositive():
+		res = &gateway.PaymentResult{Succeeded: false, Message: "rejected: amount must be positive"}
+	}
+	if req.IdempotencyKey != "" {
+		g.results[req.IdempotencyKey] = res
+	}
+	return res, nil
+}
+
+// Requests これまでに実行した依頼を返す（冪等キーが同じ再送は含まない）
+func (g *FakeGateway) Requests() []gateway.PaymentRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]gateway.PaymentRequest(nil), g.requests...)
+}
@@ -0,0 +1,152 @@
+package rdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/entity"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) repository.APIKey {
+	return &APIKeyRepository{db: db}
+}
+
+// GetByID APIキーをIDで取得します
+func (r *APIKeyRepository) GetByID(id uint) (*model.APIKey, error) {
+	var e entity.APIKey
+	if err := r.db.Where("api_key_id = ?", id).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve api key with ID %d: %w", id, err)
+	}
+
+	return toAPIKeyModel(&e), nil
+}
+
+// GetByKeyID APIキーを識別子で取得します
+func (r *APIKeyRepository) GetByKeyID(keyID string) (*model.APIKey, error) {
+	var e entity.APIKey
+	if err := r.db.Where("key_id = ?", keyID).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve api key by key ID: %w", err)
+	}
+
+	return toAPIKeyModel(&e), nil
+}
+
+// FindByOrganizationID 組織のAPIキーをIDの昇順で取得します
+func (r *APIKeyRepository) FindByOrganizationID(organizationID uint) ([]*model.APIKey, error) {
+	var entities []entity.APIKey
+	if err := r.db.Where("organization_id = ?", organizationID).
+		Order("api_key_id asc").
+		Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve api keys of organization ID %d: %w", organizationID, err)
+	}
+
+	keys := make([]*model.APIKey, len(entities))
+	for i := range entities {
+		keys[i] = toAPIKeyModel(&entities[i])
+	}
+	return keys, nil
+}
+
+// Create APIキーを登録します
+func (r *APIKeyRepository) Create(key *model.APIKey) (*model.APIKey, error) {
+	e := toAPIKeyEntity(key)
+	if err := r.db.Create(e).Error; err != nil {
+		if isDuplicateEntry(err) {
+			return nil, commonErrors.ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return toAPIKeyModel(e), nil
+}
+
+// Revoke APIキーの失効日時を記録します. 他組織のAPIキーは更新しません
+func (r *APIKeyRepository) Revoke(key *model.APIKey) (*model.APIKey, error) {
+	result := r.db.Model(&entity.APIKey{}).
+		Where("api_key_id = ? AND organization_id = ?", key.ID, key.OrganizationID).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", key.RevokedAt)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to revoke api key with ID %d: %w", key.ID, result.Error)
+	}
+
+	// 失効済みの場合は RowsAffected が0になるため、更新後の値を取得して存在を確認する
+	revoked, err := r.GetByID(key.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked.BelongsTo(key.OrganizationID) {
+		return nil, commonErrors.ErrNotFound
+	}
+	return revoked, nil
+}
+
+// UpdateLastUsedAt APIキーが最後に使われた日時を記録します
+func (r *APIKeyRepository) UpdateLastUsedAt(id uint, at time.Time) error {
+	if err := r.db.Model(&entity.APIKey{}).
+		Where("api_key_id = ?", id).
+		Update("last_used_at", at).Error; err != nil {
+		return fmt.Errorf("failed to record usage of api key with ID %d: %w", id, err)
+	}
+	return nil
+}
+
+// toAPIKeyModel ドメインモデルに変換
+func toAPIKeyModel(e *entity.APIKey) *model.APIKey {
+	fields := strings.Fields(e.Scopes)
+	scopes := make([]model.Permission, len(fields))
+	for i, scope := range fields {
+		scopes[i] = model.Permission(scope)
+	}
+
+	return &model.APIKey{
+		ID:             e.ID,
+		OrganizationID: e.OrganizationID,
+		Name:           e.Name,
+		KeyID:          e.KeyID,
+		KeyHash:        e.KeyHash,
+		Scopes:         scopes,
+		ExpiresAt:      e.ExpiresAt,
+		LastUsedAt:     e.LastUsedAt,
+		RevokedAt:      e.RevokedAt,
+		CreatedBy:      e.CreatedBy,
+		CreatedAt:      e.CreatedAt,
+	}
+}
+
+// toAPIKeyEntity Entityに変換
+func toAPIKeyEntity(key *model.APIKey) *entity.APIKey {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	return &entity.APIKey{
+		ID:             key.ID,
+		OrganizationID: key.OrganizationID,
+		Name:           key.Name,
+		KeyID:          key.KeyID,
+		KeyHash:        key.KeyHash,
+		Scopes:         strings.Join(scopes, " "),
+		ExpiresAt:      key.ExpiresAt,
+		LastUsedAt:     key.LastUsedAt,
+		RevokedAt:      key.RevokedAt,
+		CreatedBy:      key.CreatedBy,
+	}
+}
@@ -0,0 +1,100 @@
+package rdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm/logger"
+)
+
+func Test_APIKeyRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	repo := NewAPIKeyRepository(db)
+
+	expiresAt := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	created, err := repo.Create(&model.APIKey{
+		OrganizationID: 1,
+		Name:           "会計システム連携",
+		KeyID:          "0123456789ab",
+		KeyHash:        "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+		Scopes:         []model.Permission{model.PermissionReadInvoice, model.PermissionWriteInvoice},
+		ExpiresAt:      &expiresAt,
+		CreatedBy:      "auth0|user1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.ID == 0 || created.CreatedAt.IsZero() {
+		t.Errorf("created api key = %+v, want ID and creation time", created)
+	}
+
+	// 識別子の重複は ErrConflict
+	if _, err := repo.Create(&model.APIKey{
+		OrganizationID: 2,
+		Name:           "重複",
+		KeyID:          "0123456789ab",
+		KeyHash:        "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+		Scopes:         []model.Permission{model.PermissionReadInvoice},
+		CreatedBy:      "auth0|user1",
+	}); !errors.Is(err, commonErrors.ErrConflict) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrConflict)
+	}
+
+	// 識別子で取得でき、スコープは付与した順に戻る
+	byKeyID, err := repo.GetByKeyID("0123456789ab")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(created.Scopes, byKeyID.Scopes); diff != "" || byKeyID.ID != created.ID {
+		t.Errorf("api key by key ID = %+v, scopes mismatch (-want +got):\n%s", byKeyID, diff)
+	}
+	if _, err := repo.GetByKeyID("unknown"); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+
+	// 使用日時を記録する
+	usedAt := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	if err := repo.UpdateLastUsedAt(created.ID, usedAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 他組織のAPIキーとしては失効できない
+	revokedAt := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	other := *byKeyID
+	other.OrganizationID = 2
+	other.Revoke(revokedAt)
+	if _, err := repo.Revoke(&other); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+
+	byKeyID.Revoke(revokedAt)
+	revoked, err := repo.Revoke(byKeyID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revoked.RevokedAt == nil || !revoked.RevokedAt.Equal(revokedAt) ||
+		revoked.LastUsedAt == nil || !revoked.LastUsedAt.Equal(usedAt) {
+		t.Errorf("revoked api key = %+v, want revokedAt %v and lastUsedAt %v", revoked, revokedAt, usedAt)
+	}
+
+	// 失効済みのAPIキーも一覧に含める
+	keys, err := repo.FindByOrganizationID(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Status(usedAt) != model.APIKeyStatusRevoked {
+		t.Errorf("api keys = %+v, want the revoked key", keys)
+	}
+	keys, _ = repo.FindByOrganizationID(2)
+	if len(keys) != 0 {
+		t.Errorf("api keys of other organization = %d, want 0", len(keys))
+	}
+}
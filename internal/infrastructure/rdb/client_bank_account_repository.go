@@ -0,0 +1,231 @@
+package rdb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/entity"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ClientBankAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewClientBankAccountRepository(db *gorm.DB) repository.ClientBankAccount {
+	return &ClientBankAccountRepository{db: db}
+}
+
+// FindByClientID 取引先の振込先口座を登録順に取得します
+func (r *ClientBankAccountRepository) FindByClientID(clientID uint) ([]*model.ClientBankAccount, error) {
+	var entities []entity.ClientBankAccount
+	if err := r.db.Where("client_id = ?", clientID).
+		Order("account_id ASC").
+		Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve bank accounts for client ID %d: %w", clientID, err)
+	}
+
+	accounts := make([]*model.ClientBankAccount, len(entities))
+	for i := range entities {
+		accounts[i] = toClientBankAccountModel(&entities[i])
+	}
+	return accounts, nil
+}
+
+// GetByID 取引先の振込先口座を取得します
+func (r *ClientBankAccountRepository) GetByID(clientID, id uint) (*model.ClientBankAccount, error) {
+	e, err := findClientBankAccount(r.db, clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toClientBankAccountModel(e), nil
+}
+
+// Create 振込先口座を登録します. 同じ取引先の口座の登録・削除と競合しないよう取引先の行をロックする
+func (r *ClientBankAccountRepository) Create(account *model.ClientBankAccount) (*model.ClientBankAccount, error) {
+	e := toClientBankAccountEntity(account)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockClient(tx, account.ClientID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&entity.ClientBankAccount{}).
+			Where("client_id = ?", account.ClientID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count bank accounts for client ID %d: %w", account.ClientID, err)
+		}
+		// 最初の口座は既定の口座にする
+		if count == 0 {
+			e.IsDefault = true
+		}
+		if e.IsDefault {
+			if err := clearDefaultBankAccount(tx, account.ClientID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(e).Error; err != nil {
+			return fmt.Errorf("failed to create bank account: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toClientBankAccountModel(e), nil
+}
+
+// Update 振込先口座の登録内容を更新します
+func (r *ClientBankAccountRepository) Update(account *model.ClientBankAccount) (*model.ClientBankAccount, error) {
+	// 値が変わらない場合も RowsAffected は0になるため、先に存在を確認する
+	if _, err := findClientBankAccount(r.db, account.ClientID, account.ID); err != nil {
+		return nil, err
+	}
+
+	e := toClientBankAccountEntity(account)
+	if err := r.db.Model(&entity.ClientBankAccount{}).
+		Where("account_id = ? AND client_id = ?", account.ID, account.ClientID).
+		Updates(map[string]interface{}{
+			"bank_code":      e.BankCode,
+			"bank_name":      e.BankName,
+			"branch_code":    e.BranchCode,
+			"branch_name":    e.BranchName,
+			"account_type":   e.AccountType,
+			"account_number": e.AccountNumber,
+			"account_name":   e.AccountName,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update bank account with ID %d: %w", account.ID, err)
+	}
+
+	return r.GetByID(account.ClientID, account.ID)
+}
+
+// Delete 振込先口座を削除します. 既定の口座を削除した場合は残りの最も古い口座を既定にする
+func (r *ClientBankAccountRepository) Delete(clientID, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockClient(tx, clientID); err != nil {
+			return err
+		}
+
+		deleted, err := findClientBankAccount(tx, clientID, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.ClientBankAccount{}, deleted.ID).Error; err != nil {
+			return fmt.Errorf("failed to delete bank account with ID %d: %w", id, err)
+		}
+		if !deleted.IsDefault {
+			return nil
+		}
+
+		var oldest entity.ClientBankAccount
+		if err := tx.Where("client_id = ?", clientID).Order("account_id ASC").First(&oldest).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to retrieve bank accounts for client ID %d: %w", clientID, err)
+		}
+		if err := tx.Model(&oldest).Update("is_default", true).Error; err != nil {
+			return fmt.Errorf("failed to set default bank account with ID %d: %w", oldest.ID, err)
+		}
+		return nil
+	})
+}
+
+// SetDefault 支払に使う既定の口座を変更します
+func (r *ClientBankAccountRepository) SetDefault(clientID, id uint) (*model.ClientBankAccount, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockClient(tx, clientID); err != nil {
+			return err
+		}
+		if _, err := findClientBankAccount(tx, clientID, id); err != nil {
+			return err
+		}
+
+		if err := clearDefaultBankAccount(tx, clientID); err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.ClientBankAccount{}).
+			Where("account_id = ?", id).
+			Update("is_default", true).Error; err != nil {
+			return fmt.Errorf("failed to set default bank account with ID %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(clientID, id)
+}
+
+// lockClient 取引先の行を排他ロックする. 取引先が存在しない場合は ErrNotFound を返す
+func lockClient(tx *gorm.DB, clientID uint) error {
+	var client entity.Client
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("client_id = ?", clientID).
+		First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return commonErrors.ErrNotFound
+		}
+		return fmt.Errorf("failed to lock client with ID %d: %w", clientID, err)
+	}
+	return nil
+}
+
+// clearDefaultBankAccount 取引先の既定の口座を解除する
+func clearDefaultBankAccount(tx *gorm.DB, clientID uint) error {
+	if err := tx.Model(&entity.ClientBankAccount{}).
+		Where("client_id = ? AND is_default = ?", clientID, true).
+		Update("is_default", false).Error; err != nil {
+		return fmt.Errorf("failed to clear default bank account for client ID %d: %w", clientID, err)
+	}
+	return nil
+}
+
+func findClientBankAccount(db *gorm.DB, clientID, id uint) (*entity.ClientBankAccount, error) {
+	var e entity.ClientBankAccount
+	if err := db.Where("account_id = ? AND client_id = ?", id, clientID).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve bank account with ID %d: %w", id, err)
+	}
+	return &e, nil
+}
+
+// toClientBankAccountEntity Entityに変換
+func toClientBankAccountEntity(account *model.ClientBankAccount) *entity.ClientBankAccount {
+	return &entity.ClientBankAccount{
+		ID:            account.ID,
+		ClientID:      account.ClientID,
+		BankCode:      account.BankCode,
+		BankName:      account.BankName,
+		BranchCode:    account.BranchCode,
+		BranchName:    account.BranchName,
+		AccountType:   string(account.AccountType),
+		AccountNumber: account.AccountNumber,
+		AccountName:   account.AccountName,
+		IsDefault:     account.IsDefault,
+	}
+}
+
+// toClientBankAccountModel ドメインモデルに変換
+func toClientBankAccountModel(e *entity.ClientBankAccount) *model.ClientBankAccount {
+	return &model.ClientBankAccount{
+		ID:            e.ID,
+		ClientID:      e.ClientID,
+		BankCode:      e.BankCode,
+		BankName:      e.BankName,
+		BranchCode:    e.BranchCode,
+		BranchName:    e.BranchName,
+		AccountType:   model.AccountType(e.AccountType),
+		AccountNumber: e.AccountNumber,
+		AccountName:   e.AccountName,
+		IsDefault:     e.IsDefault,
+	}
+}
@@ -0,0 +1,118 @@
+package rdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm/logger"
+)
+
+// defaultAccountIDs 既定の口座のIDを登録順に返す
+func defaultAccountIDs(accounts []*model.ClientBankAccount) []uint {
+	var ids []uint
+	for _, account := range accounts {
+		if account.IsDefault {
+			ids = append(ids, account.ID)
+		}
+	}
+	return ids
+}
+
+func Test_ClientBankAccountRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	repo := NewClientBankAccountRepository(db)
+
+	// 初期データの口座は既定の口座になっている
+	accounts, err := repo.FindByClientID(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]uint{1}, defaultAccountIDs(accounts)); diff != "" {
+		t.Errorf("default accounts mismatch (-want +got):\n%s", diff)
+	}
+
+	// 既定の口座として登録すると、それまでの既定は解除される
+	created, err := repo.Create(&model.ClientBankAccount{
+		ClientID:      1,
+		BankCode:      "0009",
+		BankName:      "三井住友銀行",
+		BranchCode:    "001",
+		BranchName:    "東京営業部",
+		AccountType:   model.AccountTypeChecking,
+		AccountNumber: "7654321",
+		AccountName:   "ﾄﾘﾋｷｻｷｴｰ",
+		IsDefault:     true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.ID != 4 {
+		t.Errorf("ID = %d, want 4", created.ID)
+	}
+	accounts, _ = repo.FindByClientID(1)
+	if diff := cmp.Diff([]uint{4}, defaultAccountIDs(accounts)); diff != "" {
+		t.Errorf("default accounts mismatch (-want +got):\n%s", diff)
+	}
+
+	// 支払に使う口座も既定の口座になる
+	invoice, err := NewInvoiceRepository(db).FindByID(1, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if invoice.Client.BankAccount == nil || invoice.Client.BankAccount.ID != 4 {
+		t.Errorf("bank account of invoice = %+v, want ID 4", invoice.Client.BankAccount)
+	}
+
+	// 登録内容を更新しても既定かどうかは変わらない
+	created.BranchName = "本店営業部"
+	created.IsDefault = false
+	updated, err := repo.Update(created)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.BranchName != "本店営業部" || !updated.IsDefault {
+		t.Errorf("updated = %+v, want branch name changed and still default", updated)
+	}
+
+	// 他の取引先の口座は扱わない
+	if _, err := repo.GetByID(2, 4); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+	if _, err := repo.SetDefault(2, 4); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+
+	// 既定の口座を変更する
+	if _, err := repo.SetDefault(1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	accounts, _ = repo.FindByClientID(1)
+	if diff := cmp.Diff([]uint{1}, defaultAccountIDs(accounts)); diff != "" {
+		t.Errorf("default accounts mismatch (-want +got):\n%s", diff)
+	}
+
+	// 既定の口座を削除すると残りの最も古い口座が既定になる
+	if err := repo.Delete(1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	accounts, _ = repo.FindByClientID(1)
+	if diff := cmp.Diff([]uint{4}, defaultAccountIDs(accounts)); diff != "" {
+		t.Errorf("default accounts mismatch (-want +got):\n%s", diff)
+	}
+	if err := repo.Delete(1, 1); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+
+	// 存在しない取引先
+	_, err = repo.Create(&model.ClientBankAccount{ClientID: 99})
+	if !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
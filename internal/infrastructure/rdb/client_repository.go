@@ -3,6 +3,7 @@ package rdb
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/take73/invoice-api-example/internal/domain/model"
 	"github.com/take73/invoice-api-example/internal/domain/repository"
@@ -29,15 +30,127 @@ func (r *ClientRepository) GetByID(id uint) (*model.Client, error) {
 		return nil, fmt.Errorf("failed to retrieve client with ID %d: %w", id, err)
 	}
 
-	client := &model.Client{
-		ID:             entity.ID,
-		OrganizationID: entity.OrganizationID,
-		Name:           entity.Name,
-		Representative: entity.RepresentativeName,
-		PhoneNumber:    entity.PhoneNumber,
-		PostalCode:     entity.PostalCode,
-		Address:        entity.Address,
+	return toClientModel(&entity), nil
+}
+
+// Create 取引先を登録します
+func (r *ClientRepository) Create(client *model.Client) (*model.Client, error) {
+	e := toClientEntity(client)
+	if err := r.db.Create(e).Error; err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return toClientModel(e), nil
+}
+
+// Update 取引先の登録内容を更新します. 他組織の取引先は更新しません
+func (r *ClientRepository) Update(client *model.Client) (*model.Client, error) {
+	e := toClientEntity(client)
+	result := r.db.Model(&entity.Client{}).
+		Where("client_id = ? AND organization_id = ?", client.ID, client.OrganizationID).
+		Updates(map[string]interface{}{
+			"name":                 e.Name,
+			"registration_number":  e.RegistrationNumber,
+			"representative_name":  e.RepresentativeName,
+			"phone_number":         e.PhoneNumber,
+			"postal_code":          e.PostalCode,
+			"address":              e.Address,
+			"payee_type":           e.PayeeType,
+			"withholding_category": e.WithholdingCategory,
+			"archived_at":          e.ArchivedAt,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update client with ID %d: %w", client.ID, result.Error)
+	}
+
+	// 値が変わらない場合も RowsAffected は0になるため、更新後の値を取得して存在を確認する
+	updated, err := r.GetByID(client.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !updated.BelongsTo(client.OrganizationID) {
+		return nil, commonErrors.ErrNotFound
+	}
+	return updated, nil
+}
+
+// Search 取引先を取引先名・取引先IDの昇順で検索します
+func (r *ClientRepository) Search(condition repository.ClientSearchCondition) (*repository.ClientPage, error) {
+	if condition.Limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", condition.Limit)
+	}
+
+	query := r.db.Model(&entity.Client{}).Where("organization_id = ?", condition.OrganizationID)
+	if condition.Name != "" {
+		query = query.Where("name LIKE ?", "%"+escapeLike(condition.Name)+"%")
+	}
+	if !condition.IncludeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+	if condition.After != nil {
+		query = query.Where("(name > ? OR (name = ? AND client_id > ?))",
+			condition.After.Name, condition.After.Name, condition.After.ID)
 	}
 
-	return client, nil
+	// 次のページの有無を判定するため1件多く取得する
+	var entities []entity.Client
+	if err := query.
+		Order("name ASC, client_id ASC").
+		Limit(condition.Limit + 1).
+		Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to search clients: %w", err)
+	}
+
+	page := &repository.ClientPage{}
+	if len(entities) > condition.Limit {
+		entities = entities[:condition.Limit]
+		last := entities[len(entities)-1]
+		page.NextCursor = &repository.ClientCursor{Name: last.Name, ID: last.ID}
+	}
+
+	page.Clients = make([]*model.Client, len(entities))
+	for i := range entities {
+		page.Clients[i] = toClientModel(&entities[i])
+	}
+	return page, nil
+}
+
+// likeEscaper LIKE のワイルドカードをエスケープする（MySQLの既定のエスケープ文字はバックスラッシュ）
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// toClientEntity Entityに変換
+func toClientEntity(client *model.Client) *entity.Client {
+	return &entity.Client{
+		ID:                  client.ID,
+		OrganizationID:      client.OrganizationID,
+		Name:                client.Name,
+		RegistrationNumber:  nullableString(client.RegistrationNumber),
+		RepresentativeName:  client.Representative,
+		PhoneNumber:         client.PhoneNumber,
+		PostalCode:          client.PostalCode,
+		Address:             client.Address,
+		PayeeType:           string(client.PayeeType),
+		WithholdingCategory: string(client.WithholdingCategory),
+		ArchivedAt:          client.ArchivedAt,
+	}
+}
+
+// toClientModel ドメインモデルに変換
+func toClientModel(e *entity.Client) *model.Client {
+	return &model.Client{
+		ID:                  e.ID,
+		OrganizationID:      e.OrganizationID,
+		Name:                e.Name,
+		RegistrationNumber:  stringValue(e.RegistrationNumber),
+		Representative:      e.RepresentativeName,
+		PhoneNumber:         e.PhoneNumber,
+		PostalCode:          e.PostalCode,
+		Address:             e.Address,
+		PayeeType:           model.PayeeType(e.PayeeType),
+		WithholdingCategory: model.WithholdingCategory(e.WithholdingCategory),
+		ArchivedAt:          e.ArchivedAt,
+	}
 }
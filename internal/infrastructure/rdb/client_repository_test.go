@@ -1,11 +1,15 @@
 package rdb
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
 	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 	"gorm.io/gorm/logger"
 )
 
@@ -33,13 +37,16 @@ func Test_ClientRepository_GetByID(t *testing.T) {
 				id: 1,
 			},
 			want: &model.Client{
-				ID:             1,
-				OrganizationID: 1,
-				Name:           "取引先A",
-				Representative: "取引先担当者A",
-				PhoneNumber:    "03-1234-0001",
-				PostalCode:     "100-0010",
-				Address:        "東京都港区芝公園1-1-1",
+				ID:                  1,
+				OrganizationID:      1,
+				Name:                "取引先A",
+				RegistrationNumber:  "T9234567890123",
+				Representative:      "取引先担当者A",
+				PhoneNumber:         "03-1234-0001",
+				PostalCode:          "100-0010",
+				Address:             "東京都港区芝公園1-1-1",
+				PayeeType:           model.PayeeTypeCorporation,
+				WithholdingCategory: model.WithholdingCategoryNone,
 			},
 		},
 	}
@@ -64,3 +71,124 @@ func Test_ClientRepository_GetByID(t *testing.T) {
 	}
 
 }
+
+func Test_ClientRepository_CreateAndUpdate(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	repo := NewClientRepository(db)
+
+	created, err := repo.Create(&model.Client{
+		OrganizationID:      1,
+		Name:                "個人デザイナー",
+		Representative:      "田中 次郎",
+		PostalCode:          "1500001",
+		Address:             "東京都渋谷区神宮前1-1-1",
+		PayeeType:           model.PayeeTypeIndividual,
+		WithholdingCategory: model.WithholdingCategoryRemuneration,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.ID != 4 {
+		t.Errorf("ID = %d, want 4", created.ID)
+	}
+
+	got, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, created); diff != "" {
+		t.Errorf("api got != want (-got +want)\n%s", diff)
+	}
+
+	// 登録番号の登録とアーカイブ
+	archivedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	created.RegistrationNumber = "T7000012050002"
+	created.ArchivedAt = &archivedAt
+	updated, err := repo.Update(created)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(updated, created); diff != "" {
+		t.Errorf("api got != want (-got +want)\n%s", diff)
+	}
+
+	// 他組織の取引先は更新できない
+	other := *created
+	other.OrganizationID = 2
+	if _, err := repo.Update(&other); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
+
+func Test_ClientRepository_Search(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	// 取引先Bをアーカイブし、名前にワイルドカードを含む取引先を追加する
+	if err := db.Exec("UPDATE client SET archived_at = '2024-01-01 00:00:00' WHERE client_id = 2").Error; err != nil {
+		t.Fatalf("failed to archive client: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO client (organization_id, name, representative_name, postal_code, address)
+		VALUES (1, '100%商事', '担当者', '100-0001', '東京都千代田区'), (1, '1000商事', '担当者', '100-0001', '東京都千代田区')`).Error; err != nil {
+		t.Fatalf("failed to insert clients: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		condition  repository.ClientSearchCondition
+		wantIDs    []uint
+		wantCursor *repository.ClientCursor
+	}{
+		{
+			name:      "アーカイブした取引先を除く",
+			condition: repository.ClientSearchCondition{OrganizationID: 1, Limit: 10},
+			wantIDs:   []uint{4, 5, 1},
+		},
+		{
+			name:      "アーカイブした取引先を含む",
+			condition: repository.ClientSearchCondition{OrganizationID: 1, IncludeArchived: true, Limit: 10},
+			wantIDs:   []uint{4, 5, 1, 2},
+		},
+		{
+			name:      "取引先名の部分一致（ワイルドカードはエスケープする）",
+			condition: repository.ClientSearchCondition{OrganizationID: 1, Name: "0%", Limit: 10},
+			wantIDs:   []uint{4},
+		},
+		{
+			name:       "次のページがある",
+			condition:  repository.ClientSearchCondition{OrganizationID: 1, Limit: 2},
+			wantIDs:    []uint{4, 5},
+			wantCursor: &repository.ClientCursor{Name: "1000商事", ID: 5},
+		},
+		{
+			name:      "カーソル以降",
+			condition: repository.ClientSearchCondition{OrganizationID: 1, After: &repository.ClientCursor{Name: "1000商事", ID: 5}, Limit: 2},
+			wantIDs:   []uint{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewClientRepository(db)
+			got, err := repo.Search(tt.condition)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var gotIDs []uint
+			for _, client := range got.Clients {
+				gotIDs = append(gotIDs, client.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ids mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantCursor, got.NextCursor); diff != "" {
+				t.Errorf("cursor mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// APIKey ORMのEntity
+type APIKey struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement;column:api_key_id"`
+	OrganizationID uint       `gorm:"column:organization_id;not null"`
+	Name           string     `gorm:"column:name;not null"`
+	KeyID          string     `gorm:"column:key_id;type:char(12);not null;unique"`
+	KeyHash        string     `gorm:"column:key_hash;type:char(64);not null"` // キー全体のSHA-256
+	Scopes         string     `gorm:"column:scopes;not null"`                 // 空白区切り
+	ExpiresAt      *time.Time `gorm:"column:expires_at"`
+	LastUsedAt     *time.Time `gorm:"column:last_used_at"`
+	RevokedAt      *time.Time `gorm:"column:revoked_at"`
+	CreatedBy      string     `gorm:"column:created_by;not null"`
+	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime"`
+
+	// Associations
+	Organization Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName overrides the table name used by GORM.
+func (APIKey) TableName() string {
+	return "api_key"
+}
@@ -4,15 +4,19 @@ import "time"
 
 // Client ORMのEntity
 type Client struct {
-	ID                 uint      `gorm:"primaryKey;autoIncrement;column:client_id"`
-	OrganizationID     uint      `gorm:"column:organization_id;not null"`
-	Name               string    `gorm:"column:name;not null"`
-	RepresentativeName string    `gorm:"column:representative_name;not null"`
-	PhoneNumber        string    `gorm:"column:phone_number"`
-	PostalCode         string    `gorm:"column:postal_code"`
-	Address            string    `gorm:"column:address"`
-	CreatedAt          time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt          time.Time `gorm:"column:updated_at;autoUpdateTime"`
+	ID                  uint       `gorm:"primaryKey;autoIncrement;column:client_id"`
+	OrganizationID      uint       `gorm:"column:organization_id;not null"`
+	Name                string     `gorm:"column:name;not null"`
+	RegistrationNumber  *string    `gorm:"column:registration_number;type:char(14)"` // 適格請求書発行事業者の登録番号（未登録の場合はNULL）
+	RepresentativeName  string     `gorm:"column:representative_name;not null"`
+	PhoneNumber         string     `gorm:"column:phone_number"`
+	PostalCode          string     `gorm:"column:postal_code"`
+	Address             string     `gorm:"column:address"`
+	PayeeType           string     `gorm:"column:payee_type;type:enum('corporation','individual');not null;default:'corporation'"`
+	WithholdingCategory string     `gorm:"column:withholding_category;type:enum('none','remuneration');not null;default:'none'"`
+	ArchivedAt          *time.Time `gorm:"column:archived_at"` // アーカイブした日時（アーカイブしていない場合はNULL）
+	CreatedAt           time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt           time.Time  `gorm:"column:updated_at;autoUpdateTime"`
 
 	// Associations
 	Organization Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE"`
@@ -6,10 +6,14 @@ import "time"
 type ClientBankAccount struct {
 	ID            uint      `gorm:"primaryKey;autoIncrement;column:account_id"`
 	ClientID      uint      `gorm:"column:client_id;not null"`
+	BankCode      string    `gorm:"column:bank_code;not null"`
 	BankName      string    `gorm:"column:bank_name;not null"`
+	BranchCode    string    `gorm:"column:branch_code;not null"`
 	BranchName    string    `gorm:"column:branch_name;not null"`
+	AccountType   string    `gorm:"column:account_type;not null"`
 	AccountNumber string    `gorm:"column:account_number;not null"`
 	AccountName   string    `gorm:"column:account_name;not null"`
+	IsDefault     bool      `gorm:"column:is_default;not null"`
 	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt     time.Time `gorm:"column:updated_at;autoUpdateTime"`
 
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FeePlan ORMのEntity
+type FeePlan struct {
+	ID             uint            `gorm:"primaryKey;autoIncrement;column:fee_plan_id"`
+	OrganizationID *uint           `gorm:"column:organization_id"`                 // 適用する組織（NULLなら標準プラン）
+	Name           string          `gorm:"column:name;not null"`                   // プラン名
+	Rate           decimal.Decimal `gorm:"column:rate;type:decimal(6,4);not null"` // 手数料率（例: 0.0400 = 4%）
+	StartDate      time.Time       `gorm:"column:start_date;not null"`             // 適用開始日
+	EndDate        *time.Time      `gorm:"column:end_date"`                        // 適用終了日（NULLなら現在も有効）
+	CreatedAt      time.Time       `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time       `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (FeePlan) TableName() string {
+	return "fee_plan"
+}
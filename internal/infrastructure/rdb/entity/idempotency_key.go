@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// IdempotencyKey ORMのEntity
+type IdempotencyKey struct {
+	ID                  uint      `gorm:"primaryKey;autoIncrement;column:idempotency_key_id"`
+	Scope               string    `gorm:"column:scope;not null;uniqueIndex:uk_scope_key"`
+	IdempotencyKey      string    `gorm:"column:idempotency_key;not null;uniqueIndex:uk_scope_key"`
+	RequestHash         string    `gorm:"column:request_hash;type:char(64);not null"`
+	ResponseStatus      *int      `gorm:"column:response_status"` // 処理中はNULL
+	ResponseContentType *string   `gorm:"column:response_content_type"`
+	ResponseHeaders     *string   `gorm:"column:response_headers;type:text"` // JSON
+	ResponseBody        []byte    `gorm:"column:response_body;type:mediumblob"`
+	ExpiresAt           time.Time `gorm:"column:expires_at;not null"`
+	CreatedAt           time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName overrides the table name used by GORM.
+func (IdempotencyKey) TableName() string {
+	return "idempotency_key"
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceLineItem ORMのEntity
+type InvoiceLineItem struct {
+	ID          uint            `gorm:"primaryKey;autoIncrement;column:invoice_line_item_id"`
+	InvoiceID   uint            `gorm:"column:invoice_id;not null"`
+	LineNumber  int             `gorm:"column:line_number;not null"`
+	Description string          `gorm:"column:description;not null"`
+	Quantity    decimal.Decimal `gorm:"column:quantity;type:decimal(10,3);not null"`
+	UnitPrice   decimal.Decimal `gorm:"column:unit_price;type:decimal(10,2);not null"`
+	Amount      decimal.Decimal `gorm:"column:amount;type:decimal(10,2);not null"`
+	TaxCategory string          `gorm:"column:tax_category;type:enum('standard','reduced','exempt','non_taxable');not null"`
+	TaxRate     decimal.Decimal `gorm:"column:tax_rate;type:decimal(5,2);not null"`
+	CreatedAt   time.Time       `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time       `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName overrides the table name used by GORM.
+func (InvoiceLineItem) TableName() string {
+	return "invoice_line_item"
+}
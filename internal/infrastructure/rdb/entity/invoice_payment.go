@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// InvoicePayment ORMのEntity
+type InvoicePayment struct {
+	ID            uint            `gorm:"primaryKey;autoIncrement;column:invoice_payment_id"`
+	InvoiceID     uint            `gorm:"column:invoice_id;not null"`
+	AttemptID     *uint           `gorm:"column:attempt_id"` // 消込で記録した場合はNULL
+	Amount        decimal.Decimal `gorm:"column:amount;type:decimal(10,2);not null"`
+	Succeeded     bool            `gorm:"column:succeeded;not null"`
+	TransactionID string          `gorm:"column:transaction_id"`
+	Response      string          `gorm:"column:response"`
+	ProcessedAt   time.Time       `gorm:"column:processed_at;autoCreateTime"`
+
+	// Associations
+	Invoice Invoice `gorm:"foreignKey:InvoiceID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName overrides the table name used by GORM.
+func (InvoicePayment) TableName() string {
+	return "invoice_payment"
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// InvoiceStatusHistory ORMのEntity
+type InvoiceStatusHistory struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement;column:invoice_status_history_id"`
+	InvoiceID  uint      `gorm:"column:invoice_id;not null"`
+	FromStatus string    `gorm:"column:from_status;type:enum('pending','processing','paid','error');not null"`
+	ToStatus   string    `gorm:"column:to_status;type:enum('pending','processing','paid','error');not null"`
+	ChangedBy  string    `gorm:"column:changed_by;not null"`
+	ChangedAt  time.Time `gorm:"column:changed_at;autoCreateTime"`
+
+	// Associations
+	Invoice Invoice `gorm:"foreignKey:InvoiceID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName overrides the table name used by GORM.
+func (InvoiceStatusHistory) TableName() string {
+	return "invoice_status_history"
+}
@@ -6,10 +6,13 @@ import "time"
 type Organization struct {
 	ID                 uint      `gorm:"primaryKey;autoIncrement;column:organization_id"`
 	Name               string    `gorm:"column:name;not null"`
+	RegistrationNumber *string   `gorm:"column:registration_number;type:char(14)"` // 適格請求書発行事業者の登録番号（未登録の場合はNULL）
 	RepresentativeName string    `gorm:"column:representative_name;not null"`
 	PhoneNumber        string    `gorm:"column:phone_number"`
 	PostalCode         string    `gorm:"column:postal_code"`
 	Address            string    `gorm:"column:address"`
+	RoundingPolicy     string    `gorm:"column:rounding_policy;type:enum('truncate','ceil','half_up','bankers');not null;default:'truncate'"`
+	PaymentMethod      string    `gorm:"column:payment_method;type:enum('transfer_file','gateway');not null;default:'transfer_file'"`
 	CreatedAt          time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt          time.Time `gorm:"column:updated_at;autoUpdateTime"`
 
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// OrganizationBankAccount ORMのEntity
+type OrganizationBankAccount struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement;column:organization_bank_account_id"`
+	OrganizationID uint      `gorm:"column:organization_id;not null;uniqueIndex"`
+	RequesterCode  string    `gorm:"column:requester_code;not null"`
+	RequesterName  string    `gorm:"column:requester_name;not null"`
+	BankCode       string    `gorm:"column:bank_code;not null"`
+	BankName       string    `gorm:"column:bank_name;not null"`
+	BranchCode     string    `gorm:"column:branch_code;not null"`
+	BranchName     string    `gorm:"column:branch_name;not null"`
+	AccountType    string    `gorm:"column:account_type;not null"`
+	AccountNumber  string    `gorm:"column:account_number;not null"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;autoUpdateTime"`
+
+	// Associations
+	Organization Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName overrides the table name used by GORM.
+func (OrganizationBankAccount) TableName() string {
+	return "organization_bank_account"
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// OrganizationSettings ORMのEntity. 版ごとに1行で、登録後は更新しない
+type OrganizationSettings struct {
+	OrganizationID       uint      `gorm:"primaryKey;column:organization_id"`
+	Version              uint      `gorm:"primaryKey;column:version"`
+	DefaultFeePlanID     *uint     `gorm:"column:default_fee_plan_id"`
+	RoundingPolicy       string    `gorm:"column:rounding_policy;type:enum('truncate','ceil','half_up','bankers');not null;default:'truncate'"`
+	PaymentTermsDays     int       `gorm:"column:payment_terms_days;not null"`
+	PaymentMethod        string    `gorm:"column:payment_method;type:enum('transfer_file','gateway');not null;default:'transfer_file'"`
+	NotificationEmail    *string   `gorm:"column:notification_email"`
+	NotifyOnPaid         bool      `gorm:"column:notify_on_paid;not null"`
+	NotifyOnPaymentError bool      `gorm:"column:notify_on_payment_error;not null"`
+	NotifyOnOverdue      bool      `gorm:"column:notify_on_overdue;not null"`
+	CreatedBy            string    `gorm:"column:created_by;not null"`
+	CreatedAt            time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName overrides the table name used by GORM.
+func (OrganizationSettings) TableName() string {
+	return "organization_settings"
+}
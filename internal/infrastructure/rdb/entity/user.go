@@ -4,13 +4,17 @@ import "time"
 
 // User ORMのEntity
 type User struct {
-	ID             uint      `gorm:"primaryKey;autoIncrement;column:user_id"`
-	OrganizationID uint      `gorm:"column:organization_id;not null"`
-	Name           string    `gorm:"column:name;not null"`
-	Email          string    `gorm:"column:email;not null;unique"`
-	Password       string    `gorm:"column:password;not null"`
-	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt      time.Time `gorm:"column:updated_at;autoUpdateTime"`
+	ID                  uint       `gorm:"primaryKey;autoIncrement;column:user_id"`
+	OrganizationID      uint       `gorm:"column:organization_id;not null"`
+	Name                string     `gorm:"column:name;not null"`
+	Email               string     `gorm:"column:email;not null;unique"`
+	Role                string     `gorm:"column:role;type:enum('owner','admin','accountant','approver','viewer');not null;default:'viewer'"`
+	PasswordHash        *string    `gorm:"column:password_hash"`                // パスワードのハッシュ（未設定の場合はNULL）
+	InvitationTokenHash *string    `gorm:"column:invitation_token_hash;unique"` // 招待トークンのSHA-256
+	InvitationExpiresAt *time.Time `gorm:"column:invitation_expires_at"`
+	DeactivatedAt       *time.Time `gorm:"column:deactivated_at"`
+	CreatedAt           time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt           time.Time  `gorm:"column:updated_at;autoUpdateTime"`
 
 	// Associations
 	Organization Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE"`
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// UserRoleHistory ORMのEntity. 登録後は更新しない
+type UserRoleHistory struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement;column:user_role_history_id"`
+	UserID    uint      `gorm:"column:user_id;not null"`
+	FromRole  *string   `gorm:"column:from_role;type:enum('owner','admin','accountant','approver','viewer')"` // 招待時はNULL
+	ToRole    string    `gorm:"column:to_role;type:enum('owner','admin','accountant','approver','viewer');not null"`
+	ChangedBy string    `gorm:"column:changed_by;not null"`
+	ChangedAt time.Time `gorm:"column:changed_at;autoCreateTime"`
+
+	// Associations
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName overrides the table name used by GORM.
+func (UserRoleHistory) TableName() string {
+	return "user_role_history"
+}
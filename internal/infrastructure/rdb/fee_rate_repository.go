@@ -0,0 +1,65 @@
+package rdb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/entity"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm"
+)
+
+type FeeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewFeeRateRepository(db *gorm.DB) repository.FeeRate {
+	return &FeeRateRepository{db: db}
+}
+
+// GetPlanByDate 指定した日付に組織へ適用される手数料プランを取得します.
+// 組織ごとのプランを標準プラン（organization_id が NULL）より優先します
+func (r *FeeRateRepository) GetPlanByDate(organizationID uint, date time.Time) (*model.FeePlan, error) {
+	var plan entity.FeePlan
+
+	if err := r.db.Where("organization_id = ? OR organization_id IS NULL", organizationID).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", date, date).
+		Order("organization_id IS NULL, start_date DESC").
+		First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve fee plan of organization ID %d for date %s: %w", organizationID, date.Format("2006-01-02"), err)
+	}
+
+	return toFeePlanModel(&plan), nil
+}
+
+// GetPlanByID 手数料プランをIDで取得します
+func (r *FeeRateRepository) GetPlanByID(id uint) (*model.FeePlan, error) {
+	var plan entity.FeePlan
+	if err := r.db.Where("fee_plan_id = ?", id).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve fee plan with ID %d: %w", id, err)
+	}
+
+	return toFeePlanModel(&plan), nil
+}
+
+// toFeePlanModel ドメインモデルに変換
+func toFeePlanModel(plan *entity.FeePlan) *model.FeePlan {
+	rate, _ := plan.Rate.Float64()
+	return &model.FeePlan{
+		ID:             plan.ID,
+		OrganizationID: uintValue(plan.OrganizationID),
+		Name:           plan.Name,
+		Rate:           rate,
+		StartDate:      plan.StartDate,
+		EndDate:        plan.EndDate,
+	}
+}
@@ -0,0 +1,131 @@
+package rdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm/logger"
+)
+
+func Test_FeeRateRepository_GetPlanByDate(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	type input struct {
+		organizationID uint
+		date           time.Time
+	}
+
+	tests := []struct {
+		name    string
+		input   input
+		want    *model.FeePlan
+		wantErr error
+	}{
+		{
+			name: "組織のプランを標準プランより優先",
+			input: input{
+				organizationID: 1,
+				date:           time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: &model.FeePlan{
+				ID:             2,
+				OrganizationID: 1,
+				Name:           "大口契約プラン",
+				Rate:           0.03,
+				StartDate:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "組織のプランの開始前は標準プラン",
+			input: input{
+				organizationID: 1,
+				date:           time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: &model.FeePlan{
+				ID:        1,
+				Name:      "標準プラン",
+				Rate:      0.04,
+				StartDate: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "プランのない組織は標準プラン",
+			input: input{
+				organizationID: 2,
+				date:           time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: &model.FeePlan{
+				ID:        1,
+				Name:      "標準プラン",
+				Rate:      0.04,
+				StartDate: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "適用期間内のプランがない",
+			input: input{
+				organizationID: 1,
+				date:           time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: commonErrors.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewFeeRateRepository(db)
+			got, err := repo.GetPlanByDate(tt.input.organizationID, tt.input.date)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			opts := cmpopts.EquateApproxTime(24 * time.Hour)
+			if diff := cmp.Diff(got, tt.want, opts); diff != "" {
+				t.Errorf("api got != want (-got +want)\n%s", diff)
+				return
+			}
+		})
+	}
+}
+
+func Test_FeeRateRepository_GetPlanByID(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	repo := NewFeeRateRepository(db)
+
+	got, err := repo.GetPlanByID(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &model.FeePlan{
+		ID:             2,
+		OrganizationID: 1,
+		Name:           "大口契約プラン",
+		Rate:           0.03,
+		StartDate:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if diff := cmp.Diff(got, want, cmpopts.EquateApproxTime(24*time.Hour)); diff != "" {
+		t.Errorf("api got != want (-got +want)\n%s", diff)
+	}
+
+	if _, err := repo.GetPlanByID(99); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
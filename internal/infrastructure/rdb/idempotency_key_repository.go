@@ -0,0 +1,132 @@
+package rdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/entity"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm"
+)
+
+type IdempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) repository.IdempotencyKey {
+	return &IdempotencyKeyRepository{db: db}
+}
+
+// Get 操作主体のキーを取得します
+func (r *IdempotencyKeyRepository) Get(scope, key string) (*model.IdempotencyKey, error) {
+	var e entity.IdempotencyKey
+	if err := r.db.Where("scope = ? AND idempotency_key = ?", scope, key).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve idempotency key: %w", err)
+	}
+
+	return toIdempotencyKeyModel(&e)
+}
+
+// Create キーを予約します. 同時に同じキーで予約した場合は一意制約により一方だけが成功します
+func (r *IdempotencyKeyRepository) Create(key *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	e, err := toIdempotencyKeyEntity(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Create(e).Error; err != nil {
+		if isDuplicateEntry(err) {
+			return nil, commonErrors.ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create idempotency key: %w", err)
+	}
+
+	return toIdempotencyKeyModel(e)
+}
+
+// Complete 予約したキーに応答と有効期限を保存します
+func (r *IdempotencyKeyRepository) Complete(key *model.IdempotencyKey) error {
+	e, err := toIdempotencyKeyEntity(key)
+	if err != nil {
+		return err
+	}
+	result := r.db.Model(&entity.IdempotencyKey{}).
+		Where("idempotency_key_id = ?", key.ID).
+		Updates(map[string]interface{}{
+			"response_status":       e.ResponseStatus,
+			"response_content_type": e.ResponseContentType,
+			"response_headers":      e.ResponseHeaders,
+			"response_body":         e.ResponseBody,
+			"expires_at":            e.ExpiresAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to complete idempotency key with ID %d: %w", key.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return commonErrors.ErrNotFound
+	}
+	return nil
+}
+
+// Delete キーの予約・保存した応答を削除します
+func (r *IdempotencyKeyRepository) Delete(id uint) error {
+	if err := r.db.Where("idempotency_key_id = ?", id).Delete(&entity.IdempotencyKey{}).Error; err != nil {
+		return fmt.Errorf("failed to delete idempotency key with ID %d: %w", id, err)
+	}
+	return nil
+}
+
+// toIdempotencyKeyModel ドメインモデルに変換
+func toIdempotencyKeyModel(e *entity.IdempotencyKey) (*model.IdempotencyKey, error) {
+	key := &model.IdempotencyKey{
+		ID:          e.ID,
+		Scope:       e.Scope,
+		Key:         e.IdempotencyKey,
+		RequestHash: e.RequestHash,
+		ExpiresAt:   e.ExpiresAt,
+		CreatedAt:   e.CreatedAt,
+	}
+	if e.ResponseStatus != nil {
+		key.Response = &model.StoredResponse{
+			StatusCode:  *e.ResponseStatus,
+			ContentType: stringValue(e.ResponseContentType),
+			Body:        e.ResponseBody,
+		}
+		if e.ResponseHeaders != nil {
+			if err := json.Unmarshal([]byte(*e.ResponseHeaders), &key.Response.Headers); err != nil {
+				return nil, fmt.Errorf("failed to decode response headers of idempotency key with ID %d: %w", e.ID, err)
+			}
+		}
+	}
+	return key, nil
+}
+
+// toIdempotencyKeyEntity Entityに変換
+func toIdempotencyKeyEntity(key *model.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	e := &entity.IdempotencyKey{
+		ID:             key.ID,
+		Scope:          key.Scope,
+		IdempotencyKey: key.Key,
+		RequestHash:    key.RequestHash,
+		ExpiresAt:      key.ExpiresAt,
+	}
+	if key.Response != nil {
+		status := key.Response.StatusCode
+		e.ResponseStatus = &status
+		e.ResponseContentType = nullableString(key.Response.ContentType)
+		e.ResponseBody = key.Response.Body
+		if len(key.Response.Headers) > 0 {
+			headers, err := json.Marshal(key.Response.Headers)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode response headers: %w", err)
+			}
+			e.ResponseHeaders = nullableString(string(headers))
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,78 @@
+package rdb
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm/logger"
+)
+
+func Test_IdempotencyKeyRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	repo := NewIdempotencyKeyRepository(db)
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	reserved, err := model.NewIdempotencyKey("auth0|user1", "order-1", "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	created, err := repo.Create(reserved)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 同じ操作主体の同じキーは ErrConflict. 他の操作主体は同じキーを使える
+	if _, err := repo.Create(reserved); !errors.Is(err, commonErrors.ErrConflict) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrConflict)
+	}
+	other := *reserved
+	other.Scope = "auth0|user2"
+	otherCreated, err := repo.Create(&other)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 処理中は応答をもたない
+	got, err := repo.Get("auth0|user1", "order-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ID != created.ID || got.IsCompleted() {
+		t.Errorf("reserved key = %+v, want the reservation without response", got)
+	}
+
+	// 応答を保存する
+	created.Complete(model.StoredResponse{StatusCode: 201, ContentType: "application/json", Headers: map[string][]string{"Etag": {`"1"`}}, Body: []byte(`{"id":1}`)}, now)
+	if err := repo.Complete(created); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err = repo.Get("auth0|user1", "order-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.IsCompleted() || got.Response.StatusCode != 201 || got.Response.ContentType != "application/json" ||
+		!bytes.Equal(got.Response.Body, []byte(`{"id":1}`)) || !got.ExpiresAt.Equal(now.Add(model.IdempotencyKeyRetention)) ||
+		!reflect.DeepEqual(got.Response.Headers, map[string][]string{"Etag": {`"1"`}}) {
+		t.Errorf("completed key = %+v, want the stored response", got)
+	}
+
+	// 削除した予約には応答を保存できない
+	if err := repo.Delete(otherCreated.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.Get("auth0|user2", "order-1"); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+	if err := repo.Complete(otherCreated); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
@@ -2,12 +2,14 @@ package rdb
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/take73/invoice-api-example/internal/domain/model"
 	"github.com/take73/invoice-api-example/internal/domain/repository"
 	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/entity"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 	"github.com/take73/invoice-api-example/internal/shared/validation"
 	"gorm.io/gorm"
 )
@@ -26,18 +28,33 @@ func (r *InvoiceRepository) Create(invoice *model.Invoice) (*model.Invoice, erro
 
 	// トランザクションの開始（複数のリポジトリをまたぐ管理をしたい場合は、ドメインサービスを作り、そこでトランザクションを管理する）
 	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 取引先が請求元企業に属していることをDB上でも保証する
+		var clientCount int64
+		if err := tx.Model(&entity.Client{}).
+			Where("client_id = ? AND organization_id = ?", invoice.Client.ID, invoice.Organization.ID).
+			Count(&clientCount).Error; err != nil {
+			return err
+		}
+		if clientCount == 0 {
+			return model.ErrClientNotInOrganization
+		}
+
 		entity := entity.Invoice{
-			OrganizationID: invoice.Organization.ID,
-			ClientID:       invoice.Client.ID,
-			IssueDate:      invoice.IssueDate,
-			PaymentAmount:  invoice.Amount,
-			Fee:            invoice.Fee,
-			FeeRate:        decimal.NewFromFloat(invoice.FeeRate),
-			Tax:            invoice.Tax,
-			TaxRate:        decimal.NewFromFloat(invoice.TaxRate),
-			TotalAmount:    invoice.TotalAmount,
-			DueDate:        invoice.DueDate,
-			Status:         string(invoice.Status),
+			OrganizationID:  invoice.Organization.ID,
+			ClientID:        invoice.Client.ID,
+			IssueDate:       invoice.IssueDate,
+			PaymentAmount:   invoice.Amount,
+			Fee:             invoice.Fee,
+			FeeRate:         decimal.NewFromFloat(invoice.FeeRate),
+			FeePlanID:       nullableID(invoice.FeePlanID),
+			SettingsVersion: nullableID(invoice.SettingsVersion),
+			Tax:             invoice.Tax,
+			TaxRate:         decimal.NewFromFloat(invoice.TaxRate),
+			TotalAmount:     invoice.TotalAmount,
+			WithholdingTax:  invoice.WithholdingTax,
+			DueDate:         invoice.DueDate,
+			Status:          string(invoice.Status),
+			Version:         invoice.Version,
 		}
 
 		// データベースに登録
@@ -45,6 +62,12 @@ func (r *InvoiceRepository) Create(invoice *model.Invoice) (*model.Invoice, erro
 			return err
 		}
 
+		// 明細行を登録
+		lineItems, err := createLineItems(tx, entity.ID, invoice.LineItems)
+		if err != nil {
+			return err
+		}
+
 		// 税率・手数料率の妥当性を検証
 		taxRate, _ := entity.TaxRate.Float64()
 		if !validation.ValidRate(taxRate) {
@@ -66,15 +89,20 @@ func (r *InvoiceRepository) Create(invoice *model.Invoice) (*model.Invoice, erro
 				ID:   entity.ClientID,
 				Name: invoice.Client.Name,
 			},
-			IssueDate:   entity.IssueDate,
-			Amount:      entity.PaymentAmount,
-			Fee:         entity.Fee,
-			FeeRate:     feeRate,
-			Tax:         entity.Tax,
-			TaxRate:     taxRate,
-			TotalAmount: entity.TotalAmount,
-			DueDate:     entity.DueDate,
-			Status:      model.InvoiceStatus(entity.Status),
+			IssueDate:       entity.IssueDate,
+			Amount:          entity.PaymentAmount,
+			Fee:             entity.Fee,
+			FeeRate:         feeRate,
+			FeePlanID:       uintValue(entity.FeePlanID),
+			SettingsVersion: uintValue(entity.SettingsVersion),
+			Tax:             entity.Tax,
+			TaxRate:         taxRate,
+			TotalAmount:     entity.TotalAmount,
+			WithholdingTax:  entity.WithholdingTax,
+			DueDate:         entity.DueDate,
+			Status:          model.InvoiceStatus(entity.Status),
+			LineItems:       lineItems,
+			Version:         entity.Version,
 		}
 
 		return nil
@@ -87,54 +115,597 @@ func (r *InvoiceRepository) Create(invoice *model.Invoice) (*model.Invoice, erro
 	return createdInvoice, nil
 }
 
-func (r *InvoiceRepository) FindByDueDateRange(startDate, endDate time.Time) ([]*model.Invoice, error) {
-	type listInvoiceItem struct {
-		entity.Invoice
-		OrganizationName string `gorm:"column:organization_name"`
-		ClientName       string `gorm:"column:client_name"`
+// createLineItems 請求書の明細行を登録する
+func createLineItems(tx *gorm.DB, invoiceID uint, items []*model.InvoiceLineItem) ([]*model.InvoiceLineItem, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	entities := make([]entity.InvoiceLineItem, len(items))
+	for i, item := range items {
+		entities[i] = entity.InvoiceLineItem{
+			InvoiceID:   invoiceID,
+			LineNumber:  item.LineNumber,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+			TaxCategory: string(item.TaxCategory),
+			TaxRate:     decimal.NewFromFloat(item.TaxRate),
+		}
+	}
+	if err := tx.Create(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to create line items of invoice with ID %d: %w", invoiceID, err)
 	}
 
-	var entities []listInvoiceItem
+	created := make([]*model.InvoiceLineItem, len(entities))
+	for i := range entities {
+		created[i] = toInvoiceLineItemModel(&entities[i])
+	}
+	return created, nil
+}
+
+// findLineItems 請求書ごとの明細行を行番号の昇順で取得する
+func (r *InvoiceRepository) findLineItems(invoiceIDs []uint) (map[uint][]*model.InvoiceLineItem, error) {
+	var entities []entity.InvoiceLineItem
+	if err := r.db.Where("invoice_id IN ?", invoiceIDs).
+		Order("invoice_id asc, line_number asc").
+		Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve line items for invoice IDs %v: %w", invoiceIDs, err)
+	}
+
+	items := make(map[uint][]*model.InvoiceLineItem, len(invoiceIDs))
+	for i := range entities {
+		items[entities[i].InvoiceID] = append(items[entities[i].InvoiceID], toInvoiceLineItemModel(&entities[i]))
+	}
+	return items, nil
+}
+
+// toInvoiceLineItemModel 明細行をドメインモデルに変換
+func toInvoiceLineItemModel(e *entity.InvoiceLineItem) *model.InvoiceLineItem {
+	taxRate, _ := e.TaxRate.Float64()
+	return &model.InvoiceLineItem{
+		ID:          e.ID,
+		LineNumber:  e.LineNumber,
+		Description: e.Description,
+		Quantity:    e.Quantity,
+		UnitPrice:   e.UnitPrice,
+		Amount:      e.Amount,
+		TaxCategory: model.TaxCategory(e.TaxCategory),
+		TaxRate:     taxRate,
+	}
+}
+
+// invoiceRow 請求元企業名・取引先名をjoinした請求書の検索結果
+type invoiceRow struct {
+	entity.Invoice
+	OrganizationName string `gorm:"column:organization_name"`
+	ClientName       string `gorm:"column:client_name"`
+}
 
-	err := r.db.Table("invoice").
+// invoiceQuery 請求書に請求元企業名・取引先名をjoinしたクエリを返す
+func (r *InvoiceRepository) invoiceQuery() *gorm.DB {
+	return r.db.Table("invoice").
 		Select("invoice.*, organization.name AS organization_name, client.name AS client_name").
 		Joins("JOIN organization ON invoice.organization_id = organization.organization_id").
-		Joins("JOIN client ON invoice.client_id = client.client_id").
-		Where("due_date >= ? AND due_date <= ?", startDate, endDate).
-		Order("due_date asc").
-		Find(&entities).Error
+		Joins("JOIN client ON invoice.client_id = client.client_id")
+}
+
+// toModel ドメインモデルに変換
+func (e *invoiceRow) toModel() *model.Invoice {
+	taxRate, _ := e.TaxRate.Float64()
+	feeRate, _ := e.FeeRate.Float64()
+
+	return &model.Invoice{
+		ID: e.ID,
+		Organization: &model.Organization{
+			ID:   e.OrganizationID,
+			Name: e.OrganizationName,
+		},
+		Client: &model.Client{
+			ID:   e.ClientID,
+			Name: e.ClientName,
+		},
+		IssueDate:       e.IssueDate,
+		Amount:          e.PaymentAmount,
+		Fee:             e.Fee,
+		FeeRate:         feeRate,
+		FeePlanID:       uintValue(e.FeePlanID),
+		SettingsVersion: uintValue(e.SettingsVersion),
+		Tax:             e.Tax,
+		TaxRate:         taxRate,
+		TotalAmount:     e.TotalAmount,
+		WithholdingTax:  e.WithholdingTax,
+		DueDate:         e.DueDate,
+		Status:          model.InvoiceStatus(e.Status),
+		Version:         e.Version,
+	}
+}
+
+// FindByID 請求書をIDで取得する.
+// 請求元企業・請求先取引先の詳細と、取引先の振込先口座もあわせて取得する
+func (r *InvoiceRepository) FindByID(organizationID, id uint) (*model.Invoice, error) {
+	var e entity.Invoice
+	if err := r.db.Preload("Organization").Preload("Client").
+		Where("invoice_id = ? AND organization_id = ?", id, organizationID).
+		First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve invoice with ID %d: %w", id, err)
+	}
+
+	accounts, err := r.findBankAccounts([]uint{e.ClientID})
+	if err != nil {
+		return nil, err
+	}
+
+	lineItems, err := r.findLineItems([]uint{e.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := (&invoiceRow{Invoice: e}).toModel()
+	invoice.Organization = toOrganizationModel(&e.Organization)
+	invoice.Client = toClientModel(&e.Client)
+	invoice.Client.BankAccount = accounts[e.ClientID]
+	invoice.LineItems = lineItems[e.ID]
+
+	return invoice, nil
+}
+
+// FindByIDs 複数の請求書を請求書IDの昇順で取得する. 取引先の振込先口座もあわせて取得する
+func (r *InvoiceRepository) FindByIDs(organizationID uint, ids []uint) ([]*model.Invoice, error) {
+	var rows []invoiceRow
+	if err := r.invoiceQuery().
+		Where("invoice.organization_id = ? AND invoice.invoice_id IN ?", organizationID, ids).
+		Order("invoice.invoice_id asc").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve invoices with IDs %v: %w", ids, err)
+	}
+
+	return r.toModelsWithBankAccounts(rows)
+}
+
+// FindByStatusAndDueDate 指定したステータスで支払期日が期間内の請求書を、支払期日・請求書IDの昇順で取得する
+func (r *InvoiceRepository) FindByStatusAndDueDate(organizationID uint, status model.InvoiceStatus, dueDateFrom, dueDateTo time.Time) ([]*model.Invoice, error) {
+	var rows []invoiceRow
+	if err := r.invoiceQuery().
+		Where("invoice.organization_id = ? AND invoice.status = ?", organizationID, string(status)).
+		Where("invoice.due_date BETWEEN ? AND ?", dueDateFrom, dueDateTo).
+		Order("invoice.due_date asc, invoice.invoice_id asc").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve %s invoices due between %s and %s: %w",
+			status, dueDateFrom.Format("2006-01-02"), dueDateTo.Format("2006-01-02"), err)
+	}
+
+	return r.toModelsWithBankAccounts(rows)
+}
+
+// FindByClientAndAmount 取引先と支払金額が一致し、発行日が期間内の請求書を請求書IDの昇順で取得する
+func (r *InvoiceRepository) FindByClientAndAmount(organizationID, clientID uint, amount decimal.Decimal, issueDateFrom, issueDateTo time.Time) ([]*model.Invoice, error) {
+	var rows []invoiceRow
+	if err := r.invoiceQuery().
+		Where("invoice.organization_id = ? AND invoice.client_id = ? AND invoice.payment_amount = ?", organizationID, clientID, amount).
+		Where("invoice.issue_date BETWEEN ? AND ?", issueDateFrom, issueDateTo).
+		Order("invoice.invoice_id asc").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve invoices of client %d with amount %s: %w", clientID, amount, err)
+	}
+
+	invoices := make([]*model.Invoice, len(rows))
+	for i := range rows {
+		invoices[i] = rows[i].toModel()
+	}
+	return invoices, nil
+}
 
+// FindSharingClientAndAmount 取引先と支払金額が同じ請求書が期間内に2件以上ある請求書を、取引先・支払金額・発行日の順で取得する
+func (r *InvoiceRepository) FindSharingClientAndAmount(organizationID uint, issueDateFrom, issueDateTo time.Time) ([]*model.Invoice, error) {
+	withinPeriod := func(query *gorm.DB, table string) *gorm.DB {
+		query = query.Where(table+".organization_id = ?", organizationID)
+		if !issueDateFrom.IsZero() {
+			query = query.Where(table+".issue_date >= ?", issueDateFrom)
+		}
+		if !issueDateTo.IsZero() {
+			query = query.Where(table+".issue_date <= ?", issueDateTo)
+		}
+		return query
+	}
+
+	groups := withinPeriod(r.db.Table("invoice AS grouped"), "grouped").
+		Select("grouped.client_id, grouped.payment_amount").
+		Group("grouped.client_id, grouped.payment_amount").
+		Having("COUNT(*) > 1")
+
+	var rows []invoiceRow
+	if err := withinPeriod(r.invoiceQuery(), "invoice").
+		Where("(invoice.client_id, invoice.payment_amount) IN (?)", groups).
+		Order("invoice.client_id asc, invoice.payment_amount asc, invoice.issue_date asc, invoice.invoice_id asc").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve invoices sharing client and amount: %w", err)
+	}
+
+	invoices := make([]*model.Invoice, len(rows))
+	for i := range rows {
+		invoices[i] = rows[i].toModel()
+	}
+	return invoices, nil
+}
+
+// toModelsWithBankAccounts 検索結果をドメインモデルに変換し、取引先の振込先口座を設定する
+func (r *InvoiceRepository) toModelsWithBankAccounts(rows []invoiceRow) ([]*model.Invoice, error) {
+	if len(rows) == 0 {
+		return []*model.Invoice{}, nil
+	}
+
+	clientIDs := make([]uint, len(rows))
+	for i := range rows {
+		clientIDs[i] = rows[i].ClientID
+	}
+	accounts, err := r.findBankAccounts(clientIDs)
 	if err != nil {
 		return nil, err
 	}
 
+	invoices := make([]*model.Invoice, len(rows))
+	for i := range rows {
+		invoices[i] = rows[i].toModel()
+		invoices[i].Client.BankAccount = accounts[rows[i].ClientID]
+	}
+
+	return invoices, nil
+}
+
+// findBankAccounts 取引先ごとの振込先口座を取得する（既定の口座. 既定がない場合は最初に登録されたもの）
+func (r *InvoiceRepository) findBankAccounts(clientIDs []uint) (map[uint]*model.ClientBankAccount, error) {
+	var entities []entity.ClientBankAccount
+	if err := r.db.Where("client_id IN ?", clientIDs).
+		Order("is_default desc, account_id asc").
+		Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve bank accounts for client IDs %v: %w", clientIDs, err)
+	}
+
+	accounts := make(map[uint]*model.ClientBankAccount, len(clientIDs))
+	for i := range entities {
+		if _, ok := accounts[entities[i].ClientID]; !ok {
+			accounts[entities[i].ClientID] = toClientBankAccountModel(&entities[i])
+		}
+	}
+	return accounts, nil
+}
+
+// invoiceSortColumns 並び替えキーに対応するカラム
+var invoiceSortColumns = map[repository.InvoiceSortKey]string{
+	repository.InvoiceSortByDueDate:     "invoice.due_date",
+	repository.InvoiceSortByIssueDate:   "invoice.issue_date",
+	repository.InvoiceSortByTotalAmount: "invoice.total_amount",
+	repository.InvoiceSortByCreatedAt:   "invoice.created_at",
+}
+
+const sortValueDateFormat = "2006-01-02"
+
+// Search 条件に一致する請求書をキーセットページネーションで取得する.
+// 並び替えキーが同値の行は請求書IDで順序を確定させる
+func (r *InvoiceRepository) Search(condition repository.InvoiceSearchCondition) (*repository.InvoicePage, error) {
+	column, ok := invoiceSortColumns[condition.SortKey]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort key: %s", condition.SortKey)
+	}
+	if condition.Limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", condition.Limit)
+	}
+	direction, operator := "ASC", ">"
+	if condition.Descending {
+		direction, operator = "DESC", "<"
+	}
+
+	query := r.invoiceQuery().Where("invoice.organization_id = ?", condition.OrganizationID)
+	if !condition.DueDateFrom.IsZero() {
+		query = query.Where("invoice.due_date >= ?", condition.DueDateFrom)
+	}
+	if !condition.DueDateTo.IsZero() {
+		query = query.Where("invoice.due_date <= ?", condition.DueDateTo)
+	}
+	if !condition.IssueDateFrom.IsZero() {
+		query = query.Where("invoice.issue_date >= ?", condition.IssueDateFrom)
+	}
+	if !condition.IssueDateTo.IsZero() {
+		query = query.Where("invoice.issue_date <= ?", condition.IssueDateTo)
+	}
+	if len(condition.Statuses) > 0 {
+		statuses := make([]string, len(condition.Statuses))
+		for i, status := range condition.Statuses {
+			statuses[i] = string(status)
+		}
+		query = query.Where("invoice.status IN ?", statuses)
+	}
+	if condition.ClientID != 0 {
+		query = query.Where("invoice.client_id = ?", condition.ClientID)
+	}
+	if condition.MinAmount != nil {
+		query = query.Where("invoice.payment_amount >= ?", *condition.MinAmount)
+	}
+	if condition.MaxAmount != nil {
+		query = query.Where("invoice.payment_amount <= ?", *condition.MaxAmount)
+	}
+	if condition.After != nil {
+		value, err := parseSortValue(condition.SortKey, condition.After.SortValue)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(
+			fmt.Sprintf("((%s %s ?) OR (%s = ? AND invoice.invoice_id %s ?))", column, operator, column, operator),
+			value, value, condition.After.ID,
+		)
+	}
+
+	// 次のページの有無を判定するため1件多く取得する
+	var rows []invoiceRow
+	if err := query.
+		Order(fmt.Sprintf("%s %s, invoice.invoice_id %s", column, direction, direction)).
+		Limit(condition.Limit + 1).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to search invoices: %w", err)
+	}
+
+	page := &repository.InvoicePage{}
+	if len(rows) > condition.Limit {
+		rows = rows[:condition.Limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = &repository.InvoiceCursor{
+			SortValue: formatSortValue(condition.SortKey, &last),
+			ID:        last.ID,
+		}
+	}
+
 	// ドメインモデルに変換
-	invoices := make([]*model.Invoice, len(entities))
-	for i, e := range entities {
-		taxRate, _ := e.TaxRate.Float64()
-		feeRate, _ := e.FeeRate.Float64()
+	page.Invoices = make([]*model.Invoice, len(rows))
+	if len(rows) == 0 {
+		return page, nil
+	}
+	invoiceIDs := make([]uint, len(rows))
+	for i := range rows {
+		invoiceIDs[i] = rows[i].ID
+	}
+	lineItems, err := r.findLineItems(invoiceIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		page.Invoices[i] = rows[i].toModel()
+		page.Invoices[i].LineItems = lineItems[rows[i].ID]
+	}
 
-		invoices[i] = &model.Invoice{
-			ID: e.ID,
-			Organization: &model.Organization{
-				ID:   e.OrganizationID,
-				Name: e.OrganizationName,
-			},
-			Client: &model.Client{
-				ID:   e.ClientID,
-				Name: e.ClientName,
-			},
-			IssueDate:   e.IssueDate,
-			Amount:      e.PaymentAmount,
-			Fee:         e.Fee,
-			FeeRate:     feeRate,
-			Tax:         e.Tax,
-			TaxRate:     taxRate,
-			TotalAmount: e.TotalAmount,
-			DueDate:     e.DueDate,
-			Status:      model.InvoiceStatus(e.Status),
+	return page, nil
+}
+
+// formatSortValue カーソルに保持する並び替えキーの値を文字列にする
+func formatSortValue(key repository.InvoiceSortKey, row *invoiceRow) string {
+	switch key {
+	case repository.InvoiceSortByIssueDate:
+		return row.IssueDate.Format(sortValueDateFormat)
+	case repository.InvoiceSortByTotalAmount:
+		return row.TotalAmount.String()
+	case repository.InvoiceSortByCreatedAt:
+		return row.CreatedAt.UTC().Format(time.RFC3339)
+	default:
+		return row.DueDate.Format(sortValueDateFormat)
+	}
+}
+
+// parseSortValue カーソルに保持した並び替えキーの値をクエリのパラメータに変換する
+func parseSortValue(key repository.InvoiceSortKey, value string) (interface{}, error) {
+	var (
+		parsed interface{}
+		err    error
+	)
+	switch key {
+	case repository.InvoiceSortByIssueDate, repository.InvoiceSortByDueDate:
+		parsed, err = time.Parse(sortValueDateFormat, value)
+	case repository.InvoiceSortByTotalAmount:
+		parsed, err = decimal.NewFromString(value)
+	case repository.InvoiceSortByCreatedAt:
+		parsed, err = time.Parse(time.RFC3339, value)
+	default:
+		err = fmt.Errorf("invalid sort key: %s", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor value %q: %w", value, err)
+	}
+	return parsed, nil
+}
+
+// UpdateStatus 請求書のステータスを更新して版数を1増やし、変更履歴を記録する.
+// 更新対象は版数が version で変更前ステータスのままの請求書に限定し、並行して更新された場合は ErrConflict を返す
+func (r *InvoiceRepository) UpdateStatus(organizationID, version uint, history *model.InvoiceStatusHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return updateStatus(tx, organizationID, version, history)
+	})
+}
+
+// RecordPayment 支払結果を記録し、ステータスの更新・変更履歴の記録を同一トランザクションで行う
+func (r *InvoiceRepository) RecordPayment(organizationID, version uint, history *model.InvoiceStatusHistory, payment *model.InvoicePayment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateStatus(tx, organizationID, version, history); err != nil {
+			return err
 		}
+		return createPayment(tx, payment)
+	})
+}
+
+// CreatePayment 支払結果だけを記録する. 請求書の版数・ステータスは確認しない.
+// 同じ試行の支払結果が記録済みの場合は ErrConflict を返す
+func (r *InvoiceRepository) CreatePayment(payment *model.InvoicePayment) error {
+	return createPayment(r.db, payment)
+}
+
+func createPayment(tx *gorm.DB, payment *model.InvoicePayment) error {
+	paymentEntity := entity.InvoicePayment{
+		InvoiceID:     payment.InvoiceID,
+		AttemptID:     nullableID(payment.AttemptID),
+		Amount:        payment.Amount,
+		Succeeded:     payment.Succeeded,
+		TransactionID: payment.TransactionID,
+		Response:      payment.Response,
+	}
+	if err := tx.Create(&paymentEntity).Error; err != nil {
+		if isDuplicateEntry(err) {
+			return commonErrors.ErrConflict
+		}
+		return fmt.Errorf("failed to record payment of invoice with ID %d: %w", payment.InvoiceID, err)
+	}
+
+	payment.ID = paymentEntity.ID
+	payment.ProcessedAt = paymentEntity.ProcessedAt
+	return nil
+}
+
+// updateStatus 版数が version で変更前ステータスのままの請求書に限定してステータスを更新し、変更履歴を記録する
+func updateStatus(tx *gorm.DB, organizationID, version uint, history *model.InvoiceStatusHistory) error {
+	result := tx.Model(&entity.Invoice{}).
+		Where("invoice_id = ? AND organization_id = ?", history.InvoiceID, organizationID).
+		Where("status = ? AND version = ?", string(history.FromStatus), version).
+		Updates(map[string]interface{}{
+			"status":  string(history.ToStatus),
+			"version": gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update status of invoice with ID %d: %w", history.InvoiceID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return commonErrors.ErrConflict
+	}
+
+	historyEntity := entity.InvoiceStatusHistory{
+		InvoiceID:  history.InvoiceID,
+		FromStatus: string(history.FromStatus),
+		ToStatus:   string(history.ToStatus),
+		ChangedBy:  history.ChangedBy,
+	}
+	if err := tx.Create(&historyEntity).Error; err != nil {
+		return fmt.Errorf("failed to record status history of invoice with ID %d: %w", history.InvoiceID, err)
+	}
+
+	history.ID = historyEntity.ID
+	history.ChangedAt = historyEntity.ChangedAt
+	return nil
+}
+
+// FindPendingDueBy 支払方法が支払ゲートウェイの組織の、支払期日が dueBy 以前の未処理の請求書を、支払期日の早い順に組織横断で取得する
+func (r *InvoiceRepository) FindPendingDueBy(dueBy time.Time, limit int) ([]*model.Invoice, error) {
+	var rows []invoiceRow
+	if err := r.invoiceQuery().
+		Where("organization.payment_method = ?", string(model.PaymentMethodGateway)).
+		Where("invoice.status = ? AND invoice.due_date <= ?", string(model.StatusPending), dueBy).
+		Order("invoice.due_date asc, invoice.invoice_id asc").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve pending invoices due by %s: %w", dueBy.Format("2006-01-02"), err)
+	}
+
+	return r.toModelsWithBankAccounts(rows)
+}
+
+// stalledPaymentAttemptRow 処理中にした変更履歴をjoinした請求書の検索結果
+type stalledPaymentAttemptRow struct {
+	invoiceRow
+	AttemptID uint `gorm:"column:attempt_id"`
+}
+
+// FindStalledPaymentAttempts 最新の変更履歴が changedBy による処理中への変更で、startedBefore より前に変更された処理中の請求書を、
+// 変更の古い順に組織横断で取得する. 試行IDが一致する支払結果が記録済みであればあわせて取得する
+func (r *InvoiceRepository) FindStalledPaymentAttempts(changedBy string, startedBefore time.Time, limit int) ([]*model.PaymentAttempt, error) {
+	var rows []stalledPaymentAttemptRow
+	if err := r.invoiceQuery().
+		Select("invoice.*, organization.name AS organization_name, client.name AS client_name, history.invoice_status_history_id AS attempt_id").
+		Joins("JOIN invoice_status_history history ON history.invoice_id = invoice.invoice_id").
+		Where("history.invoice_status_history_id = (?)",
+			r.db.Table("invoice_status_history latest").
+				Select("MAX(latest.invoice_status_history_id)").
+				Where("latest.invoice_id = invoice.invoice_id")).
+		Where("invoice.status = ? AND history.to_status = ?", string(model.StatusProcessing), string(model.StatusProcessing)).
+		Where("history.changed_by = ? AND history.changed_at < ?", changedBy, startedBefore).
+		Order("history.changed_at asc, invoice.invoice_id asc").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve stalled payment attempts: %w", err)
+	}
+	if len(rows) == 0 {
+		return []*model.PaymentAttempt{}, nil
+	}
+
+	invoiceRows := make([]invoiceRow, len(rows))
+	attemptIDs := make([]uint, len(rows))
+	for i := range rows {
+		invoiceRows[i] = rows[i].invoiceRow
+		attemptIDs[i] = rows[i].AttemptID
+	}
+	invoices, err := r.toModelsWithBankAccounts(invoiceRows)
+	if err != nil {
+		return nil, err
+	}
+
+	var paymentEntities []entity.InvoicePayment
+	if err := r.db.Where("attempt_id IN ?", attemptIDs).Find(&paymentEntities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve payments of stalled payment attempts: %w", err)
+	}
+	payments := make(map[uint]*model.InvoicePayment, len(paymentEntities))
+	for i := range paymentEntities {
+		payments[uintValue(paymentEntities[i].AttemptID)] = toInvoicePaymentModel(&paymentEntities[i])
+	}
+
+	attempts := make([]*model.PaymentAttempt, len(rows))
+	for i := range rows {
+		attempts[i] = &model.PaymentAttempt{
+			ID:      rows[i].AttemptID,
+			Invoice: invoices[i],
+			Payment: payments[rows[i].AttemptID],
+		}
+	}
+	return attempts, nil
+}
+
+// toInvoicePaymentModel ドメインモデルに変換
+func toInvoicePaymentModel(e *entity.InvoicePayment) *model.InvoicePayment {
+	return &model.InvoicePayment{
+		ID:            e.ID,
+		InvoiceID:     e.InvoiceID,
+		AttemptID:     uintValue(e.AttemptID),
+		Amount:        e.Amount,
+		Succeeded:     e.Succeeded,
+		TransactionID: e.TransactionID,
+		Response:      e.Response,
+		ProcessedAt:   e.ProcessedAt,
+	}
+}
+
+// FindUnpaidIssuedFrom 発行日が issueDateFrom 以降の支払済みでない請求書を、発行日・請求書IDの昇順で明細とあわせて取得する
+func (r *InvoiceRepository) FindUnpaidIssuedFrom(issueDateFrom time.Time) ([]*model.Invoice, error) {
+	var rows []invoiceRow
+	if err := r.invoiceQuery().
+		Where("invoice.status <> ? AND invoice.issue_date >= ?", string(model.StatusPaid), issueDateFrom).
+		Order("invoice.issue_date asc, invoice.invoice_id asc").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve unpaid invoices issued from %s: %w", issueDateFrom.Format("2006-01-02"), err)
+	}
+	if len(rows) == 0 {
+		return []*model.Invoice{}, nil
 	}
 
+	invoiceIDs := make([]uint, len(rows))
+	for i := range rows {
+		invoiceIDs[i] = rows[i].ID
+	}
+	lineItems, err := r.findLineItems(invoiceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]*model.Invoice, len(rows))
+	for i := range rows {
+		invoices[i] = rows[i].toModel()
+		invoices[i].LineItems = lineItems[rows[i].ID]
+	}
 	return invoices, nil
 }
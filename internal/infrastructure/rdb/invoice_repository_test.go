@@ -1,13 +1,16 @@
 package rdb
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/shopspring/decimal"
 	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
 	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 	"gorm.io/gorm/logger"
 )
 
@@ -71,6 +74,83 @@ func Test_InvoiceRepository_Create(t *testing.T) {
 				Status:      model.StatusPending,
 			},
 		},
+		{
+			name: "明細つきで登録",
+			input: input{
+				invoice: &model.Invoice{
+					Organization: &model.Organization{
+						ID:   1,
+						Name: "株式会社サンプル",
+					},
+					Client: &model.Client{
+						ID:   1,
+						Name: "取引先A",
+					},
+					IssueDate:   time.Date(2024, 04, 15, 0, 0, 0, 0, time.Local),
+					Amount:      decimal.NewFromInt(16400),
+					Fee:         decimal.NewFromInt(656),
+					FeeRate:     0.04,
+					Tax:         decimal.NewFromInt(65),
+					TaxRate:     0.1,
+					TotalAmount: decimal.NewFromInt(17121),
+					DueDate:     time.Date(2024, 04, 30, 0, 0, 0, 0, time.Local),
+					Status:      model.StatusPending,
+					LineItems: []*model.InvoiceLineItem{
+						{LineNumber: 1, Description: "コンサルティング", Quantity: decimal.NewFromInt(2), UnitPrice: decimal.NewFromInt(5000), Amount: decimal.NewFromInt(10000), TaxCategory: model.TaxCategoryStandard, TaxRate: 0.1},
+						{LineNumber: 2, Description: "お弁当", Quantity: decimal.NewFromInt(10), UnitPrice: decimal.NewFromInt(500), Amount: decimal.NewFromInt(5000), TaxCategory: model.TaxCategoryReduced, TaxRate: 0.08},
+					},
+				},
+			},
+			want: &model.Invoice{
+				ID: 2,
+				Organization: &model.Organization{
+					ID:   1,
+					Name: "株式会社サンプル",
+				},
+				Client: &model.Client{
+					ID:   1,
+					Name: "取引先A",
+				},
+				IssueDate:   time.Date(2024, 04, 15, 0, 0, 0, 0, time.Local),
+				Amount:      decimal.NewFromInt(16400),
+				Fee:         decimal.NewFromInt(656),
+				FeeRate:     0.04,
+				Tax:         decimal.NewFromInt(65),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(17121),
+				DueDate:     time.Date(2024, 04, 30, 0, 0, 0, 0, time.Local),
+				Status:      model.StatusPending,
+				LineItems: []*model.InvoiceLineItem{
+					{ID: 1, LineNumber: 1, Description: "コンサルティング", Quantity: decimal.NewFromInt(2), UnitPrice: decimal.NewFromInt(5000), Amount: decimal.NewFromInt(10000), TaxCategory: model.TaxCategoryStandard, TaxRate: 0.1},
+					{ID: 2, LineNumber: 2, Description: "お弁当", Quantity: decimal.NewFromInt(10), UnitPrice: decimal.NewFromInt(500), Amount: decimal.NewFromInt(5000), TaxCategory: model.TaxCategoryReduced, TaxRate: 0.08},
+				},
+			},
+		},
+		{
+			name: "他組織の取引先は登録できない",
+			input: input{
+				invoice: &model.Invoice{
+					Organization: &model.Organization{
+						ID:   1,
+						Name: "株式会社サンプル",
+					},
+					Client: &model.Client{
+						ID:   3,
+						Name: "取引先C",
+					},
+					IssueDate:   time.Date(2018, 04, 15, 0, 0, 0, 0, time.Local),
+					Amount:      decimal.NewFromInt(10000),
+					Fee:         decimal.NewFromInt(400),
+					FeeRate:     0.04,
+					Tax:         decimal.NewFromInt(40),
+					TaxRate:     0.1,
+					TotalAmount: decimal.NewFromInt(10440),
+					DueDate:     time.Date(2018, 04, 30, 0, 0, 0, 0, time.Local),
+					Status:      model.StatusPending,
+				},
+			},
+			wantErr: model.ErrClientNotInOrganization,
+		},
 	}
 
 	for _, tt := range tests {
@@ -93,81 +173,187 @@ func Test_InvoiceRepository_Create(t *testing.T) {
 	}
 }
 
-func Test_InvoiceRepository_FindByDueDateRange(t *testing.T) {
+func Test_InvoiceRepository_Search(t *testing.T) {
 	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
 	defer cleanup()
 	db.Logger = db.Logger.LogMode(logger.Info)
 	// テストデータの挿入
-	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_find_by_due_date_range.sql")
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+
+	invoice1 := &model.Invoice{
+		ID:           1,
+		Organization: &model.Organization{ID: 1, Name: "株式会社サンプル"},
+		Client:       &model.Client{ID: 1, Name: "取引先A"},
+		IssueDate:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Amount:       decimal.NewFromInt(10000),
+		Fee:          decimal.NewFromInt(400),
+		FeeRate:      0.04,
+		Tax:          decimal.NewFromInt(40),
+		TaxRate:      0.1,
+		TotalAmount:  decimal.NewFromInt(10440),
+		DueDate:      time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		Status:       model.StatusPending,
+	}
+	invoice2 := &model.Invoice{
+		ID:           2,
+		Organization: &model.Organization{ID: 1, Name: "株式会社サンプル"},
+		Client:       &model.Client{ID: 2, Name: "取引先B"},
+		IssueDate:    time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		Amount:       decimal.NewFromInt(20000),
+		Fee:          decimal.NewFromInt(800),
+		FeeRate:      0.04,
+		Tax:          decimal.NewFromInt(80),
+		TaxRate:      0.1,
+		TotalAmount:  decimal.NewFromInt(20880),
+		DueDate:      time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		Status:       model.StatusProcessing,
+	}
+	invoice3 := &model.Invoice{
+		ID:           3,
+		Organization: &model.Organization{ID: 2, Name: "有限会社テスト"},
+		Client:       &model.Client{ID: 3, Name: "取引先C"},
+		IssueDate:    time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		Amount:       decimal.NewFromInt(30000),
+		Fee:          decimal.NewFromInt(1200),
+		FeeRate:      0.04,
+		Tax:          decimal.NewFromInt(120),
+		TaxRate:      0.1,
+		TotalAmount:  decimal.NewFromInt(31320),
+		DueDate:      time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+		Status:       model.StatusPaid,
+	}
+	invoice4 := &model.Invoice{
+		ID:           4,
+		Organization: &model.Organization{ID: 2, Name: "有限会社テスト"},
+		Client:       &model.Client{ID: 1, Name: "取引先A"},
+		IssueDate:    time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		Amount:       decimal.NewFromInt(40000),
+		Fee:          decimal.NewFromInt(1600),
+		FeeRate:      0.04,
+		Tax:          decimal.NewFromInt(160),
+		TaxRate:      0.1,
+		TotalAmount:  decimal.NewFromInt(41760),
+		DueDate:      time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Status:       model.StatusError,
+	}
+	minAmount := decimal.NewFromInt(15000)
 
 	type input struct {
-		startDate time.Time
-		endDate   time.Time
+		condition repository.InvoiceSearchCondition
 	}
 
 	tests := []struct {
 		name    string
 		input   input
-		want    []*model.Invoice
+		want    *repository.InvoicePage
 		wantErr error
 	}{
 		{
-			name: "2件取得",
-			input: input{
-				startDate: time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC),
-				endDate:   time.Date(2024, 2, 2, 23, 59, 59, 0, time.UTC),
-			},
-			want: []*model.Invoice{
-				{
-					ID:           2,
-					Organization: &model.Organization{ID: 1, Name: "株式会社サンプル"},
-					Client:       &model.Client{ID: 2, Name: "取引先B"},
-					IssueDate:    time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
-					Amount:       decimal.NewFromInt(20000),
-					Fee:          decimal.NewFromInt(800),
-					FeeRate:      0.04,
-					Tax:          decimal.NewFromInt(80),
-					TaxRate:      0.1,
-					TotalAmount:  decimal.NewFromInt(20880),
-					DueDate:      time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
-					Status:       model.StatusProcessing,
-				},
-				{
-					ID:           3,
-					Organization: &model.Organization{ID: 2, Name: "有限会社テスト"},
-					Client:       &model.Client{ID: 3, Name: "取引先C"},
-					IssueDate:    time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
-					Amount:       decimal.NewFromInt(30000),
-					Fee:          decimal.NewFromInt(1200),
-					FeeRate:      0.04,
-					Tax:          decimal.NewFromInt(120),
-					TaxRate:      0.1,
-					TotalAmount:  decimal.NewFromInt(31320),
-					DueDate:      time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
-					Status:       model.StatusPaid,
-				},
-				{
-					ID:           4,
-					Organization: &model.Organization{ID: 2, Name: "有限会社テスト"},
-					Client:       &model.Client{ID: 1, Name: "取引先A"},
-					IssueDate:    time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
-					Amount:       decimal.NewFromInt(40000),
-					Fee:          decimal.NewFromInt(1600),
-					FeeRate:      0.04,
-					Tax:          decimal.NewFromInt(160),
-					TaxRate:      0.1,
-					TotalAmount:  decimal.NewFromInt(41760),
-					DueDate:      time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
-					Status:       model.StatusError,
+			name: "組織1の請求書のみ取得",
+			input: input{
+				condition: repository.InvoiceSearchCondition{
+					OrganizationID: 1,
+					DueDateFrom:    time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC),
+					DueDateTo:      time.Date(2024, 2, 2, 23, 59, 59, 0, time.UTC),
+					SortKey:        repository.InvoiceSortByDueDate,
+					Limit:          10,
+				},
+			},
+			want: &repository.InvoicePage{
+				Invoices: []*model.Invoice{invoice2},
+			},
+		},
+		{
+			name: "組織2の請求書のみ取得",
+			input: input{
+				condition: repository.InvoiceSearchCondition{
+					OrganizationID: 2,
+					DueDateFrom:    time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC),
+					DueDateTo:      time.Date(2024, 2, 2, 23, 59, 59, 0, time.UTC),
+					SortKey:        repository.InvoiceSortByDueDate,
+					Limit:          10,
 				},
 			},
+			want: &repository.InvoicePage{
+				Invoices: []*model.Invoice{invoice3, invoice4},
+			},
+		},
+		{
+			name: "件数を超える場合は次のカーソルを返す",
+			input: input{
+				condition: repository.InvoiceSearchCondition{
+					OrganizationID: 1,
+					SortKey:        repository.InvoiceSortByDueDate,
+					Limit:          1,
+				},
+			},
+			want: &repository.InvoicePage{
+				Invoices:   []*model.Invoice{invoice1},
+				NextCursor: &repository.InvoiceCursor{SortValue: "2024-01-10", ID: 1},
+			},
+		},
+		{
+			name: "カーソル以降を取得",
+			input: input{
+				condition: repository.InvoiceSearchCondition{
+					OrganizationID: 1,
+					SortKey:        repository.InvoiceSortByDueDate,
+					After:          &repository.InvoiceCursor{SortValue: "2024-01-10", ID: 1},
+					Limit:          1,
+				},
+			},
+			want: &repository.InvoicePage{
+				Invoices: []*model.Invoice{invoice2},
+			},
+		},
+		{
+			name: "合計金額の降順",
+			input: input{
+				condition: repository.InvoiceSearchCondition{
+					OrganizationID: 2,
+					SortKey:        repository.InvoiceSortByTotalAmount,
+					Descending:     true,
+					Limit:          1,
+				},
+			},
+			want: &repository.InvoicePage{
+				Invoices:   []*model.Invoice{invoice4},
+				NextCursor: &repository.InvoiceCursor{SortValue: "41760", ID: 4},
+			},
+		},
+		{
+			name: "ステータス・取引先・金額で絞り込み",
+			input: input{
+				condition: repository.InvoiceSearchCondition{
+					OrganizationID: 2,
+					Statuses:       []model.InvoiceStatus{model.StatusError, model.StatusPending},
+					ClientID:       1,
+					MinAmount:      &minAmount,
+					SortKey:        repository.InvoiceSortByIssueDate,
+					Limit:          10,
+				},
+			},
+			want: &repository.InvoicePage{
+				Invoices: []*model.Invoice{invoice4},
+			},
+		},
+		{
+			name: "未定義の並び替えキー",
+			input: input{
+				condition: repository.InvoiceSearchCondition{
+					OrganizationID: 1,
+					SortKey:        repository.InvoiceSortKey("amount"),
+					Limit:          10,
+				},
+			},
+			wantErr: errors.New("invalid sort key: amount"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := NewInvoiceRepository(db)
-			got, err := repo.FindByDueDateRange(tt.input.startDate, tt.input.endDate)
+			got, err := repo.Search(tt.input.condition)
 
 			if tt.wantErr != nil {
 				if err == nil {
@@ -183,3 +369,737 @@ func Test_InvoiceRepository_FindByDueDateRange(t *testing.T) {
 		})
 	}
 }
+
+func Test_InvoiceRepository_FindByID(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+
+	type input struct {
+		organizationID uint
+		id             uint
+	}
+
+	tests := []struct {
+		name    string
+		input   input
+		want    *model.Invoice
+		wantErr error
+	}{
+		{
+			name: "1件取得",
+			input: input{
+				organizationID: 1,
+				id:             2,
+			},
+			want: &model.Invoice{
+				ID: 2,
+				Organization: &model.Organization{
+					ID:                 1,
+					Name:               "株式会社サンプル",
+					RegistrationNumber: "T7123456789012",
+					Representative:     "山田 太郎",
+					PhoneNumber:        "03-1234-5678",
+					PostalCode:         "100-0001",
+					Address:            "東京都千代田区丸の内1-1-1",
+					RoundingPolicy:     model.RoundingTruncate,
+					PaymentMethod:      model.PaymentMethodTransferFile,
+				},
+				Client: &model.Client{
+					ID:                  2,
+					OrganizationID:      1,
+					Name:                "取引先B",
+					Representative:      "取引先担当者B",
+					PhoneNumber:         "03-1234-0002",
+					PostalCode:          "100-0020",
+					Address:             "東京都新宿区新宿2-2-2",
+					PayeeType:           model.PayeeTypeCorporation,
+					WithholdingCategory: model.WithholdingCategoryNone,
+					BankAccount: &model.ClientBankAccount{
+						ID:            2,
+						ClientID:      2,
+						BankCode:      "0005",
+						BankName:      "三菱UFJ銀行",
+						BranchCode:    "050",
+						BranchName:    "新宿支店",
+						AccountType:   model.AccountTypeOrdinary,
+						AccountNumber: "2345678",
+						AccountName:   "ﾄﾘﾋｷｻｷﾋﾞ-",
+						IsDefault:     true,
+					},
+				},
+				IssueDate:   time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+				Amount:      decimal.NewFromInt(20000),
+				Fee:         decimal.NewFromInt(800),
+				FeeRate:     0.04,
+				Tax:         decimal.NewFromInt(80),
+				TaxRate:     0.1,
+				TotalAmount: decimal.NewFromInt(20880),
+				DueDate:     time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+				Status:      model.StatusProcessing,
+			},
+		},
+		{
+			name: "存在しないID",
+			input: input{
+				organizationID: 1,
+				id:             999,
+			},
+			wantErr: commonErrors.ErrNotFound,
+		},
+		{
+			name: "他組織の請求書は取得できない",
+			input: input{
+				organizationID: 2,
+				id:             2,
+			},
+			wantErr: commonErrors.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewInvoiceRepository(db)
+			got, err := repo.FindByID(tt.input.organizationID, tt.input.id)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("api got != want (-got +want)\n%s", diff)
+				return
+			}
+		})
+	}
+}
+
+func Test_InvoiceRepository_UpdateStatus(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+
+	type input struct {
+		organizationID uint
+		version        uint
+		history        *model.InvoiceStatusHistory
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		wantStatus model.InvoiceStatus
+		wantErr    error
+	}{
+		{
+			name: "未処理から処理中に更新",
+			input: input{
+				organizationID: 1,
+				version:        1,
+				history: &model.InvoiceStatusHistory{
+					InvoiceID:  1,
+					FromStatus: model.StatusPending,
+					ToStatus:   model.StatusProcessing,
+					ChangedBy:  "auth0|user1",
+				},
+			},
+			wantStatus: model.StatusProcessing,
+		},
+		{
+			name: "版数が一致しない場合は競合",
+			input: input{
+				organizationID: 3,
+				version:        2,
+				history: &model.InvoiceStatusHistory{
+					InvoiceID:  5,
+					FromStatus: model.StatusPending,
+					ToStatus:   model.StatusProcessing,
+					ChangedBy:  "auth0|user1",
+				},
+			},
+			wantStatus: model.StatusPending,
+			wantErr:    commonErrors.ErrConflict,
+		},
+		{
+			name: "変更前ステータスが一致しない場合は競合",
+			input: input{
+				organizationID: 2,
+				version:        1,
+				history: &model.InvoiceStatusHistory{
+					InvoiceID:  3,
+					FromStatus: model.StatusProcessing,
+					ToStatus:   model.StatusPaid,
+					ChangedBy:  "auth0|user1",
+				},
+			},
+			wantStatus: model.StatusPaid,
+			wantErr:    commonErrors.ErrConflict,
+		},
+		{
+			name: "他組織の請求書は更新できない",
+			input: input{
+				organizationID: 1,
+				version:        1,
+				history: &model.InvoiceStatusHistory{
+					InvoiceID:  4,
+					FromStatus: model.StatusError,
+					ToStatus:   model.StatusPending,
+					ChangedBy:  "auth0|user1",
+				},
+			},
+			wantStatus: model.StatusError,
+			wantErr:    commonErrors.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewInvoiceRepository(db)
+			err := repo.UpdateStatus(tt.input.organizationID, tt.input.version, tt.input.history)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := repo.FindByID(tt.input.organizationID, tt.input.history.InvoiceID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if got.Version != tt.input.version+1 {
+				t.Errorf("version = %d, want %d", got.Version, tt.input.version+1)
+			}
+
+			var count int64
+			db.Table("invoice_status_history").Where("invoice_id = ?", tt.input.history.InvoiceID).Count(&count)
+			if count != 1 {
+				t.Errorf("history count = %d, want 1", count)
+			}
+		})
+	}
+}
+
+func Test_InvoiceRepository_FindPendingDueBy(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+	db.Exec("UPDATE organization SET payment_method = 'gateway' WHERE organization_id IN (1, 3)")
+
+	type input struct {
+		dueBy time.Time
+		limit int
+	}
+
+	tests := []struct {
+		name    string
+		input   input
+		wantIDs []uint
+	}{
+		{
+			name:    "組織をまたいで支払期日までの未処理の請求書を取得",
+			input:   input{dueBy: time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), limit: 10},
+			wantIDs: []uint{1, 5},
+		},
+		{
+			name:    "支払期日当日を含む",
+			input:   input{dueBy: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), limit: 10},
+			wantIDs: []uint{1},
+		},
+		{
+			name:    "件数を制限する",
+			input:   input{dueBy: time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), limit: 1},
+			wantIDs: []uint{1},
+		},
+		{
+			name:    "該当なし",
+			input:   input{dueBy: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), limit: 10},
+			wantIDs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewInvoiceRepository(db)
+			got, err := repo.FindPendingDueBy(tt.input.dueBy, tt.input.limit)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var gotIDs []uint
+			for _, invoice := range got {
+				gotIDs = append(gotIDs, invoice.ID)
+				if invoice.Status != model.StatusPending {
+					t.Errorf("status = %s, want %s", invoice.Status, model.StatusPending)
+				}
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ids mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("支払方法が振込ファイルの組織の請求書は取得しない", func(t *testing.T) {
+		db.Exec("UPDATE organization SET payment_method = 'transfer_file' WHERE organization_id = 3")
+		repo := NewInvoiceRepository(db)
+		got, err := repo.FindPendingDueBy(time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 || got[0].ID != 1 {
+			t.Errorf("unexpected invoices: %+v", got)
+		}
+	})
+}
+
+func Test_InvoiceRepository_RecordPayment(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+
+	repo := NewInvoiceRepository(db)
+	history := &model.InvoiceStatusHistory{
+		InvoiceID:  2,
+		FromStatus: model.StatusProcessing,
+		ToStatus:   model.StatusPaid,
+		ChangedBy:  "system:payment-processor",
+	}
+	payment := &model.InvoicePayment{
+		InvoiceID:     2,
+		Amount:        decimal.NewFromInt(20000),
+		Succeeded:     true,
+		TransactionID: "FAKE-00000002",
+		Response:      "accepted",
+	}
+
+	if err := repo.RecordPayment(1, 1, history, payment); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := repo.FindByID(1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Status != model.StatusPaid {
+		t.Errorf("status = %s, want %s", got.Status, model.StatusPaid)
+	}
+
+	var count int64
+	db.Table("invoice_payment").Where("invoice_id = ? AND transaction_id = ?", 2, "FAKE-00000002").Count(&count)
+	if count != 1 {
+		t.Errorf("payment count = %d, want 1", count)
+	}
+
+	// 同じ遷移をもう一度記録しようとすると競合になり、支払結果も記録されない
+	err = repo.RecordPayment(1, got.Version, history, payment)
+	if !errors.Is(err, commonErrors.ErrConflict) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrConflict)
+	}
+	db.Table("invoice_payment").Where("invoice_id = ?", 2).Count(&count)
+	if count != 1 {
+		t.Errorf("payment count = %d, want 1", count)
+	}
+}
+
+func Test_InvoiceRepository_CreatePayment(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+
+	repo := NewInvoiceRepository(db)
+	// 請求書のステータスによらず記録し、ステータスは変更しない
+	payment := &model.InvoicePayment{
+		InvoiceID:     4,
+		Amount:        decimal.NewFromInt(40000),
+		Succeeded:     true,
+		TransactionID: "FAKE-00000004",
+		Response:      "accepted",
+	}
+	if err := repo.CreatePayment(payment); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.ID == 0 {
+		t.Errorf("expected payment ID to be set")
+	}
+
+	got, err := repo.FindByID(2, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Status != model.StatusError {
+		t.Errorf("status = %s, want %s", got.Status, model.StatusError)
+	}
+
+	var count int64
+	db.Table("invoice_payment").Where("invoice_id = ? AND transaction_id = ?", 4, "FAKE-00000004").Count(&count)
+	if count != 1 {
+		t.Errorf("payment count = %d, want 1", count)
+	}
+}
+
+func Test_InvoiceRepository_FindStalledPaymentAttempts(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+
+	const processor = "system:payment-processor"
+	repo := NewInvoiceRepository(db)
+	// 請求書1は支払処理が、請求書5は操作者が処理中にする
+	toProcessing := func(organizationID, id uint, changedBy string) *model.InvoiceStatusHistory {
+		invoice, err := repo.FindByID(organizationID, id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		history := &model.InvoiceStatusHistory{
+			InvoiceID: id, FromStatus: model.StatusPending, ToStatus: model.StatusProcessing, ChangedBy: changedBy,
+		}
+		if err := repo.UpdateStatus(organizationID, invoice.Version, history); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return history
+	}
+	attempt := toProcessing(1, 1, processor)
+	toProcessing(3, 5, "auth0|operator")
+	db.Exec("UPDATE invoice_status_history SET changed_at = ?", time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC))
+
+	payment := &model.InvoicePayment{
+		InvoiceID: 1, AttemptID: attempt.ID, Amount: decimal.NewFromInt(10000), Succeeded: true, TransactionID: "FAKE-00000001", Response: "accepted",
+	}
+	if err := repo.CreatePayment(payment); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 同じ試行の支払結果は1件だけ記録する
+	duplicated := *payment
+	if err := repo.CreatePayment(&duplicated); !errors.Is(err, commonErrors.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+
+	t.Run("支払処理が処理中にしたまま止まった試行を支払結果とあわせて取得", func(t *testing.T) {
+		got, err := repo.FindStalledPaymentAttempts(processor, time.Date(2024, 1, 10, 8, 30, 0, 0, time.UTC), 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("expected 1 attempt, got %d", len(got))
+		}
+		if got[0].ID != attempt.ID || got[0].Invoice.ID != 1 || got[0].Invoice.Status != model.StatusProcessing {
+			t.Errorf("unexpected attempt: %+v", got[0])
+		}
+		if got[0].Invoice.Client.BankAccount == nil {
+			t.Errorf("expected bank account to be set")
+		}
+		if got[0].Payment == nil || got[0].Payment.ID != payment.ID || got[0].Payment.AttemptID != attempt.ID {
+			t.Errorf("unexpected payment: %+v", got[0].Payment)
+		}
+	})
+
+	t.Run("処理中にしてから間もない試行は取得しない", func(t *testing.T) {
+		got, err := repo.FindStalledPaymentAttempts(processor, time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC), 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("expected no attempts, got %d", len(got))
+		}
+	})
+}
+
+func Test_InvoiceRepository_FindByIDs(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+
+	type input struct {
+		organizationID uint
+		ids            []uint
+	}
+
+	tests := []struct {
+		name          string
+		input         input
+		wantIDs       []uint
+		wantAccountID []uint
+	}{
+		{
+			name:          "請求書IDの昇順で振込先口座とあわせて取得",
+			input:         input{organizationID: 1, ids: []uint{2, 1}},
+			wantIDs:       []uint{1, 2},
+			wantAccountID: []uint{1, 2},
+		},
+		{
+			name:          "他組織の請求書は含めない",
+			input:         input{organizationID: 1, ids: []uint{1, 3}},
+			wantIDs:       []uint{1},
+			wantAccountID: []uint{1},
+		},
+		{
+			name:    "存在しないIDのみ",
+			input:   input{organizationID: 1, ids: []uint{99}},
+			wantIDs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewInvoiceRepository(db)
+			got, err := repo.FindByIDs(tt.input.organizationID, tt.input.ids)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var gotIDs, gotAccountIDs []uint
+			for _, invoice := range got {
+				gotIDs = append(gotIDs, invoice.ID)
+				if invoice.Client.BankAccount != nil {
+					gotAccountIDs = append(gotAccountIDs, invoice.Client.BankAccount.ID)
+				}
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ids mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantAccountID, gotAccountIDs); diff != "" {
+				t.Errorf("bank account ids mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_InvoiceRepository_FindByStatusAndDueDate(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+
+	type input struct {
+		organizationID uint
+		status         model.InvoiceStatus
+		from           time.Time
+		to             time.Time
+	}
+
+	tests := []struct {
+		name    string
+		input   input
+		wantIDs []uint
+	}{
+		{
+			name: "処理中で支払期日が期間内の請求書を取得",
+			input: input{
+				organizationID: 1,
+				status:         model.StatusProcessing,
+				from:           time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC),
+				to:             time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+			wantIDs: []uint{2},
+		},
+		{
+			name: "ステータスが異なる請求書は含めない",
+			input: input{
+				organizationID: 1,
+				status:         model.StatusPending,
+				from:           time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC),
+				to:             time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+			wantIDs: nil,
+		},
+		{
+			name: "他組織の請求書は含めない",
+			input: input{
+				organizationID: 2,
+				status:         model.StatusProcessing,
+				from:           time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				to:             time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC),
+			},
+			wantIDs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewInvoiceRepository(db)
+			got, err := repo.FindByStatusAndDueDate(tt.input.organizationID, tt.input.status, tt.input.from, tt.input.to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var gotIDs []uint
+			for _, invoice := range got {
+				gotIDs = append(gotIDs, invoice.ID)
+				if invoice.Client.BankAccount == nil {
+					t.Errorf("bank account of invoice %d is nil", invoice.ID)
+				}
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ids mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_InvoiceRepository_FindUnpaidIssuedFrom(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_search.sql")
+
+	tests := []struct {
+		name          string
+		issueDateFrom time.Time
+		wantIDs       []uint
+	}{
+		{
+			name:          "組織をまたいで発行日以降の支払済みでない請求書を取得",
+			issueDateFrom: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+			wantIDs:       []uint{2, 4, 5},
+		},
+		{
+			name:          "該当なし",
+			issueDateFrom: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC),
+			wantIDs:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewInvoiceRepository(db)
+			got, err := repo.FindUnpaidIssuedFrom(tt.issueDateFrom)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var gotIDs []uint
+			for _, invoice := range got {
+				gotIDs = append(gotIDs, invoice.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ids mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_InvoiceRepository_FindByClientAndAmount(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_duplicates.sql")
+
+	tests := []struct {
+		name     string
+		clientID uint
+		amount   int64
+		from     time.Time
+		to       time.Time
+		wantIDs  []uint
+	}{
+		{
+			name:     "取引先と支払金額が一致し発行日が期間内の請求書を取得",
+			clientID: 1,
+			amount:   10000,
+			from:     time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC),
+			to:       time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+			wantIDs:  []uint{1, 2},
+		},
+		{
+			name:     "支払金額が異なる請求書は含めない",
+			clientID: 1,
+			amount:   30000,
+			from:     time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC),
+			to:       time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+			wantIDs:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewInvoiceRepository(db)
+			got, err := repo.FindByClientAndAmount(1, tt.clientID, decimal.NewFromInt(tt.amount), tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var gotIDs []uint
+			for _, invoice := range got {
+				gotIDs = append(gotIDs, invoice.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ids mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_InvoiceRepository_FindSharingClientAndAmount(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+	// テストデータの挿入
+	testutils.ExecSQLFile(db, "testdata/test_invoice_repository_duplicates.sql")
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		wantIDs []uint
+	}{
+		{
+			name:    "組織全体で取引先と支払金額が同じ請求書を取得",
+			wantIDs: []uint{1, 2, 3},
+		},
+		{
+			name:    "発行日の期間内だけで判定する",
+			from:    time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			wantIDs: []uint{2, 3},
+		},
+		{
+			name:    "期間内に同じ取引先と支払金額の請求書が1件だけの場合は含めない",
+			to:      time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			wantIDs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewInvoiceRepository(db)
+			got, err := repo.FindSharingClientAndAmount(1, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var gotIDs []uint
+			for _, invoice := range got {
+				gotIDs = append(gotIDs, invoice.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ids mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
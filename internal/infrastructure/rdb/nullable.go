@@ -0,0 +1,33 @@
+package rdb
+
+// stringValue NULL許容のカラムの値を返す. NULLの場合は空文字
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// nullableString 空文字をNULLとして文字列カラムの値を返す
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// uintValue NULL許容のIDカラムの値を返す. NULLの場合は0
+func uintValue(id *uint) uint {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// nullableID 0をNULLとしてIDカラムの値を返す
+func nullableID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/entity"
 	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrganizationRepository struct {
@@ -28,37 +29,193 @@ func (r *OrganizationRepository) GetByID(id uint) (*model.Organization, error) {
 		return nil, fmt.Errorf("failed to retrieve organization with ID %d: %w", id, err)
 	}
 
-	organization := &model.Organization{
-		ID:             entity.ID,
-		Name:           entity.Name,
-		Representative: entity.RepresentativeName,
-		PhoneNumber:    entity.PhoneNumber,
-		PostalCode:     entity.PostalCode,
-		Address:        entity.Address,
+	return toOrganizationModel(&entity), nil
+}
+
+// toOrganizationModel ドメインモデルに変換
+func toOrganizationModel(e *entity.Organization) *model.Organization {
+	return &model.Organization{
+		ID:                 e.ID,
+		Name:               e.Name,
+		RegistrationNumber: stringValue(e.RegistrationNumber),
+		Representative:     e.RepresentativeName,
+		PhoneNumber:        e.PhoneNumber,
+		PostalCode:         e.PostalCode,
+		Address:            e.Address,
+		RoundingPolicy:     model.RoundingPolicy(e.RoundingPolicy),
+		PaymentMethod:      model.PaymentMethod(e.PaymentMethod),
 	}
+}
 
-	return organization, nil
+// UpdateProfile 組織のプロフィールを更新する
+func (r *OrganizationRepository) UpdateProfile(organization *model.Organization) (*model.Organization, error) {
+	result := r.db.Model(&entity.Organization{}).
+		Where("organization_id = ?", organization.ID).
+		Updates(map[string]interface{}{
+			"name":                organization.Name,
+			"registration_number": nullableString(organization.RegistrationNumber),
+			"representative_name": organization.Representative,
+			"phone_number":        organization.PhoneNumber,
+			"postal_code":         organization.PostalCode,
+			"address":             organization.Address,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update organization with ID %d: %w", organization.ID, result.Error)
+	}
+
+	return r.GetByID(organization.ID)
+}
+
+// GetSettings 最新の設定を取得する
+func (r *OrganizationRepository) GetSettings(organizationID uint) (*model.OrganizationSettings, error) {
+	var e entity.OrganizationSettings
+	if err := r.db.Where("organization_id = ?", organizationID).
+		Order("version desc").
+		First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve settings of organization ID %d: %w", organizationID, err)
+	}
+
+	return toOrganizationSettingsModel(&e), nil
+}
+
+// GetSettingsByVersion 指定した版の設定を取得する
+func (r *OrganizationRepository) GetSettingsByVersion(organizationID, version uint) (*model.OrganizationSettings, error) {
+	var e entity.OrganizationSettings
+	if err := r.db.Where("organization_id = ? AND version = ?", organizationID, version).
+		First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve settings version %d of organization ID %d: %w", version, organizationID, err)
+	}
+
+	return toOrganizationSettingsModel(&e), nil
+}
+
+// FindSettingsVersions 設定の全ての版を版数の降順で取得する
+func (r *OrganizationRepository) FindSettingsVersions(organizationID uint) ([]*model.OrganizationSettings, error) {
+	var entities []entity.OrganizationSettings
+	if err := r.db.Where("organization_id = ?", organizationID).
+		Order("version desc").
+		Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve settings versions of organization ID %d: %w", organizationID, err)
+	}
+
+	settings := make([]*model.OrganizationSettings, len(entities))
+	for i := range entities {
+		settings[i] = toOrganizationSettingsModel(&entities[i])
+	}
+	return settings, nil
 }
 
+// CreateSettings 設定の新しい版を登録する.
+// 組織の行をロックして版数の重複を確認し、組織の端数処理・支払方法も最新の設定の値に更新する
+func (r *OrganizationRepository) CreateSettings(settings *model.OrganizationSettings) (*model.OrganizationSettings, error) {
+	e := toOrganizationSettingsEntity(settings)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var organization entity.Organization
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("organization_id = ?", settings.OrganizationID).
+			First(&organization).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return commonErrors.ErrNotFound
+			}
+			return fmt.Errorf("failed to lock organization with ID %d: %w", settings.OrganizationID, err)
+		}
+
+		var count int64
+		if err := tx.Model(&entity.OrganizationSettings{}).
+			Where("organization_id = ? AND version = ?", settings.OrganizationID, settings.Version).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return commonErrors.ErrConflict
+		}
+
+		if err := tx.Create(e).Error; err != nil {
+			return fmt.Errorf("failed to create settings version %d of organization ID %d: %w", settings.Version, settings.OrganizationID, err)
+		}
+
+		return tx.Model(&entity.Organization{}).
+			Where("organization_id = ?", settings.OrganizationID).
+			Updates(map[string]interface{}{
+				"rounding_policy": e.RoundingPolicy,
+				"payment_method":  e.PaymentMethod,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toOrganizationSettingsModel(e), nil
+}
+
+// toOrganizationSettingsModel ドメインモデルに変換
+func toOrganizationSettingsModel(e *entity.OrganizationSettings) *model.OrganizationSettings {
+	return &model.OrganizationSettings{
+		OrganizationID:   e.OrganizationID,
+		Version:          e.Version,
+		DefaultFeePlanID: uintValue(e.DefaultFeePlanID),
+		RoundingPolicy:   model.RoundingPolicy(e.RoundingPolicy),
+		PaymentTermsDays: e.PaymentTermsDays,
+		PaymentMethod:    model.PaymentMethod(e.PaymentMethod),
+		Notification: model.NotificationPreferences{
+			Email:          stringValue(e.NotificationEmail),
+			OnPaid:         e.NotifyOnPaid,
+			OnPaymentError: e.NotifyOnPaymentError,
+			OnOverdue:      e.NotifyOnOverdue,
+		},
+		CreatedBy: e.CreatedBy,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// toOrganizationSettingsEntity Entityに変換
+func toOrganizationSettingsEntity(s *model.OrganizationSettings) *entity.OrganizationSettings {
+	return &entity.OrganizationSettings{
+		OrganizationID:       s.OrganizationID,
+		Version:              s.Version,
+		DefaultFeePlanID:     nullableID(s.DefaultFeePlanID),
+		RoundingPolicy:       string(s.RoundingPolicy),
+		PaymentTermsDays:     s.PaymentTermsDays,
+		PaymentMethod:        string(s.PaymentMethod),
+		NotificationEmail:    nullableString(s.Notification.Email),
+		NotifyOnPaid:         s.Notification.OnPaid,
+		NotifyOnPaymentError: s.Notification.OnPaymentError,
+		NotifyOnOverdue:      s.Notification.OnOverdue,
+		CreatedBy:            s.CreatedBy,
+		CreatedAt:            s.CreatedAt,
+	}
+}
+
+// GetByUserID ユーザーの所属組織を取得します. 無効化したユーザーは ErrNotFound とします
 func (r *OrganizationRepository) GetByUserID(userID uint) (*model.Organization, error) {
 	// joinしたクエリ結果を格納する構造体
 	type result struct {
 		OrganizationID     uint
 		OrganizationName   string
+		RegistrationNumber *string
 		RepresentativeName string
 		PhoneNumber        string
 		PostalCode         string
 		Address            string
+		RoundingPolicy     string
+		PaymentMethod      string
 	}
 
 	var res result
 
 	if err := r.db.Table("user").
 		Select("organization.organization_id, organization.name AS organization_name, "+
-			"organization.representative_name, organization.phone_number, "+
-			"organization.postal_code, organization.address").
+			"organization.registration_number, organization.representative_name, organization.phone_number, "+
+			"organization.postal_code, organization.address, organization.rounding_policy, organization.payment_method").
 		Joins("JOIN organization ON user.organization_id = organization.organization_id").
-		Where("user.user_id = ?", userID).
+		Where("user.user_id = ? AND user.deactivated_at IS NULL", userID).
 		Scan(&res).Error; err != nil {
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -66,15 +223,45 @@ func (r *OrganizationRepository) GetByUserID(userID uint) (*model.Organization,
 		}
 		return nil, fmt.Errorf("failed to retrieve organization for user ID %d: %w", userID, err)
 	}
+	// Scan は該当行がなくてもエラーにならないため、IDで存在を判定する
+	if res.OrganizationID == 0 {
+		return nil, commonErrors.ErrNotFound
+	}
 
 	organization := &model.Organization{
-		ID:             res.OrganizationID,
-		Name:           res.OrganizationName,
-		Representative: res.RepresentativeName,
-		PhoneNumber:    res.PhoneNumber,
-		PostalCode:     res.PostalCode,
-		Address:        res.Address,
+		ID:                 res.OrganizationID,
+		Name:               res.OrganizationName,
+		RegistrationNumber: stringValue(res.RegistrationNumber),
+		Representative:     res.RepresentativeName,
+		PhoneNumber:        res.PhoneNumber,
+		PostalCode:         res.PostalCode,
+		Address:            res.Address,
+		RoundingPolicy:     model.RoundingPolicy(res.RoundingPolicy),
+		PaymentMethod:      model.PaymentMethod(res.PaymentMethod),
 	}
 
 	return organization, nil
 }
+
+func (r *OrganizationRepository) GetBankAccount(organizationID uint) (*model.OrganizationBankAccount, error) {
+	var e entity.OrganizationBankAccount
+	if err := r.db.Where("organization_id = ?", organizationID).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve bank account for organization ID %d: %w", organizationID, err)
+	}
+
+	return &model.OrganizationBankAccount{
+		ID:             e.ID,
+		OrganizationID: e.OrganizationID,
+		RequesterCode:  e.RequesterCode,
+		RequesterName:  e.RequesterName,
+		BankCode:       e.BankCode,
+		BankName:       e.BankName,
+		BranchCode:     e.BranchCode,
+		BranchName:     e.BranchName,
+		AccountType:    model.AccountType(e.AccountType),
+		AccountNumber:  e.AccountNumber,
+	}, nil
+}
@@ -1,11 +1,15 @@
 package rdb
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/take73/invoice-api-example/internal/domain/model"
 	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 	"gorm.io/gorm/logger"
 )
 
@@ -33,12 +37,15 @@ func Test_OrganizationRepository_GetByID(t *testing.T) {
 				id: 1,
 			},
 			want: &model.Organization{
-				ID:             1,
-				Name:           "株式会社サンプル",
-				Representative: "山田 太郎",
-				PhoneNumber:    "03-1234-5678",
-				PostalCode:     "100-0001",
-				Address:        "東京都千代田区丸の内1-1-1",
+				ID:                 1,
+				Name:               "株式会社サンプル",
+				RegistrationNumber: "T7123456789012",
+				Representative:     "山田 太郎",
+				PhoneNumber:        "03-1234-5678",
+				PostalCode:         "100-0001",
+				Address:            "東京都千代田区丸の内1-1-1",
+				RoundingPolicy:     model.RoundingTruncate,
+				PaymentMethod:      model.PaymentMethodTransferFile,
 			},
 		},
 	}
@@ -87,14 +94,24 @@ func Test_OrganizationRepository_GetByUserID(t *testing.T) {
 				userID: 1,
 			},
 			want: &model.Organization{
-				ID:             1,
-				Name:           "株式会社サンプル",
-				Representative: "山田 太郎",
-				PhoneNumber:    "03-1234-5678",
-				PostalCode:     "100-0001",
-				Address:        "東京都千代田区丸の内1-1-1",
+				ID:                 1,
+				Name:               "株式会社サンプル",
+				RegistrationNumber: "T7123456789012",
+				Representative:     "山田 太郎",
+				PhoneNumber:        "03-1234-5678",
+				PostalCode:         "100-0001",
+				Address:            "東京都千代田区丸の内1-1-1",
+				RoundingPolicy:     model.RoundingTruncate,
+				PaymentMethod:      model.PaymentMethodTransferFile,
 			},
 		},
+		{
+			name: "存在しないユーザー",
+			input: input{
+				userID: 999,
+			},
+			wantErr: commonErrors.ErrNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -116,3 +133,194 @@ func Test_OrganizationRepository_GetByUserID(t *testing.T) {
 		})
 	}
 }
+
+func Test_OrganizationRepository_GetBankAccount(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	tests := []struct {
+		name           string
+		organizationID uint
+		want           *model.OrganizationBankAccount
+		wantErr        error
+	}{
+		{
+			name:           "1件取得",
+			organizationID: 1,
+			want: &model.OrganizationBankAccount{
+				ID:             1,
+				OrganizationID: 1,
+				RequesterCode:  "1234567890",
+				RequesterName:  "カ)サンプル",
+				BankCode:       "0001",
+				BankName:       "ミズホ",
+				BranchCode:     "001",
+				BranchName:     "トウキヨウエイギヨウブ",
+				AccountType:    model.AccountTypeOrdinary,
+				AccountNumber:  "7654321",
+			},
+		},
+		{
+			name:           "未登録の場合はErrNotFound",
+			organizationID: 99,
+			wantErr:        commonErrors.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewOrganizationRepository(db)
+			got, err := repo.GetBankAccount(tt.organizationID)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("api got != want (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_OrganizationRepository_UpdateProfile(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+
+	db.Logger = db.Logger.LogMode(logger.Info)
+	repo := NewOrganizationRepository(db)
+
+	got, err := repo.UpdateProfile(&model.Organization{
+		ID:             1,
+		Name:           "株式会社サンプルホールディングス",
+		Representative: "山田 花子",
+		PhoneNumber:    "03-9876-5432",
+		PostalCode:     "100-0005",
+		Address:        "東京都千代田区丸の内2-2-2",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &model.Organization{
+		ID:             1,
+		Name:           "株式会社サンプルホールディングス",
+		Representative: "山田 花子",
+		PhoneNumber:    "03-9876-5432",
+		PostalCode:     "100-0005",
+		Address:        "東京都千代田区丸の内2-2-2",
+		RoundingPolicy: model.RoundingTruncate,
+		PaymentMethod:  model.PaymentMethodTransferFile,
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("api got != want (-got +want)\n%s", diff)
+	}
+}
+
+func Test_OrganizationRepository_Settings(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+
+	db.Logger = db.Logger.LogMode(logger.Info)
+	repo := NewOrganizationRepository(db)
+	ignoreCreatedAt := cmpopts.IgnoreFields(model.OrganizationSettings{}, "CreatedAt")
+
+	// マイグレーションで作成した最初の版
+	initial := &model.OrganizationSettings{
+		OrganizationID:   1,
+		Version:          1,
+		RoundingPolicy:   model.RoundingTruncate,
+		PaymentTermsDays: model.DefaultPaymentTermsDays,
+		PaymentMethod:    model.PaymentMethodTransferFile,
+		CreatedBy:        "migration",
+	}
+	got, err := repo.GetSettings(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, initial, ignoreCreatedAt); diff != "" {
+		t.Errorf("GetSettings got != want (-got +want)\n%s", diff)
+	}
+
+	second := &model.OrganizationSettings{
+		OrganizationID:   1,
+		Version:          2,
+		DefaultFeePlanID: 2,
+		RoundingPolicy:   model.RoundingHalfUp,
+		PaymentTermsDays: 45,
+		PaymentMethod:    model.PaymentMethodGateway,
+		Notification: model.NotificationPreferences{
+			Email:  "billing@example.com",
+			OnPaid: true,
+		},
+		CreatedBy: "auth0|user1",
+		CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if _, err := repo.CreateSettings(second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("最新の版を取得", func(t *testing.T) {
+		got, err := repo.GetSettings(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(got, second, ignoreCreatedAt); diff != "" {
+			t.Errorf("got != want (-got +want)\n%s", diff)
+		}
+	})
+
+	t.Run("組織の端数処理・支払方法も更新", func(t *testing.T) {
+		organization, err := repo.GetByID(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if organization.RoundingPolicy != model.RoundingHalfUp {
+			t.Errorf("RoundingPolicy = %s, want %s", organization.RoundingPolicy, model.RoundingHalfUp)
+		}
+		if organization.PaymentMethod != model.PaymentMethodGateway {
+			t.Errorf("PaymentMethod = %s, want %s", organization.PaymentMethod, model.PaymentMethodGateway)
+		}
+	})
+
+	t.Run("指定した版を取得", func(t *testing.T) {
+		got, err := repo.GetSettingsByVersion(1, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(got, initial, ignoreCreatedAt); diff != "" {
+			t.Errorf("got != want (-got +want)\n%s", diff)
+		}
+
+		if _, err := repo.GetSettingsByVersion(1, 99); !errors.Is(err, commonErrors.ErrNotFound) {
+			t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+		}
+	})
+
+	t.Run("全ての版を降順で取得", func(t *testing.T) {
+		got, err := repo.FindSettingsVersions(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []*model.OrganizationSettings{second, initial}
+		if diff := cmp.Diff(got, want, ignoreCreatedAt); diff != "" {
+			t.Errorf("got != want (-got +want)\n%s", diff)
+		}
+	})
+
+	t.Run("同じ版数はErrConflict", func(t *testing.T) {
+		duplicated := *second
+		duplicated.RoundingPolicy = model.RoundingCeil
+		if _, err := repo.CreateSettings(&duplicated); !errors.Is(err, commonErrors.ErrConflict) {
+			t.Errorf("error = %v, want %v", err, commonErrors.ErrConflict)
+		}
+	})
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/take73/invoice-api-example/internal/domain/model"
 	"github.com/take73/invoice-api-example/internal/domain/repository"
 	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/entity"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TaxRateRepository struct {
@@ -18,18 +21,181 @@ func NewTaxRateRepository(db *gorm.DB) repository.TaxRate {
 	return &TaxRateRepository{db: db}
 }
 
-// GetRateByDate 指定した日付に適用される税率を取得します
+// GetRateByDate 指定した日付に適用される標準税率を取得します
 func (r *TaxRateRepository) GetRateByDate(date time.Time) (float64, error) {
+	return r.GetRateByCategory(date, model.TaxCategoryStandard)
+}
+
+// GetRateByCategory 指定した日付に適用される税区分の税率を取得します
+func (r *TaxRateRepository) GetRateByCategory(date time.Time, category model.TaxCategory) (float64, error) {
 	var taxRate entity.TaxRate
 
-	if err := r.db.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", date, date).
+	if err := r.db.Where("category = ?", string(category)).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", date, date).
 		Order("start_date DESC").
 		First(&taxRate).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return 0, fmt.Errorf("no tax rate found for date %s: %w", date.Format("2006-01-02"), err)
+			return 0, fmt.Errorf("no %s tax rate found for date %s: %w", category, date.Format("2006-01-02"), err)
 		}
-		return 0, fmt.Errorf("failed to retrieve tax rate for date %s: %w", date.Format("2006-01-02"), err)
+		return 0, fmt.Errorf("failed to retrieve %s tax rate for date %s: %w", category, date.Format("2006-01-02"), err)
 	}
 
 	return taxRate.Rate, nil
 }
+
+// GetByID 税率をIDで取得します
+func (r *TaxRateRepository) GetByID(id uint) (*model.TaxRate, error) {
+	var taxRate entity.TaxRate
+	if err := r.db.First(&taxRate, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve tax rate with ID %d: %w", id, err)
+	}
+	return toTaxRateModel(&taxRate), nil
+}
+
+// FindByCategory 税区分の税率を適用開始日の昇順で取得します
+func (r *TaxRateRepository) FindByCategory(category model.TaxCategory) ([]*model.TaxRate, error) {
+	var entities []entity.TaxRate
+	if err := r.db.Where("category = ?", string(category)).
+		Order("start_date asc").
+		Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve %s tax rates: %w", category, err)
+	}
+	return toTaxRateModels(entities), nil
+}
+
+// FindByDate 指定した日付に適用される税率を、標準税率・軽減税率の順に取得します
+func (r *TaxRateRepository) FindByDate(date time.Time) ([]*model.TaxRate, error) {
+	var entities []entity.TaxRate
+	if err := r.db.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", date, date).
+		Order("category asc, start_date asc").
+		Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve tax rates for date %s: %w", date.Format("2006-01-02"), err)
+	}
+	return toTaxRateModels(entities), nil
+}
+
+// ReplaceCategory 税区分の税率を rates の通りに置き換えます.
+// 同時に置き換えが行われないよう、税区分の税率を行ロックしてから read と同じままであることを確認して更新します
+func (r *TaxRateRepository) ReplaceCategory(category model.TaxCategory, read, rates []*model.TaxRate) ([]*model.TaxRate, error) {
+	saved := make([]*model.TaxRate, 0, len(rates))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current []entity.TaxRate
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("category = ?", string(category)).
+			Find(&current).Error; err != nil {
+			return fmt.Errorf("failed to lock %s tax rates: %w", category, err)
+		}
+		// 読み込んでから検証するまでの間に他の操作で変更された税率を上書きしない
+		if !sameTaxRates(current, read) {
+			return fmt.Errorf("%w: %s tax rates were changed by another operation", commonErrors.ErrConflict, category)
+		}
+
+		keep := make(map[uint]bool, len(rates))
+		for _, rate := range rates {
+			if rate.ID != 0 {
+				keep[rate.ID] = true
+			}
+		}
+		var deleteIDs []uint
+		for i := range current {
+			if !keep[current[i].ID] {
+				deleteIDs = append(deleteIDs, current[i].ID)
+			}
+		}
+		if len(deleteIDs) > 0 {
+			if err := tx.Delete(&entity.TaxRate{}, deleteIDs).Error; err != nil {
+				return fmt.Errorf("failed to delete tax rates with IDs %v: %w", deleteIDs, err)
+			}
+		}
+
+		for _, rate := range rates {
+			e := entity.TaxRate{
+				ID:        rate.ID,
+				Category:  string(category),
+				StartDate: rate.StartDate,
+				EndDate:   rate.EndDate,
+				Rate:      rate.Rate,
+			}
+			if rate.ID == 0 {
+				if err := tx.Create(&e).Error; err != nil {
+					return fmt.Errorf("failed to create %s tax rate starting %s: %w", category, rate.StartDate.Format("2006-01-02"), err)
+				}
+			} else {
+				// 他の税区分の税率は更新しない
+				if !containsTaxRate(current, rate.ID) {
+					return commonErrors.ErrNotFound
+				}
+				if err := tx.Model(&entity.TaxRate{}).
+					Where("tax_rate_id = ?", rate.ID).
+					Updates(map[string]interface{}{
+						"start_date": e.StartDate,
+						"end_date":   e.EndDate,
+						"rate":       e.Rate,
+					}).Error; err != nil {
+					return fmt.Errorf("failed to update tax rate with ID %d: %w", rate.ID, err)
+				}
+			}
+			saved = append(saved, toTaxRateModel(&e))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// sameTaxRates ロックした税率と読み込んだ時点の税率が同じかどうか
+func sameTaxRates(entities []entity.TaxRate, read []*model.TaxRate) bool {
+	if len(entities) != len(read) {
+		return false
+	}
+	byID := make(map[uint]*model.TaxRate, len(read))
+	for _, rate := range read {
+		byID[rate.ID] = rate
+	}
+	for i := range entities {
+		rate, ok := byID[entities[i].ID]
+		if !ok || !rate.StartDate.Equal(entities[i].StartDate) || rate.Rate != entities[i].Rate {
+			return false
+		}
+		if (rate.EndDate == nil) != (entities[i].EndDate == nil) {
+			return false
+		}
+		if rate.EndDate != nil && !rate.EndDate.Equal(*entities[i].EndDate) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsTaxRate(entities []entity.TaxRate, id uint) bool {
+	for i := range entities {
+		if entities[i].ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func toTaxRateModels(entities []entity.TaxRate) []*model.TaxRate {
+	rates := make([]*model.TaxRate, len(entities))
+	for i := range entities {
+		rates[i] = toTaxRateModel(&entities[i])
+	}
+	return rates
+}
+
+// toTaxRateModel 税率をドメインモデルに変換
+func toTaxRateModel(e *entity.TaxRate) *model.TaxRate {
+	return &model.TaxRate{
+		ID:        e.ID,
+		Category:  model.TaxCategory(e.Category),
+		StartDate: e.StartDate,
+		EndDate:   e.EndDate,
+		Rate:      e.Rate,
+	}
+}
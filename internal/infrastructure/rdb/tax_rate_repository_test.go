@@ -1,11 +1,14 @@
 package rdb
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/take73/invoice-api-example/internal/domain/model"
 	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
 	"gorm.io/gorm/logger"
 )
 
@@ -53,3 +56,182 @@ func Test_TaxRateRepository_GetRateByDate(t *testing.T) {
 		})
 	}
 }
+
+func Test_TaxRateRepository_GetRateByCategory(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	type input struct {
+		date     time.Time
+		category model.TaxCategory
+	}
+
+	tests := []struct {
+		name    string
+		input   input
+		want    float64
+		wantErr bool
+	}{
+		{
+			name: "標準税率",
+			input: input{
+				date:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+				category: model.TaxCategoryStandard,
+			},
+			want: 0.1,
+		},
+		{
+			name: "軽減税率",
+			input: input{
+				date:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+				category: model.TaxCategoryReduced,
+			},
+			want: 0.08,
+		},
+		{
+			name: "軽減税率の導入前",
+			input: input{
+				date:     time.Date(2019, 9, 30, 0, 0, 0, 0, time.Local),
+				category: model.TaxCategoryReduced,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewTaxRateRepository(db)
+			got, err := repo.GetRateByCategory(tt.input.date, tt.input.category)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("error is nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("api got != want (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_TaxRateRepository_FindByDate(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	tests := []struct {
+		name string
+		date time.Time
+		want []*model.TaxRate
+	}{
+		{
+			name: "標準税率と軽減税率",
+			date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []*model.TaxRate{
+				{ID: 2, Category: model.TaxCategoryStandard, StartDate: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.1},
+				{ID: 3, Category: model.TaxCategoryReduced, StartDate: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.08},
+			},
+		},
+		{
+			name: "軽減税率の導入前",
+			date: time.Date(2019, 9, 30, 0, 0, 0, 0, time.UTC),
+			want: []*model.TaxRate{
+				{ID: 1, Category: model.TaxCategoryStandard, StartDate: time.Date(2014, 4, 1, 0, 0, 0, 0, time.UTC), EndDate: timePtr(time.Date(2019, 9, 30, 0, 0, 0, 0, time.UTC)), Rate: 0.08},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewTaxRateRepository(db)
+			got, err := repo.FindByDate(tt.date)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("api got != want (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_TaxRateRepository_ReplaceCategory(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	repo := NewTaxRateRepository(db)
+
+	read, err := repo.FindByCategory(model.TaxCategoryStandard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 現在の税率を終了し、将来の税率を追加する
+	rates := []*model.TaxRate{
+		{ID: 1, Category: model.TaxCategoryStandard, StartDate: time.Date(2014, 4, 1, 0, 0, 0, 0, time.UTC), EndDate: timePtr(time.Date(2019, 9, 30, 0, 0, 0, 0, time.UTC)), Rate: 0.08},
+		{ID: 2, Category: model.TaxCategoryStandard, StartDate: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), EndDate: timePtr(time.Date(2029, 9, 30, 0, 0, 0, 0, time.UTC)), Rate: 0.1},
+		{Category: model.TaxCategoryStandard, StartDate: time.Date(2029, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.12},
+	}
+	saved, err := repo.ReplaceCategory(model.TaxCategoryStandard, read, rates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved[2].ID == 0 {
+		t.Error("ID of the added tax rate is not set")
+	}
+
+	got, err := repo.FindByCategory(model.TaxCategoryStandard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, saved); diff != "" {
+		t.Errorf("api got != want (-got +want)\n%s", diff)
+	}
+
+	// 置き換える前に読み込んだ税率で置き換えると、他の操作の変更を上書きするため競合する
+	if _, err := repo.ReplaceCategory(model.TaxCategoryStandard, read, read); !errors.Is(err, commonErrors.ErrConflict) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrConflict)
+	}
+
+	// 追加した税率を削除し、現在の税率を元に戻す
+	if _, err := repo.ReplaceCategory(model.TaxCategoryStandard, got, []*model.TaxRate{
+		rates[0],
+		{ID: 2, Category: model.TaxCategoryStandard, StartDate: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.1},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.GetByID(saved[2].ID); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+	rate, err := repo.GetRateByCategory(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), model.TaxCategoryStandard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rate != 0.1 {
+		t.Errorf("rate = %v, want %v", rate, 0.1)
+	}
+
+	// 他の税区分の税率は更新できない
+	reduced, err := repo.FindByCategory(model.TaxCategoryReduced)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.ReplaceCategory(model.TaxCategoryReduced, reduced, []*model.TaxRate{
+		{ID: 2, Category: model.TaxCategoryReduced, StartDate: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 0.08},
+	}); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,13 @@
+SET FOREIGN_KEY_CHECKS = 0;
+
+INSERT INTO invoice (
+    organization_id, client_id, issue_date, payment_amount, fee, fee_rate, tax, tax_rate, total_amount, due_date, status
+) VALUES
+    (1, 1, '2024-03-01', 10000.00, 400.00, 0.04, 40.00, 0.1, 10440.00, '2024-03-31', 'pending'),
+    (1, 1, '2024-03-03', 10000.00, 400.00, 0.04, 40.00, 0.1, 10440.00, '2024-03-31', 'pending'),
+    (1, 1, '2024-03-20', 10000.00, 400.00, 0.04, 40.00, 0.1, 10440.00, '2024-04-19', 'paid'),
+    (1, 2, '2024-03-01', 10000.00, 400.00, 0.04, 40.00, 0.1, 10440.00, '2024-03-31', 'pending'),
+    (1, 1, '2024-03-02', 20000.00, 800.00, 0.04, 80.00, 0.1, 20880.00, '2024-03-31', 'pending'),
+    (2, 1, '2024-03-01', 10000.00, 400.00, 0.04, 40.00, 0.1, 10440.00, '2024-03-31', 'pending');
+
+SET FOREIGN_KEY_CHECKS = 1;
@@ -0,0 +1,236 @@
+package rdb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/domain/repository"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/entity"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm"
+)
+
+// mysqlErrDuplicateEntry 一意制約違反のエラー番号
+const mysqlErrDuplicateEntry = 1062
+
+type UserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) repository.User {
+	return &UserRepository{db: db}
+}
+
+// GetByID ユーザーをIDで取得します
+func (r *UserRepository) GetByID(id uint) (*model.User, error) {
+	var e entity.User
+	if err := r.db.Where("user_id = ?", id).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve user with ID %d: %w", id, err)
+	}
+
+	return toUserModel(&e), nil
+}
+
+// GetByEmail ユーザーをメールアドレスで取得します
+func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
+	var e entity.User
+	if err := r.db.Where("email = ?", email).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve user by email: %w", err)
+	}
+
+	return toUserModel(&e), nil
+}
+
+// GetByInvitationTokenHash 招待トークンのハッシュでユーザーを取得します
+func (r *UserRepository) GetByInvitationTokenHash(tokenHash string) (*model.User, error) {
+	var e entity.User
+	if err := r.db.Where("invitation_token_hash = ?", tokenHash).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commonErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve user by invitation: %w", err)
+	}
+
+	return toUserModel(&e), nil
+}
+
+// FindByOrganizationID 組織のユーザーをユーザーIDの昇順で取得します
+func (r *UserRepository) FindByOrganizationID(organizationID uint, includeDeactivated bool) ([]*model.User, error) {
+	query := r.db.Where("organization_id = ?", organizationID)
+	if !includeDeactivated {
+		query = query.Where("deactivated_at IS NULL")
+	}
+
+	var entities []entity.User
+	if err := query.Order("user_id asc").Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve users of organization ID %d: %w", organizationID, err)
+	}
+
+	users := make([]*model.User, len(entities))
+	for i := range entities {
+		users[i] = toUserModel(&entities[i])
+	}
+	return users, nil
+}
+
+// Create ユーザーを登録し、ロールの割り当てを履歴に記録します
+func (r *UserRepository) Create(user *model.User, roleChange *model.UserRoleChange) (*model.User, error) {
+	e := toUserEntity(user)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(e).Error; err != nil {
+			if isDuplicateEntry(err) {
+				return commonErrors.ErrConflict
+			}
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		roleChange.UserID = e.ID
+		return createRoleHistory(tx, roleChange)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toUserModel(e), nil
+}
+
+// Update ユーザーの登録内容を更新します. 他組織のユーザーは更新しません
+func (r *UserRepository) Update(user *model.User) (*model.User, error) {
+	e := toUserEntity(user)
+	result := r.db.Model(&entity.User{}).
+		Where("user_id = ? AND organization_id = ?", user.ID, user.OrganizationID).
+		Updates(map[string]interface{}{
+			"name":                  e.Name,
+			"email":                 e.Email,
+			"password_hash":         e.PasswordHash,
+			"invitation_token_hash": e.InvitationTokenHash,
+			"invitation_expires_at": e.InvitationExpiresAt,
+			"deactivated_at":        e.DeactivatedAt,
+		})
+	if result.Error != nil {
+		if isDuplicateEntry(result.Error) {
+			return nil, commonErrors.ErrConflict
+		}
+		return nil, fmt.Errorf("failed to update user with ID %d: %w", user.ID, result.Error)
+	}
+
+	// 値が変わらない場合も RowsAffected は0になるため、更新後の値を取得して存在を確認する
+	updated, err := r.GetByID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !updated.BelongsTo(user.OrganizationID) {
+		return nil, commonErrors.ErrNotFound
+	}
+	return updated, nil
+}
+
+// UpdateRole 変更前のロールのままのユーザーに限定してロールを更新し、変更履歴を記録します
+func (r *UserRepository) UpdateRole(organizationID uint, change *model.UserRoleChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.User{}).
+			Where("user_id = ? AND organization_id = ?", change.UserID, organizationID).
+			Where("role = ?", string(change.FromRole)).
+			Update("role", string(change.ToRole))
+		if result.Error != nil {
+			return fmt.Errorf("failed to update role of user with ID %d: %w", change.UserID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			if err := tx.Model(&entity.User{}).
+				Where("user_id = ? AND organization_id = ?", change.UserID, organizationID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return commonErrors.ErrNotFound
+			}
+			return commonErrors.ErrConflict
+		}
+
+		return createRoleHistory(tx, change)
+	})
+}
+
+// FindRoleChanges ユーザーのロールの変更履歴を古い順に取得します
+func (r *UserRepository) FindRoleChanges(userID uint) ([]*model.UserRoleChange, error) {
+	var entities []entity.UserRoleHistory
+	if err := r.db.Where("user_id = ?", userID).
+		Order("changed_at asc, user_role_history_id asc").
+		Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve role history of user with ID %d: %w", userID, err)
+	}
+
+	changes := make([]*model.UserRoleChange, len(entities))
+	for i, e := range entities {
+		changes[i] = &model.UserRoleChange{
+			ID:        e.ID,
+			UserID:    e.UserID,
+			FromRole:  model.Role(stringValue(e.FromRole)),
+			ToRole:    model.Role(e.ToRole),
+			ChangedBy: e.ChangedBy,
+			ChangedAt: e.ChangedAt,
+		}
+	}
+	return changes, nil
+}
+
+// createRoleHistory ロールの変更履歴を記録する. 変更日時はデータベースの時刻にする
+func createRoleHistory(tx *gorm.DB, change *model.UserRoleChange) error {
+	historyEntity := entity.UserRoleHistory{
+		UserID:    change.UserID,
+		FromRole:  nullableString(string(change.FromRole)),
+		ToRole:    string(change.ToRole),
+		ChangedBy: change.ChangedBy,
+	}
+	if err := tx.Create(&historyEntity).Error; err != nil {
+		return fmt.Errorf("failed to record role history of user with ID %d: %w", change.UserID, err)
+	}
+
+	change.ID = historyEntity.ID
+	change.ChangedAt = historyEntity.ChangedAt
+	return nil
+}
+
+// isDuplicateEntry 一意制約違反かどうか
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
+
+// toUserModel ドメインモデルに変換
+func toUserModel(e *entity.User) *model.User {
+	return &model.User{
+		ID:                  e.ID,
+		OrganizationID:      e.OrganizationID,
+		Name:                e.Name,
+		Email:               e.Email,
+		Role:                model.Role(e.Role),
+		PasswordHash:        stringValue(e.PasswordHash),
+		InvitationTokenHash: stringValue(e.InvitationTokenHash),
+		InvitationExpiresAt: e.InvitationExpiresAt,
+		DeactivatedAt:       e.DeactivatedAt,
+	}
+}
+
+// toUserEntity Entityに変換
+func toUserEntity(user *model.User) *entity.User {
+	return &entity.User{
+		ID:                  user.ID,
+		OrganizationID:      user.OrganizationID,
+		Name:                user.Name,
+		Email:               user.Email,
+		Role:                string(user.Role),
+		PasswordHash:        nullableString(user.PasswordHash),
+		InvitationTokenHash: nullableString(user.InvitationTokenHash),
+		InvitationExpiresAt: user.InvitationExpiresAt,
+		DeactivatedAt:       user.DeactivatedAt,
+	}
+}
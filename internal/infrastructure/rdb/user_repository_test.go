@@ -0,0 +1,141 @@
+package rdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/domain/model"
+	"github.com/take73/invoice-api-example/internal/infrastructure/rdb/testutils"
+	commonErrors "github.com/take73/invoice-api-example/internal/shared/errors"
+	"gorm.io/gorm/logger"
+)
+
+func Test_UserRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDB(testutils.GetFuncName())
+	defer cleanup()
+	db.Logger = db.Logger.LogMode(logger.Info)
+
+	repo := NewUserRepository(db)
+
+	// 初期データの平文のパスワードはマイグレーションで無効になっている
+	seeded, err := repo.GetByID(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seeded.PasswordHash != "" || seeded.Status() != model.UserStatusInvited {
+		t.Errorf("seeded user = %+v, want the plaintext password to be invalidated", seeded)
+	}
+	// ロールを導入する前のユーザーは所有者になっている
+	if seeded.Role != model.RoleOwner {
+		t.Errorf("role = %s, want %s", seeded.Role, model.RoleOwner)
+	}
+
+	// 招待したユーザーを登録する
+	expiresAt := time.Date(2025, 6, 8, 12, 0, 0, 0, time.UTC)
+	created, err := repo.Create(&model.User{
+		OrganizationID:      1,
+		Name:                "伊藤 四郎",
+		Email:               "shiro.ito@example.com",
+		Role:                model.RoleViewer,
+		InvitationTokenHash: "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+		InvitationExpiresAt: &expiresAt,
+	}, &model.UserRoleChange{ToRole: model.RoleViewer, ChangedBy: "auth0|user1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.ID != 4 || created.Role != model.RoleViewer {
+		t.Errorf("created user = %+v, want ID 4 with viewer role", created)
+	}
+
+	// メールアドレスの重複は ErrConflict
+	duplicated := &model.User{OrganizationID: 2, Name: "重複", Email: "shiro.ito@example.com", Role: model.RoleViewer}
+	if _, err := repo.Create(duplicated, &model.UserRoleChange{ToRole: model.RoleViewer, ChangedBy: "auth0|user1"}); !errors.Is(err, commonErrors.ErrConflict) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrConflict)
+	}
+
+	// ロールを変更すると履歴に記録する
+	change := &model.UserRoleChange{UserID: created.ID, FromRole: model.RoleViewer, ToRole: model.RoleAccountant, ChangedBy: "auth0|user1"}
+	if err := repo.UpdateRole(1, change); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 変更前のロールが異なる場合は ErrConflict、他組織のユーザーは ErrNotFound
+	if err := repo.UpdateRole(1, &model.UserRoleChange{UserID: created.ID, FromRole: model.RoleViewer, ToRole: model.RoleAdmin, ChangedBy: "auth0|user1"}); !errors.Is(err, commonErrors.ErrConflict) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrConflict)
+	}
+	if err := repo.UpdateRole(2, &model.UserRoleChange{UserID: created.ID, FromRole: model.RoleAccountant, ToRole: model.RoleAdmin, ChangedBy: "auth0|user1"}); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+	changes, err := repo.FindRoleChanges(created.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 2 || changes[0].FromRole != "" || changes[0].ToRole != model.RoleViewer ||
+		changes[1].FromRole != model.RoleViewer || changes[1].ToRole != model.RoleAccountant {
+		t.Errorf("role changes = %+v, want the initial assignment and the change to accountant", changes)
+	}
+
+	// メールアドレスで取得できる
+	byEmail, err := repo.GetByEmail("shiro.ito@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if byEmail.ID != created.ID {
+		t.Errorf("ID = %d, want %d", byEmail.ID, created.ID)
+	}
+	if _, err := repo.GetByEmail("unknown@example.com"); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+
+	// 招待トークンのハッシュで取得できる
+	invited, err := repo.GetByInvitationTokenHash(created.InvitationTokenHash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if invited.ID != created.ID {
+		t.Errorf("ID = %d, want %d", invited.ID, created.ID)
+	}
+
+	// パスワードを設定すると招待は取り消される
+	invited.PasswordHash = "$argon2id$v=19$m=19456,t=2,p=1$c2FsdHNhbHRzYWx0$a2V5"
+	invited.InvitationTokenHash = ""
+	invited.InvitationExpiresAt = nil
+	updated, err := repo.Update(invited)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status() != model.UserStatusActive || updated.InvitationExpiresAt != nil {
+		t.Errorf("updated user = %+v, want active without invitation", updated)
+	}
+	if _, err := repo.GetByInvitationTokenHash(created.InvitationTokenHash); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+
+	// 他組織のユーザーとしては更新できない
+	other := *updated
+	other.OrganizationID = 2
+	if _, err := repo.Update(&other); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+
+	// 無効化したユーザーは一覧から除き、所属組織も解決できない
+	deactivatedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	updated.DeactivatedAt = &deactivatedAt
+	if _, err := repo.Update(updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	users, err := repo.FindByOrganizationID(1, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 {
+		t.Errorf("users = %d, want 2", len(users))
+	}
+	users, _ = repo.FindByOrganizationID(1, true)
+	if len(users) != 3 || users[2].Status() != model.UserStatusDeactivated {
+		t.Errorf("users including deactivated = %+v, want 3 users with the last one deactivated", users)
+	}
+	if _, err := NewOrganizationRepository(db).GetByUserID(updated.ID); !errors.Is(err, commonErrors.ErrNotFound) {
+		t.Errorf("error = %v, want %v", err, commonErrors.ErrNotFound)
+	}
+}
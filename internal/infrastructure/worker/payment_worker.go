@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/application"
+)
+
+// PaymentWorker 一定間隔で支払処理を実行するバックグラウンドワーカー
+type PaymentWorker struct {
+	usecase  application.PaymentUsecase
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewPaymentWorker(usecase application.PaymentUsecase, interval time.Duration) *PaymentWorker {
+	return &PaymentWorker{
+		usecase:  usecase,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run ctx がキャンセルされるまで interval ごとに支払処理を実行する.
+// 実行中の処理が終わってから戻るため、呼び出し元は Run の終了を待つことで安全に停止できる
+func (w *PaymentWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Printf("Payment worker started (interval: %s)", w.interval)
+	for {
+		w.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Payment worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *PaymentWorker) runOnce(ctx context.Context) {
+	result, err := w.usecase.ProcessDuePayments(ctx, w.now())
+	if err != nil {
+		log.Printf("Failed to process due payments: %v", err)
+	}
+	if result != nil && result.Paid+result.Failed+result.Skipped+result.Unreconciled > 0 {
+		log.Printf("Processed due payments: paid=%d failed=%d skipped=%d unreconciled=%d recovered=%d",
+			result.Paid, result.Failed, result.Skipped, result.Unreconciled, result.Recovered)
+	}
+	if result != nil && result.Unreconciled > 0 {
+		log.Printf("ERROR: %d submitted payments could not be reflected in invoice status and need reconciliation", result.Unreconciled)
+	}
+}
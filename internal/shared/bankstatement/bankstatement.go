@@ -0,0 +1,105 @@
+// Package bankstatement 銀行の入出金明細ファイルを共通の明細行に変換する
+package bankstatement
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/take73/invoice-api-example/internal/shared/zengin"
+)
+
+// Format 明細ファイルの形式
+type Format string
+
+const (
+	FormatZengin Format = "zengin" // 全銀協 入出金取引明細
+	FormatCSV    Format = "csv"    // 汎用CSV
+)
+
+// Direction 入出金の区分
+type Direction string
+
+const (
+	DirectionCredit Direction = "credit" // 入金
+	DirectionDebit  Direction = "debit"  // 出金
+)
+
+// Line 明細の1行
+type Line struct {
+	Number        int       // 明細の行番号（1から）
+	Reference     string    // 照会番号などの取引の識別子
+	Date          time.Time // 取引日（勘定日）
+	Direction     Direction // 入出金の区分
+	Amount        int64     // 取引金額
+	PayeeName     string    // 相手方の名義（半角カナに正規化済み）
+	BankCode      string    // 相手方の金融機関コード（分かる場合のみ）
+	BranchCode    string    // 相手方の支店コード（分かる場合のみ）
+	AccountNumber string    // 相手方の口座番号（分かる場合のみ）
+	Description   string    // 摘要
+}
+
+// ParseError 明細ファイルの内容が形式に合わないことを表すエラー
+type ParseError struct {
+	Line   int    // 行番号（ファイルの先頭を1とする）
+	Reason string // 理由
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// Parse 形式に応じて明細ファイルを読み込む
+func Parse(format Format, data []byte) ([]Line, error) {
+	switch format {
+	case FormatZengin:
+		return ParseZengin(data)
+	case FormatCSV:
+		return ParseCSV(data)
+	}
+	return nil, fmt.Errorf("unsupported statement format: %s", format)
+}
+
+// ParseZengin 全銀協 入出金取引明細（Shift_JIS）を読み込む.
+// 出金の相手方の名義は振込依頼人名、空の場合は摘要から取得する
+func ParseZengin(data []byte) ([]Line, error) {
+	statement, err := zengin.ParseStatement(data)
+	if err != nil {
+		var parseErr *zengin.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &ParseError{Line: parseErr.Record, Reason: parseErr.Reason}
+		}
+		return nil, err
+	}
+
+	lines := make([]Line, len(statement.Records))
+	for i, record := range statement.Records {
+		direction := DirectionCredit
+		if record.EntryType == zengin.EntryTypeWithdrawal {
+			direction = DirectionDebit
+		}
+		payee := record.RequesterName
+		if payee == "" {
+			payee = record.Description
+		}
+		lines[i] = Line{
+			Number:      record.Record,
+			Reference:   record.InquiryNumber,
+			Date:        record.TransactionDate,
+			Direction:   direction,
+			Amount:      record.Amount,
+			PayeeName:   normalizeName(payee),
+			Description: record.Description,
+		}
+	}
+	return lines, nil
+}
+
+// normalizeName 名義を半角カナにし、比較しやすいよう空白を取り除く. 変換できない場合はそのまま返す
+func normalizeName(name string) string {
+	if converted, err := zengin.ToHalfWidthKana(name); err == nil {
+		name = converted
+	}
+	return strings.ReplaceAll(name, " ", "")
+}
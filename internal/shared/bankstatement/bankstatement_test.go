@@ -0,0 +1,105 @@
+package bankstatement
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func Test_ParseZengin(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "zengin", "testdata", "statement.txt"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	got, err := ParseZengin(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Line{
+		{Number: 1, Reference: "00000001", Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), Direction: DirectionDebit, Amount: 10000, PayeeName: "ﾄﾘﾋｷｻｷｴ-", Description: "ﾌﾘｺﾐ"},
+		{Number: 2, Reference: "00000002", Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), Direction: DirectionDebit, Amount: 20000, PayeeName: "ﾄﾘﾋｷｻｷﾋﾞ-", Description: "ﾌﾘｺﾐ"},
+		{Number: 3, Reference: "00000003", Date: time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC), Direction: DirectionCredit, Amount: 50000, PayeeName: "ｶ)ｺｷﾔｸ", Description: "ﾌﾘｺﾐ"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("lines mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_ParseCSV(t *testing.T) {
+	shiftJIS, _ := japanese.ShiftJIS.NewEncoder().String("date,type,amount,payee_name\n2024/01/20,出金,\"10,000\",トリヒキサキ エー\n")
+
+	tests := []struct {
+		name     string
+		input    string
+		want     []Line
+		wantLine int
+	}{
+		{
+			name: "列名で列を識別する",
+			input: "\xef\xbb\xbfReference,Date,Type,Amount,Payee_Name,Bank_Code,Branch_Code,Account_Number,Memo\n" +
+				"A-1,2024-01-20,debit,10000,ﾄﾘﾋｷｻｷｴ-,0001,100,1234567,振込\n" +
+				"\n" +
+				"A-2,20240121,credit,50000,カ）コキャク,,,,\n",
+			want: []Line{
+				{Number: 1, Reference: "A-1", Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), Direction: DirectionDebit, Amount: 10000, PayeeName: "ﾄﾘﾋｷｻｷｴ-", BankCode: "0001", BranchCode: "100", AccountNumber: "1234567"},
+				{Number: 2, Reference: "A-2", Date: time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC), Direction: DirectionCredit, Amount: 50000, PayeeName: "ｶ)ｺｷﾔｸ"},
+			},
+		},
+		{
+			name:  "Shift_JISの場合",
+			input: shiftJIS,
+			want: []Line{
+				{Number: 1, Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), Direction: DirectionDebit, Amount: 10000, PayeeName: "ﾄﾘﾋｷｻｷｴ-"},
+			},
+		},
+		{
+			name:     "必須の列がない",
+			input:    "date,amount\n2024-01-20,10000\n",
+			wantLine: 1,
+		},
+		{
+			name:     "日付が不正",
+			input:    "date,type,amount\n2024-01-20,debit,100\n\n2024-13-01,debit,100\n",
+			wantLine: 4,
+		},
+		{
+			name:     "区分が不正",
+			input:    "date,type,amount\n2024-01-20,transfer,100\n",
+			wantLine: 2,
+		},
+		{
+			name:     "金額が0",
+			input:    "date,type,amount\n2024-01-20,debit,0\n",
+			wantLine: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV([]byte(tt.input))
+			if tt.wantLine != 0 {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("error = %v, want ParseError", err)
+				}
+				if parseErr.Line != tt.wantLine {
+					t.Errorf("error = %v, want line %d", err, tt.wantLine)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("lines mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package bankstatement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// csvDateLayouts 取引日として受け付ける日付の形式
+var csvDateLayouts = []string{"2006-01-02", "2006/01/02", "20060102"}
+
+// csvDirections 入出金の区分として受け付ける値
+var csvDirections = map[string]Direction{
+	"credit":     DirectionCredit,
+	"deposit":    DirectionCredit,
+	"入金":         DirectionCredit,
+	"debit":      DirectionDebit,
+	"withdrawal": DirectionDebit,
+	"出金":         DirectionDebit,
+}
+
+// csvColumns 列名（ヘッダー行）と必須かどうか
+var csvColumns = map[string]bool{
+	"date":           true,
+	"type":           true,
+	"amount":         true,
+	"reference":      false,
+	"payee_name":     false,
+	"bank_code":      false,
+	"branch_code":    false,
+	"account_number": false,
+	"description":    false,
+}
+
+// ParseCSV 1行目をヘッダー行とする汎用CSVを読み込む.
+// 列は列名で識別し、date・type・amount を必須とする. 文字コードは UTF-8（BOM付き可）または Shift_JIS
+func ParseCSV(data []byte) ([]Line, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, &ParseError{Line: 1, Reason: "unsupported character encoding"}
+		}
+		data = decoded
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &ParseError{Line: 1, Reason: "empty file"}
+		}
+		return nil, &ParseError{Line: 1, Reason: err.Error()}
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := csvColumns[name]; ok {
+			columns[name] = i
+		}
+	}
+	for name, required := range csvColumns {
+		if _, ok := columns[name]; required && !ok {
+			return nil, &ParseError{Line: 1, Reason: fmt.Sprintf("column %s is required", name)}
+		}
+	}
+
+	var lines []Line
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var csvErr *csv.ParseError
+			if errors.As(err, &csvErr) {
+				return nil, &ParseError{Line: csvErr.Line, Reason: csvErr.Err.Error()}
+			}
+			return nil, err
+		}
+		lineNumber, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		line, reason := parseCSVLine(field)
+		if reason != "" {
+			return nil, &ParseError{Line: lineNumber, Reason: reason}
+		}
+		line.Number = len(lines) + 1
+		lines = append(lines, *line)
+	}
+
+	return lines, nil
+}
+
+func parseCSVLine(field func(name string) string) (*Line, string) {
+	date, ok := parseCSVDate(field("date"))
+	if !ok {
+		return nil, fmt.Sprintf("invalid date %q", field("date"))
+	}
+	direction, ok := csvDirections[strings.ToLower(field("type"))]
+	if !ok {
+		return nil, fmt.Sprintf("invalid type %q", field("type"))
+	}
+	amount, err := strconv.ParseInt(strings.ReplaceAll(field("amount"), ",", ""), 10, 64)
+	if err != nil || amount <= 0 {
+		return nil, fmt.Sprintf("invalid amount %q", field("amount"))
+	}
+
+	return &Line{
+		Reference:     field("reference"),
+		Date:          date,
+		Direction:     direction,
+		Amount:        amount,
+		PayeeName:     normalizeName(field("payee_name")),
+		BankCode:      field("bank_code"),
+		BranchCode:    field("branch_code"),
+		AccountNumber: field("account_number"),
+		Description:   field("description"),
+	}, ""
+}
+
+func parseCSVDate(value string) (time.Time, bool) {
+	for _, layout := range csvDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -4,6 +4,9 @@ import "errors"
 
 var (
 	ErrNotFound            = errors.New("record not found")
-	ErrUnauthorized        = errors.New("unauthorized")
+	ErrUnauthenticated     = errors.New("unauthenticated") // トークンのユーザーが存在しない・無効化された・別の組織に所属している
+	ErrUnauthorized        = errors.New("unauthorized")    // トークンから組織を解決できない
+	ErrForbidden           = errors.New("forbidden")       // 操作主体に必要な権限がない
+	ErrConflict            = errors.New("conflict")
 	ErrInternalServerError = errors.New("internal server error")
 )
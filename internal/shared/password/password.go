@@ -0,0 +1,93 @@
+// Package password パスワードのハッシュ化と照合. ハッシュは argon2id の PHC 文字列形式で保存する
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// ErrUnsupportedHash 照合できない形式のハッシュ（平文を含む）
+var ErrUnsupportedHash = errors.New("unsupported password hash")
+
+// argon2id のパラメータ（OWASP Password Storage Cheat Sheet の推奨値）
+const (
+	memory      = 19 * 1024 // KiB
+	iterations  = 2
+	parallelism = 1
+	saltLength  = 16
+	keyLength   = 32
+)
+
+const hashPrefix = "$argon2id$"
+
+// Hash パスワードを argon2id でハッシュ化し、
+// "$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>" 形式の文字列を返します
+func Hash(password string) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, keyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", hashPrefix, argon2.Version, memory, iterations, parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify パスワードがハッシュと一致するかどうかを検証します.
+// ハッシュに記録したパラメータで計算するため、パラメータを変更する前のハッシュも照合できます
+func Verify(hash, password string) (bool, error) {
+	params, salt, key, err := decode(hash)
+	if err != nil {
+		return false, err
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+// IsHashed 値がこのパッケージで照合できるハッシュかどうかを返します
+func IsHashed(value string) bool {
+	_, _, _, err := decode(value)
+	return err == nil
+}
+
+type hashParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// decode PHC 文字列形式のハッシュからパラメータ・ソルト・ハッシュ値を取り出す
+func decode(hash string) (*hashParams, []byte, []byte, error) {
+	if !strings.HasPrefix(hash, hashPrefix) {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+	var params hashParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+	return &params, salt, key, nil
+}
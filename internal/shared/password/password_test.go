@@ -0,0 +1,74 @@
+package password_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/take73/invoice-api-example/internal/shared/password"
+)
+
+func TestHashAndVerify(t *testing.T) {
+	hash, err := password.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$") {
+		t.Errorf("hash = %q; want argon2id PHC string", hash)
+	}
+	if strings.Contains(hash, "correct horse") {
+		t.Errorf("hash contains the plaintext password: %q", hash)
+	}
+
+	other, err := password.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash == other {
+		t.Error("hashes of the same password must differ by salt")
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		expected bool
+	}{
+		{name: "Same password", password: "correct horse battery staple", expected: true},
+		{name: "Different password", password: "correct horse battery stapler", expected: false},
+		{name: "Empty", password: "", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := password.Verify(hash, tt.password)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Verify(%q) = %v; want %v", tt.password, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVerify_UnsupportedHash(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "Plaintext", hash: "password123"},
+		{name: "Empty", hash: ""},
+		{name: "Broken parameters", hash: "$argon2id$v=19$m=x$c2FsdA$a2V5"},
+		{name: "Missing key", hash: "$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := password.Verify(tt.hash, "password123")
+			if ok || !errors.Is(err, password.ErrUnsupportedHash) {
+				t.Errorf("Verify(%q) = %v, %v; want false, %v", tt.hash, ok, err, password.ErrUnsupportedHash)
+			}
+			if password.IsHashed(tt.hash) {
+				t.Errorf("IsHashed(%q) = true; want false", tt.hash)
+			}
+		})
+	}
+}
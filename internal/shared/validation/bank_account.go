@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	bankCodePattern      = regexp.MustCompile(`^[0-9]{4}$`)
+	branchCodePattern    = regexp.MustCompile(`^[0-9]{3}$`)
+	accountNumberPattern = regexp.MustCompile(`^[0-9]{7}$`)
+)
+
+// accountNameMaxLength 口座名義の最大文字数（全銀フォーマットの受取人名の桁数）
+const accountNameMaxLength = 30
+
+// accountNameSymbols 口座名義に使用できる英数カナ以外の記号
+const accountNameSymbols = " ()-./,｢｣\\"
+
+// ValidBankCode 金融機関コード（4桁の数字）の妥当性を検証します
+func ValidBankCode(code string) bool {
+	return bankCodePattern.MatchString(code)
+}
+
+// ValidBranchCode 支店コード（3桁の数字）の妥当性を検証します
+func ValidBranchCode(code string) bool {
+	return branchCodePattern.MatchString(code)
+}
+
+// ValidAccountNumber 口座番号（7桁の数字）の妥当性を検証します
+func ValidAccountNumber(number string) bool {
+	return accountNumberPattern.MatchString(number)
+}
+
+// ValidAccountName 口座名義（半角カナ・英大文字・数字・記号, 30文字以内）の妥当性を検証します.
+// 全銀フォーマットで使用できない小書きのカナ（ｧ, ｯ など）は許容しない
+func ValidAccountName(name string) bool {
+	if strings.TrimSpace(name) == "" || utf8.RuneCountInString(name) > accountNameMaxLength {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= '0' && r <= '9', r >= 'A' && r <= 'Z':
+		case r >= 'ｧ' && r <= 'ｯ':
+			return false
+		case r >= 'ｦ' && r <= 'ﾟ':
+		case strings.ContainsRune(accountNameSymbols, r):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+func TestValidBankCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		valid    func(string) bool
+		code     string
+		expected bool
+	}{
+		{name: "Bank code", valid: validation.ValidBankCode, code: "0001", expected: true},
+		{name: "Bank code too short", valid: validation.ValidBankCode, code: "001", expected: false},
+		{name: "Bank code with letters", valid: validation.ValidBankCode, code: "00A1", expected: false},
+		{name: "Branch code", valid: validation.ValidBranchCode, code: "100", expected: true},
+		{name: "Branch code too long", valid: validation.ValidBranchCode, code: "1000", expected: false},
+		{name: "Account number", valid: validation.ValidAccountNumber, code: "1234567", expected: true},
+		{name: "Account number too short", valid: validation.ValidAccountNumber, code: "123456", expected: false},
+		{name: "Account number with hyphen", valid: validation.ValidAccountNumber, code: "123-4567", expected: false},
+		{name: "Full-width digits", valid: validation.ValidAccountNumber, code: "１２３４５６７", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.valid(tt.code)
+			if got != tt.expected {
+				t.Errorf("valid(%q) = %v; want %v", tt.code, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidAccountName(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountName string
+		expected    bool
+	}{
+		{name: "Half-width kana", accountName: "ﾄﾘﾋｷｻｷｴｰ", expected: true},
+		{name: "Voiced marks and symbols", accountName: "ｶ)ﾃﾞｻﾞｲﾝ.ABC", expected: true},
+		{name: "Full-width kana", accountName: "トリヒキサキ", expected: false},
+		{name: "Small kana", accountName: "ｷｯﾄ", expected: false},
+		{name: "Lowercase letters", accountName: "abc", expected: false},
+		{name: "Kanji", accountName: "取引先", expected: false},
+		{name: "Too long", accountName: "ｱｲｳｴｵｱｲｳｴｵｱｲｳｴｵｱｲｳｴｵｱｲｳｴｵｱｲｳｴｵｱ", expected: false},
+		{name: "Empty", accountName: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validation.ValidAccountName(tt.accountName)
+			if got != tt.expected {
+				t.Errorf("ValidAccountName(%q) = %v; want %v", tt.accountName, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package validation
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var (
+	postalCodePattern  = regexp.MustCompile(`^[0-9]{3}-?[0-9]{4}$`)
+	phoneNumberPattern = regexp.MustCompile(`^0[0-9-]+[0-9]$`)
+)
+
+// ValidPostalCode 郵便番号（"123-4567" または "1234567"）の妥当性を検証します
+func ValidPostalCode(code string) bool {
+	return postalCodePattern.MatchString(code)
+}
+
+// ValidPhoneNumber 国内の電話番号（0から始まる10桁または11桁. ハイフン区切り可）の妥当性を検証します.
+// ハイフンは連続や先頭・末尾を許容しない
+func ValidPhoneNumber(number string) bool {
+	if !phoneNumberPattern.MatchString(number) || strings.Contains(number, "--") {
+		return false
+	}
+	digits := len(strings.ReplaceAll(number, "-", ""))
+	return digits == 10 || digits == 11
+}
+
+// ValidEmail メールアドレスの妥当性を検証します. 表示名付きの形式（"名前 <addr>"）は許容しない
+func ValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
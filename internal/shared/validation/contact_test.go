@@ -0,0 +1,80 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+func TestValidPostalCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		expected bool
+	}{
+		{name: "With hyphen", code: "100-0001", expected: true},
+		{name: "Without hyphen", code: "1000001", expected: true},
+		{name: "Too short", code: "100-001", expected: false},
+		{name: "Full-width digits", code: "１００-０００１", expected: false},
+		{name: "Empty", code: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validation.ValidPostalCode(tt.code)
+			if got != tt.expected {
+				t.Errorf("ValidPostalCode(%q) = %v; want %v", tt.code, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidPhoneNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   string
+		expected bool
+	}{
+		{name: "Landline", number: "03-1234-5678", expected: true},
+		{name: "Mobile", number: "090-1234-5678", expected: true},
+		{name: "Without hyphen", number: "0312345678", expected: true},
+		{name: "Not starting with 0", number: "3-1234-5678", expected: false},
+		{name: "Too short", number: "03-1234-567", expected: false},
+		{name: "Too long", number: "090-1234-56789", expected: false},
+		{name: "Consecutive hyphens", number: "03--1234-5678", expected: false},
+		{name: "Trailing hyphen", number: "0312345678-", expected: false},
+		{name: "Empty", number: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validation.ValidPhoneNumber(tt.number)
+			if got != tt.expected {
+				t.Errorf("ValidPhoneNumber(%q) = %v; want %v", tt.number, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		expected bool
+	}{
+		{name: "Valid", email: "ichiro.sato@example.com", expected: true},
+		{name: "With display name", email: "佐藤 <ichiro.sato@example.com>", expected: false},
+		{name: "Missing domain", email: "ichiro.sato@", expected: false},
+		{name: "Surrounding spaces", email: " ichiro.sato@example.com", expected: false},
+		{name: "Empty", email: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validation.ValidEmail(tt.email)
+			if got != tt.expected {
+				t.Errorf("ValidEmail(%q) = %v; want %v", tt.email, got, tt.expected)
+			}
+		})
+	}
+}
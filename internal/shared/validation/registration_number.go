@@ -0,0 +1,26 @@
+package validation
+
+// ValidRegistrationNumber 適格請求書発行事業者の登録番号（"T" + 13桁の数字）の妥当性を検証します.
+// 先頭の数字はチェックデジットで、法人番号と同じ計算方法で検証する
+func ValidRegistrationNumber(number string) bool {
+	if len(number) != 14 || number[0] != 'T' {
+		return false
+	}
+	digits := number[1:]
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	// 基礎番号（12桁）の各桁に、下の桁から奇数桁は1、偶数桁は2を掛けて合計する
+	sum := 0
+	for n := 1; n <= 12; n++ {
+		digit := int(digits[13-n] - '0')
+		if n%2 == 0 {
+			digit *= 2
+		}
+		sum += digit
+	}
+	return int(digits[0]-'0') == 9-sum%9
+}
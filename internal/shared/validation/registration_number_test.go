@@ -0,0 +1,65 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/take73/invoice-api-example/internal/shared/validation"
+)
+
+func TestValidRegistrationNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   string
+		expected bool
+	}{
+		{
+			name:     "Valid number",
+			number:   "T7000012050002",
+			expected: true,
+		},
+		{
+			name:     "Valid number (check digit 9)",
+			number:   "T9234567890123",
+			expected: true,
+		},
+		{
+			name:     "Invalid check digit",
+			number:   "T8000012050002",
+			expected: false,
+		},
+		{
+			name:     "Without prefix T",
+			number:   "7000012050002",
+			expected: false,
+		},
+		{
+			name:     "Lowercase prefix",
+			number:   "t7000012050002",
+			expected: false,
+		},
+		{
+			name:     "Too short",
+			number:   "T700001205000",
+			expected: false,
+		},
+		{
+			name:     "Contains non-digit",
+			number:   "T70000120500O2",
+			expected: false,
+		},
+		{
+			name:     "Empty",
+			number:   "",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validation.ValidRegistrationNumber(tt.number)
+			if got != tt.expected {
+				t.Errorf("ValidRegistrationNumber(%q) = %v; want %v", tt.number, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package zengin 全銀協フォーマット（固定長・Shift_JIS）のファイルを扱う
+package zengin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// ErrUnsupportedCharacter 全銀フォーマットで使用できない文字が含まれている
+var ErrUnsupportedCharacter = errors.New("unsupported character")
+
+// smallKanaReplacer 全銀フォーマットで使用できない小書きのカナを大きいカナに置き換える
+var smallKanaReplacer = strings.NewReplacer(
+	"ｧ", "ｱ", "ｨ", "ｲ", "ｩ", "ｳ", "ｪ", "ｴ", "ｫ", "ｵ",
+	"ｬ", "ﾔ", "ｭ", "ﾕ", "ｮ", "ﾖ", "ｯ", "ﾂ",
+	"ｰ", "-", "･", ".",
+)
+
+// allowedSymbols 英数カナ以外に使用できる記号
+const allowedSymbols = " ()-./,｢｣\\"
+
+// ToHalfWidthKana 文字列を全銀フォーマットで使用できる半角カナ・英大文字・数字・記号に変換する.
+// ひらがな・全角カナ・全角英数は半角に、濁点・半濁点は分離し、小書きのカナは大きいカナにする.
+// 漢字など変換できない文字が含まれる場合は ErrUnsupportedCharacter を返す
+func ToHalfWidthKana(s string) (string, error) {
+	// ひらがなをカタカナにする
+	s = strings.Map(func(r rune) rune {
+		if r >= 'ぁ' && r <= 'ゖ' {
+			return r + ('ァ' - 'ぁ')
+		}
+		return r
+	}, s)
+	// 濁点・半濁点を分離してから半角にする
+	s = width.Narrow.String(norm.NFD.String(s))
+	s = strings.ToUpper(smallKanaReplacer.Replace(s))
+
+	for _, r := range s {
+		if !isAllowed(r) {
+			return "", fmt.Errorf("%w: %q", ErrUnsupportedCharacter, r)
+		}
+	}
+	return s, nil
+}
+
+func isAllowed(r rune) bool {
+	switch {
+	case r >= '0' && r <= '9', r >= 'A' && r <= 'Z':
+		return true
+	case r >= 'ｦ' && r <= 'ﾟ':
+		return true
+	}
+	return strings.ContainsRune(allowedSymbols, r)
+}
@@ -0,0 +1,69 @@
+package zengin
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_ToHalfWidthKana(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "全角カナを半角にする",
+			input: "トリヒキサキ",
+			want:  "ﾄﾘﾋｷｻｷ",
+		},
+		{
+			name:  "濁点・半濁点は分離する",
+			input: "ガパヴ",
+			want:  "ｶﾞﾊﾟｳﾞ",
+		},
+		{
+			name:  "ひらがなはカナにする",
+			input: "みずほ",
+			want:  "ﾐｽﾞﾎ",
+		},
+		{
+			name:  "小書きのカナは大きいカナにする",
+			input: "キャッシュ",
+			want:  "ｷﾔﾂｼﾕ",
+		},
+		{
+			name:  "全角英数・記号を半角にし英字は大文字にする",
+			input: "カ）ａｂｃ１２３　ー",
+			want:  "ｶ)ABC123 -",
+		},
+		{
+			name:  "半角カナはそのまま",
+			input: "ｶ)ｻﾝﾌﾟﾙ",
+			want:  "ｶ)ｻﾝﾌﾟﾙ",
+		},
+		{
+			name:    "漢字は変換できない",
+			input:   "取引先",
+			wantErr: ErrUnsupportedCharacter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToHalfWidthKana(tt.input)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
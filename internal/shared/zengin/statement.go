@@ -0,0 +1,235 @@
+package zengin
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+const (
+	// StatementRecordLength 入出金取引明細の1レコードのバイト数
+	StatementRecordLength = 200
+
+	statementTypeCode = "03" // 種別コード: 入出金取引明細
+)
+
+// EntryType 入払区分
+type EntryType int
+
+const (
+	EntryTypeDeposit    EntryType = 1 // 入金
+	EntryTypeWithdrawal EntryType = 2 // 出金
+)
+
+// StatementHeader 入出金取引明細のヘッダーレコード（照会口座の情報）
+type StatementHeader struct {
+	CreatedDate   time.Time // 作成日
+	BankCode      string    // 金融機関コード
+	BranchCode    string    // 支店コード
+	AccountType   AccountType
+	AccountNumber string // 口座番号
+	AccountName   string // 口座名
+}
+
+// StatementRecord 入出金取引明細のデータレコード（1件の取引）
+type StatementRecord struct {
+	Record          int       // レコード番号（データレコードは1から）
+	InquiryNumber   string    // 照会番号
+	TransactionDate time.Time // 勘定日
+	EntryType       EntryType // 入払区分
+	Amount          int64     // 取引金額
+	RequesterName   string    // 振込依頼人名（出金の場合は振込先の名義が入ることがある）
+	Description     string    // 摘要内容
+}
+
+// Statement 入出金取引明細ファイル
+type Statement struct {
+	Header  StatementHeader
+	Records []StatementRecord
+}
+
+// ParseError ファイルの内容がフォーマットに合わないことを表すエラー
+type ParseError struct {
+	Record int    // レコード番号（ファイルの先頭レコードを1とする）
+	Reason string // 理由
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("record %d: %s", e.Record, e.Reason)
+}
+
+// ParseStatement Shift_JIS の入出金取引明細ファイルを読み込む.
+// レコードは改行区切り・区切りなしのどちらにも対応する. 日付は西暦の下2桁（YYMMDD）として扱う
+func ParseStatement(data []byte) (*Statement, error) {
+	records := splitRecords(data)
+	if len(records) == 0 {
+		return nil, &ParseError{Record: 1, Reason: "empty file"}
+	}
+
+	statement := &Statement{}
+	var headerFound, trailerFound bool
+	for i, record := range records {
+		n := i + 1
+		if len(record) != StatementRecordLength {
+			return nil, &ParseError{Record: n, Reason: fmt.Sprintf("record length must be %d but %d", StatementRecordLength, len(record))}
+		}
+
+		p := &recordParser{data: record, record: n}
+		switch record[0] {
+		case '1':
+			if headerFound {
+				return nil, &ParseError{Record: n, Reason: "duplicated header record"}
+			}
+			headerFound = true
+			p.skip(1)
+			if typeCode := p.text(2); typeCode != statementTypeCode {
+				return nil, &ParseError{Record: n, Reason: fmt.Sprintf("type code must be %s but %s", statementTypeCode, typeCode)}
+			}
+			p.skip(1)                                               // コード区分
+			statement.Header.CreatedDate = p.date()                 // 作成日
+			p.skip(12)                                              // 勘定日（自）・勘定日（至）
+			statement.Header.BankCode = p.text(4)                   // 金融機関コード
+			p.skip(15)                                              // 金融機関名
+			statement.Header.BranchCode = p.text(3)                 // 支店コード
+			p.skip(15 + 3)                                          // 支店名・ダミー
+			statement.Header.AccountType = AccountType(p.number(1)) // 預金種目
+			statement.Header.AccountNumber = p.text(10)             // 口座番号
+			statement.Header.AccountName = p.text(40)               // 口座名
+		case '2':
+			if !headerFound || trailerFound {
+				return nil, &ParseError{Record: n, Reason: "data record must be between header and trailer"}
+			}
+			p.skip(1)
+			r := StatementRecord{Record: len(statement.Records) + 1}
+			r.InquiryNumber = p.text(8)          // 照会番号
+			r.TransactionDate = p.date()         // 勘定日
+			p.skip(6)                            // 起算日
+			r.EntryType = EntryType(p.number(1)) // 入払区分
+			p.skip(2)                            // 取引区分
+			r.Amount = p.number(12)              // 取引金額
+			p.skip(12 + 6 + 6 + 1 + 7 + 3 + 10)  // 他店券金額〜振込依頼人コード
+			r.RequesterName = p.text(48)         // 振込依頼人名
+			p.skip(15 + 15)                      // 仕向銀行名・仕向店名
+			r.Description = p.text(20)           // 摘要内容
+			if r.EntryType != EntryTypeDeposit && r.EntryType != EntryTypeWithdrawal {
+				p.fail(fmt.Sprintf("invalid entry type %d", r.EntryType))
+			}
+			statement.Records = append(statement.Records, r)
+		case '8':
+			if !headerFound || trailerFound {
+				return nil, &ParseError{Record: n, Reason: "unexpected trailer record"}
+			}
+			trailerFound = true
+			p.skip(1)
+			depositCount, depositTotal := p.number(6), p.number(13)       // 入金件数・入金額合計
+			withdrawalCount, withdrawalTotal := p.number(6), p.number(13) // 出金件数・出金額合計
+			if p.err == nil && !statement.matchesTotals(depositCount, depositTotal, withdrawalCount, withdrawalTotal) {
+				p.fail("trailer totals do not match data records")
+			}
+		case '9':
+			// エンドレコード以降は読まない
+			if !trailerFound {
+				return nil, &ParseError{Record: n, Reason: "trailer record is missing"}
+			}
+			return statement, p.err
+		default:
+			return nil, &ParseError{Record: n, Reason: fmt.Sprintf("invalid data type %q", record[0])}
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+	}
+
+	return nil, &ParseError{Record: len(records), Reason: "end record is missing"}
+}
+
+// matchesTotals トレーラーレコードの件数・合計金額がデータレコードと一致するかどうか
+func (s *Statement) matchesTotals(depositCount, depositTotal, withdrawalCount, withdrawalTotal int64) bool {
+	var counts, totals [3]int64
+	for _, r := range s.Records {
+		counts[r.EntryType]++
+		totals[r.EntryType] += r.Amount
+	}
+	return counts[EntryTypeDeposit] == depositCount && totals[EntryTypeDeposit] == depositTotal &&
+		counts[EntryTypeWithdrawal] == withdrawalCount && totals[EntryTypeWithdrawal] == withdrawalTotal
+}
+
+// splitRecords ファイルをレコードに分割する. 改行を含まない場合は固定長で区切る
+func splitRecords(data []byte) [][]byte {
+	data = bytes.TrimRight(data, "\r\n\x1a") // 末尾の改行・EOF
+	if len(data) == 0 {
+		return nil
+	}
+	if bytes.ContainsAny(data, "\r\n") {
+		lines := bytes.Split(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), []byte("\n"))
+		return lines
+	}
+
+	var records [][]byte
+	for len(data) > StatementRecordLength {
+		records = append(records, data[:StatementRecordLength])
+		data = data[StatementRecordLength:]
+	}
+	return append(records, data)
+}
+
+// recordParser 固定長レコードを先頭から項目ごとに読み込む. 最初に発生したエラーを保持する
+type recordParser struct {
+	data   []byte
+	pos    int
+	record int
+	err    error
+}
+
+func (p *recordParser) fail(reason string) {
+	if p.err == nil {
+		p.err = &ParseError{Record: p.record, Reason: reason}
+	}
+}
+
+func (p *recordParser) next(size int) []byte {
+	field := p.data[p.pos : p.pos+size]
+	p.pos += size
+	return field
+}
+
+func (p *recordParser) skip(size int) {
+	p.pos += size
+}
+
+// text Shift_JIS の項目を文字列として読み込み、前後の空白を取り除く
+func (p *recordParser) text(size int) string {
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(p.next(size))
+	if err != nil {
+		p.fail(fmt.Sprintf("invalid Shift_JIS at byte %d", p.pos-size))
+		return ""
+	}
+	return strings.TrimSpace(string(decoded))
+}
+
+func (p *recordParser) number(size int) int64 {
+	field := strings.TrimSpace(string(p.next(size)))
+	if field == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(field, 10, 64)
+	if err != nil || n < 0 {
+		p.fail(fmt.Sprintf("invalid number %q at byte %d", field, p.pos-size))
+	}
+	return n
+}
+
+// date 西暦の下2桁の年月日（YYMMDD）を読み込む
+func (p *recordParser) date() time.Time {
+	field := string(p.next(6))
+	t, err := time.Parse("060102", field)
+	if err != nil {
+		p.fail(fmt.Sprintf("invalid date %q at byte %d", field, p.pos-6))
+		return time.Time{}
+	}
+	return t
+}
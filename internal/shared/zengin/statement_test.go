@@ -0,0 +1,129 @@
+package zengin
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func readStatementFixture(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "statement.txt"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func Test_ParseStatement(t *testing.T) {
+	data := readStatementFixture(t)
+	want := &Statement{
+		Header: StatementHeader{
+			CreatedDate:   time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC),
+			BankCode:      "0001",
+			BranchCode:    "001",
+			AccountType:   AccountTypeOrdinary,
+			AccountNumber: "0007654321",
+			AccountName:   "ｶ)ｻﾝﾌﾟﾙ",
+		},
+		Records: []StatementRecord{
+			{Record: 1, InquiryNumber: "00000001", TransactionDate: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), EntryType: EntryTypeWithdrawal, Amount: 10000, RequesterName: "ﾄﾘﾋｷｻｷｴ-", Description: "ﾌﾘｺﾐ"},
+			{Record: 2, InquiryNumber: "00000002", TransactionDate: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), EntryType: EntryTypeWithdrawal, Amount: 20000, RequesterName: "ﾄﾘﾋｷｻｷﾋﾞ-", Description: "ﾌﾘｺﾐ"},
+			{Record: 3, InquiryNumber: "00000003", TransactionDate: time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC), EntryType: EntryTypeDeposit, Amount: 50000, RequesterName: "ｶ)ｺｷﾔｸ", Description: "ﾌﾘｺﾐ"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "改行区切り",
+			input: data,
+		},
+		{
+			name:  "区切りなしの固定長",
+			input: bytes.ReplaceAll(data, []byte("\r\n"), nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStatement(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("statement mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ParseStatement_Error(t *testing.T) {
+	data := readStatementFixture(t)
+	records := bytes.Split(bytes.TrimSuffix(data, []byte("\r\n")), []byte("\r\n"))
+	join := func(records ...[]byte) []byte {
+		return bytes.Join(records, []byte("\r\n"))
+	}
+	modified := func(record []byte, offset int, value string) []byte {
+		copied := append([]byte(nil), record...)
+		copy(copied[offset:], value)
+		return copied
+	}
+
+	tests := []struct {
+		name       string
+		input      []byte
+		wantRecord int
+	}{
+		{
+			name:       "空のファイル",
+			input:      []byte{},
+			wantRecord: 1,
+		},
+		{
+			name:       "レコード長が不正",
+			input:      join(records[0], records[1][:199]),
+			wantRecord: 2,
+		},
+		{
+			name:       "種別コードが入出金取引明細でない",
+			input:      join(modified(records[0], 1, "21"), records[1], records[4], records[5]),
+			wantRecord: 1,
+		},
+		{
+			name:       "取引金額が数字でない",
+			input:      join(records[0], modified(records[1], 24, "ABC"), records[2], records[3], records[4], records[5]),
+			wantRecord: 2,
+		},
+		{
+			name:       "トレーラーの合計が一致しない",
+			input:      join(records[0], records[1], records[2], records[4], records[5]),
+			wantRecord: 4,
+		},
+		{
+			name:       "エンドレコードがない",
+			input:      join(records[0], records[1], records[2], records[3], records[4]),
+			wantRecord: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStatement(tt.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("error = %v, want ParseError", err)
+			}
+			if parseErr.Record != tt.wantRecord {
+				t.Errorf("error = %v, want record %d", err, tt.wantRecord)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package zengin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+const (
+	// RecordLength 1レコードのバイト数
+	RecordLength = 120
+	// recordSeparator レコードの区切り
+	recordSeparator = "\r\n"
+
+	transferTypeCode = "21" // 種別コード: 総合振込
+	codeTypeShiftJIS = "0"  // コード区分: JIS（Shift_JIS）
+
+	maxRecordCount = 999999       // 合計件数の上限（6桁）
+	maxAmount      = 9999999999   // 振込金額の上限（10桁）
+	maxTotalAmount = 999999999999 // 合計金額の上限（12桁）
+)
+
+var (
+	// ErrNoRecords 振込データが1件もない
+	ErrNoRecords = errors.New("no transfer records")
+	// ErrTooManyRecords 振込データの件数がトレーラーレコードの桁数を超えている
+	ErrTooManyRecords = errors.New("too many transfer records")
+	// ErrTotalAmountOverflow 合計金額がトレーラーレコードの桁数を超えている
+	ErrTotalAmountOverflow = errors.New("total amount overflows")
+)
+
+// AccountType 預金種目
+type AccountType int
+
+const (
+	AccountTypeOrdinary AccountType = 1 // 普通
+	AccountTypeChecking AccountType = 2 // 当座
+)
+
+// FieldError 項目の値がフォーマットに合わないことを表すエラー
+type FieldError struct {
+	Record int    // レコード番号（ヘッダーレコードは0、データレコードは1から）
+	Field  string // 項目名
+	Value  string // 項目の値
+	Reason string // 理由
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("record %d: %s %q: %s", e.Record, e.Field, e.Value, e.Reason)
+}
+
+// TransferHeader 総合振込のヘッダーレコード（振込依頼人の情報）
+type TransferHeader struct {
+	RequesterCode string      // 振込依頼人コード（10桁以内の数字）
+	RequesterName string      // 振込依頼人名（カナ）
+	TransferDate  time.Time   // 取組日
+	BankCode      string      // 仕向金融機関コード（4桁）
+	BankName      string      // 仕向金融機関名（カナ、任意）
+	BranchCode    string      // 仕向支店コード（3桁）
+	BranchName    string      // 仕向支店名（カナ、任意）
+	AccountType   AccountType // 預金種目
+	AccountNumber string      // 口座番号（7桁以内の数字）
+}
+
+// TransferRecord 総合振込のデータレコード（1件の振込）
+type TransferRecord struct {
+	BankCode      string      // 被仕向金融機関コード（4桁）
+	BankName      string      // 被仕向金融機関名（カナ、任意）
+	BranchCode    string      // 被仕向支店コード（3桁）
+	BranchName    string      // 被仕向支店名（カナ、任意）
+	AccountType   AccountType // 預金種目
+	AccountNumber string      // 口座番号（7桁以内の数字）
+	AccountName   string      // 受取人名（カナ）
+	Amount        int64       // 振込金額
+	CustomerCode  string      // 顧客コード1（10桁以内の数字、任意）
+}
+
+// TransferFile 総合振込ファイル
+type TransferFile struct {
+	Header  TransferHeader
+	Records []TransferRecord
+}
+
+// TotalAmount 振込金額の合計
+func (f *TransferFile) TotalAmount() int64 {
+	var total int64
+	for _, record := range f.Records {
+		total += record.Amount
+	}
+	return total
+}
+
+// Encode ヘッダー・データ・トレーラー・エンドレコードからなる総合振込ファイルを Shift_JIS で生成する.
+// 各レコードは120バイトの固定長で、CRLFで区切る
+func (f *TransferFile) Encode() ([]byte, error) {
+	if len(f.Records) == 0 {
+		return nil, ErrNoRecords
+	}
+	if len(f.Records) > maxRecordCount {
+		return nil, ErrTooManyRecords
+	}
+
+	records := make([]string, 0, len(f.Records)+3)
+
+	header, err := f.encodeHeader()
+	if err != nil {
+		return nil, err
+	}
+	records = append(records, header)
+
+	var total int64
+	for i := range f.Records {
+		data, err := encodeData(i+1, &f.Records[i])
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, data)
+		total += f.Records[i].Amount
+	}
+	if total > maxTotalAmount {
+		return nil, ErrTotalAmountOverflow
+	}
+
+	trailer := &recordBuilder{}
+	trailer.fixed("8")                                            // データ区分
+	trailer.number("record count", fmt.Sprint(len(f.Records)), 6) // 合計件数
+	trailer.number("total amount", fmt.Sprint(total), 12)         // 合計金額
+	trailer.space(101)                                            // ダミー
+	records = append(records, trailer.String())
+
+	end := &recordBuilder{}
+	end.fixed("9") // データ区分
+	end.space(119) // ダミー
+	records = append(records, end.String())
+
+	encoded, err := japanese.ShiftJIS.NewEncoder().String(strings.Join(records, recordSeparator) + recordSeparator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transfer file: %w", err)
+	}
+	return []byte(encoded), nil
+}
+
+func (f *TransferFile) encodeHeader() (string, error) {
+	h := &f.Header
+	b := &recordBuilder{record: 0}
+	b.fixed("1")                                        // データ区分
+	b.fixed(transferTypeCode)                           // 種別コード
+	b.fixed(codeTypeShiftJIS)                           // コード区分
+	b.number("requester code", h.RequesterCode, 10)     // 振込依頼人コード
+	b.text("requester name", h.RequesterName, 40, true) // 振込依頼人名
+	b.date("transfer date", h.TransferDate)             // 取組日
+	b.code("bank code", h.BankCode, 4)                  // 仕向金融機関番号
+	b.text("bank name", h.BankName, 15, false)          // 仕向金融機関名
+	b.code("branch code", h.BranchCode, 3)              // 仕向支店番号
+	b.text("branch name", h.BranchName, 15, false)      // 仕向支店名
+	b.accountType(h.AccountType)                        // 預金種目
+	b.number("account number", h.AccountNumber, 7)      // 口座番号
+	b.space(17)                                         // ダミー
+	return b.result()
+}
+
+func encodeData(index int, r *TransferRecord) (string, error) {
+	b := &recordBuilder{record: index}
+	b.fixed("2")                                          // データ区分
+	b.code("bank code", r.BankCode, 4)                    // 被仕向金融機関番号
+	b.text("bank name", r.BankName, 15, false)            // 被仕向金融機関名
+	b.code("branch code", r.BranchCode, 3)                // 被仕向支店番号
+	b.text("branch name", r.BranchName, 15, false)        // 被仕向支店名
+	b.space(4)                                            // 手形交換所番号
+	b.accountType(r.AccountType)                          // 預金種目
+	b.number("account number", r.AccountNumber, 7)        // 口座番号
+	b.text("account name", r.AccountName, 30, true)       // 受取人名
+	b.amount(r.Amount)                                    // 振込金額
+	b.fixed("0")                                          // 新規コード
+	b.optionalNumber("customer code", r.CustomerCode, 10) // 顧客コード1
+	b.space(10)                                           // 顧客コード2
+	b.fixed("7")                                          // 振込指定区分: テレ振込
+	b.space(1)                                            // 識別表示
+	b.space(7)                                            // ダミー
+	return b.result()
+}
+
+// recordBuilder 固定長レコードを項目ごとに組み立てる. 最初に発生したエラーを保持する
+type recordBuilder struct {
+	record int
+	buf    strings.Builder
+	err    error
+}
+
+func (b *recordBuilder) String() string {
+	return b.buf.String()
+}
+
+func (b *recordBuilder) result() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	if n := utf8.RuneCountInString(b.buf.String()); n != RecordLength {
+		return "", fmt.Errorf("record %d: invalid record length %d", b.record, n)
+	}
+	return b.buf.String(), nil
+}
+
+func (b *recordBuilder) fail(field, value, reason string) {
+	if b.err == nil {
+		b.err = &FieldError{Record: b.record, Field: field, Value: value, Reason: reason}
+	}
+}
+
+func (b *recordBuilder) fixed(value string) {
+	b.buf.WriteString(value)
+}
+
+func (b *recordBuilder) space(size int) {
+	b.buf.WriteString(strings.Repeat(" ", size))
+}
+
+// code 桁数が決まっているコードを書き込む
+func (b *recordBuilder) code(field, value string, size int) {
+	if len(value) != size || !isDigits(value) {
+		b.fail(field, value, fmt.Sprintf("must be %d digits", size))
+	}
+	b.pad(value, size, '0')
+}
+
+// number 数字を右詰め・前ゼロ埋めで書き込む
+func (b *recordBuilder) number(field, value string, size int) {
+	if value == "" || len(value) > size || !isDigits(value) {
+		b.fail(field, value, fmt.Sprintf("must be 1 to %d digits", size))
+	}
+	b.pad(value, size, '0')
+}
+
+// optionalNumber 任意項目の数字を書き込む. 空の場合は空白にする
+func (b *recordBuilder) optionalNumber(field, value string, size int) {
+	if value == "" {
+		b.space(size)
+		return
+	}
+	b.number(field, value, size)
+}
+
+func (b *recordBuilder) amount(value int64) {
+	if value <= 0 || value > maxAmount {
+		b.fail("amount", fmt.Sprint(value), fmt.Sprintf("must be between 1 and %d", int64(maxAmount)))
+	}
+	b.pad(fmt.Sprint(value), 10, '0')
+}
+
+// date 日付を月日（MMDD）で書き込む
+func (b *recordBuilder) date(field string, value time.Time) {
+	if value.IsZero() {
+		b.fail(field, "", "is required")
+	}
+	b.buf.WriteString(value.Format("0102"))
+}
+
+func (b *recordBuilder) accountType(value AccountType) {
+	if value != AccountTypeOrdinary && value != AccountTypeChecking {
+		b.fail("account type", fmt.Sprint(value), "must be ordinary(1) or checking(2)")
+	}
+	b.pad(fmt.Sprint(int(value)), 1, '0')
+}
+
+// text 半角カナに変換して左詰め・空白埋めで書き込む. 桁数を超える部分は切り捨てる.
+// 金融機関名・支店名などの任意項目は、カナに変換できない場合は空白にする
+func (b *recordBuilder) text(field, value string, size int, required bool) {
+	converted, err := ToHalfWidthKana(value)
+	if err != nil || strings.TrimSpace(converted) == "" {
+		if required {
+			reason := "is required"
+			if err != nil {
+				reason = err.Error()
+			}
+			b.fail(field, value, reason)
+		}
+		converted = ""
+	}
+	runes := []rune(converted)
+	if len(runes) > size {
+		runes = runes[:size]
+	}
+	b.buf.WriteString(string(runes))
+	b.space(size - len(runes))
+}
+
+// pad 右詰めで指定の文字で埋めて書き込む. 桁数を超える場合は末尾を残す
+func (b *recordBuilder) pad(value string, size int, fill byte) {
+	if len(value) > size {
+		value = value[len(value)-size:]
+	}
+	b.buf.WriteString(strings.Repeat(string(fill), size-len(value)) + value)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
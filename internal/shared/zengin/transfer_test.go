@@ -0,0 +1,174 @@
+package zengin
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func newTestTransferFile() *TransferFile {
+	return &TransferFile{
+		Header: TransferHeader{
+			RequesterCode: "1234567890",
+			RequesterName: "カ）サンプル",
+			TransferDate:  time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+			BankCode:      "0001",
+			BankName:      "ミズホ",
+			BranchCode:    "001",
+			BranchName:    "トウキヨウエイギヨウブ",
+			AccountType:   AccountTypeOrdinary,
+			AccountNumber: "7654321",
+		},
+		Records: []TransferRecord{
+			{
+				BankCode:      "0001",
+				BankName:      "ミズホ",
+				BranchCode:    "100",
+				BranchName:    "ホンテン",
+				AccountType:   AccountTypeOrdinary,
+				AccountNumber: "1234567",
+				AccountName:   "トリヒキサキエー",
+				Amount:        10000,
+				CustomerCode:  "1",
+			},
+			{
+				BankCode:      "0005",
+				BankName:      "三菱ＵＦＪ銀行", // カナに変換できない任意項目は空白にする
+				BranchCode:    "050",
+				BranchName:    "しんじゅく",
+				AccountType:   AccountTypeChecking,
+				AccountNumber: "45678",
+				AccountName:   "カブシキガイシャ　ビー・ショウジ",
+				Amount:        1234567,
+				CustomerCode:  "2",
+			},
+		},
+	}
+}
+
+func Test_TransferFile_Encode_Golden(t *testing.T) {
+	got, err := newTestTransferFile().Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "transfer.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("encoded file does not match %s\nwant:\n%q\ngot:\n%q", golden, want, got)
+	}
+
+	// ヘッダー・データ2件・トレーラー・エンドの5レコードがすべて120バイト
+	records := bytes.Split(bytes.TrimSuffix(got, []byte(recordSeparator)), []byte(recordSeparator))
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records, got %d", len(records))
+	}
+	for i, record := range records {
+		if len(record) != RecordLength {
+			t.Errorf("record %d: length = %d, want %d", i, len(record), RecordLength)
+		}
+	}
+	// トレーラーの合計件数・合計金額がデータレコードと一致する
+	if trailer := string(records[3][:19]); trailer != "8000002000001244567" {
+		t.Errorf("trailer = %q", trailer)
+	}
+}
+
+func Test_TransferFile_Encode_Error(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(f *TransferFile)
+		wantErr   error
+		wantField *FieldError
+	}{
+		{
+			name:    "振込データがない",
+			modify:  func(f *TransferFile) { f.Records = nil },
+			wantErr: ErrNoRecords,
+		},
+		{
+			name:      "受取人名をカナに変換できない",
+			modify:    func(f *TransferFile) { f.Records[1].AccountName = "取引先B" },
+			wantField: &FieldError{Record: 2, Field: "account name"},
+		},
+		{
+			name:      "金融機関コードが4桁でない",
+			modify:    func(f *TransferFile) { f.Records[0].BankCode = "12" },
+			wantField: &FieldError{Record: 1, Field: "bank code"},
+		},
+		{
+			name:      "口座番号が8桁",
+			modify:    func(f *TransferFile) { f.Records[0].AccountNumber = "12345678" },
+			wantField: &FieldError{Record: 1, Field: "account number"},
+		},
+		{
+			name:      "振込金額が0",
+			modify:    func(f *TransferFile) { f.Records[0].Amount = 0 },
+			wantField: &FieldError{Record: 1, Field: "amount"},
+		},
+		{
+			name:      "預金種目が不正",
+			modify:    func(f *TransferFile) { f.Records[0].AccountType = 4 },
+			wantField: &FieldError{Record: 1, Field: "account type"},
+		},
+		{
+			name:      "振込依頼人コードが数字でない",
+			modify:    func(f *TransferFile) { f.Header.RequesterCode = "ABC" },
+			wantField: &FieldError{Record: 0, Field: "requester code"},
+		},
+		{
+			name:      "取組日がない",
+			modify:    func(f *TransferFile) { f.Header.TransferDate = time.Time{} },
+			wantField: &FieldError{Record: 0, Field: "transfer date"},
+		},
+		{
+			name: "合計金額が12桁を超える",
+			modify: func(f *TransferFile) {
+				f.Records = nil
+				for i := 0; i < 101; i++ {
+					record := newTestTransferFile().Records[0]
+					record.Amount = 9999999999
+					f.Records = append(f.Records, record)
+				}
+			},
+			wantErr: ErrTotalAmountOverflow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestTransferFile()
+			tt.modify(f)
+
+			_, err := f.Encode()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("error = %v, want FieldError", err)
+			}
+			if fieldErr.Record != tt.wantField.Record || fieldErr.Field != tt.wantField.Field {
+				t.Errorf("error = %v, want record %d field %s", err, tt.wantField.Record, tt.wantField.Field)
+			}
+		})
+	}
+}